@@ -7,6 +7,7 @@ package backend
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/state"
@@ -111,6 +112,26 @@ type Operation struct {
 	PlanOutPath    string // PlanOutPath is the path to save the plan
 	PlanOutBackend *terraform.BackendState
 
+	// PlanOutSkipEmpty, if true, skips writing PlanOutPath when the plan
+	// has no changes. Any existing file at that path is removed so that a
+	// stale plan can't be applied by mistake.
+	PlanOutSkipEmpty bool
+
+	// PlanHighlightNew, if true, marks the resources that the plan creates
+	// for the first time, with no prior state, when showing the plan.
+	PlanHighlightNew bool
+
+	// PlanDenyDestroy, if true, fails a plan that would destroy any
+	// resources, including by replacing them. PlanDenyReplace only fails
+	// a plan that would replace resources.
+	PlanDenyDestroy bool
+	PlanDenyReplace bool
+
+	// ApplyReportPath, if set, is the path to write a JSON report of the
+	// resources changed by an apply to. The report is written even if the
+	// apply fails, covering the resources that did complete.
+	ApplyReportPath string
+
 	// Module settings specify the root module to use for operations.
 	Module *module.Tree
 
@@ -124,6 +145,14 @@ type Operation struct {
 	Targets   []string
 	Variables map[string]interface{}
 
+	// TargetDepth, if non-nil, limits how many resources away from
+	// a target dependencies are included. See terraform.ContextOpts.
+	TargetDepth *int
+
+	// TargetModules are modules whose resources, including those of their
+	// child modules, are all targeted. See terraform.ContextOpts.
+	TargetModules []string
+
 	// Input/output/control options.
 	UIIn  terraform.UIInput
 	UIOut terraform.UIOutput
@@ -132,6 +161,20 @@ type Operation struct {
 	// state.Lockers for its duration, and Unlock when complete.
 	LockState bool
 
+	// LockTimeout is how long to keep retrying to acquire the state lock
+	// if it is held by someone else. If zero, only a single attempt is
+	// made.
+	LockTimeout time.Duration
+
+	// WarnUnusedVariables, if true, makes validation warn about variables
+	// that are declared but never referenced. See terraform.ContextOpts.
+	WarnUnusedVariables bool
+
+	// CompactWarnings, if true, asks the backend to render warnings as a
+	// short summary with one line per distinct warning. Errors are always
+	// shown in full.
+	CompactWarnings bool
+
 	// Environment is the named state that should be loaded from the Backend.
 	Environment string
 }
@@ -155,6 +198,10 @@ type RunningOperation struct {
 	// to note whether a plan is empty or has changes.
 	PlanEmpty bool
 
+	// ApplyEmpty is populated after an Apply operation completes without
+	// error to note whether no resources were added, changed or destroyed.
+	ApplyEmpty bool
+
 	// State is the final state after the operation completed. Persisting
 	// this state is managed by the backend. This should only be read
 	// after the operation completes to avoid read/write races.
@@ -37,12 +37,16 @@ func (b *Local) opApply(
 	// Setup our count hook that keeps track of resource changes
 	countHook := new(CountHook)
 	stateHook := new(StateHook)
+	reportHook := new(ReportHook)
 	if b.ContextOpts == nil {
 		b.ContextOpts = new(terraform.ContextOpts)
 	}
 	old := b.ContextOpts.Hooks
 	defer func() { b.ContextOpts.Hooks = old }()
 	b.ContextOpts.Hooks = append(b.ContextOpts.Hooks, countHook, stateHook)
+	if op.ApplyReportPath != "" {
+		b.ContextOpts.Hooks = append(b.ContextOpts.Hooks, reportHook)
+	}
 
 	// Get our context
 	tfCtx, opState, err := b.context(op)
@@ -54,7 +58,10 @@ func (b *Local) opApply(
 	if op.LockState {
 		lockInfo := state.NewLockInfo()
 		lockInfo.Operation = op.Type.String()
-		lockID, err := clistate.Lock(opState, lockInfo, b.CLI, b.Colorize())
+		lockCtx, cancel := context.WithTimeout(ctx, op.LockTimeout)
+		defer cancel()
+
+		lockID, err := clistate.Lock(lockCtx, opState, lockInfo, b.CLI, b.Colorize())
 		if err != nil {
 			runningOp.Err = errwrap.Wrapf("Error locking state: {{err}}", err)
 			return
@@ -92,6 +99,7 @@ func (b *Local) opApply(
 
 	// Setup our hook for continuous state updates
 	stateHook.State = opState
+	stateHook.Hooks = b.ContextOpts.Hooks
 
 	// Start the apply in a goroutine so that we can be interrupted.
 	var applyState *terraform.State
@@ -130,12 +138,17 @@ func (b *Local) opApply(
 	// Store the final state
 	runningOp.State = applyState
 
-	// Persist the state
-	if err := opState.WriteState(applyState); err != nil {
-		runningOp.Err = fmt.Errorf("Failed to save state: %s", err)
-		return
+	// Write the report before anything else can fail so that it covers
+	// the resources that completed even if the apply did not.
+	var reportErr error
+	if op.ApplyReportPath != "" {
+		if err := reportHook.WriteReport(op.ApplyReportPath, applyErr); err != nil {
+			reportErr = fmt.Errorf("Failed to write apply report: %s", err)
+		}
 	}
-	if err := opState.PersistState(); err != nil {
+
+	// Persist the state
+	if err := b.persistState(opState, applyState); err != nil {
 		runningOp.Err = fmt.Errorf("Failed to save state: %s", err)
 		return
 	}
@@ -149,9 +162,21 @@ func (b *Local) opApply(
 				"any resources that successfully completed. Please address the error\n"+
 				"above and apply again to incrementally change your infrastructure.",
 			multierror.Flatten(applyErr))
+		if reportErr != nil {
+			runningOp.Err = multierror.Append(runningOp.Err, reportErr)
+		}
+		return
+	}
+
+	if reportErr != nil {
+		runningOp.Err = reportErr
 		return
 	}
 
+	// Only count resources that were actually changed, so that a diff that
+	// just reads data sources is still reported as empty.
+	runningOp.ApplyEmpty = countHook.Added+countHook.Changed+countHook.Removed == 0
+
 	// If we have a UI, output the results
 	if b.CLI != nil {
 		if op.Destroy {
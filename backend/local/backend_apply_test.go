@@ -45,12 +45,69 @@ func TestLocal_applyBasic(t *testing.T) {
 		t.Fatal("apply should be called")
 	}
 
+	if run.ApplyEmpty {
+		t.Fatal("apply should not be empty")
+	}
+
 	checkState(t, b.StateOutPath, `
 test_instance.foo:
   ID = yes
 	`)
 }
 
+func TestLocal_applyDataSourceOnly(t *testing.T) {
+	b := TestLocal(t)
+	p := TestLocalProvider(t, b, "test")
+	p.DataSourcesReturn = []terraform.DataSource{
+		terraform.DataSource{Name: "test_ds"},
+	}
+	p.ReadDataApplyReturn = &terraform.InstanceState{ID: "yes"}
+
+	mod, modCleanup := module.TestTree(t, "./test-fixtures/apply-data")
+	defer modCleanup()
+
+	op := testOperationApply()
+	op.Plan = &terraform.Plan{
+		Module: mod,
+		Diff: &terraform.Diff{
+			Modules: []*terraform.ModuleDiff{
+				&terraform.ModuleDiff{
+					Path: []string{"root"},
+					Resources: map[string]*terraform.InstanceDiff{
+						"data.test_ds.foo": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"id": &terraform.ResourceAttrDiff{
+									NewComputed: true,
+									RequiresNew: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		State: terraform.NewState(),
+	}
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Err != nil {
+		t.Fatalf("err: %s", run.Err)
+	}
+
+	if !p.ReadDataApplyCalled {
+		t.Fatal("data source should be read")
+	}
+
+	// Reading a data source doesn't change any resources
+	if !run.ApplyEmpty {
+		t.Fatal("apply should be empty")
+	}
+}
+
 func TestLocal_applyEmptyDir(t *testing.T) {
 	b := TestLocal(t)
 	p := TestLocalProvider(t, b, "test")
@@ -158,6 +215,107 @@ test_instance.foo:
 	`)
 }
 
+func TestLocal_applyStateWriteHooks(t *testing.T) {
+	b := TestLocal(t)
+	p := TestLocalProvider(t, b, "test")
+	hook := new(testStateWriteHook)
+	b.ContextOpts.Hooks = append(b.ContextOpts.Hooks, hook)
+
+	p.ApplyReturn = &terraform.InstanceState{ID: "yes"}
+
+	mod, modCleanup := module.TestTree(t, "./test-fixtures/apply-multi")
+	defer modCleanup()
+
+	op := testOperationApply()
+	op.Module = mod
+
+	run, err := b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Err != nil {
+		t.Fatalf("err: %s", run.Err)
+	}
+
+	// Each intermediate write of a resource's state gets its own hook
+	// calls, plus the final persist.
+	if hook.Updates < 3 {
+		t.Fatalf("bad: %d updates", hook.Updates)
+	}
+	if len(hook.Pre) != hook.Updates+1 || len(hook.Post) != hook.Updates+1 {
+		t.Fatalf("bad: %d updates, pre %v, post %v", hook.Updates, hook.Pre, hook.Post)
+	}
+	applied := len(hook.Post)
+
+	// Refresh into a different ID so the state changes again
+	p.RefreshFn = nil
+	p.RefreshReturn = &terraform.InstanceState{ID: "changed"}
+
+	op = testOperationRefresh()
+	op.Module = mod
+
+	run, err = b.Operation(context.Background(), op)
+	if err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+	<-run.Done()
+	if run.Err != nil {
+		t.Fatalf("err: %s", run.Err)
+	}
+
+	if len(hook.Pre) != applied+1 || len(hook.Post) != applied+1 {
+		t.Fatalf("bad: pre %v, post %v", hook.Pre, hook.Post)
+	}
+	if hook.Post[applied] <= hook.Post[applied-1] {
+		t.Fatalf("serial should increment: %v", hook.Post)
+	}
+
+	checkState(t, b.StateOutPath, `
+test_instance.bar:
+  ID = changed
+test_instance.baz:
+  ID = changed
+test_instance.foo:
+  ID = changed
+	`)
+}
+
+// testStateWriteHook records the serials seen by the state write hooks
+// and counts the state updates.
+type testStateWriteHook struct {
+	terraform.NilHook
+	sync.Mutex
+
+	Pre     []int64
+	Post    []int64
+	Updates int
+}
+
+func (h *testStateWriteHook) PostStateUpdate(s *terraform.State) (terraform.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.Updates++
+	return terraform.HookActionContinue, nil
+}
+
+func (h *testStateWriteHook) PreStateWrite(s *terraform.State) (terraform.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.Pre = append(h.Pre, s.Serial)
+	return terraform.HookActionContinue, nil
+}
+
+func (h *testStateWriteHook) PostStateWrite(s *terraform.State) (terraform.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.Post = append(h.Post, s.Serial)
+	return terraform.HookActionContinue, nil
+}
+
 func testOperationApply() *backend.Operation {
 	return &backend.Operation{
 		Type: backend.OperationTypeApply,
@@ -42,7 +42,10 @@ func (b *Local) context(op *backend.Operation) (*terraform.Context, state.State,
 	opts.Destroy = op.Destroy
 	opts.Module = op.Module
 	opts.Targets = op.Targets
+	opts.TargetDepth = op.TargetDepth
+	opts.TargetModules = op.TargetModules
 	opts.UIInput = op.UIIn
+	opts.WarnUnusedVariables = op.WarnUnusedVariables
 	if op.Variables != nil {
 		opts.Variables = op.Variables
 	}
@@ -86,9 +89,13 @@ func (b *Local) context(op *backend.Operation) (*terraform.Context, state.State,
 
 				// If we have a CLI, output the warnings
 				if b.CLI != nil {
-					b.CLI.Warn(strings.TrimSpace(validateWarnHeader) + "\n")
-					for _, w := range ws {
-						b.CLI.Warn(fmt.Sprintf("  * %s", w))
+					if op.CompactWarnings {
+						b.outputCompactWarnings(ws)
+					} else {
+						b.CLI.Warn(strings.TrimSpace(validateWarnHeader) + "\n")
+						for _, w := range ws {
+							b.CLI.Warn(fmt.Sprintf("  * %s", w))
+						}
 					}
 
 					// Make a newline before continuing
@@ -105,6 +112,81 @@ func (b *Local) context(op *backend.Operation) (*terraform.Context, state.State,
 	return tfCtx, s, nil
 }
 
+// outputCompactWarnings writes a summary of the given warnings to the CLI.
+// Only the first line of each warning is shown and identical lines are
+// collapsed into one, annotated with the number of occurrences.
+func (b *Local) outputCompactWarnings(ws []string) {
+	var order []string
+	counts := make(map[string]int)
+	for _, w := range ws {
+		line := strings.TrimSpace(w)
+		if idx := strings.Index(line, "\n"); idx >= 0 {
+			line = strings.TrimSpace(line[:idx])
+		}
+
+		if _, ok := counts[line]; !ok {
+			order = append(order, line)
+		}
+		counts[line]++
+	}
+
+	b.CLI.Warn(fmt.Sprintf(
+		strings.TrimSpace(validateCompactWarnHeader)+"\n", len(ws)))
+	for _, line := range order {
+		if n := counts[line]; n > 1 {
+			line = fmt.Sprintf("%s (%d occurrences)", line, n)
+		}
+
+		b.CLI.Warn(fmt.Sprintf("  - %s", line))
+	}
+}
+
+// persistState writes and persists the given state, calling the
+// PreStateWrite and PostStateWrite hooks around it. Errors returned by a
+// hook abort the write; halt actions are ignored since the operation has
+// already completed by the time state is persisted.
+func (b *Local) persistState(s state.State, newState *terraform.State) error {
+	var hooks []terraform.Hook
+	if b.ContextOpts != nil {
+		hooks = b.ContextOpts.Hooks
+	}
+
+	return writeStateHooks(hooks, s, newState, true)
+}
+
+// writeStateHooks writes the given state, and persists it if persist is
+// true, calling the PreStateWrite and PostStateWrite hooks around it.
+func writeStateHooks(
+	hooks []terraform.Hook,
+	s state.State,
+	newState *terraform.State,
+	persist bool) error {
+	for _, h := range hooks {
+		if _, err := h.PreStateWrite(newState); err != nil {
+			return err
+		}
+	}
+
+	if err := s.WriteState(newState); err != nil {
+		return err
+	}
+	if persist {
+		if err := s.PersistState(); err != nil {
+			return err
+		}
+	}
+
+	// Pass the written state so hooks see the new serial
+	written := s.State()
+	for _, h := range hooks {
+		if _, err := h.PostStateWrite(written); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 const validateWarnHeader = `
 There are warnings related to your configuration. If no errors occurred,
 Terraform will continue despite these warnings. It is a good idea to resolve
@@ -112,3 +194,8 @@ these warnings in the near future.
 
 Warnings:
 `
+
+const validateCompactWarnHeader = `
+There are %d warnings related to your configuration. Run the command again
+without -compact-warnings to see the full warning messages:
+`
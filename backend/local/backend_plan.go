@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/errwrap"
@@ -12,6 +13,7 @@ import (
 	"github.com/hashicorp/terraform/backend"
 	"github.com/hashicorp/terraform/command/format"
 	clistate "github.com/hashicorp/terraform/command/state"
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/state"
 	"github.com/hashicorp/terraform/terraform"
@@ -63,7 +65,10 @@ func (b *Local) opPlan(
 	if op.LockState {
 		lockInfo := state.NewLockInfo()
 		lockInfo.Operation = op.Type.String()
-		lockID, err := clistate.Lock(opState, lockInfo, b.CLI, b.Colorize())
+		lockCtx, cancel := context.WithTimeout(ctx, op.LockTimeout)
+		defer cancel()
+
+		lockID, err := clistate.Lock(lockCtx, opState, lockInfo, b.CLI, b.Colorize())
 		if err != nil {
 			runningOp.Err = errwrap.Wrapf("Error locking state: {{err}}", err)
 			return
@@ -105,11 +110,58 @@ func (b *Local) opPlan(
 	// Record state
 	runningOp.PlanEmpty = plan.Diff.Empty()
 
+	// Fail before the plan is written if it makes changes we were asked
+	// to deny, and make sure no stale plan is left behind in its place.
+	if op.PlanDenyDestroy || op.PlanDenyReplace {
+		denied := planDeniedChanges(plan, op.PlanDenyDestroy, op.PlanDenyReplace)
+		if len(denied) > 0 {
+			flag := "-deny-replace"
+			if op.PlanDenyDestroy {
+				flag = "-deny-destroy"
+			}
+
+			if path := op.PlanOutPath; path != "" {
+				if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+					runningOp.Err = fmt.Errorf("Error removing stale plan file: %s", err)
+					return
+				}
+			}
+
+			runningOp.Err = fmt.Errorf(
+				strings.TrimSpace(planErrDenied), flag, strings.Join(denied, "\n  "))
+			return
+		}
+	}
+
+	// Don't write an empty plan if we were asked not to, and make sure
+	// no stale plan is left behind in its place.
+	skipOut := op.PlanOutPath != "" && op.PlanOutSkipEmpty && runningOp.PlanEmpty
+	if skipOut {
+		log.Printf("[INFO] backend/local: plan is empty, not writing: %s", op.PlanOutPath)
+		if err := os.Remove(op.PlanOutPath); err != nil && !os.IsNotExist(err) {
+			runningOp.Err = fmt.Errorf("Error removing stale plan file: %s", err)
+			return
+		}
+	}
+
 	// Save the plan to disk
-	if path := op.PlanOutPath; path != "" {
+	if path := op.PlanOutPath; path != "" && !skipOut {
 		// Write the backend if we have one
 		plan.Backend = op.PlanOutBackend
 
+		// Record the configuration the plan was created from so that
+		// apply can detect if it changed.
+		if cfg := op.Module.Config(); cfg != nil && cfg.Dir != "" {
+			sum, err := config.DirChecksum(cfg.Dir)
+			if err != nil {
+				runningOp.Err = errwrap.Wrapf(
+					"Error computing configuration checksum: {{err}}", err)
+				return
+			}
+
+			plan.ConfigChecksum = sum
+		}
+
 		log.Printf("[INFO] backend/local: writing plan output to: %s", path)
 		f, err := os.Create(path)
 		if err == nil {
@@ -126,6 +178,10 @@ func (b *Local) opPlan(
 	if b.CLI != nil {
 		if plan.Diff.Empty() {
 			b.CLI.Output(b.Colorize().Color(strings.TrimSpace(planNoChanges)))
+			if skipOut {
+				b.CLI.Output(fmt.Sprintf(
+					"\n"+strings.TrimSpace(planSkippedOutput), op.PlanOutPath))
+			}
 			return
 		}
 
@@ -138,9 +194,10 @@ func (b *Local) opPlan(
 		}
 
 		b.CLI.Output(format.Plan(&format.PlanOpts{
-			Plan:        plan,
-			Color:       b.Colorize(),
-			ModuleDepth: -1,
+			Plan:         plan,
+			Color:        b.Colorize(),
+			ModuleDepth:  -1,
+			HighlightNew: op.PlanHighlightNew,
 		}))
 
 		b.CLI.Output(b.Colorize().Color(fmt.Sprintf(
@@ -152,6 +209,52 @@ func (b *Local) opPlan(
 	}
 }
 
+// planDeniedChanges returns a sorted list of the resources that the given
+// plan would destroy or replace, as allowed by denyDestroy and
+// denyReplace, along with the action. A replacement counts as a destroy.
+// Data sources are never included since they don't manage anything.
+func planDeniedChanges(plan *terraform.Plan, denyDestroy, denyReplace bool) []string {
+	if plan.Diff == nil {
+		return nil
+	}
+
+	var result []string
+	for _, m := range plan.Diff.Modules {
+		prefix := ""
+		if len(m.Path) > 1 {
+			prefix = "module." + strings.Join(m.Path[1:], ".module.") + "."
+		}
+
+		for k, d := range m.Resources {
+			if strings.HasPrefix(k, "data.") {
+				continue
+			}
+
+			switch d.ChangeType() {
+			case terraform.DiffDestroy:
+				if denyDestroy {
+					result = append(result, fmt.Sprintf("%s%s (destroy)", prefix, k))
+				}
+			case terraform.DiffDestroyCreate:
+				if denyDestroy || denyReplace {
+					result = append(result, fmt.Sprintf("%s%s (replace)", prefix, k))
+				}
+			}
+		}
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+const planErrDenied = `
+The plan would destroy or replace the resources below, which %s
+does not allow. No plan was saved, and any plan already at the -out path
+was removed.
+
+  %s
+`
+
 const planErrNoConfig = `
 No configuration files found!
 
@@ -195,6 +298,10 @@ configuration and real physical resources that exist. As a result, Terraform
 doesn't need to do anything.
 `
 
+const planSkippedOutput = `
+The plan is empty, so it was not saved to %q.
+`
+
 const planRefreshing = `
 [reset][bold]Refreshing Terraform state in-memory prior to plan...[reset]
 The refreshed state will be used to calculate this plan, but will not be
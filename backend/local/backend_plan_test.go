@@ -132,6 +132,10 @@ func TestLocal_planDestroy(t *testing.T) {
 			}
 		}
 	}
+
+	if plan.ConfigChecksum == "" {
+		t.Fatal("plan should record the configuration checksum")
+	}
 }
 
 func TestLocal_planDestroyNoConfig(t *testing.T) {
@@ -53,7 +53,10 @@ func (b *Local) opRefresh(
 	if op.LockState {
 		lockInfo := state.NewLockInfo()
 		lockInfo.Operation = op.Type.String()
-		lockID, err := clistate.Lock(opState, lockInfo, b.CLI, b.Colorize())
+		lockCtx, cancel := context.WithTimeout(ctx, op.LockTimeout)
+		defer cancel()
+
+		lockID, err := clistate.Lock(lockCtx, opState, lockInfo, b.CLI, b.Colorize())
 		if err != nil {
 			runningOp.Err = errwrap.Wrapf("Error locking state: {{err}}", err)
 			return
@@ -84,11 +87,7 @@ func (b *Local) opRefresh(
 	}
 
 	// Write and persist the state
-	if err := opState.WriteState(newState); err != nil {
-		runningOp.Err = errwrap.Wrapf("Error writing state: {{err}}", err)
-		return
-	}
-	if err := opState.PersistState(); err != nil {
+	if err := b.persistState(opState, newState); err != nil {
 		runningOp.Err = errwrap.Wrapf("Error saving state: {{err}}", err)
 		return
 	}
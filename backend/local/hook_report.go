@@ -0,0 +1,157 @@
+package local
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+// ReportHook is a hook that records the resources changed during the
+// course of an apply so that they can be written out as a report. Only
+// the changes that completed without error are recorded.
+type ReportHook struct {
+	pending   map[string]*ApplyReportResource
+	resources map[string]*ApplyReportResource
+
+	sync.Mutex
+	terraform.NilHook
+}
+
+// ApplyReport is the machine-readable record of an apply written by
+// ReportHook.
+type ApplyReport struct {
+	// Resources are the resource instances that were changed, sorted
+	// by address.
+	Resources []*ApplyReportResource `json:"resources"`
+
+	// Error is the error that the apply failed with, if any. Resources
+	// then only contains the changes that did complete.
+	Error string `json:"error,omitempty"`
+}
+
+// ApplyReportResource is a single resource instance that was changed.
+// Action is one of "create", "update", "destroy" or "replace".
+type ApplyReportResource struct {
+	Address  string `json:"address"`
+	Action   string `json:"action"`
+	BeforeID string `json:"before_id"`
+	AfterID  string `json:"after_id"`
+}
+
+func (h *ReportHook) PreApply(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState,
+	d *terraform.InstanceDiff) (terraform.HookAction, error) {
+	// We don't report anything for data sources
+	if strings.HasPrefix(n.Id, "data.") {
+		return terraform.HookActionContinue, nil
+	}
+
+	h.Lock()
+	defer h.Unlock()
+
+	if h.pending == nil {
+		h.pending = make(map[string]*ApplyReportResource)
+	}
+
+	r := &ApplyReportResource{
+		Address: n.HumanId(),
+		Action:  "update",
+	}
+	if s != nil {
+		r.BeforeID = s.ID
+	}
+	if d.GetDestroy() {
+		r.Action = "destroy"
+	} else if r.BeforeID == "" {
+		r.Action = "create"
+	}
+
+	h.pending[r.Address] = r
+
+	return terraform.HookActionContinue, nil
+}
+
+func (h *ReportHook) PostApply(
+	n *terraform.InstanceInfo,
+	s *terraform.InstanceState,
+	e error) (terraform.HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	r, ok := h.pending[n.HumanId()]
+	if !ok {
+		return terraform.HookActionContinue, nil
+	}
+	delete(h.pending, n.HumanId())
+
+	if e != nil {
+		return terraform.HookActionContinue, nil
+	}
+
+	if r.Action != "destroy" && s != nil {
+		r.AfterID = s.ID
+	}
+
+	if h.resources == nil {
+		h.resources = make(map[string]*ApplyReportResource)
+	}
+
+	// A replaced resource is destroyed and created separately, in either
+	// order depending on create_before_destroy, so merge the two.
+	if prev, ok := h.resources[r.Address]; ok && prev.Action != r.Action {
+		created, destroyed := r, prev
+		if r.Action == "destroy" {
+			created, destroyed = prev, r
+		}
+
+		r = &ApplyReportResource{
+			Address:  r.Address,
+			Action:   "replace",
+			BeforeID: destroyed.BeforeID,
+			AfterID:  created.AfterID,
+		}
+	}
+
+	h.resources[r.Address] = r
+
+	return terraform.HookActionContinue, nil
+}
+
+// Report returns the report of the changes recorded so far. The given
+// error is the error the apply failed with, if any.
+func (h *ReportHook) Report(applyErr error) *ApplyReport {
+	h.Lock()
+	defer h.Unlock()
+
+	report := &ApplyReport{
+		Resources: make([]*ApplyReportResource, 0, len(h.resources)),
+	}
+	for _, r := range h.resources {
+		report.Resources = append(report.Resources, r)
+	}
+	sort.Slice(report.Resources, func(i, j int) bool {
+		return report.Resources[i].Address < report.Resources[j].Address
+	})
+
+	if applyErr != nil {
+		report.Error = applyErr.Error()
+	}
+
+	return report
+}
+
+// WriteReport writes the report of the changes recorded so far to the
+// given path as JSON.
+func (h *ReportHook) WriteReport(path string, applyErr error) error {
+	data, err := json.MarshalIndent(h.Report(applyErr), "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,57 @@
+package local
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+)
+
+func TestReportHook_impl(t *testing.T) {
+	var _ terraform.Hook = new(ReportHook)
+}
+
+func TestReportHook(t *testing.T) {
+	h := new(ReportHook)
+
+	apply := func(id string, before, after string, d *terraform.InstanceDiff, err error) {
+		n := &terraform.InstanceInfo{Id: id}
+		h.PreApply(n, &terraform.InstanceState{ID: before}, d)
+
+		var s *terraform.InstanceState
+		if after != "" {
+			s = &terraform.InstanceState{ID: after}
+		}
+		h.PostApply(n, s, err)
+	}
+
+	apply("test_instance.create", "", "c1", &terraform.InstanceDiff{}, nil)
+	apply("test_instance.update", "u1", "u1", &terraform.InstanceDiff{}, nil)
+	apply("test_instance.destroy", "d1", "", &terraform.InstanceDiff{Destroy: true}, nil)
+	apply("test_instance.failed", "", "", &terraform.InstanceDiff{}, fmt.Errorf("error"))
+	apply("data.test_data.read", "", "r1", &terraform.InstanceDiff{}, nil)
+
+	// Destroy and then create, as when replacing a resource
+	apply("test_instance.replace", "r1", "", &terraform.InstanceDiff{Destroy: true}, nil)
+	apply("test_instance.replace", "", "r2", &terraform.InstanceDiff{}, nil)
+
+	// Create and then destroy, as with create_before_destroy
+	apply("test_instance.cbd", "", "b2", &terraform.InstanceDiff{}, nil)
+	apply("test_instance.cbd", "b1", "", &terraform.InstanceDiff{Destroy: true}, nil)
+
+	actual := h.Report(fmt.Errorf("error"))
+	expected := &ApplyReport{
+		Resources: []*ApplyReportResource{
+			{Address: "test_instance.cbd", Action: "replace", BeforeID: "b1", AfterID: "b2"},
+			{Address: "test_instance.create", Action: "create", AfterID: "c1"},
+			{Address: "test_instance.destroy", Action: "destroy", BeforeID: "d1"},
+			{Address: "test_instance.replace", Action: "replace", BeforeID: "r1", AfterID: "r2"},
+			{Address: "test_instance.update", Action: "update", BeforeID: "u1", AfterID: "u1"},
+		},
+		Error: "error",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad:\n%#v\n\nexpected:\n%#v", actual, expected)
+	}
+}
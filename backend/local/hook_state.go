@@ -9,11 +9,15 @@ import (
 
 // StateHook is a hook that continuously updates the state by calling
 // WriteState on a state.State.
+//
+// The PreStateWrite and PostStateWrite hooks of Hooks are called around
+// each write.
 type StateHook struct {
 	terraform.NilHook
 	sync.Mutex
 
 	State state.State
+	Hooks []terraform.Hook
 }
 
 func (h *StateHook) PostStateUpdate(
@@ -23,7 +27,7 @@ func (h *StateHook) PostStateUpdate(
 
 	if h.State != nil {
 		// Write the new state
-		if err := h.State.WriteState(s); err != nil {
+		if err := writeStateHooks(h.Hooks, h.State, s, false); err != nil {
 			return terraform.HookActionHalt, err
 		}
 	}
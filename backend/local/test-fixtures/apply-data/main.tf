@@ -0,0 +1 @@
+data "test_ds" "foo" {}
@@ -93,6 +93,12 @@ func (c *RemoteClient) putLockInfo(info *state.LockInfo) error {
 	return err
 }
 
+// LockInfo returns the lock info stored next to the state, which only
+// exists while the state is locked.
+func (c *RemoteClient) LockInfo() (*state.LockInfo, error) {
+	return c.getLockInfo()
+}
+
 func (c *RemoteClient) getLockInfo() (*state.LockInfo, error) {
 	path := c.Path + lockInfoSuffix
 	pair, _, err := c.Client.KV().Get(path, nil)
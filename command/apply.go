@@ -29,7 +29,8 @@ type ApplyCommand struct {
 }
 
 func (c *ApplyCommand) Run(args []string) int {
-	var destroyForce, refresh bool
+	var destroyForce, planForce, refresh, compactWarnings, detailed bool
+	var reportPath string
 	args = c.Meta.process(args, true)
 
 	cmdName := "apply"
@@ -40,14 +41,23 @@ func (c *ApplyCommand) Run(args []string) int {
 	cmdFlags := c.Meta.flagSet(cmdName)
 	if c.Destroy {
 		cmdFlags.BoolVar(&destroyForce, "force", false, "force")
+		cmdFlags.BoolVar(&destroyForce, "auto-approve", false, "auto-approve")
+	} else {
+		cmdFlags.BoolVar(&planForce, "force", false, "force")
+		cmdFlags.IntVar(&c.Meta.retryFailed, "retry-failed", 0, "retry-failed")
+		cmdFlags.BoolVar(&c.Meta.recordApplyTime, "record-apply-time", false, "record-apply-time")
 	}
 	cmdFlags.BoolVar(&refresh, "refresh", true, "refresh")
+	cmdFlags.BoolVar(&compactWarnings, "compact-warnings", false, "compact-warnings")
+	cmdFlags.BoolVar(&detailed, "detailed-exitcode", false, "detailed-exitcode")
 	cmdFlags.IntVar(
 		&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
 	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
+	cmdFlags.StringVar(&reportPath, "report", "", "path")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -105,6 +115,15 @@ func (c *ApplyCommand) Run(args []string) int {
 	if plan != nil {
 		// Reset the config path for backend loading
 		configPath = ""
+
+		// Make sure the configuration the plan was created from hasn't
+		// changed since, unless we've been told to apply it anyways.
+		if !planForce {
+			if err := checkPlanConfig(plan); err != nil {
+				c.Ui.Error(err.Error())
+				return 1
+			}
+		}
 	}
 
 	// Load the module if we don't have one yet (not running from plan)
@@ -135,6 +154,7 @@ func (c *ApplyCommand) Run(args []string) int {
 	b, err := c.Backend(&BackendOpts{
 		ConfigPath: configPath,
 		Plan:       plan,
+		PlanForce:  planForce,
 	})
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Failed to load backend: %s", err))
@@ -148,8 +168,16 @@ func (c *ApplyCommand) Run(args []string) int {
 		desc := "Terraform will delete all your managed infrastructure.\n" +
 			"There is no undo. Only 'yes' will be accepted to confirm."
 
-		// If targets are specified, list those to user
+		// If targets are specified, list those to user along with anything
+		// that will be destroyed because it depends on them.
 		if c.Meta.targets != nil {
+			dependents, err := c.destroyDependents(b, mod)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf(
+					"Error determining resources that depend on the targets: %s", err))
+				return 1
+			}
+
 			var descBuffer bytes.Buffer
 			descBuffer.WriteString("Terraform will delete the following infrastructure:\n")
 			for _, target := range c.Meta.targets {
@@ -157,6 +185,14 @@ func (c *ApplyCommand) Run(args []string) int {
 				descBuffer.WriteString(target)
 				descBuffer.WriteString("\n")
 			}
+			if len(dependents) > 0 {
+				descBuffer.WriteString("The following resources depend on the targets and will also be deleted:\n")
+				for _, dep := range dependents {
+					descBuffer.WriteString("\t")
+					descBuffer.WriteString(dep)
+					descBuffer.WriteString("\n")
+				}
+			}
 			descBuffer.WriteString("There is no undo. Only 'yes' will be accepted to confirm")
 			desc = descBuffer.String()
 		}
@@ -184,6 +220,9 @@ func (c *ApplyCommand) Run(args []string) int {
 	opReq.PlanRefresh = refresh
 	opReq.Type = backend.OperationTypeApply
 	opReq.LockState = c.Meta.stateLock
+	opReq.LockTimeout = c.Meta.stateLockTimeout
+	opReq.CompactWarnings = compactWarnings
+	opReq.ApplyReportPath = reportPath
 
 	// Perform the operation
 	ctx, ctxCancel := context.WithCancel(context.Background())
@@ -231,6 +270,12 @@ func (c *ApplyCommand) Run(args []string) int {
 		}
 	}
 
+	// Nothing changed gets its own exit code, the same one plan uses for
+	// an empty plan with -skip-empty-out, so automation can tell.
+	if detailed && op.ApplyEmpty {
+		return 3
+	}
+
 	return 0
 }
 
@@ -274,8 +319,25 @@ Options:
                          modifying. Defaults to the "-state-out" path with
                          ".backup" extension. Set to "-" to disable backup.
 
+  -compact-warnings      If set, warnings are shown as a short summary with
+                         one line per distinct warning. Errors are still
+                         shown in full.
+
+  -detailed-exitcode     Return detailed exit codes when the command exits.
+                         This will change the meaning of exit codes to:
+                         0 - Succeeded, changes were applied
+                         1 - Errored
+                         3 - Succeeded, there were no changes to apply
+
+  -force                 Apply a plan file even if the state or configuration
+                         it was created from have changed since. Only the
+                         root module configuration is checked; changes to
+                         child modules aren't detected.
+
   -lock=true             Lock the state file when locking is supported.
 
+  -lock-timeout=0s       Duration to retry a state lock.
+
   -input=true            Ask for input for variables if not directly set.
 
   -no-color              If specified, output won't contain any color.
@@ -283,9 +345,21 @@ Options:
   -parallelism=n         Limit the number of parallel resource operations.
                          Defaults to 10.
 
+  -record-apply-time     Record the time each resource is created or updated
+                         in the state as "last_applied".
+
   -refresh=true          Update state prior to checking for differences. This
                          has no effect if a plan file is given to apply.
 
+  -report=path           Write a JSON report of the resources changed by the
+                         apply, with their actions and IDs before and after,
+                         to path. The report is written even if the apply
+                         fails, covering the resources that did complete.
+
+  -retry-failed=n        Retry resources that failed with an error the
+                         provider reports as transient up to n times once
+                         the rest of the apply has completed. Defaults to 0.
+
   -state=path            Path to read and save state (unless state-out
                          is specified). Defaults to "terraform.tfstate".
 
@@ -295,7 +369,12 @@ Options:
 
   -target=resource       Resource to target. Operation will be limited to this
                          resource and its dependencies. This flag can be used
-                         multiple times.
+                         multiple times. If a plan file is given, only the
+                         matching part of the plan is applied.
+
+  -target-depth=n        Limit the dependencies included by -target to those
+                         at most n resources away from a target. 0 means only
+                         the targets themselves. Defaults to no limit.
 
   -var 'foo=bar'         Set a variable in the Terraform configuration. This
                          flag can be set multiple times.
@@ -321,10 +400,20 @@ Options:
                          modifying. Defaults to the "-state-out" path with
                          ".backup" extension. Set to "-" to disable backup.
 
+  -auto-approve          Same as -force.
+
+  -detailed-exitcode     Return detailed exit codes when the command exits.
+                         This will change the meaning of exit codes to:
+                         0 - Succeeded, resources were destroyed
+                         1 - Errored
+                         3 - Succeeded, there was nothing to destroy
+
   -force                 Don't ask for input for destroy confirmation.
 
   -lock=true             Lock the state file when locking is supported.
 
+  -lock-timeout=0s       Duration to retry a state lock.
+
   -no-color              If specified, output won't contain any color.
 
   -parallelism=n         Limit the number of concurrent operations.
@@ -333,6 +422,10 @@ Options:
   -refresh=true          Update state prior to checking for differences. This
                          has no effect if a plan file is given to apply.
 
+  -report=path           Write a JSON report of the resources destroyed to
+                         path. The report is written even if the destroy
+                         fails, covering the resources that did complete.
+
   -state=path            Path to read and save state (unless state-out
                          is specified). Defaults to "terraform.tfstate".
 
@@ -356,6 +449,122 @@ Options:
 	return strings.TrimSpace(helpText)
 }
 
+// checkPlanConfig returns an error if the configuration the plan was
+// created from has changed since the plan was created. If the plan didn't
+// record a checksum or the configuration isn't around anymore, there is
+// nothing to compare against and the plan is used as-is.
+//
+// Only the root module is compared, so changes to the sources of child
+// modules aren't detected.
+func checkPlanConfig(plan *terraform.Plan) error {
+	if plan.ConfigChecksum == "" || plan.Module == nil {
+		return nil
+	}
+
+	cfg := plan.Module.Config()
+	if cfg == nil || cfg.Dir == "" {
+		return nil
+	}
+	empty, err := config.IsEmptyDir(cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("Error checking plan configuration: %s", err)
+	}
+	if empty {
+		return nil
+	}
+
+	sum, err := config.DirChecksum(cfg.Dir)
+	if err != nil {
+		return fmt.Errorf("Error checking plan configuration: %s", err)
+	}
+	if sum != plan.ConfigChecksum {
+		return fmt.Errorf(strings.TrimSpace(errApplyPlanConfigChanged), cfg.Dir)
+	}
+
+	return nil
+}
+
+// destroyDependents returns the addresses of the resources that a targeted
+// destroy will delete only because they depend on one of the targets. This
+// is the difference between the full destroy plan and a destroy plan of
+// just the targets themselves.
+func (c *ApplyCommand) destroyDependents(b backend.Backend, mod *module.Tree) ([]string, error) {
+	// We need a local backend to plan. Other backends will still ask for
+	// confirmation, but only with the targets listed.
+	local, ok := b.(backend.Local)
+	if !ok {
+		return nil, nil
+	}
+
+	all, err := c.destroyPlanAddrs(local, mod, c.Meta.targetDepthOpt())
+	if err != nil {
+		return nil, err
+	}
+
+	depth := 0
+	targeted, err := c.destroyPlanAddrs(local, mod, &depth)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for addr := range all {
+		if _, ok := targeted[addr]; !ok {
+			result = append(result, addr)
+		}
+	}
+	sort.Strings(result)
+
+	return result, nil
+}
+
+// destroyPlanAddrs builds a destroy plan of the targets, including
+// dependents up to the given depth, and returns the addresses of the
+// resources it would destroy.
+func (c *ApplyCommand) destroyPlanAddrs(
+	local backend.Local, mod *module.Tree, depth *int) (map[string]struct{}, error) {
+	opReq := c.Operation()
+	opReq.Destroy = true
+	opReq.Module = mod
+	opReq.TargetDepth = depth
+
+	ctx, _, err := local.Context(opReq)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]struct{})
+	if plan.Diff == nil {
+		return result, nil
+	}
+
+	for _, md := range plan.Diff.Modules {
+		var prefix string
+		if len(md.Path) > 1 {
+			prefix = "module." + strings.Join(md.Path[1:], ".module.") + "."
+		}
+
+		for k, rd := range md.Resources {
+			if rd.GetDestroy() {
+				result[prefix+k] = struct{}{}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+const errApplyPlanConfigChanged = `
+The configuration in %s has changed since this
+plan was created. Please create a new plan file from the latest configuration
+and try again, or apply with "-force" to use this plan anyways.
+`
+
 func outputsAsString(state *terraform.State, modPath []string, schema []*config.Output, includeHeader bool) string {
 	if state == nil {
 		return ""
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"bytes"
 	"os"
 	"strings"
 	"testing"
@@ -262,3 +263,78 @@ func TestApply_destroyTargeted(t *testing.T) {
 const testApplyDestroyStr = `
 <no state>
 `
+
+func TestApply_destroyTargetedDependents(t *testing.T) {
+	// Disable test mode so input would be asked
+	test = false
+	defer func() { test = true }()
+
+	// Answer "no" to the confirmation and capture what was asked
+	defaultInputReader = bytes.NewBufferString("no\n")
+	inputWriter := new(bytes.Buffer)
+	defaultInputWriter = inputWriter
+
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "i-ab123",
+						},
+					},
+					"test_load_balancer.foo": &terraform.ResourceState{
+						Type: "test_load_balancer",
+						Primary: &terraform.InstanceState{
+							ID: "lb-abc123",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, originalState)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Destroy: true,
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-target", "test_instance.foo",
+		"-state", statePath,
+		testFixturePath("apply-destroy-targeted"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The dependent load balancer should be listed, but the target
+	// itself shouldn't be listed as a dependent.
+	prompt := inputWriter.String()
+	expected := "will also be deleted:\n  \ttest_load_balancer.foo\n"
+	if !strings.Contains(prompt, expected) {
+		t.Fatalf("bad: %s", prompt)
+	}
+	if strings.Count(prompt, "test_instance.foo") != 1 {
+		t.Fatalf("bad: %s", prompt)
+	}
+
+	if !strings.Contains(ui.OutputWriter.String(), "Destroy cancelled") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+	if p.ApplyCalled {
+		t.Fatal("apply should not be called")
+	}
+
+	// The state should be untouched
+	testStateOutput(t, statePath, originalState.String())
+}
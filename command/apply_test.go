@@ -2,6 +2,7 @@ package command
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net"
@@ -15,6 +16,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/hashicorp/go-getter"
+	"github.com/hashicorp/terraform/backend/local"
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/state"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
@@ -59,7 +64,115 @@ func TestApply(t *testing.T) {
 	}
 }
 
+func TestApply_compactWarnings(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	p.ValidateResourceReturnWarns = []string{
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+	}
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-compact-warnings",
+		"-state", statePath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := ui.ErrorWriter.String()
+	if !strings.Contains(actual, "deprecated attribute (2 occurrences)") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if strings.Contains(actual, "will be removed") {
+		t.Fatalf("details should be omitted: %s", actual)
+	}
+}
+
 // test apply with locked state
+func TestApply_detailedExitcode(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	p.DiffReturn = &terraform.InstanceDiff{
+		Attributes: map[string]*terraform.ResourceAttrDiff{
+			"ami": &terraform.ResourceAttrDiff{
+				New: "bar",
+			},
+		},
+	}
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-detailed-exitcode",
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if !p.ApplyCalled {
+		t.Fatal("apply should be called")
+	}
+}
+
+func TestApply_detailedExitcodeNoop(t *testing.T) {
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+	statePath := testStateFile(t, originalState)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-detailed-exitcode",
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 3 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if p.ApplyCalled {
+		t.Fatal("apply should not be called")
+	}
+}
+
 func TestApply_lockedState(t *testing.T) {
 	statePath := testTempFile(t)
 
@@ -92,6 +205,40 @@ func TestApply_lockedState(t *testing.T) {
 	}
 }
 
+func TestApply_lockedStateWait(t *testing.T) {
+	statePath := testTempFile(t)
+
+	unlock, err := testLockState("./testdata", statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// unlock during apply
+	go func() {
+		time.Sleep(500 * time.Millisecond)
+		unlock()
+	}()
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// wait long enough for the lock to be retried after it is released
+	args := []string{
+		"-state", statePath,
+		"-lock-timeout", "4s",
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("lock should have succeeded in less than 4s: %s", ui.ErrorWriter)
+	}
+}
+
 // high water mark counter
 type hwm struct {
 	sync.Mutex
@@ -341,6 +488,111 @@ func TestApply_error(t *testing.T) {
 	}
 }
 
+func TestApply_report(t *testing.T) {
+	statePath := testTempFile(t)
+	reportPath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+	p.ApplyReturn = &terraform.InstanceState{ID: "foo"}
+
+	args := []string{
+		"-state", statePath,
+		"-report", reportPath,
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := testApplyReport(t, reportPath)
+	expected := &local.ApplyReport{
+		Resources: []*local.ApplyReportResource{
+			{Address: "test_instance.foo", Action: "create", AfterID: "foo"},
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestApply_reportError(t *testing.T) {
+	statePath := testTempFile(t)
+	reportPath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	p.ApplyFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		d *terraform.InstanceDiff) (*terraform.InstanceState, error) {
+		if info.Id == "test_instance.bar" {
+			return nil, fmt.Errorf("error")
+		}
+
+		return &terraform.InstanceState{ID: "foo"}, nil
+	}
+	p.DiffFn = func(
+		*terraform.InstanceInfo,
+		*terraform.InstanceState,
+		*terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+		return &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"ami": &terraform.ResourceAttrDiff{
+					New: "bar",
+				},
+			},
+		}, nil
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-report", reportPath,
+		testFixturePath("apply-error"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := testApplyReport(t, reportPath)
+	if !strings.Contains(actual.Error, "error") {
+		t.Fatalf("bad: %#v", actual)
+	}
+	expected := []*local.ApplyReportResource{
+		{Address: "test_instance.foo", Action: "create", AfterID: "foo"},
+	}
+	if !reflect.DeepEqual(actual.Resources, expected) {
+		t.Fatalf("bad: %#v", actual.Resources)
+	}
+}
+
+func testApplyReport(t *testing.T, path string) *local.ApplyReport {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var report local.ApplyReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("err: %s\n\n%s", err, data)
+	}
+
+	return &report
+}
+
 func TestApply_init(t *testing.T) {
 	// Change to the temporary directory
 	cwd, err := os.Getwd()
@@ -529,6 +781,83 @@ func TestApply_noArgs(t *testing.T) {
 	}
 }
 
+func TestApply_retryFailed(t *testing.T) {
+	statePath := testTempFile(t)
+
+	// Fail the first attempt with a transient error
+	attempts := 0
+	p := testProvider()
+	p.ApplyFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		d *terraform.InstanceDiff) (*terraform.InstanceState, error) {
+		attempts++
+		if attempts == 1 {
+			return nil, terraform.NewRetryableError(fmt.Errorf("timeout"))
+		}
+
+		return &terraform.InstanceState{ID: "foo"}, nil
+	}
+
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-retry-failed", "1",
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if attempts != 2 {
+		t.Fatalf("bad: %d", attempts)
+	}
+
+	state := testStateRead(t, statePath)
+	if state.RootModule().Resources["test_instance.foo"] == nil {
+		t.Fatalf("bad: %s", state)
+	}
+}
+
+func TestApply_recordApplyTime(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	p.ApplyReturn = &terraform.InstanceState{ID: "foo"}
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-record-apply-time",
+		testFixturePath("apply"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	state := testStateRead(t, statePath)
+	rs := state.RootModule().Resources["test_instance.foo"]
+	if rs == nil {
+		t.Fatalf("bad: %s", state)
+	}
+	if _, err := time.Parse(time.RFC3339, rs.LastApplied); err != nil {
+		t.Fatalf("bad: %q: %s", rs.LastApplied, err)
+	}
+}
+
 func TestApply_plan(t *testing.T) {
 	// Disable test mode so input would be asked
 	test = false
@@ -777,6 +1106,152 @@ func TestApply_planWithVarFile(t *testing.T) {
 	}
 }
 
+func TestApply_planConfigChanged(t *testing.T) {
+	td := testTempDir(t)
+	defer os.RemoveAll(td)
+	configPath := filepath.Join(td, "main.tf")
+	if err := ioutil.WriteFile(configPath, []byte(testApplyPlanConfig), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	mod, err := module.NewTreeModule("", td)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := mod.Load(&getter.FolderStorage{StorageDir: tempDir(t)}, module.GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	sum, err := config.DirChecksum(td)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module:         mod,
+		ConfigChecksum: sum,
+	})
+
+	// Change the configuration after the plan was created
+	if err := ioutil.WriteFile(configPath, []byte(testApplyPlanConfig+"\n"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Applying the plan is refused
+	{
+		statePath := testTempFile(t)
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &ApplyCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-state-out", statePath,
+			planPath,
+		}
+		if code := c.Run(args); code != 1 {
+			t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "has changed since") {
+			t.Fatalf("bad: %s", ui.ErrorWriter.String())
+		}
+		if p.ApplyCalled {
+			t.Fatal("apply should not be called")
+		}
+	}
+
+	// Applying the plan with -force works
+	{
+		statePath := testTempFile(t)
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &ApplyCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-force",
+			"-state-out", statePath,
+			planPath,
+		}
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+		}
+		if _, err := os.Stat(statePath); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+}
+
+func TestApply_planConfigUnreadable(t *testing.T) {
+	// The configuration "directory" is a file, so it can't be read
+	path := testTempFile(t)
+	if err := ioutil.WriteFile(path, []byte(testApplyPlanConfig), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	plan := &terraform.Plan{
+		Module:         module.NewTree("", &config.Config{Dir: path}),
+		ConfigChecksum: "abc",
+	}
+	err := checkPlanConfig(plan)
+	if err == nil || !strings.Contains(err.Error(), "Error checking plan configuration") {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestApply_planConfigUnchanged(t *testing.T) {
+	td := testTempDir(t)
+	defer os.RemoveAll(td)
+	if err := ioutil.WriteFile(filepath.Join(td, "main.tf"), []byte(testApplyPlanConfig), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	mod, err := module.NewTreeModule("", td)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := mod.Load(&getter.FolderStorage{StorageDir: tempDir(t)}, module.GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	sum, err := config.DirChecksum(td)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module:         mod,
+		ConfigChecksum: sum,
+	})
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state-out", statePath,
+		planPath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestApply_planVars(t *testing.T) {
 	planPath := testPlanFile(t, &terraform.Plan{
 		Module: testModule(t, "apply"),
@@ -802,6 +1277,80 @@ func TestApply_planVars(t *testing.T) {
 	}
 }
 
+func TestApply_planTargeted(t *testing.T) {
+	diff := &terraform.Diff{
+		Modules: []*terraform.ModuleDiff{
+			&terraform.ModuleDiff{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.InstanceDiff{
+					"test_instance.foo": &terraform.InstanceDiff{
+						Attributes: map[string]*terraform.ResourceAttrDiff{
+							"ami": &terraform.ResourceAttrDiff{New: "foo"},
+						},
+					},
+					"test_instance.bar": &terraform.InstanceDiff{
+						Attributes: map[string]*terraform.ResourceAttrDiff{
+							"ami": &terraform.ResourceAttrDiff{New: "bar"},
+						},
+					},
+				},
+			},
+		},
+	}
+	planPath := testPlanFile(t, &terraform.Plan{
+		Module: testModule(t, "apply-plan-targeted"),
+		Diff:   diff,
+		State:  terraform.NewState(),
+	})
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	p.DiffFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+		return diff.RootModule().Resources[info.Id].Copy()
+	}
+	var applied []string
+	p.ApplyFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		d *terraform.InstanceDiff) (*terraform.InstanceState, error) {
+		applied = append(applied, info.Id)
+		return &terraform.InstanceState{ID: info.Id}, nil
+	}
+
+	ui := new(cli.MockUi)
+	c := &ApplyCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state-out", statePath,
+		"-target", "test_instance.foo",
+		planPath,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if !reflect.DeepEqual(applied, []string{"test_instance.foo"}) {
+		t.Fatalf("bad: %#v", applied)
+	}
+
+	state := testStateRead(t, statePath)
+	resources := state.RootModule().Resources
+	if _, ok := resources["test_instance.foo"]; !ok {
+		t.Fatalf("test_instance.foo should be in the state:\n%s", state)
+	}
+	if _, ok := resources["test_instance.bar"]; ok {
+		t.Fatalf("test_instance.bar should not be in the state:\n%s", state)
+	}
+}
+
 func TestApply_refresh(t *testing.T) {
 	originalState := &terraform.State{
 		Modules: []*terraform.ModuleState{
@@ -1667,3 +2216,9 @@ const testApplyStateDiffStr = `
 ID = bar
 Tainted = false
 `
+
+const testApplyPlanConfig = `
+resource "test_instance" "foo" {
+    ami = "bar"
+}
+`
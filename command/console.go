@@ -23,7 +23,9 @@ type ConsoleCommand struct {
 
 func (c *ConsoleCommand) Run(args []string) int {
 	args = c.Meta.process(args, true)
+	var command string
 	cmdFlags := c.Meta.flagSet("console")
+	cmdFlags.StringVar(&command, "command", "", "expression")
 	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
@@ -79,6 +81,11 @@ func (c *ConsoleCommand) Run(args []string) int {
 		Interpolater: ctx.Interpolater(),
 	}
 
+	// If an expression was given, evaluate just that one and exit
+	if command != "" {
+		return c.modeCommand(session, ui, command)
+	}
+
 	// Determine if stdin is a pipe. If so, we evaluate directly.
 	if c.StdinPiped() {
 		return c.modePiped(session, ui)
@@ -87,12 +94,36 @@ func (c *ConsoleCommand) Run(args []string) int {
 	return c.modeInteractive(session, ui)
 }
 
+func (c *ConsoleCommand) modeCommand(session *repl.Session, ui cli.Ui, command string) int {
+	result, err := session.Handle(strings.TrimSpace(command))
+	if err == repl.ErrSessionExit {
+		return 0
+	}
+	if err != nil {
+		ui.Error(err.Error())
+		return 1
+	}
+
+	ui.Output(result)
+
+	return 0
+}
+
 func (c *ConsoleCommand) modePiped(session *repl.Session, ui cli.Ui) int {
 	var lastResult string
+	var input []string
 	scanner := bufio.NewScanner(wrappedstreams.Stdin())
 	for scanner.Scan() {
+		// Keep reading lines until the expression is complete
+		input = append(input, strings.TrimSpace(scanner.Text()))
+		line := strings.Join(input, "\n")
+		if !repl.InputComplete(line) {
+			continue
+		}
+		input = nil
+
 		// Handle it. If there is an error exit immediately
-		result, err := session.Handle(strings.TrimSpace(scanner.Text()))
+		result, err := session.Handle(line)
 		if err != nil {
 			ui.Error(err.Error())
 			return 1
@@ -102,6 +133,15 @@ func (c *ConsoleCommand) modePiped(session *repl.Session, ui cli.Ui) int {
 		lastResult = result
 	}
 
+	// An unfinished expression at the end of the input is still handled
+	// so that the error for it is shown.
+	if len(input) > 0 {
+		if _, err := session.Handle(strings.Join(input, "\n")); err != nil {
+			ui.Error(err.Error())
+			return 1
+		}
+	}
+
 	// Output the final result
 	ui.Output(lastResult)
 
@@ -122,11 +162,17 @@ Usage: terraform console [options] [DIR]
 
   This command will never modify your state.
 
+  Expressions may span multiple lines: input is read until all the
+  parentheses and brackets are closed.
+
   DIR can be set to a directory with a Terraform state to load. By
   default, this will default to the current working directory.
 
 Options:
 
+  -command='expr'        Evaluate a single expression, print the result and
+                         exit instead of starting the interactive console.
+
   -state=path            Path to read state. Defaults to "terraform.tfstate"
 
   -var 'foo=bar'         Set a variable in the Terraform configuration. This
@@ -8,6 +8,7 @@ package command
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/hashicorp/terraform/helper/wrappedreadline"
 	"github.com/hashicorp/terraform/repl"
@@ -32,10 +33,18 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 	}
 	defer l.Close()
 
+	var input []string
 	for {
 		// Read a line
 		line, err := l.Readline()
 		if err == readline.ErrInterrupt {
+			// Interrupting an unfinished expression discards it
+			if len(input) > 0 {
+				input = nil
+				l.SetPrompt("> ")
+				continue
+			}
+
 			if len(line) == 0 {
 				break
 			} else {
@@ -45,6 +54,16 @@ func (c *ConsoleCommand) modeInteractive(session *repl.Session, ui cli.Ui) int {
 			break
 		}
 
+		// Keep reading lines until the expression is complete
+		input = append(input, line)
+		line = strings.Join(input, "\n")
+		if !repl.InputComplete(line) {
+			l.SetPrompt("... ")
+			continue
+		}
+		input = nil
+		l.SetPrompt("> ")
+
 		out, err := session.Handle(line)
 		if err == repl.ErrSessionExit {
 			break
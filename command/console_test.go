@@ -84,3 +84,119 @@ func TestConsole_tfvars(t *testing.T) {
 		t.Fatalf("bad: %q", actual)
 	}
 }
+
+func TestConsole_state(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	originalState := testState()
+	originalState.RootModule().Resources["test_instance.foo"].Primary.Attributes = map[string]string{
+		"id":  "bar",
+		"ami": "ami-123456",
+	}
+	statePath := testStateFile(t, originalState)
+
+	cases := map[string]string{
+		"test_instance.foo.ami":     "ami-123456\n",
+		"test_instance.foo.missing": "<computed>\n",
+	}
+
+	for input, expected := range cases {
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &ConsoleCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		var output bytes.Buffer
+		closeStdin := testStdinPipe(t, strings.NewReader(input+"\n"))
+		outCloser := testStdoutCapture(t, &output)
+
+		args := []string{
+			"-state", statePath,
+		}
+		code := c.Run(args)
+		outCloser()
+		closeStdin()
+		if code != 0 {
+			t.Fatalf("%s: bad: %d\n\n%s", input, code, ui.ErrorWriter.String())
+		}
+
+		actual := output.String()
+		if actual != expected {
+			t.Fatalf("%s: bad: %q", input, actual)
+		}
+	}
+}
+
+func TestConsole_multiline(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ConsoleCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	input := `lookup(map(
+  "a", "1",
+  "b", element(list("2", "3"), 1)
+), "b")
+`
+
+	var output bytes.Buffer
+	defer testStdinPipe(t, strings.NewReader(input))()
+	outCloser := testStdoutCapture(t, &output)
+
+	args := []string{}
+	code := c.Run(args)
+	outCloser()
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := output.String()
+	if actual != "3\n" {
+		t.Fatalf("bad: %q", actual)
+	}
+}
+
+func TestConsole_command(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ConsoleCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	var output bytes.Buffer
+	outCloser := testStdoutCapture(t, &output)
+
+	args := []string{
+		"-var", "foo=bar",
+		"-command", "var.foo",
+		testFixturePath("apply-vars"),
+	}
+	code := c.Run(args)
+	outCloser()
+	if code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := output.String()
+	if actual != "bar\n" {
+		t.Fatalf("bad: %q", actual)
+	}
+}
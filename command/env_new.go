@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
@@ -86,7 +87,10 @@ func (c *EnvNewCommand) Run(args []string) int {
 	// Lock the state if we can
 	lockInfo := state.NewLockInfo()
 	lockInfo.Operation = "env new"
-	lockID, err := clistate.Lock(sMgr, lockInfo, c.Ui, c.Colorize())
+	lockCtx, cancel := context.WithTimeout(context.Background(), c.Meta.stateLockTimeout)
+	defer cancel()
+
+	lockID, err := clistate.Lock(lockCtx, sMgr, lockInfo, c.Ui, c.Colorize())
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf("Error locking state: %s", err))
 		return 1
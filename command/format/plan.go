@@ -21,6 +21,11 @@ type PlanOpts struct {
 	// ModuleDepth is the depth of the modules to expand. By default this
 	// is zero which will not expand modules at all.
 	ModuleDepth int
+
+	// HighlightNew, if true, marks the resources that are created for the
+	// first time, with no prior state, so they stand out from resources
+	// that are replaced or changed.
+	HighlightNew bool
 }
 
 // Plan takes a plan and returns a
@@ -79,6 +84,10 @@ func formatPlanModuleExpand(
 		}
 
 		dataSource := strings.HasPrefix(name, "data.")
+		isNew := false
+		if opts.HighlightNew && !dataSource {
+			isNew = !planHasPriorState(opts.Plan, m.Path, name)
+		}
 
 		if moduleName != "" {
 			name = moduleName + "." + name
@@ -115,6 +124,9 @@ func formatPlanModuleExpand(
 		}
 
 		var extraAttr []string
+		if isNew && rdiff.ChangeType() == terraform.DiffCreate {
+			extraAttr = append(extraAttr, "new")
+		}
 		if rdiff.DestroyTainted {
 			extraAttr = append(extraAttr, "tainted")
 		}
@@ -196,6 +208,22 @@ func formatPlanModuleExpand(
 	}
 }
 
+// planHasPriorState returns true if the state the plan was created from
+// has the named resource in the module with the given path.
+func planHasPriorState(p *terraform.Plan, path []string, name string) bool {
+	if p.State == nil {
+		return false
+	}
+
+	mod := p.State.ModuleByPath(path)
+	if mod == nil {
+		return false
+	}
+
+	rs, ok := mod.Resources[name]
+	return ok && rs != nil && rs.Primary != nil
+}
+
 // formatPlanModuleSingle will output the given module and all of its
 // resources.
 func formatPlanModuleSingle(
@@ -168,3 +168,144 @@ func TestPlan_nestedDataSource(t *testing.T) {
 		t.Fatalf("expected:\n\n%s\n\ngot:\n\n%s", expected, actual)
 	}
 }
+
+// Test that only resources without prior state are marked as new
+func TestPlan_highlightNew(t *testing.T) {
+	plan := &terraform.Plan{
+		Diff: &terraform.Diff{
+			Modules: []*terraform.ModuleDiff{
+				&terraform.ModuleDiff{
+					Path: []string{"root"},
+					Resources: map[string]*terraform.InstanceDiff{
+						// Created for the first time
+						"aws_instance.new": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{
+									New:         "foo",
+									RequiresNew: true,
+								},
+							},
+						},
+						// Changed in place
+						"aws_instance.changed": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"tags": &terraform.ResourceAttrDiff{
+									Old: "a",
+									New: "b",
+								},
+							},
+						},
+						// Replaced
+						"aws_instance.replaced": &terraform.InstanceDiff{
+							Destroy: true,
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{
+									Old:         "foo",
+									New:         "bar",
+									RequiresNew: true,
+								},
+							},
+						},
+						// Read for the first time
+						"data.aws_ami.new": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"id": &terraform.ResourceAttrDiff{
+									NewComputed: true,
+									RequiresNew: true,
+								},
+							},
+						},
+					},
+				},
+				&terraform.ModuleDiff{
+					Path: []string{"root", "child"},
+					Resources: map[string]*terraform.InstanceDiff{
+						"aws_instance.new": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{
+									New:         "foo",
+									RequiresNew: true,
+								},
+							},
+						},
+						// In the state, but being created again without
+						// destroying it first
+						"aws_instance.existing": &terraform.InstanceDiff{
+							Attributes: map[string]*terraform.ResourceAttrDiff{
+								"ami": &terraform.ResourceAttrDiff{
+									New:         "foo",
+									RequiresNew: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		State: &terraform.State{
+			Modules: []*terraform.ModuleState{
+				&terraform.ModuleState{
+					Path: []string{"root"},
+					Resources: map[string]*terraform.ResourceState{
+						"aws_instance.changed": &terraform.ResourceState{
+							Type:    "aws_instance",
+							Primary: &terraform.InstanceState{ID: "changed"},
+						},
+						"aws_instance.replaced": &terraform.ResourceState{
+							Type:    "aws_instance",
+							Primary: &terraform.InstanceState{ID: "replaced"},
+						},
+					},
+				},
+				&terraform.ModuleState{
+					Path: []string{"root", "child"},
+					Resources: map[string]*terraform.ResourceState{
+						"aws_instance.existing": &terraform.ResourceState{
+							Type:    "aws_instance",
+							Primary: &terraform.InstanceState{ID: "existing"},
+						},
+					},
+				},
+			},
+		},
+	}
+	opts := &PlanOpts{
+		Plan: plan,
+		Color: &colorstring.Colorize{
+			Colors:  colorstring.DefaultColors,
+			Disable: true,
+		},
+		ModuleDepth:  -1,
+		HighlightNew: true,
+	}
+
+	actual := Plan(opts)
+
+	expected := strings.TrimSpace(`
+~ aws_instance.changed
+    tags: "a" => "b"
+
++ aws_instance.new (new)
+    ami: "foo"
+
+-/+ aws_instance.replaced
+    ami: "foo" => "bar" (forces new resource)
+
+<= data.aws_ami.new
+
++ module.child.aws_instance.existing
+    ami: "foo"
+
++ module.child.aws_instance.new (new)
+    ami: "foo"
+	`)
+	if actual != expected {
+		t.Fatalf("expected:\n\n%s\n\ngot:\n\n%s", expected, actual)
+	}
+
+	// Nothing is marked by default
+	opts.HighlightNew = false
+	if actual := Plan(opts); strings.Contains(actual, "(new)") {
+		t.Fatalf("should not highlight new resources:\n\n%s", actual)
+	}
+}
@@ -152,8 +152,9 @@ Usage: terraform graph [options] [DIR]
 
 Options:
 
-  -draw-cycles   Highlight any cycles in the graph with colored edges.
-                 This helps when diagnosing cycle errors.
+  -draw-cycles   Highlight any cycles in the graph by coloring the nodes
+                 and edges that are part of a cycle red. This helps when
+                 diagnosing cycle errors.
 
   -no-color      If specified, output won't contain any color.
 
@@ -34,6 +34,64 @@ func TestGraph(t *testing.T) {
 	}
 }
 
+func TestGraph_drawCycles(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-draw-cycles",
+		testFixturePath("graph-cycle"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	for _, expected := range []string{
+		`"[root] test_instance.foo" [color = "red"`,
+		`"[root] test_instance.bar" -> "[root] test_instance.foo" [color = "red"`,
+		`"[root] test_instance.foo" -> "[root] test_instance.bar" [color = "red"`,
+	} {
+		if !strings.Contains(output, expected) {
+			t.Fatalf("expected %q in output:\n%s", expected, output)
+		}
+	}
+}
+
+func TestGraph_drawCyclesNoCycle(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	ui := new(cli.MockUi)
+	c := &GraphCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-draw-cycles",
+		testFixturePath("graph"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if strings.Contains(output, `color = "red"`) {
+		t.Fatalf("no cycles should be highlighted:\n%s", output)
+	}
+}
+
 func TestGraph_multipleArgs(t *testing.T) {
 	ui := new(cli.MockUi)
 	c := &GraphCommand{
@@ -268,7 +268,6 @@ func (h *UiHook) ProvisionOutput(
 	msg string) {
 	id := n.HumanId()
 	var buf bytes.Buffer
-	buf.WriteString(h.Colorize.Color("[reset]"))
 
 	prefix := fmt.Sprintf("%s (%s): ", id, provId)
 	s := bufio.NewScanner(strings.NewReader(msg))
@@ -280,7 +279,12 @@ func (h *UiHook) ProvisionOutput(
 		}
 	}
 
-	h.ui.Output(strings.TrimSpace(buf.String()))
+	// Blank lines of output aren't worth a line of their own
+	if buf.Len() == 0 {
+		return
+	}
+
+	h.ui.Output(h.Colorize.Color("[reset]") + strings.TrimSpace(buf.String()))
 }
 
 func (h *UiHook) PreRefresh(
@@ -331,6 +335,15 @@ func (h *UiHook) PostImportState(
 	return terraform.HookActionContinue, nil
 }
 
+func (h *UiHook) PostStateMove(from, to string) (terraform.HookAction, error) {
+	h.once.Do(h.init)
+
+	h.ui.Output(h.Colorize.Color(fmt.Sprintf(
+		"[reset][bold]%s: Moved to %s", from, to)))
+
+	return terraform.HookActionContinue, nil
+}
+
 func (h *UiHook) init() {
 	if h.Colorize == nil {
 		panic("colorize not given")
@@ -1,14 +1,17 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/backend"
 	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/terraform"
+	"github.com/ryanuber/columnize"
 )
 
 // ImportCommand is a cli.Command implementation that imports resources
@@ -26,6 +29,7 @@ func (c *ImportCommand) Run(args []string) int {
 	}
 
 	var configPath string
+	var showAttrs, jsonOutput, checkReplace bool
 	args = c.Meta.process(args, true)
 
 	cmdFlags := c.Meta.flagSet("import")
@@ -35,11 +39,18 @@ func (c *ImportCommand) Run(args []string) int {
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
 	cmdFlags.StringVar(&configPath, "config", pwd, "path")
 	cmdFlags.StringVar(&c.Meta.provider, "provider", "", "provider")
+	cmdFlags.BoolVar(&showAttrs, "show-attrs", false, "show-attrs")
+	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&checkReplace, "check-replace", false, "check-replace")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
 
+	// The JSON must be the only thing on the output, so the progress
+	// of the import isn't shown.
+	c.Meta.noUiHook = jsonOutput
+
 	args = cmdFlags.Args()
 	if len(args) != 2 {
 		c.Ui.Error("The import command expects two arguments.")
@@ -83,6 +94,10 @@ func (c *ImportCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Remember what was in the state before so we can tell what the
+	// import added.
+	oldState := state.State()
+
 	// Perform the import. Note that as you can see it is possible for this
 	// API to import more than one resource at once. For now, we only allow
 	// one while we stabilize this feature.
@@ -111,6 +126,36 @@ func (c *ImportCommand) Run(args []string) int {
 		return 1
 	}
 
+	checkReplace = checkReplace && mod != nil
+	if showAttrs || jsonOutput || checkReplace {
+		imported, err := importedResources(oldState, newState)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Error reading imported resources: %s", err))
+			return 1
+		}
+
+		// Plan right away to warn about resources that the configuration
+		// would replace, since applying that would destroy what was just
+		// imported.
+		if checkReplace {
+			replaced, err := importReplacements(ctx, imported)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Error planning imported resources: %s", err))
+				return 1
+			}
+
+			c.outputReplacements(replaced)
+		}
+
+		if jsonOutput {
+			return c.outputJSON(imported)
+		}
+
+		if showAttrs {
+			c.outputAttrs(imported)
+		}
+	}
+
 	c.Ui.Output(c.Colorize().Color(fmt.Sprintf(
 		"[reset][green]\n" +
 			"Import success! The resources imported are shown above. These are\n" +
@@ -122,6 +167,167 @@ func (c *ImportCommand) Run(args []string) int {
 	return 0
 }
 
+// outputReplacements warns about each imported resource that the next
+// plan would replace, listing the attributes forcing the replacement.
+func (c *ImportCommand) outputReplacements(replaced map[string][]string) {
+	addrs := make([]string, 0, len(replaced))
+	for addr := range replaced {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+
+	for _, addr := range addrs {
+		msg := fmt.Sprintf(
+			"Warning: %s will be replaced by the next apply because the\n"+
+				"imported attributes differ from the configuration.", addr)
+		if attrs := replaced[addr]; len(attrs) > 0 {
+			msg += fmt.Sprintf(" Attributes forcing the replacement:\n\n  %s",
+				strings.Join(attrs, "\n  "))
+		}
+
+		c.Ui.Warn(c.Colorize().Color("[reset][yellow]" + msg + "\n"))
+	}
+}
+
+// outputAttrs prints the attributes of each imported resource in the
+// same format as "terraform state show".
+func (c *ImportCommand) outputAttrs(imported []*terraform.StateFilterResult) {
+	config := columnize.DefaultConfig()
+	config.Glue = " = "
+	config.Prefix = "  "
+
+	for _, r := range imported {
+		is := r.Value.(*terraform.ResourceState).Primary
+
+		var keys []string
+		for k, _ := range is.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		output := []string{fmt.Sprintf("id | %s", is.ID)}
+		for _, k := range keys {
+			if k != "id" {
+				output = append(output, fmt.Sprintf("%s | %s", k, is.Attributes[k]))
+			}
+		}
+
+		c.Ui.Output(fmt.Sprintf("%s:\n%s", r.Address, columnize.Format(output, config)))
+	}
+}
+
+// outputJSON prints the attributes of each imported resource as a JSON
+// object keyed by resource address.
+func (c *ImportCommand) outputJSON(imported []*terraform.StateFilterResult) int {
+	result := make(map[string]map[string]string, len(imported))
+	for _, r := range imported {
+		is := r.Value.(*terraform.ResourceState).Primary
+
+		attrs := make(map[string]string, len(is.Attributes)+1)
+		for k, v := range is.Attributes {
+			attrs[k] = v
+		}
+		attrs["id"] = is.ID
+
+		result[r.Address] = attrs
+	}
+
+	jsonOut, err := json.MarshalIndent(result, "", "    ")
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error encoding imported resources: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(string(jsonOut))
+	return 0
+}
+
+// importReplacements plans with ctx and returns the imported resources
+// that the plan would replace, each mapped to the sorted names of the
+// attributes forcing the replacement.
+func importReplacements(
+	ctx *terraform.Context,
+	imported []*terraform.StateFilterResult) (map[string][]string, error) {
+	plan, err := ctx.Plan()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string][]string)
+	for _, r := range imported {
+		addr, err := terraform.ParseResourceAddress(r.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		path := append([]string{"root"}, addr.Path...)
+		mod := plan.Diff.ModuleByPath(path)
+		if mod == nil {
+			continue
+		}
+
+		key := &terraform.ResourceStateKey{
+			Name:  addr.Name,
+			Type:  addr.Type,
+			Mode:  addr.Mode,
+			Index: addr.Index,
+		}
+		d, ok := mod.Resources[key.String()]
+		if !ok || !d.RequiresNew() {
+			continue
+		}
+
+		attrs := make([]string, 0)
+		for k, attr := range d.CopyAttributes() {
+			if attr.RequiresNew {
+				attrs = append(attrs, k)
+			}
+		}
+		sort.Strings(attrs)
+
+		result[r.Address] = attrs
+	}
+
+	return result, nil
+}
+
+// importedResources returns the resources in newState that weren't in
+// oldState, which is the set of resources added by an import.
+func importedResources(oldState, newState *terraform.State) ([]*terraform.StateFilterResult, error) {
+	existing := make(map[string]struct{})
+	if oldState != nil {
+		results, err := (&terraform.StateFilter{State: oldState}).Filter()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range results {
+			if _, ok := r.Value.(*terraform.ResourceState); ok {
+				existing[r.Address] = struct{}{}
+			}
+		}
+	}
+
+	results, err := (&terraform.StateFilter{State: newState}).Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []*terraform.StateFilterResult
+	for _, r := range results {
+		rs, ok := r.Value.(*terraform.ResourceState)
+		if !ok || rs.Primary == nil {
+			continue
+		}
+
+		if _, ok := existing[r.Address]; !ok {
+			imported = append(imported, r)
+		}
+	}
+
+	return imported, nil
+}
+
 func (c *ImportCommand) Help() string {
 	helpText := `
 Usage: terraform import [options] ADDR ID
@@ -155,6 +361,11 @@ Options:
                       modifying. Defaults to the "-state-out" path with
                       ".backup" extension. Set to "-" to disable backup.
 
+  -check-replace      If specified, plan the imported resources against the
+                      configuration after the import and warn about any
+                      that would be replaced, listing the attributes that
+                      force the replacement.
+
   -config=path        Path to a directory of Terraform configuration files
                       to use to configure the provider. Defaults to pwd.
                       If no config files are present, they must be provided
@@ -162,12 +373,20 @@ Options:
 
   -input=true         Ask for input for variables if not directly set.
 
+  -json               If specified, print the attributes of the imported
+                      resources as JSON in place of the success message.
+                      The import progress isn't shown, so the output is
+                      only the JSON.
+
   -no-color           If specified, output won't contain any color.
 
   -provider=provider  Specific provider to use for import. This is used for
                       specifying aliases, such as "aws.eu". Defaults to the
                       normal provider prefix of the resource being imported.
 
+  -show-attrs         If specified, print the attributes of each
+                      imported resource after the import completes.
+
   -state=path         Path to read and save state (unless state-out
                       is specified). Defaults to "terraform.tfstate".
 
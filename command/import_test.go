@@ -1,7 +1,10 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -46,6 +49,203 @@ func TestImport(t *testing.T) {
 	testStateOutput(t, statePath, testImportStr)
 }
 
+func TestImport_showAttrs(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ImportCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	p.ImportStateFn = nil
+	p.ImportStateReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{
+			ID: "yay",
+			Attributes: map[string]string{
+				"id":  "yay",
+				"ami": "ami-123456",
+			},
+			Ephemeral: terraform.EphemeralState{
+				Type: "test_instance",
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-show-attrs",
+		"test_instance.foo",
+		"bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := ui.OutputWriter.String()
+	expected := "test_instance.foo:\n  id  = yay\n  ami = ami-123456\n"
+	if !strings.Contains(actual, expected) {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
+
+func TestImport_showAttrsJSONMultiple(t *testing.T) {
+	statePath := testTempFile(t)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &ImportCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	p.ImportStateFn = nil
+	p.ImportStateReturn = []*terraform.InstanceState{
+		&terraform.InstanceState{
+			ID: "yay",
+			Attributes: map[string]string{
+				"ami": "ami-123456",
+			},
+			Ephemeral: terraform.EphemeralState{
+				Type: "test_instance",
+			},
+		},
+		&terraform.InstanceState{
+			ID: "rule",
+			Attributes: map[string]string{
+				"port": "80",
+			},
+			Ephemeral: terraform.EphemeralState{
+				Type: "test_instance_rule",
+			},
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-json",
+		"test_instance.foo",
+		"bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The JSON is the only output
+	output := ui.OutputWriter.String()
+	var actual map[string]map[string]string
+	if err := json.Unmarshal([]byte(output), &actual); err != nil {
+		t.Fatalf("err: %s\n\n%s", err, output)
+	}
+
+	expected := map[string]map[string]string{
+		"test_instance.foo": map[string]string{
+			"id":  "yay",
+			"ami": "ami-123456",
+		},
+		"test_instance_rule.foo": map[string]string{
+			"id":   "rule",
+			"port": "80",
+		},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestImport_checkReplace(t *testing.T) {
+	defer testChdir(t, testFixturePath("import-check-replace"))()
+
+	cases := []struct {
+		Name    string
+		Ami     string
+		Replace bool
+	}{
+		{"replace", "ami-old", true},
+		{"no replace", "ami-new", false},
+	}
+
+	for _, tc := range cases {
+		statePath := testTempFile(t)
+
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &ImportCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		p.ImportStateFn = nil
+		p.ImportStateReturn = []*terraform.InstanceState{
+			&terraform.InstanceState{
+				ID: "yay",
+				Attributes: map[string]string{
+					"ami": tc.Ami,
+				},
+				Ephemeral: terraform.EphemeralState{
+					Type: "test_instance",
+				},
+			},
+		}
+		p.DiffFn = func(
+			info *terraform.InstanceInfo,
+			s *terraform.InstanceState,
+			c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+			d := &terraform.InstanceDiff{
+				Attributes: make(map[string]*terraform.ResourceAttrDiff),
+			}
+			var old string
+			if s != nil {
+				old = s.Attributes["ami"]
+			}
+			if v, _ := c.Get("ami"); v != old {
+				d.Attributes["ami"] = &terraform.ResourceAttrDiff{
+					Old:         old,
+					New:         v.(string),
+					RequiresNew: true,
+				}
+			}
+
+			return d, nil
+		}
+
+		args := []string{
+			"-state", statePath,
+			"-check-replace",
+			"test_instance.foo",
+			"bar",
+		}
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("%s: bad: %d\n\n%s", tc.Name, code, ui.ErrorWriter.String())
+		}
+
+		if !p.DiffCalled {
+			t.Fatalf("%s: Diff should be called", tc.Name)
+		}
+
+		actual := ui.ErrorWriter.String()
+		replaced := strings.Contains(actual, "test_instance.foo will be replaced")
+		if replaced != tc.Replace {
+			t.Fatalf("%s: bad:\n\n%s", tc.Name, actual)
+		}
+		if tc.Replace && !strings.Contains(actual, "replacement:\n\n  ami") {
+			t.Fatalf("%s: attributes not listed:\n\n%s", tc.Name, actual)
+		}
+
+		// The import itself must still have succeeded
+		if !strings.Contains(ui.OutputWriter.String(), "Import success!") {
+			t.Fatalf("%s: bad:\n\n%s", tc.Name, ui.OutputWriter.String())
+		}
+	}
+}
+
 func TestImport_providerConfig(t *testing.T) {
 	defer testChdir(t, testFixturePath("import-provider"))()
 
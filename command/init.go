@@ -26,6 +26,7 @@ func (c *InitCommand) Run(args []string) int {
 	cmdFlags.BoolVar(&flagBackend, "backend", true, "")
 	cmdFlags.Var((*variables.FlagAny)(&flagConfigExtra), "backend-config", "")
 	cmdFlags.BoolVar(&flagGet, "get", true, "")
+	cmdFlags.BoolVar(&c.Meta.migrateDryRun, "dry-run", false, "")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -144,6 +145,12 @@ func (c *InitCommand) Run(args []string) int {
 				Init:        true,
 			}
 			if _, err := c.Backend(opts); err != nil {
+				if err == errBackendMigrateDryRun {
+					c.Ui.Output(c.Colorize().Color(
+						strings.TrimSpace(outputInitMigrateDryRun)))
+					return 0
+				}
+
 				c.Ui.Error(err.Error())
 				return 1
 			}
@@ -218,6 +225,11 @@ Options:
                        times. The backend type must be in the configuration
                        itself.
 
+  -dry-run             Only report how the backend would change, including
+                       the state that would be copied if any. Neither
+                       backend nor the saved backend configuration is
+                       modified.
+
   -get=true            Download any modules for this configuration.
 
   -input=true          Ask for input if necessary. If false, will error if
@@ -248,6 +260,11 @@ The directory has no Terraform configuration files. You may begin working
 with Terraform immediately by creating Terraform configuration files.
 `
 
+const outputInitMigrateDryRun = `
+[reset]No state has been copied and neither backend has been modified. Run
+"terraform init" without -dry-run to change the backend.
+`
+
 const outputInitSuccess = `
 [reset][bold][green]Terraform has been successfully initialized![reset][green]
 
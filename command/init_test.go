@@ -1,6 +1,7 @@
 package command
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -294,6 +295,140 @@ func TestInit_backendUnset(t *testing.T) {
 	}
 }
 
+func TestInit_backendMigrateDryRun(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := tempDir(t)
+	copy.CopyDir(testFixturePath("init-backend-migrate-dry-run"), td)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	savedPath := filepath.Join(DefaultDataDir, DefaultStateFilename)
+	saved, err := ioutil.ReadFile(savedPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	// No input is given, the dry run must not ask for any
+	args := []string{"-dry-run", "-input=false"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	expected := []string{
+		`Migrating state from "local" to "local" would copy 1 state(s) with 2 resource(s)`,
+		`default: 2 resource(s) into a new state in "default"`,
+		"No state has been copied",
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Fatalf("expected %q in output:\n%s", e, output)
+		}
+	}
+
+	// Nothing may have been written to the new backend
+	if _, err := os.Stat("local-state-2.tfstate"); err == nil {
+		t.Fatal("destination state should not exist")
+	}
+
+	// The saved backend configuration must be unchanged
+	actual, err := ioutil.ReadFile(savedPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(actual, saved) {
+		t.Fatalf("saved backend modified:\n%s", actual)
+	}
+
+	// The source state must still be there
+	if s := testStateRead(t, "local-state.tfstate"); s.Empty() {
+		t.Fatal("source state should not be empty")
+	}
+}
+
+func TestInit_backendMigrateDryRunMulti(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := tempDir(t)
+	copy.CopyDir(testFixturePath("init-backend-migrate-dry-run-multi"), td)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-dry-run", "-input=false"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	expected := []string{
+		"would copy 2 state(s) with 3 resource(s)",
+		`default: 1 resource(s) into a new state in "default"`,
+		`env2: 2 resource(s) into a new state in "env2"`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Fatalf("expected %q in output:\n%s", e, output)
+		}
+	}
+
+	// No environment may have been created in the new backend
+	if _, err := os.Stat("envdir-new"); err == nil {
+		t.Fatal("destination environments should not exist")
+	}
+}
+
+func TestInit_backendDryRunNothingToMigrate(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := tempDir(t)
+	copy.CopyDir(testFixturePath("init-backend"), td)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	ui := new(cli.MockUi)
+	c := &InitCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(testProvider()),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{"-dry-run", "-input=false"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: \n%s", ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	expected := []string{
+		`There is no state to migrate. Without -dry-run, Terraform would save the configuration for the "local" backend.`,
+		"No state has been copied",
+	}
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Fatalf("expected %q in output:\n%s", e, output)
+		}
+	}
+
+	// The backend configuration must not have been saved
+	if _, err := os.Stat(filepath.Join(DefaultDataDir, DefaultStateFilename)); err == nil {
+		t.Fatal("backend configuration should not be saved")
+	}
+}
+
 func TestInit_backendConfigFile(t *testing.T) {
 	// Create a temporary working directory that is empty
 	td := tempDir(t)
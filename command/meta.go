@@ -45,6 +45,10 @@ type Meta struct {
 	// Modify the data directory location. Defaults to DefaultDataDir
 	dataDir string
 
+	// Set to true to leave the UiHook out of the context, such as when
+	// the command's output must be machine readable.
+	noUiHook bool
+
 	//----------------------------------------------------------
 	// Private: do not set these
 	//----------------------------------------------------------
@@ -59,7 +63,9 @@ type Meta struct {
 	variables     map[string]interface{}
 
 	// Targets for this context (private)
-	targets []string
+	targets       []string
+	targetDepth   int
+	targetModules []string
 
 	// Internal fields
 	color bool
@@ -83,18 +89,36 @@ type Meta struct {
 	// parallelism is used to control the number of concurrent operations
 	// allowed when walking the graph
 	//
+	// retryFailed is the number of times apply retries resources that
+	// failed with a retryable error
+	//
+	// recordApplyTime is set to record the time each resource is applied
+	// in the state
+	//
 	// shadow is used to enable/disable the shadow graph
 	//
 	// provider is to specify specific resource providers
 	//
 	// lockState is set to false to disable state locking
+	//
+	// stateLockTimeout is how long to keep retrying to acquire a state
+	// lock that is held by someone else
+	//
+	// migrateDryRun is set to only report the state migration a backend
+	// change would perform, without modifying either backend
 	statePath    string
 	stateOutPath string
 	backupPath   string
 	parallelism  int
+	retryFailed  int
 	shadow       bool
 	provider     string
 	stateLock    bool
+
+	recordApplyTime  bool
+	stateLockTimeout time.Duration
+
+	migrateDryRun bool
 }
 
 // initStatePaths is used to initialize the default values for
@@ -191,7 +215,10 @@ func (m *Meta) contextOpts() *terraform.ContextOpts {
 		opts = *v
 	}
 
-	opts.Hooks = []terraform.Hook{m.uiHook(), &terraform.DebugHook{}}
+	opts.Hooks = []terraform.Hook{&terraform.DebugHook{}}
+	if !m.noUiHook {
+		opts.Hooks = append([]terraform.Hook{m.uiHook()}, opts.Hooks...)
+	}
 	if m.ContextOpts != nil {
 		opts.Hooks = append(opts.Hooks, m.ContextOpts.Hooks...)
 	}
@@ -210,8 +237,12 @@ func (m *Meta) contextOpts() *terraform.ContextOpts {
 	opts.Variables = vs
 
 	opts.Targets = m.targets
+	opts.TargetDepth = m.targetDepthOpt()
+	opts.TargetModules = m.targetModules
 	opts.UIInput = m.UIInput()
 	opts.Parallelism = m.parallelism
+	opts.RetryFailed = m.retryFailed
+	opts.RecordApplyTime = m.recordApplyTime
 	opts.Shadow = m.shadow
 
 	opts.Meta = &terraform.ContextMeta{
@@ -221,6 +252,17 @@ func (m *Meta) contextOpts() *terraform.ContextOpts {
 	return &opts
 }
 
+// targetDepthOpt returns the -target-depth flag value in the form
+// expected by terraform.ContextOpts. A negative depth means no limit.
+func (m *Meta) targetDepthOpt() *int {
+	if m.targetDepth < 0 {
+		return nil
+	}
+
+	depth := m.targetDepth
+	return &depth
+}
+
 // flags adds the meta flags to the given FlagSet.
 func (m *Meta) flagSet(n string) *flag.FlagSet {
 	f := flag.NewFlagSet(n, flag.ContinueOnError)
@@ -228,6 +270,7 @@ func (m *Meta) flagSet(n string) *flag.FlagSet {
 	f.Var((*variables.Flag)(&m.variables), "var", "variables")
 	f.Var((*variables.FlagFile)(&m.variables), "var-file", "variable file")
 	f.Var((*FlagStringSlice)(&m.targets), "target", "resource to target")
+	f.IntVar(&m.targetDepth, "target-depth", -1, "target dependency depth")
 
 	if m.autoKey != "" {
 		f.Var((*variables.FlagFile)(&m.autoVariables), m.autoKey, "variable file")
@@ -4,6 +4,7 @@ package command
 // exported and private.
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -45,6 +46,10 @@ type BackendOpts struct {
 	// configuration and output configuration will come from this plan.
 	Plan *terraform.Plan
 
+	// PlanForce allows a Plan that was created against an older state
+	// than the current state to be used anyways.
+	PlanForce bool
+
 	// Init should be set to true if initialization is allowed. If this is
 	// false, then any configuration that requires configuration will show
 	// an error asking the user to reinitialize.
@@ -164,6 +169,8 @@ func (m *Meta) Operation() *backend.Operation {
 	return &backend.Operation{
 		PlanOutBackend: m.backendState,
 		Targets:        m.targets,
+		TargetDepth:    m.targetDepthOpt(),
+		TargetModules:  m.targetModules,
 		UIIn:           m.UIInput(),
 		Environment:    m.Env(),
 	}
@@ -597,7 +604,10 @@ func (m *Meta) backendFromPlan(opts *BackendOpts) (backend.Backend, error) {
 	lockInfo := state.NewLockInfo()
 	lockInfo.Operation = "backend from plan"
 
-	lockID, err := clistate.Lock(realMgr, lockInfo, m.Ui, m.Colorize())
+	lockCtx, cancel := context.WithTimeout(context.Background(), m.stateLockTimeout)
+	defer cancel()
+
+	lockID, err := clistate.Lock(lockCtx, realMgr, lockInfo, m.Ui, m.Colorize())
 	if err != nil {
 		return nil, fmt.Errorf("Error locking state: %s", err)
 	}
@@ -634,7 +644,13 @@ func (m *Meta) backendFromPlan(opts *BackendOpts) (backend.Backend, error) {
 				break
 			}
 
-			// The real state is newer, this is not allowed.
+			// The real state is newer, this is only allowed if forced.
+			if opts.PlanForce {
+				log.Printf(
+					"[WARN] command: state in plan is older, but forced to continue")
+				break
+			}
+
 			return nil, fmt.Errorf(
 				strings.TrimSpace(errBackendPlanOlder),
 				planState.Serial, real.Serial)
@@ -685,7 +701,7 @@ func (m *Meta) backend_c_r_S(
 	backendType := s.Backend.Type
 
 	// Confirm with the user that the copy should occur
-	copy, err := m.confirm(&terraform.InputOpts{
+	copy, err := m.confirmBackendCopy(&terraform.InputOpts{
 		Id:    "backend-migrate-to-local",
 		Query: fmt.Sprintf("Do you want to copy the state from %q?", s.Backend.Type),
 		Description: fmt.Sprintf(
@@ -723,6 +739,11 @@ func (m *Meta) backend_c_r_S(
 		}
 	}
 
+	if err := m.backendChangeDryRun(fmt.Sprintf(
+		"unset the %q backend", backendType)); err != nil {
+		return nil, err
+	}
+
 	// Remove the stored metadata
 	s.Backend = nil
 	if err := sMgr.WriteState(s); err != nil {
@@ -805,7 +826,7 @@ func (m *Meta) backend_c_R_S(
 	s := sMgr.State()
 
 	// Ask the user if they want to migrate their existing remote state
-	copy, err := m.confirm(&terraform.InputOpts{
+	copy, err := m.confirmBackendCopy(&terraform.InputOpts{
 		Id: "backend-migrate-to-new",
 		Query: fmt.Sprintf(
 			"Do you want to copy the legacy remote state from %q?",
@@ -837,6 +858,10 @@ func (m *Meta) backend_c_R_S(
 		}
 	}
 
+	if err := m.backendChangeDryRun("unset the legacy remote state"); err != nil {
+		return nil, err
+	}
+
 	// Unset the remote state
 	s = sMgr.State()
 	if s == nil {
@@ -872,7 +897,9 @@ func (m *Meta) backend_C_R_s(
 	}
 
 	// Next, save the new configuration. This will not overwrite our
-	// legacy remote state. We'll handle that after.
+	// legacy remote state. We'll handle that after. During a dry run
+	// nothing is saved; the copy below is always previewed, which stops
+	// the change before the legacy remote state is unset.
 	s := sMgr.State()
 	if s == nil {
 		s = terraform.NewState()
@@ -882,23 +909,30 @@ func (m *Meta) backend_C_R_s(
 		Config: c.RawConfig.Raw,
 		Hash:   c.Hash,
 	}
-	if err := sMgr.WriteState(s); err != nil {
-		return nil, fmt.Errorf(errBackendWriteSaved, err)
-	}
-	if err := sMgr.PersistState(); err != nil {
-		return nil, fmt.Errorf(errBackendWriteSaved, err)
+	if !m.migrateDryRun {
+		if err := sMgr.WriteState(s); err != nil {
+			return nil, fmt.Errorf(errBackendWriteSaved, err)
+		}
+		if err := sMgr.PersistState(); err != nil {
+			return nil, fmt.Errorf(errBackendWriteSaved, err)
+		}
 	}
 
 	// I don't know how this is possible but if we don't have remote
 	// state config anymore somehow, just return the backend. This
 	// shouldn't be possible, though.
 	if s.Remote.Empty() {
+		if err := m.backendChangeDryRun(fmt.Sprintf(
+			"save the configuration for the %q backend", c.Type)); err != nil {
+			return nil, err
+		}
+
 		return b, nil
 	}
 
 	// Finally, ask the user if they want to copy the state from
 	// their old remote state location.
-	copy, err := m.confirm(&terraform.InputOpts{
+	copy, err := m.confirmBackendCopy(&terraform.InputOpts{
 		Id: "backend-migrate-to-new",
 		Query: fmt.Sprintf(
 			"Do you want to copy the legacy remote state from %q?",
@@ -998,11 +1032,19 @@ func (m *Meta) backend_C_r_s(
 		}
 	}
 
+	if err := m.backendChangeDryRun(fmt.Sprintf(
+		"save the configuration for the %q backend", c.Type)); err != nil {
+		return nil, err
+	}
+
 	// Lock the state if we can
 	lockInfo := state.NewLockInfo()
 	lockInfo.Operation = "backend from config"
 
-	lockID, err := clistate.Lock(sMgr, lockInfo, m.Ui, m.Colorize())
+	lockCtx, cancel := context.WithTimeout(context.Background(), m.stateLockTimeout)
+	defer cancel()
+
+	lockID, err := clistate.Lock(lockCtx, sMgr, lockInfo, m.Ui, m.Colorize())
 	if err != nil {
 		return nil, fmt.Errorf("Error locking state: %s", err)
 	}
@@ -1055,7 +1097,7 @@ func (m *Meta) backend_C_r_S_changed(
 	}
 
 	// Check with the user if we want to migrate state
-	copy, err := m.confirm(&terraform.InputOpts{
+	copy, err := m.confirmBackendCopy(&terraform.InputOpts{
 		Id:          "backend-migrate-to-new",
 		Query:       fmt.Sprintf("Do you want to copy the state from %q?", c.Type),
 		Description: strings.TrimSpace(fmt.Sprintf(inputBackendMigrateChange, c.Type, s.Backend.Type)),
@@ -1087,11 +1129,19 @@ func (m *Meta) backend_C_r_S_changed(
 		}
 	}
 
+	if err := m.backendChangeDryRun(fmt.Sprintf(
+		"save the configuration for the %q backend", c.Type)); err != nil {
+		return nil, err
+	}
+
 	// Lock the state if we can
 	lockInfo := state.NewLockInfo()
 	lockInfo.Operation = "backend from config"
 
-	lockID, err := clistate.Lock(sMgr, lockInfo, m.Ui, m.Colorize())
+	lockCtx, cancel := context.WithTimeout(context.Background(), m.stateLockTimeout)
+	defer cancel()
+
+	lockID, err := clistate.Lock(lockCtx, sMgr, lockInfo, m.Ui, m.Colorize())
 	if err != nil {
 		return nil, fmt.Errorf("Error locking state: %s", err)
 	}
@@ -1198,7 +1248,7 @@ func (m *Meta) backend_C_R_S_unchanged(
 	}
 
 	// Ask if the user wants to move their legacy remote state
-	copy, err := m.confirm(&terraform.InputOpts{
+	copy, err := m.confirmBackendCopy(&terraform.InputOpts{
 		Id: "backend-migrate-to-new",
 		Query: fmt.Sprintf(
 			"Do you want to copy the legacy remote state from %q?",
@@ -1230,11 +1280,18 @@ func (m *Meta) backend_C_R_S_unchanged(
 		}
 	}
 
+	if err := m.backendChangeDryRun("unset the legacy remote state"); err != nil {
+		return nil, err
+	}
+
 	// Lock the state if we can
 	lockInfo := state.NewLockInfo()
 	lockInfo.Operation = "backend from config"
 
-	lockID, err := clistate.Lock(sMgr, lockInfo, m.Ui, m.Colorize())
+	lockCtx, cancel := context.WithTimeout(context.Background(), m.stateLockTimeout)
+	defer cancel()
+
+	lockID, err := clistate.Lock(lockCtx, sMgr, lockInfo, m.Ui, m.Colorize())
 	if err != nil {
 		return nil, fmt.Errorf("Error locking state: %s", err)
 	}
@@ -1576,7 +1633,8 @@ the final state is written.
 
 const errBackendPlanOlder = `
 This plan was created against an older state than is current. Please create
-a new plan file against the latest state and try again.
+a new plan file against the latest state and try again, or apply with "-force"
+to use this plan anyways.
 
 Terraform doesn't allow you to run plans that were created from older
 states since it doesn't properly represent the latest changes Terraform
@@ -1,6 +1,9 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -39,7 +42,7 @@ func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 			errMigrateLoadStates), opts.OneType, err)
 	}
 
-	_, err = opts.Two.States()
+	twoStates, err := opts.Two.States()
 	if err == backend.ErrNamedStatesNotSupported {
 		twoSingle = true
 		err = nil
@@ -49,6 +52,17 @@ func (m *Meta) backendMigrateState(opts *backendMigrateOpts) error {
 			errMigrateLoadStates), opts.TwoType, err)
 	}
 
+	// If we're only previewing the migration, report it and abort the
+	// backend change so that nothing is written.
+	if m.migrateDryRun {
+		err := m.backendMigrateDryRun(opts, oneStates, twoStates, oneSingle, twoSingle)
+		if err != nil {
+			return err
+		}
+
+		return errBackendMigrateDryRun
+	}
+
 	// Setup defaults
 	opts.oneEnv = backend.DefaultStateName
 	opts.twoEnv = backend.DefaultStateName
@@ -216,7 +230,10 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 	lockInfoOne.Operation = "migration"
 	lockInfoOne.Info = "source state"
 
-	lockIDOne, err := clistate.Lock(stateOne, lockInfoOne, m.Ui, m.Colorize())
+	lockCtx, cancel := context.WithTimeout(context.Background(), m.stateLockTimeout)
+	defer cancel()
+
+	lockIDOne, err := clistate.Lock(lockCtx, stateOne, lockInfoOne, m.Ui, m.Colorize())
 	if err != nil {
 		return fmt.Errorf("Error locking source state: %s", err)
 	}
@@ -226,7 +243,7 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 	lockInfoTwo.Operation = "migration"
 	lockInfoTwo.Info = "destination state"
 
-	lockIDTwo, err := clistate.Lock(stateTwo, lockInfoTwo, m.Ui, m.Colorize())
+	lockIDTwo, err := clistate.Lock(lockCtx, stateTwo, lockInfoTwo, m.Ui, m.Colorize())
 	if err != nil {
 		return fmt.Errorf("Error locking destination state: %s", err)
 	}
@@ -285,6 +302,106 @@ func (m *Meta) backendMigrateState_s_s(opts *backendMigrateOpts) error {
 	return nil
 }
 
+// backendMigrateDryRun reports the states that backendMigrateState would
+// copy, following the same rules, without copying them. Destination states
+// are only loaded if they already exist since some backends create a
+// state when it is accessed.
+func (m *Meta) backendMigrateDryRun(
+	opts *backendMigrateOpts, oneStates, twoStates []string,
+	oneSingle, twoSingle bool) error {
+	onlyDefault := len(oneStates) == 1 && oneStates[0] == backend.DefaultStateName
+
+	// Determine the source and destination names of each state to copy
+	var names [][2]string
+	switch {
+	case oneSingle || onlyDefault:
+		names = append(names, [2]string{
+			backend.DefaultStateName, backend.DefaultStateName})
+	case twoSingle:
+		names = append(names, [2]string{m.Env(), backend.DefaultStateName})
+	default:
+		sorted := make([]string, len(oneStates))
+		copy(sorted, oneStates)
+		sort.Strings(sorted)
+		for _, name := range sorted {
+			names = append(names, [2]string{name, name})
+		}
+	}
+
+	existing := map[string]struct{}{backend.DefaultStateName: struct{}{}}
+	for _, name := range twoStates {
+		existing[name] = struct{}{}
+	}
+
+	var buf bytes.Buffer
+	var copied, resources int
+	for _, pair := range names {
+		oneName, twoName := pair[0], pair[1]
+
+		stateOne, err := opts.One.State(oneName)
+		if err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.OneType, err)
+		}
+		if err := stateOne.RefreshState(); err != nil {
+			return fmt.Errorf(strings.TrimSpace(
+				errMigrateSingleLoadDefault), opts.OneType, err)
+		}
+
+		one := stateOne.State()
+		if one.Empty() {
+			fmt.Fprintf(&buf, "  %s: empty, nothing to copy\n", oneName)
+			continue
+		}
+
+		count := stateResourceCount(one)
+		copied++
+		resources += count
+
+		dest := "a new state"
+		if _, ok := existing[twoName]; ok {
+			stateTwo, err := opts.Two.State(twoName)
+			if err != nil {
+				return fmt.Errorf(strings.TrimSpace(
+					errMigrateSingleLoadDefault), opts.TwoType, err)
+			}
+			if err := stateTwo.RefreshState(); err != nil {
+				return fmt.Errorf(strings.TrimSpace(
+					errMigrateSingleLoadDefault), opts.TwoType, err)
+			}
+
+			if two := stateTwo.State(); !two.Empty() {
+				dest = fmt.Sprintf(
+					"an existing state with %d resource(s), which would be overwritten",
+					stateResourceCount(two))
+			}
+		}
+
+		fmt.Fprintf(&buf, "  %s: %d resource(s) into %s in %q\n",
+			oneName, count, dest, twoName)
+	}
+
+	m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+		"[reset][bold]"+strings.TrimSpace(outputBackendMigrateDryRun)+"[reset]\n\n%s",
+		opts.OneType, opts.TwoType, copied, resources, buf.String())))
+
+	return nil
+}
+
+// stateResourceCount returns the number of resources in all modules of s.
+func stateResourceCount(s *terraform.State) int {
+	if s == nil {
+		return 0
+	}
+
+	count := 0
+	for _, mod := range s.Modules {
+		count += len(mod.Resources)
+	}
+
+	return count
+}
+
 func (m *Meta) backendMigrateEmptyConfirm(one, two state.State, opts *backendMigrateOpts) (bool, error) {
 	inputOpts := &terraform.InputOpts{
 		Id: "backend-migrate-copy-to-empty",
@@ -377,6 +494,17 @@ func (m *Meta) backendMigrateNonEmptyConfirm(
 	}
 }
 
+// confirmBackendCopy asks the user whether state should be copied while
+// changing backends. During a dry run the copy is always previewed, so the
+// user isn't asked.
+func (m *Meta) confirmBackendCopy(opts *terraform.InputOpts) (bool, error) {
+	if m.migrateDryRun {
+		return true, nil
+	}
+
+	return m.confirm(opts)
+}
+
 type backendMigrateOpts struct {
 	OneType, TwoType string
 	One, Two         backend.Backend
@@ -388,6 +516,23 @@ type backendMigrateOpts struct {
 	force  bool   // if true, won't ask for confirmation
 }
 
+// backendChangeDryRun reports a backend change that doesn't migrate any
+// state during a dry run, returning errBackendMigrateDryRun so that the
+// caller returns before saving anything. It does nothing otherwise.
+func (m *Meta) backendChangeDryRun(change string) error {
+	if !m.migrateDryRun {
+		return nil
+	}
+
+	m.Ui.Output(m.Colorize().Color(fmt.Sprintf(
+		"[reset][bold]"+strings.TrimSpace(outputBackendChangeDryRun)+"[reset]\n", change)))
+	return errBackendMigrateDryRun
+}
+
+// errBackendMigrateDryRun is returned by backendMigrateState once a dry run
+// has been reported, to abort the backend change without modifying anything.
+var errBackendMigrateDryRun = errors.New("backend state migration dry run")
+
 const errMigrateLoadStates = `
 Error inspecting state in %q: %s
 
@@ -428,6 +573,14 @@ The state in %[1]q remains intact and unmodified. Please resolve the
 error above and try again.
 `
 
+const outputBackendChangeDryRun = `
+There is no state to migrate. Without -dry-run, Terraform would %s.
+`
+
+const outputBackendMigrateDryRun = `
+Migrating state from %q to %q would copy %d state(s) with %d resource(s):
+`
+
 const inputBackendMigrateEmpty = `
 Pre-existing state was found in %q while migrating to %q. No existing
 state was found in %[2]q. Do you want to copy the state from %[1]q to
@@ -2846,6 +2846,40 @@ func TestMetaBackend_planLocalNewer(t *testing.T) {
 	}
 }
 
+// A plan with an older state than is current, but forced
+func TestMetaBackend_planLocalNewerForce(t *testing.T) {
+	// Create a temporary working directory that is empty
+	td := tempDir(t)
+	copy.CopyDir(testFixturePath("backend-plan-local-newer"), td)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	// Change the serial
+	planState := testStateRead(t, DefaultStateFilename)
+	planState.Serial = 7
+	planState.RootModule().Dependencies = []string{"foo"}
+
+	// Create the plan
+	plan := &terraform.Plan{
+		Module: testModule(t, "backend-plan-local-newer"),
+		State:  planState,
+	}
+
+	// Setup the meta
+	m := testMetaBackend(t, nil)
+
+	// Get the backend
+	if _, err := m.Backend(&BackendOpts{Plan: plan, PlanForce: true}); err != nil {
+		t.Fatalf("bad: %s", err)
+	}
+
+	// Verify the plan state was written
+	actual := testStateRead(t, DefaultStateFilename)
+	if !reflect.DeepEqual(actual.RootModule().Dependencies, []string{"foo"}) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 // A plan that has a backend in an empty dir
 func TestMetaBackend_planBackendEmptyDir(t *testing.T) {
 	// Create a temporary working directory that is empty
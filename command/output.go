@@ -19,9 +19,10 @@ func (c *OutputCommand) Run(args []string) int {
 	args = c.Meta.process(args, false)
 
 	var module string
-	var jsonOutput bool
+	var jsonOutput, rawOutput bool
 	cmdFlags := flag.NewFlagSet("output", flag.ContinueOnError)
 	cmdFlags.BoolVar(&jsonOutput, "json", false, "json")
+	cmdFlags.BoolVar(&rawOutput, "raw", false, "raw")
 	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
 	cmdFlags.StringVar(&module, "module", "", "module")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
@@ -43,6 +44,20 @@ func (c *OutputCommand) Run(args []string) int {
 		name = args[0]
 	}
 
+	if rawOutput {
+		if jsonOutput {
+			c.Ui.Error("The -raw and -json flags are mutually exclusive.\n")
+			cmdFlags.Usage()
+			return 1
+		}
+		if name == "" {
+			c.Ui.Error(
+				"The -raw flag requires the name of a single output variable.\n")
+			cmdFlags.Usage()
+			return 1
+		}
+	}
+
 	// Load the backend
 	b, err := c.Backend(nil)
 	if err != nil {
@@ -64,21 +79,21 @@ func (c *OutputCommand) Run(args []string) int {
 		return 1
 	}
 
-	if module == "" {
-		module = "root"
-	} else {
-		module = "root." + module
-	}
-
 	// Get the proper module we want to get outputs for
-	modPath := strings.Split(module, ".")
+	modPath, err := outputModulePath(module)
+	if err != nil {
+		c.Ui.Error(err.Error())
+		cmdFlags.Usage()
+		return 1
+	}
 
 	state := stateStore.State()
 	mod := state.ModuleByPath(modPath)
 	if mod == nil {
 		c.Ui.Error(fmt.Sprintf(
-			"The module %s could not be found. There is nothing to output.",
-			module))
+			"The module %s could not be found in the state. There is nothing\n"+
+				"to output. Check the module address and that the module has\n"+
+				"been applied.", module))
 		return 1
 	}
 
@@ -118,6 +133,26 @@ func (c *OutputCommand) Run(args []string) int {
 		return 1
 	}
 
+	if rawOutput {
+		output, ok := v.Value.(string)
+		if !ok {
+			c.Ui.Error(fmt.Sprintf(
+				"The output variable %q is a %s. The -raw flag only supports\n"+
+					"string values; use -json for lists and maps.", name, v.Type))
+			return 1
+		}
+
+		// The value was asked for explicitly so we print it even if it is
+		// sensitive, but note that on stderr.
+		if v.Sensitive {
+			c.Ui.Warn(fmt.Sprintf(
+				"Warning: output %q is marked as sensitive.", name))
+		}
+
+		c.Ui.Output(output)
+		return 0
+	}
+
 	if jsonOutput {
 		jsonOutputs, err := json.MarshalIndent(v, "", "    ")
 		if err != nil {
@@ -145,6 +180,40 @@ func (c *OutputCommand) Run(args []string) int {
 	return 0
 }
 
+// outputModulePath returns the path in the state of the module given with
+// the -module flag. The module can be given either as an address, such as
+// "module.foo.module.bar", or as the names of the modules separated by
+// periods, such as "foo.bar". An empty module is the root module.
+func outputModulePath(module string) ([]string, error) {
+	if module == "" {
+		return []string{"root"}, nil
+	}
+
+	parts := strings.Split(module, ".")
+	if parts[0] != "module" {
+		return append([]string{"root"}, parts...), nil
+	}
+
+	if len(parts)%2 != 0 {
+		return nil, fmt.Errorf(
+			"Invalid module address %q: expected module.NAME, optionally\n"+
+				"followed by more module.NAME parts for nested modules.\n", module)
+	}
+
+	path := []string{"root"}
+	for i := 0; i < len(parts); i += 2 {
+		if parts[i] != "module" || parts[i+1] == "" {
+			return nil, fmt.Errorf(
+				"Invalid module address %q: expected module.NAME, optionally\n"+
+					"followed by more module.NAME parts for nested modules.\n", module)
+		}
+
+		path = append(path, parts[i+1])
+	}
+
+	return path, nil
+}
+
 func formatNestedList(indent string, outputList []interface{}) string {
 	outputBuf := new(bytes.Buffer)
 	outputBuf.WriteString(fmt.Sprintf("%s[", indent))
@@ -274,12 +343,17 @@ Options:
 
   -no-color        If specified, output won't contain any color.
 
-  -module=name     If specified, returns the outputs for a
-                   specific module
+  -module=addr     If specified, returns the outputs for a specific
+                   module, given by its address such as "module.foo" or
+                   "module.foo.module.bar" for a nested module.
 
   -json            If specified, machine readable output will be
                    printed in JSON format
 
+  -raw             If specified, the value of the single named string
+                   output is printed as-is, without any formatting. This
+                   is useful in shell scripts.
+
 `
 	return strings.TrimSpace(helpText)
 }
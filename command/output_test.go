@@ -50,6 +50,74 @@ func TestOutput(t *testing.T) {
 	}
 }
 
+func TestOutput_raw(t *testing.T) {
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			{
+				Path: []string{"root"},
+				Outputs: map[string]*terraform.OutputState{
+					"foo": {
+						Value: "bar baz",
+						Type:  "string",
+					},
+					"secret": {
+						Value:     "hunter2",
+						Type:      "string",
+						Sensitive: true,
+					},
+					"list": {
+						Value: []interface{}{"a", "b"},
+						Type:  "list",
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		Args   []string
+		Code   int
+		Output string
+		Error  string
+	}{
+		{[]string{"foo"}, 0, "bar baz\n", ""},
+		{[]string{"secret"}, 0, "hunter2\n", "sensitive"},
+		{[]string{"list"}, 1, "", "only supports"},
+		{[]string{"missing"}, 1, "", "could not be found"},
+		{[]string{}, 1, "", "requires the name"},
+		{[]string{"-json", "foo"}, 1, "", "mutually exclusive"},
+	}
+
+	for i, tc := range cases {
+		statePath := testStateFile(t, originalState)
+
+		ui := new(cli.MockUi)
+		c := &OutputCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+
+		args := append([]string{"-state", statePath, "-raw"}, tc.Args...)
+		if code := c.Run(args); code != tc.Code {
+			t.Fatalf("%d: bad: %d\n\n%s", i, code, ui.ErrorWriter.String())
+		}
+
+		if actual := ui.OutputWriter.String(); actual != tc.Output {
+			t.Fatalf("%d: bad: %#v", i, actual)
+		}
+
+		actual := ui.ErrorWriter.String()
+		if tc.Error == "" && actual != "" {
+			t.Fatalf("%d: unexpected stderr: %s", i, actual)
+		}
+		if !strings.Contains(actual, tc.Error) {
+			t.Fatalf("%d: bad stderr: %s", i, actual)
+		}
+	}
+}
+
 func TestModuleOutput(t *testing.T) {
 	originalState := &terraform.State{
 		Modules: []*terraform.ModuleState{
@@ -149,6 +217,71 @@ func TestModuleOutputs(t *testing.T) {
 	}
 }
 
+func TestModuleOutputs_address(t *testing.T) {
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			{
+				Path: []string{"root"},
+				Outputs: map[string]*terraform.OutputState{
+					"foo": {
+						Value: "bar",
+						Type:  "string",
+					},
+				},
+			},
+			{
+				Path: []string{"root", "my_module"},
+				Outputs: map[string]*terraform.OutputState{
+					"blah": {
+						Value: "tastatur",
+						Type:  "string",
+					},
+				},
+			},
+			{
+				Path: []string{"root", "my_module", "nested"},
+				Outputs: map[string]*terraform.OutputState{
+					"deep": {
+						Value: "value",
+						Type:  "string",
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, originalState)
+
+	cases := map[string]string{
+		"module.my_module":               "blah = tastatur",
+		"module.my_module.module.nested": "deep = value",
+	}
+
+	for module, expected := range cases {
+		ui := new(cli.MockUi)
+		c := &OutputCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-state", statePath,
+			"-module", module,
+		}
+
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("%s: bad: \n%s", module, ui.ErrorWriter.String())
+		}
+
+		actual := strings.TrimSpace(ui.OutputWriter.String())
+		if actual != expected {
+			t.Fatalf("%s: bad: %#v", module, actual)
+		}
+	}
+}
+
 func TestOutput_nestedListAndMap(t *testing.T) {
 	originalState := &terraform.State{
 		Modules: []*terraform.ModuleState{
@@ -272,6 +405,54 @@ func TestMissingModuleOutput(t *testing.T) {
 	}
 }
 
+func TestMissingModuleOutput_address(t *testing.T) {
+	originalState := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			{
+				Path: []string{"root"},
+				Outputs: map[string]*terraform.OutputState{
+					"foo": {
+						Value: "bar",
+						Type:  "string",
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, originalState)
+
+	cases := map[string]string{
+		"module.not_existing_module": "module.not_existing_module could not be found",
+		"module.foo.bar":             "Invalid module address",
+		"module.foo.module":          "Invalid module address",
+	}
+
+	for module, expected := range cases {
+		ui := new(cli.MockUi)
+		c := &OutputCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(testProvider()),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-state", statePath,
+			"-module", module,
+		}
+
+		if code := c.Run(args); code != 1 {
+			t.Fatalf("%s: bad: %d\n%s", module, code, ui.OutputWriter.String())
+		}
+
+		if !strings.Contains(ui.ErrorWriter.String(), expected) {
+			t.Fatalf("%s: expected %q in error: %s",
+				module, expected, ui.ErrorWriter.String())
+		}
+	}
+}
+
 func TestOutput_badVar(t *testing.T) {
 	originalState := &terraform.State{
 		Modules: []*terraform.ModuleState{
@@ -16,7 +16,8 @@ type PlanCommand struct {
 }
 
 func (c *PlanCommand) Run(args []string) int {
-	var destroy, refresh, detailed bool
+	var destroy, refresh, detailed, compactWarnings, skipEmpty, highlightNew bool
+	var denyDestroy, denyReplace, warnUnused bool
 	var outPath string
 	var moduleDepth int
 
@@ -25,13 +26,21 @@ func (c *PlanCommand) Run(args []string) int {
 	cmdFlags := c.Meta.flagSet("plan")
 	cmdFlags.BoolVar(&destroy, "destroy", false, "destroy")
 	cmdFlags.BoolVar(&refresh, "refresh", true, "refresh")
+	cmdFlags.Var((*FlagStringSlice)(&c.Meta.targetModules), "module", "module to target")
 	c.addModuleDepthFlag(cmdFlags, &moduleDepth)
 	cmdFlags.StringVar(&outPath, "out", "", "path")
+	cmdFlags.BoolVar(&skipEmpty, "skip-empty-out", false, "skip-empty-out")
+	cmdFlags.BoolVar(&highlightNew, "highlight-new", false, "highlight-new")
+	cmdFlags.BoolVar(&denyDestroy, "deny-destroy", false, "deny-destroy")
+	cmdFlags.BoolVar(&denyReplace, "deny-replace", false, "deny-replace")
 	cmdFlags.IntVar(
 		&c.Meta.parallelism, "parallelism", DefaultParallelism, "parallelism")
 	cmdFlags.StringVar(&c.Meta.statePath, "state", "", "path")
 	cmdFlags.BoolVar(&detailed, "detailed-exitcode", false, "detailed-exitcode")
+	cmdFlags.BoolVar(&compactWarnings, "compact-warnings", false, "compact-warnings")
+	cmdFlags.BoolVar(&warnUnused, "warn-unused-vars", false, "warn-unused-vars")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -43,6 +52,11 @@ func (c *PlanCommand) Run(args []string) int {
 		return 1
 	}
 
+	if skipEmpty && outPath == "" {
+		c.Ui.Error("The -skip-empty-out flag requires -out to be set.")
+		return 1
+	}
+
 	// Check if the path is a plan
 	plan, err := c.Plan(configPath)
 	if err != nil {
@@ -84,8 +98,15 @@ func (c *PlanCommand) Run(args []string) int {
 	opReq.Plan = plan
 	opReq.PlanRefresh = refresh
 	opReq.PlanOutPath = outPath
+	opReq.PlanOutSkipEmpty = skipEmpty
+	opReq.PlanHighlightNew = highlightNew
+	opReq.PlanDenyDestroy = denyDestroy
+	opReq.PlanDenyReplace = denyReplace
 	opReq.Type = backend.OperationTypePlan
 	opReq.LockState = c.Meta.stateLock
+	opReq.LockTimeout = c.Meta.stateLockTimeout
+	opReq.CompactWarnings = compactWarnings
+	opReq.WarnUnusedVariables = warnUnused
 
 	// Perform the operation
 	op, err := b.Operation(context.Background(), opReq)
@@ -113,6 +134,12 @@ func (c *PlanCommand) Run(args []string) int {
 		return 2
 	}
 
+	// An empty plan that wasn't written gets its own exit code so that
+	// automation knows there is nothing to apply.
+	if skipEmpty && op.PlanEmpty {
+		return 3
+	}
+
 	return 0
 }
 
@@ -132,6 +159,18 @@ Usage: terraform plan [options] [DIR-OR-PLAN]
 
 Options:
 
+  -compact-warnings   If set, warnings are shown as a short summary with one
+                      line per distinct warning. Errors are still shown in
+                      full.
+
+  -deny-destroy       Fail without saving the plan if it would destroy any
+                      resources, including by replacing them. Any plan
+                      already at the -out path is removed. Creates and
+                      in-place updates are still allowed.
+
+  -deny-replace       Fail without saving the plan if it would replace any
+                      resources. Resources can still be destroyed.
+
   -destroy            If set, a plan will be generated to destroy all resources
                       managed by the given configuration and state.
 
@@ -141,10 +180,21 @@ Options:
                       1 - Errored
                       2 - Succeeded, there is a diff
 
+  -highlight-new      Mark the resources that are created for the first time,
+                      with no prior state, with "(new)" so they stand out
+                      from resources that are replaced or changed.
+
   -input=true         Ask for input for variables if not directly set.
 
   -lock=true          Lock the state file when locking is supported.
 
+  -lock-timeout=0s    Duration to retry a state lock.
+
+  -module=module.foo  Module to target. Operation will be limited to the
+                      resources in this module, including those of its
+                      child modules, and their dependencies. This flag can
+                      be used multiple times.
+
   -module-depth=n     Specifies the depth of modules to show in the output.
                       This does not affect the plan itself, only the output
                       shown. By default, this is -1, which will expand all.
@@ -158,6 +208,10 @@ Options:
 
   -refresh=true       Update state prior to checking for differences.
 
+  -skip-empty-out     If set with -out, the plan file is not written when
+                      there are no changes and any existing file at that
+                      path is removed. The command then exits with code 3.
+
   -state=statefile    Path to a Terraform state file to use to look
                       up Terraform-managed resources. By default it will
                       use the state "terraform.tfstate" if it exists.
@@ -170,8 +224,12 @@ Options:
                       flag can be set multiple times.
 
   -var-file=foo       Set variables in the Terraform configuration from
-                      a file. If "terraform.tfvars" is present, it will be
+                      a file, or from each .tfvars file in a directory. If
+                      "terraform.tfvars" is present, it will be
                       automatically loaded if this flag is not specified.
+
+  -warn-unused-vars   Warn about variables, in the root module or any child
+                      module, that are declared but never referenced.
 `
 	return strings.TrimSpace(helpText)
 }
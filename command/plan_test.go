@@ -2,6 +2,7 @@ package command
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -471,6 +472,166 @@ func TestPlan_state(t *testing.T) {
 	}
 }
 
+func TestPlan_highlightNew(t *testing.T) {
+	// Write out some prior state with only test_instance.foo
+	tf, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	statePath := tf.Name()
+	defer os.Remove(tf.Name())
+
+	err = terraform.WriteState(testState(), tf)
+	tf.Close()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p := testProvider()
+	p.DiffFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+		return &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"ami": &terraform.ResourceAttrDiff{
+					New:         "bar",
+					RequiresNew: s == nil || s.ID == "",
+				},
+			},
+		}, nil
+	}
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-no-color",
+		"-highlight-new",
+		"-state", statePath,
+		testFixturePath("plan-highlight-new"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "+ test_instance.baz (new)\n") {
+		t.Fatalf("new resource should be highlighted:\n\n%s", output)
+	}
+	if !strings.Contains(output, "~ test_instance.foo\n") {
+		t.Fatalf("changed resource should not be highlighted:\n\n%s", output)
+	}
+}
+
+func TestPlan_denyDestroy(t *testing.T) {
+	// The state has test_instance.foo, which the configuration replaces,
+	// and test_instance.bar, which isn't in the configuration.
+	originalState := testState()
+	originalState.RootModule().Resources["test_instance.bar"] = &terraform.ResourceState{
+		Type: "test_instance",
+		Primary: &terraform.InstanceState{
+			ID: "bar",
+		},
+	}
+	statePath := testStateFile(t, originalState)
+
+	cases := map[string]string{
+		"-deny-destroy": "test_instance.bar (destroy)\n  test_instance.foo (replace)",
+		"-deny-replace": "test_instance.foo (replace)",
+	}
+
+	for flag, expected := range cases {
+		// A plan left over from an earlier run must not survive the denial
+		outPath := testTempFile(t)
+		if err := ioutil.WriteFile(outPath, []byte("stale"), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		p := testProvider()
+		p.DiffFn = func(
+			info *terraform.InstanceInfo,
+			s *terraform.InstanceState,
+			c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+			return &terraform.InstanceDiff{
+				Attributes: map[string]*terraform.ResourceAttrDiff{
+					"ami": &terraform.ResourceAttrDiff{
+						New:         "bar",
+						RequiresNew: true,
+					},
+				},
+			}, nil
+		}
+		ui := new(cli.MockUi)
+		c := &PlanCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			flag,
+			"-out", outPath,
+			"-state", statePath,
+			testFixturePath("plan"),
+		}
+		if code := c.Run(args); code != 1 {
+			t.Fatalf("%s: bad: %d\n\n%s", flag, code, ui.OutputWriter.String())
+		}
+
+		errOutput := ui.ErrorWriter.String()
+		if !strings.Contains(errOutput, expected+"\n") {
+			t.Fatalf("%s: expected the denied resources in the error:\n\n%s", flag, errOutput)
+		}
+		if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+			t.Fatalf("%s: plan should not be written: %s", flag, err)
+		}
+	}
+}
+
+func TestPlan_denyDestroyUpdate(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	p := testProvider()
+	p.DiffFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState,
+		c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
+		return &terraform.InstanceDiff{
+			Attributes: map[string]*terraform.ResourceAttrDiff{
+				"ami": &terraform.ResourceAttrDiff{
+					New:         "bar",
+					RequiresNew: s == nil || s.ID == "",
+				},
+			},
+		}, nil
+	}
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// test_instance.foo is updated in place and test_instance.baz is
+	// created, which are both allowed.
+	args := []string{
+		"-deny-destroy",
+		"-deny-replace",
+		"-state", statePath,
+		testFixturePath("plan-highlight-new"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+}
+
 func TestPlan_stateDefault(t *testing.T) {
 	originalState := testState()
 
@@ -624,6 +785,169 @@ func TestPlan_validate(t *testing.T) {
 	}
 }
 
+func TestPlan_compactWarnings(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	p.ValidateResourceReturnWarns = []string{
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+		"something else",
+	}
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-compact-warnings",
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := ui.ErrorWriter.String()
+	if !strings.Contains(actual, "There are 3 warnings") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if !strings.Contains(actual, "deprecated attribute (2 occurrences)\n") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if !strings.Contains(actual, "  - test_instance.foo: something else\n") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if strings.Contains(actual, "will be removed") {
+		t.Fatalf("details should be omitted: %s", actual)
+	}
+}
+
+func TestPlan_compactWarningsFull(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	p.ValidateResourceReturnWarns = []string{
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+	}
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Without the flag every warning is shown in full
+	actual := ui.ErrorWriter.String()
+	if n := strings.Count(actual, "The ami attribute will be removed."); n != 2 {
+		t.Fatalf("bad: %d\n\n%s", n, actual)
+	}
+	if strings.Contains(actual, "occurrences") {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestPlan_warnUnusedVars(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		testFixturePath("plan-unused-vars"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Without the flag nothing is reported
+	if actual := ui.ErrorWriter.String(); strings.Contains(actual, "never referenced") {
+		t.Fatalf("bad: %s", actual)
+	}
+
+	ui = new(cli.MockUi)
+	c = &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args = []string{
+		"-warn-unused-vars",
+		testFixturePath("plan-unused-vars"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := ui.ErrorWriter.String()
+	if !strings.Contains(actual, `var.unused: variable "unused" is declared but never referenced`) {
+		t.Fatalf("bad: %s", actual)
+	}
+	if strings.Contains(actual, `"used"`) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestPlan_compactWarningsErrors(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	p := testProvider()
+	p.ValidateResourceReturnWarns = []string{
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+		"deprecated attribute\n\nThe ami attribute will be removed.",
+	}
+	p.ValidateResourceReturnErrors = []error{
+		errors.New("ami is invalid\n\nThe given ami does not exist."),
+	}
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-compact-warnings",
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Errors are never compacted
+	actual := ui.ErrorWriter.String()
+	if !strings.Contains(actual, "The given ami does not exist.") {
+		t.Fatalf("bad: %s", actual)
+	}
+	if !strings.Contains(actual, "deprecated attribute (2 occurrences)") {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
 func TestPlan_vars(t *testing.T) {
 	tmp, cwd := testCwd(t)
 	defer testFixCwd(t, tmp, cwd)
@@ -831,6 +1155,108 @@ func TestPlan_detailedExitcode_emptyDiff(t *testing.T) {
 	}
 }
 
+func TestPlan_skipEmptyOut_emptyDiff(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := os.Chdir(testFixturePath("plan-emptydiff")); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.Chdir(cwd)
+
+	// Leave a stale plan behind that must not survive
+	outPath := filepath.Join(testTempDir(t), "terraform.tfplan")
+	if err := ioutil.WriteFile(outPath, []byte("stale"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-detailed-exitcode",
+		"-skip-empty-out",
+		"-out", outPath,
+	}
+	if code := c.Run(args); code != 3 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Fatalf("plan file should not exist: %s", err)
+	}
+
+	if !strings.Contains(ui.OutputWriter.String(), "was not saved") {
+		t.Fatalf("bad: %s", ui.OutputWriter.String())
+	}
+}
+
+func TestPlan_skipEmptyOut_changes(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	outPath := filepath.Join(testTempDir(t), "terraform.tfplan")
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	p.DiffReturn = &terraform.InstanceDiff{
+		Destroy: true,
+	}
+
+	args := []string{
+		"-detailed-exitcode",
+		"-skip-empty-out",
+		"-out", outPath,
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 2 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	f, err := os.Open(outPath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer f.Close()
+
+	if _, err := terraform.ReadPlan(f); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestPlan_skipEmptyOut_noOut(t *testing.T) {
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &PlanCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-skip-empty-out",
+		testFixturePath("plan"),
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+}
+
 const planVarFile = `
 foo = "bar"
 `
@@ -24,6 +24,7 @@ func (c *RefreshCommand) Run(args []string) int {
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
@@ -54,6 +55,7 @@ func (c *RefreshCommand) Run(args []string) int {
 	opReq.Type = backend.OperationTypeRefresh
 	opReq.Module = mod
 	opReq.LockState = c.Meta.stateLock
+	opReq.LockTimeout = c.Meta.stateLockTimeout
 
 	// Perform the operation
 	op, err := b.Operation(context.Background(), opReq)
@@ -98,6 +100,8 @@ Options:
 
   -lock=true          Lock the state file when locking is supported.
 
+  -lock-timeout=0s    Duration to retry a state lock.
+
   -no-color           If specified, output won't contain any color.
 
   -state=path         Path to read and save state (unless state-out
@@ -459,6 +459,63 @@ func TestRefresh_outPath(t *testing.T) {
 	}
 }
 
+func TestRefresh_defaultStateOutPath(t *testing.T) {
+	td := tempDir(t)
+	os.MkdirAll(td, 0755)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	// The input state is the default state in the working directory
+	originalState := testState()
+	statePath := testStateFileDefault(t, originalState)
+	outPath := "refreshed.tfstate"
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &RefreshCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	p.RefreshFn = nil
+	p.RefreshReturn = newInstanceState("yes")
+
+	args := []string{
+		"-state-out", outPath,
+		testFixturePath("refresh"),
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The input state must be untouched and not backed up
+	newState := testStateRead(t, statePath)
+	if !reflect.DeepEqual(newState, originalState) {
+		t.Fatalf("bad: %#v", newState)
+	}
+	if _, err := os.Stat(statePath + DefaultBackupExtension); err == nil {
+		t.Fatal("input state should not be backed up")
+	}
+
+	// The output gets the refreshed values
+	newState = testStateRead(t, outPath)
+	actual := newState.RootModule().Resources["test_instance.foo"].Primary
+	expected := p.RefreshReturn
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// The backup is written next to the output
+	backupState := testStateRead(t, outPath+DefaultBackupExtension)
+	actualStr := strings.TrimSpace(backupState.String())
+	expectedStr := strings.TrimSpace(originalState.String())
+	if actualStr != expectedStr {
+		t.Fatalf("bad:\n\n%s\n\n%s", actualStr, expectedStr)
+	}
+}
+
 func TestRefresh_var(t *testing.T) {
 	state := testState()
 	statePath := testStateFile(t, state)
@@ -5,6 +5,7 @@
 package message
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -48,8 +49,9 @@ that no one else is holding a lock.
 )
 
 // Lock locks the given state and outputs to the user if locking
-// is taking longer than the threshold.
-func Lock(s state.State, info *state.LockInfo, ui cli.Ui, color *colorstring.Colorize) (string, error) {
+// is taking longer than the threshold. If the lock is held by someone
+// else, acquiring it is retried until ctx is done.
+func Lock(ctx context.Context, s state.State, info *state.LockInfo, ui cli.Ui, color *colorstring.Colorize) (string, error) {
 	sl, ok := s.(state.Locker)
 	if !ok {
 		return "", nil
@@ -58,7 +60,7 @@ func Lock(s state.State, info *state.LockInfo, ui cli.Ui, color *colorstring.Col
 	var lockID string
 
 	err := slowmessage.Do(LockThreshold, func() error {
-		id, err := sl.Lock(info)
+		id, err := state.LockWithContext(ctx, sl, info)
 		lockID = id
 		return err
 	}, func() {
@@ -2,8 +2,13 @@ package command
 
 import (
 	"fmt"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/dag"
 	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 )
@@ -16,15 +21,26 @@ type StateListCommand struct {
 }
 
 func (c *StateListCommand) Run(args []string) int {
+	var changed bool
+	var order, provider string
 	args = c.Meta.process(args, true)
 
 	cmdFlags := c.Meta.flagSet("state list")
 	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	cmdFlags.BoolVar(&changed, "changed", false, "changed")
+	cmdFlags.StringVar(&order, "order", "lexical", "order")
+	cmdFlags.StringVar(&provider, "provider", "", "provider")
 	if err := cmdFlags.Parse(args); err != nil {
 		return cli.RunResultHelp
 	}
 	args = cmdFlags.Args()
 
+	if order != "lexical" && order != "dependency" {
+		c.Ui.Error(fmt.Sprintf(
+			"Invalid -order %q: must be \"lexical\" or \"dependency\"", order))
+		return cli.RunResultHelp
+	}
+
 	// Load the backend
 	b, err := c.Backend(nil)
 	if err != nil {
@@ -58,15 +74,275 @@ func (c *StateListCommand) Run(args []string) int {
 		return cli.RunResultHelp
 	}
 
+	// If we only want what changed, refresh a copy of the state and
+	// compare it to what we have.
+	var changedAddrs map[string]struct{}
+	if changed {
+		changedAddrs, err = c.changed(stateReal)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to refresh state: %s", err))
+			return 1
+		}
+	}
+
+	var instances []*terraform.StateFilterResult
 	for _, result := range results {
-		if _, ok := result.Value.(*terraform.InstanceState); ok {
-			c.Ui.Output(result.Address)
+		if _, ok := result.Value.(*terraform.InstanceState); !ok {
+			continue
+		}
+		if provider != "" && !stateListProviderMatch(result, provider) {
+			continue
+		}
+
+		instances = append(instances, result)
+	}
+
+	if order == "dependency" {
+		ordered, err := stateListDependencyOrder(instances)
+		if err != nil {
+			c.Ui.Warn(fmt.Sprintf(
+				"Listing resources in lexical order: %s", err))
+		} else {
+			instances = ordered
+		}
+	}
+
+	for _, result := range instances {
+		if changedAddrs != nil {
+			if _, ok := changedAddrs[result.Address]; !ok {
+				continue
+			}
 		}
+
+		c.Ui.Output(result.Address)
 	}
 
 	return 0
 }
 
+// stateListDependencyOrder returns the given instances ordered so that
+// every instance comes after the instances it depends on according to the
+// dependencies recorded in the state. Instances that don't depend on each
+// other keep their relative order. An error is returned if the
+// dependencies have a cycle.
+func stateListDependencyOrder(
+	instances []*terraform.StateFilterResult) ([]*terraform.StateFilterResult, error) {
+	// Index the instances by module and resource. The vertices of the
+	// graph are the indexes of the instances so that ties are broken by
+	// the original order.
+	var g dag.AcyclicGraph
+	byResource := make(map[string][]int)
+	byModule := make(map[string][]int)
+	keys := make([]string, len(instances))
+	for i, r := range instances {
+		g.Add(stateListVertex(i))
+
+		addr, err := terraform.ParseResourceAddress(r.Address)
+		if err != nil {
+			return nil, err
+		}
+
+		base := addr.Type + "." + addr.Name
+		if addr.Mode == config.DataResourceMode {
+			base = "data." + base
+		}
+
+		keys[i] = stateListResourceKey(addr.Path, base)
+		byResource[keys[i]] = append(byResource[keys[i]], i)
+		for j := range addr.Path {
+			k := strings.Join(addr.Path[:j+1], ".")
+			byModule[k] = append(byModule[k], i)
+		}
+	}
+
+	// Connect every instance to the instances it depends on
+	for i, r := range instances {
+		if r.Parent == nil {
+			continue
+		}
+		rs, ok := r.Parent.Value.(*terraform.ResourceState)
+		if !ok {
+			continue
+		}
+
+		path := r.Path
+		for _, dep := range rs.Dependencies {
+			var targets []int
+			if strings.HasPrefix(dep, "module.") {
+				parts := strings.SplitN(dep, ".", 3)
+				k := strings.Join(append(append([]string{}, path...), parts[1]), ".")
+				targets = byModule[k]
+			} else {
+				k := stateListResourceKey(path, stateListDependencyBase(dep))
+				if k == keys[i] {
+					// Instances of a resource with a count can
+					// reference each other with a splat.
+					continue
+				}
+				targets = byResource[k]
+			}
+
+			for _, t := range targets {
+				g.Connect(dag.BasicEdge(stateListVertex(i), stateListVertex(t)))
+			}
+		}
+	}
+
+	if cycles := g.Cycles(); len(cycles) > 0 {
+		var addrs []string
+		for _, v := range cycles[0] {
+			addrs = append(addrs, instances[v.(stateListVertex)].Address)
+		}
+		sort.Strings(addrs)
+
+		return nil, fmt.Errorf(
+			"the dependencies in the state have a cycle: %s",
+			strings.Join(addrs, ", "))
+	}
+
+	order, err := g.DependencyOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*terraform.StateFilterResult, len(order))
+	for i, v := range order {
+		result[i] = instances[v.(stateListVertex)]
+	}
+
+	return result, nil
+}
+
+// stateListVertex is the index of an instance as a vertex of the graph
+// built by stateListDependencyOrder. Its name sorts in the order of the
+// indexes, which is how dag orders vertices that don't depend on each
+// other.
+type stateListVertex int
+
+func (v stateListVertex) Name() string {
+	return fmt.Sprintf("%010d", int(v))
+}
+
+// stateListDependencyBase returns the resource that a dependency recorded
+// in the state refers to, without a count index or splat.
+func stateListDependencyBase(dep string) string {
+	parts := strings.Split(dep, ".")
+	if last := parts[len(parts)-1]; last == "*" {
+		parts = parts[:len(parts)-1]
+	} else if _, err := strconv.Atoi(last); err == nil {
+		parts = parts[:len(parts)-1]
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// stateListProviderMatch returns true if the instance in the given result
+// is managed by the given provider. A provider without an alias, such as
+// "aws", matches every instance of that provider including the aliased
+// ones, while "aws.west" only matches instances of that alias.
+func stateListProviderMatch(r *terraform.StateFilterResult, provider string) bool {
+	if r.Parent == nil {
+		return false
+	}
+	rs, ok := r.Parent.Value.(*terraform.ResourceState)
+	if !ok {
+		return false
+	}
+
+	// The provider is only recorded in the state for aliased providers,
+	// otherwise it is inferred from the resource type.
+	actual := rs.Provider
+	if actual == "" {
+		actual = rs.Type
+		if idx := strings.IndexRune(actual, '_'); idx != -1 {
+			actual = actual[:idx]
+		}
+	}
+
+	if actual == provider {
+		return true
+	}
+
+	return !strings.Contains(provider, ".") &&
+		strings.HasPrefix(actual, provider+".")
+}
+
+func stateListResourceKey(path []string, base string) string {
+	return strings.Join(path, ".") + "|" + base
+}
+
+// changed refreshes a copy of the given state using the configuration in
+// the current directory and returns the addresses of the instances whose
+// refreshed state differs from the given state, including instances that
+// no longer exist. Nothing is written back.
+func (c *StateListCommand) changed(state *terraform.State) (map[string]struct{}, error) {
+	pwd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := c.Module(pwd)
+	if err != nil {
+		return nil, err
+	}
+
+	// Refresh without the UI hook so the refresh progress doesn't end up
+	// mixed into the list.
+	opts := c.contextOpts()
+	hooks := make([]terraform.Hook, 0, len(opts.Hooks))
+	for _, h := range opts.Hooks {
+		if _, ok := h.(*UiHook); !ok {
+			hooks = append(hooks, h)
+		}
+	}
+	opts.Hooks = hooks
+	opts.Module = mod
+	opts.State = state
+
+	ctx, err := terraform.NewContext(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshed, err := ctx.Refresh()
+	if err != nil {
+		return nil, err
+	}
+
+	oldResults, err := (&terraform.StateFilter{State: state}).Filter()
+	if err != nil {
+		return nil, err
+	}
+
+	newInstances := make(map[string]*terraform.InstanceState)
+	if refreshed != nil {
+		newResults, err := (&terraform.StateFilter{State: refreshed}).Filter()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range newResults {
+			if is, ok := r.Value.(*terraform.InstanceState); ok {
+				newInstances[r.Address] = is
+			}
+		}
+	}
+
+	result := make(map[string]struct{})
+	for _, r := range oldResults {
+		is, ok := r.Value.(*terraform.InstanceState)
+		if !ok {
+			continue
+		}
+
+		if !is.Equal(newInstances[r.Address]) {
+			result[r.Address] = struct{}{}
+		}
+	}
+
+	return result, nil
+}
+
 func (c *StateListCommand) Help() string {
 	helpText := `
 Usage: terraform state list [options] [pattern...]
@@ -87,10 +363,29 @@ Usage: terraform state list [options] [pattern...]
 
 Options:
 
+  -changed            Refresh the state in memory and only list the
+                      resources whose refreshed state differs from the
+                      stored state. The stored state is not modified.
+                      Providers are configured from the current directory.
+
+  -order=lexical      The order to list the resources in. "lexical" sorts
+                      them by address. "dependency" lists every resource
+                      after the resources it depends on, according to the
+                      dependencies recorded in the state. Defaults to
+                      "lexical".
+
+  -provider=name      Only list the resources managed by the given
+                      provider, such as "aws". Give the alias, such as
+                      "aws.west", to only list the resources managed by
+                      that aliased provider.
+
   -state=statefile    Path to a Terraform state file to use to look
                       up Terraform-managed resources. By default it will
                       use the state "terraform.tfstate" if it exists.
 
+  -target=resource    With -changed, only refresh the given resource and
+                      its dependencies. Can be specified multiple times.
+
 `
 	return strings.TrimSpace(helpText)
 }
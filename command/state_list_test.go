@@ -1,11 +1,14 @@
 package command
 
 import (
+	"bytes"
+	"io/ioutil"
 	"os"
 	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/copy"
+	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 )
 
@@ -85,6 +88,352 @@ func TestStateList_noState(t *testing.T) {
 	}
 }
 
+func TestStateList_changed(t *testing.T) {
+	// Run from an empty directory so there's no configuration
+	defer testChdir(t, testTempDir(t))()
+
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID:         "foo",
+							Attributes: map[string]string{"id": "foo", "ami": "bar"},
+						},
+					},
+					"test_instance.bar": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID:         "bar",
+							Attributes: map[string]string{"id": "bar", "ami": "bar"},
+						},
+					},
+					"test_instance.baz": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID:         "baz",
+							Attributes: map[string]string{"id": "baz", "ami": "bar"},
+						},
+					},
+				},
+			},
+		},
+	}
+	statePath := testStateFile(t, state)
+
+	// Grab the serialized state so we can make sure it isn't written
+	original, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The "foo" instance has drifted and "baz" is gone
+	p := testProvider()
+	p.RefreshFn = func(
+		info *terraform.InstanceInfo,
+		s *terraform.InstanceState) (*terraform.InstanceState, error) {
+		switch s.ID {
+		case "foo":
+			s = s.DeepCopy()
+			s.Attributes["ami"] = "drifted"
+		case "baz":
+			return nil, nil
+		}
+
+		return s, nil
+	}
+
+	ui := new(cli.MockUi)
+	c := &StateListCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-changed",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := "test_instance.baz\ntest_instance.foo\n"
+	actual := ui.OutputWriter.String()
+	if actual != expected {
+		t.Fatalf("Expected:\n%q\n\nTo equal: %q", actual, expected)
+	}
+
+	// The state must not be modified
+	current, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !bytes.Equal(current, original) {
+		t.Fatalf("state was modified:\n\n%s", current)
+	}
+
+	// With a target only that resource is refreshed
+	ui = new(cli.MockUi)
+	c = &StateListCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args = []string{
+		"-state", statePath,
+		"-changed",
+		"-target", "test_instance.bar",
+		"-target", "test_instance.foo",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected = "test_instance.foo\n"
+	actual = ui.OutputWriter.String()
+	if actual != expected {
+		t.Fatalf("Expected:\n%q\n\nTo equal: %q", actual, expected)
+	}
+}
+
+func TestStateList_orderDependency(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.a": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.b"},
+						Primary:      &terraform.InstanceState{ID: "a"},
+					},
+					"test_instance.b": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"module.child"},
+						Primary:      &terraform.InstanceState{ID: "b"},
+					},
+					"test_instance.c.0": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.a", "test_instance.c.*"},
+						Primary:      &terraform.InstanceState{ID: "c0"},
+					},
+					"test_instance.c.1": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.a", "test_instance.c.*"},
+						Primary:      &terraform.InstanceState{ID: "c1"},
+					},
+					"test_instance.0first": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.c.*"},
+						Primary:      &terraform.InstanceState{ID: "first"},
+					},
+				},
+			},
+			&terraform.ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.z": &terraform.ResourceState{
+						Type:    "test_instance",
+						Primary: &terraform.InstanceState{ID: "z"},
+					},
+				},
+			},
+		},
+	}
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateListCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-order", "dependency",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := strings.TrimSpace(testStateListOrderDependencyOutput) + "\n"
+	actual := ui.OutputWriter.String()
+	if actual != expected {
+		t.Fatalf("Expected:\n%s\n\nTo equal:\n%s", actual, expected)
+	}
+	if ui.ErrorWriter.Len() != 0 {
+		t.Fatalf("unexpected warnings: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestStateList_orderDependencyCycle(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.a": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.b"},
+						Primary:      &terraform.InstanceState{ID: "a"},
+					},
+					"test_instance.b": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.a"},
+						Primary:      &terraform.InstanceState{ID: "b"},
+					},
+				},
+			},
+		},
+	}
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateListCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-order", "dependency",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := "test_instance.a\ntest_instance.b\n"
+	actual := ui.OutputWriter.String()
+	if actual != expected {
+		t.Fatalf("Expected:\n%s\n\nTo equal:\n%s", actual, expected)
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "have a cycle: test_instance.a, test_instance.b") {
+		t.Fatalf("expected a warning about the cycle, got: %s", ui.ErrorWriter.String())
+	}
+}
+
+func TestStateList_provider(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"aws_instance.a": &terraform.ResourceState{
+						Type:    "aws_instance",
+						Primary: &terraform.InstanceState{ID: "a"},
+					},
+					"aws_instance.b": &terraform.ResourceState{
+						Type:     "aws_instance",
+						Provider: "aws.west",
+						Primary:  &terraform.InstanceState{ID: "b"},
+					},
+					"aws_instance.c": &terraform.ResourceState{
+						Type:     "aws_instance",
+						Provider: "aws.east",
+						Primary:  &terraform.InstanceState{ID: "c"},
+					},
+					"test_instance.d": &terraform.ResourceState{
+						Type:    "test_instance",
+						Primary: &terraform.InstanceState{ID: "d"},
+					},
+					"awsx_instance.e": &terraform.ResourceState{
+						Type:    "awsx_instance",
+						Primary: &terraform.InstanceState{ID: "e"},
+					},
+				},
+			},
+			&terraform.ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*terraform.ResourceState{
+					"aws_instance.f": &terraform.ResourceState{
+						Type:     "aws_instance",
+						Provider: "aws.west",
+						Primary:  &terraform.InstanceState{ID: "f"},
+					},
+				},
+			},
+		},
+	}
+	statePath := testStateFile(t, state)
+
+	cases := map[string]string{
+		"aws":      "aws_instance.a\naws_instance.b\naws_instance.c\nmodule.child.aws_instance.f\n",
+		"aws.west": "aws_instance.b\nmodule.child.aws_instance.f\n",
+		"test":     "test_instance.d\n",
+		"google":   "",
+	}
+
+	for provider, expected := range cases {
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &StateListCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-state", statePath,
+			"-provider", provider,
+		}
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("%s: bad: %d\n\n%s", provider, code, ui.ErrorWriter.String())
+		}
+
+		var actual string
+		if ui.OutputWriter != nil {
+			actual = ui.OutputWriter.String()
+		}
+		if actual != expected {
+			t.Fatalf("%s: Expected:\n%s\n\nTo equal:\n%s", provider, actual, expected)
+		}
+	}
+}
+
+func TestStateList_orderInvalid(t *testing.T) {
+	statePath := testStateFile(t, testState())
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateListCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-order", "random",
+	}
+	if code := c.Run(args); code == 0 {
+		t.Fatalf("should fail: %s", ui.OutputWriter.String())
+	}
+}
+
+const testStateListOrderDependencyOutput = `
+module.child.test_instance.z
+test_instance.b
+test_instance.a
+test_instance.c[0]
+test_instance.c[1]
+test_instance.0first
+`
+
 const testStateListOutput = `
 test_instance.foo
 `
@@ -2,6 +2,7 @@ package command
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -19,7 +20,9 @@ func (c *StateMvCommand) Run(args []string) int {
 
 	// We create two metas to track the two states
 	var meta1, meta2 Meta
+	var dryRun bool
 	cmdFlags := c.Meta.flagSet("state mv")
+	cmdFlags.BoolVar(&dryRun, "dry-run", false, "dry run")
 	cmdFlags.StringVar(&meta1.backupPath, "backup", "-", "backup")
 	cmdFlags.StringVar(&meta1.statePath, "state", DefaultStateFilename, "path")
 	cmdFlags.StringVar(&meta2.backupPath, "backup-out", "-", "backup")
@@ -92,7 +95,15 @@ func (c *StateMvCommand) Run(args []string) int {
 	// Get the item to add to the state
 	add := c.addableResult(results)
 
-	// Do the actual move
+	// Work out where everything ends up before the move modifies the state
+	moves, err := c.moveAddresses(results, add, args[1])
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateMv, err))
+		return cli.RunResultHelp
+	}
+
+	// Do the actual move. For a dry run this still happens in memory so
+	// that an invalid move is reported the same way.
 	if err := stateFromReal.Remove(args[0]); err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateMv, err))
 		return 1
@@ -103,6 +114,26 @@ func (c *StateMvCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Point anything that depended on the moved item at its new address.
+	// This only makes sense if it stayed in the same state.
+	if stateToReal == stateFromReal {
+		if err := stateToReal.RenameDependencies(args[0], args[1]); err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateMv, err))
+			return 1
+		}
+	}
+
+	// Clean up any modules that were left empty by the move
+	stateFromReal.Prune()
+	stateToReal.Prune()
+
+	if dryRun {
+		for _, m := range moves {
+			c.Ui.Output(fmt.Sprintf("Would move %s to %s", m[0], m[1]))
+		}
+		return 0
+	}
+
 	// Write the new state
 	if err := stateTo.WriteState(stateToReal); err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateMvPersist, err))
@@ -180,6 +211,95 @@ func (c *StateMvCommand) addableResult(results []*terraform.StateFilterResult) i
 	}
 }
 
+// moveAddresses returns the address of each resource that moving add to
+// toAddrRaw would move, paired with the address it would be moved to.
+// This follows the semantics of terraform.State.Add, so counted resources
+// and the resources of nested modules are listed individually.
+func (c *StateMvCommand) moveAddresses(
+	results []*terraform.StateFilterResult,
+	add interface{},
+	toAddrRaw string) ([][2]string, error) {
+	toAddr, err := terraform.ParseResourceAddress(toAddrRaw)
+	if err != nil {
+		return nil, err
+	}
+
+	var moves [][2]string
+	switch v := add.(type) {
+	case []*terraform.ModuleState:
+		root := v[0]
+		for _, ms := range v {
+			if ms != root && !root.IsDescendent(ms) {
+				continue
+			}
+
+			keys := make([]string, 0, len(ms.Resources))
+			for k := range ms.Resources {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+
+			for _, k := range keys {
+				key, err := terraform.ParseResourceStateKey(k)
+				if err != nil {
+					return nil, err
+				}
+
+				from := &terraform.ResourceAddress{
+					Path:  ms.Path[1:],
+					Mode:  key.Mode,
+					Type:  key.Type,
+					Name:  key.Name,
+					Index: key.Index,
+				}
+				to := from.Copy()
+				to.Path = append(
+					append([]string{}, toAddr.Path...), ms.Path[len(root.Path):]...)
+
+				moves = append(moves, [2]string{from.String(), to.String()})
+			}
+		}
+
+	case *terraform.ResourceState, []*terraform.ResourceState:
+		// Collect the addresses of the resources in the same way as
+		// addableResult did.
+		var from []*terraform.ResourceAddress
+		first := results[0].Value.(*terraform.ResourceState)
+		for _, r := range results {
+			rs, ok := r.Value.(*terraform.ResourceState)
+			if !ok || rs.Type != first.Type {
+				continue
+			}
+
+			addr, err := terraform.ParseResourceAddress(r.Address)
+			if err != nil {
+				return nil, err
+			}
+			from = append(from, addr)
+		}
+
+		for i, addr := range from {
+			// Moving to a module keeps the name of the resource
+			to := toAddr.Copy()
+			if to.Type == "" {
+				to.Mode = addr.Mode
+				to.Type = addr.Type
+				to.Name = addr.Name
+			}
+			if len(from) > 1 {
+				to.Index = i
+			}
+
+			moves = append(moves, [2]string{addr.String(), to.String()})
+		}
+
+	default:
+		moves = append(moves, [2]string{results[0].Address, toAddr.String()})
+	}
+
+	return moves, nil
+}
+
 func (c *StateMvCommand) Help() string {
 	helpText := `
 Usage: terraform state mv [options] ADDRESS ADDRESS
@@ -213,6 +333,11 @@ Options:
                       to be specified if -state-out is set to a different path
                       than -state.
 
+  -dry-run            If specified, print the address each item would be
+                      moved to, including every instance of a counted
+                      resource, without modifying any state or creating
+                      backups.
+
   -state=PATH         Path to a Terraform state file to use to look
                       up Terraform-managed resources. By default it will
                       use the state "terraform.tfstate" if it exists.
@@ -3,6 +3,7 @@ package command
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -72,6 +73,216 @@ func TestStateMv(t *testing.T) {
 	testStateOutput(t, backups[0], testStateMvOutputOriginal)
 }
 
+func TestStateMv_dependencies(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+						},
+					},
+
+					"test_instance.baz": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.foo", "test_instance.foobar"},
+						Primary: &terraform.InstanceState{
+							ID: "foo",
+						},
+					},
+				},
+			},
+			&terraform.ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.other": &terraform.ResourceState{
+						Type:         "test_instance",
+						Dependencies: []string{"test_instance.foo"},
+						Primary: &terraform.InstanceState{
+							ID: "child",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMvCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+		"test_instance.bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Only the dependencies on the moved resource are renamed. The
+	// dependency in the child module is on a test_instance.foo of its own.
+	testStateOutput(t, statePath, testStateMvDependenciesOutput)
+}
+
+func TestStateMv_dryRun(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo.0": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "foo",
+						},
+					},
+
+					"test_instance.foo.1": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMvCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-dry-run",
+		"test_instance.foo",
+		"test_instance.bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := strings.TrimSpace(`
+Would move test_instance.foo[0] to test_instance.bar[0]
+Would move test_instance.foo[1] to test_instance.bar[1]
+`)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+
+	// The state must be untouched and not backed up
+	if actual := testStateRead(t, statePath); !actual.Equal(state) {
+		t.Fatalf("bad:\n%s", actual)
+	}
+	if backups := testStateBackups(t, filepath.Dir(statePath)); len(backups) != 0 {
+		t.Fatalf("bad: %#v", backups)
+	}
+}
+
+func TestStateMv_dryRunModule(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path:      []string{"root"},
+				Resources: map[string]*terraform.ResourceState{},
+			},
+
+			&terraform.ModuleState{
+				Path: []string{"root", "foo"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "foo",
+						},
+					},
+				},
+			},
+
+			&terraform.ModuleState{
+				Path: []string{"root", "foo", "child"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.bar.0": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar0",
+						},
+					},
+					"test_instance.bar.1": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar1",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, state)
+	stateOutPath := statePath + ".out"
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateMvCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-state-out", stateOutPath,
+		"-dry-run",
+		"module.foo",
+		"module.bar",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := strings.TrimSpace(ui.OutputWriter.String())
+	expected := strings.TrimSpace(`
+Would move module.foo.test_instance.foo to module.bar.test_instance.foo
+Would move module.foo.module.child.test_instance.bar[0] to module.bar.module.child.test_instance.bar[0]
+Would move module.foo.module.child.test_instance.bar[1] to module.bar.module.child.test_instance.bar[1]
+`)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+
+	// Neither state may be written
+	if actual := testStateRead(t, statePath); !actual.Equal(state) {
+		t.Fatalf("bad:\n%s", actual)
+	}
+	if _, err := os.Stat(stateOutPath); err == nil {
+		t.Fatal("destination state should not be written")
+	}
+	if backups := testStateBackups(t, filepath.Dir(statePath)); len(backups) != 0 {
+		t.Fatalf("bad: %#v", backups)
+	}
+}
+
 func TestStateMv_backupExplicit(t *testing.T) {
 	td := tempDir(t)
 	defer os.RemoveAll(td)
@@ -651,6 +862,24 @@ test_instance.baz:
   foo = value
 `
 
+const testStateMvDependenciesOutput = `
+test_instance.bar:
+  ID = bar
+test_instance.baz:
+  ID = foo
+
+  Dependencies:
+    test_instance.bar
+    test_instance.foobar
+
+module.child:
+  test_instance.other:
+    ID = child
+
+    Dependencies:
+      test_instance.foo
+`
+
 const testStateMvCount_stateOut = `
 test_instance.bar.0:
   ID = foo
@@ -791,8 +1020,6 @@ test_instance.foo.10:
 
 const testStateMvNestedModule_stateOut = `
 <no state>
-module.bar:
-  <no state>
 module.bar.child1:
   test_instance.foo:
     ID = bar
@@ -0,0 +1,140 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateRestoreCommand is a Command implementation that replaces the
+// current state with a snapshot created by "terraform state snapshot".
+type StateRestoreCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateRestoreCommand) Run(args []string) int {
+	args = c.Meta.process(args, true)
+
+	var flagForce bool
+	cmdFlags := c.Meta.flagSet("state restore")
+	cmdFlags.BoolVar(&flagForce, "force", false, "")
+	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "-", "backup")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+
+	if len(args) != 1 {
+		c.Ui.Error("Exactly one argument expected: name of the snapshot")
+		return cli.RunResultHelp
+	}
+	path, err := stateSnapshotPath(args[0])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	// Read the snapshot
+	f, err := os.Open(path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading snapshot %q: %s", args[0], err))
+		return 1
+	}
+	snapshot, err := terraform.ReadState(f)
+	f.Close()
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Error reading snapshot %q: %s", args[0], err))
+		return 1
+	}
+
+	state, err := c.StateMeta.State(&c.Meta)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return cli.RunResultHelp
+	}
+	if err := state.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	// A snapshot of an unrelated state is most likely a mistake
+	stateReal := state.State()
+	if !flagForce && !stateReal.Empty() && !stateReal.SameLineage(snapshot) {
+		c.Ui.Error(strings.TrimSpace(errStateRestoreLineage))
+		return 1
+	}
+
+	// The serial of the snapshot is bumped past the current state when it
+	// is written so that the restore is seen as the newest version.
+	if err := state.WriteState(snapshot); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRestorePersist, err))
+		return 1
+	}
+	if err := state.PersistState(); err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateRestorePersist, err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("State restored from %s.", path))
+	return 0
+}
+
+func (c *StateRestoreCommand) Help() string {
+	helpText := `
+Usage: terraform state restore [options] NAME
+
+  Replace the current state with a snapshot.
+
+  This command loads the snapshot NAME.tfstate.snapshot created by
+  "terraform state snapshot" and writes it as the current state. The
+  command will refuse to restore a snapshot of a state with a different
+  lineage unless the "-force" flag is given.
+
+  This command creates a timestamped backup of the state on every invocation.
+  This can't be disabled. Due to the destructive nature of this command,
+  the backup is ensured by Terraform for safety reasons.
+
+Options:
+
+  -backup=PATH        Path where Terraform should write the backup
+                      state. This can't be disabled. If not set, Terraform
+                      will write it to the same path as the statefile with
+                      a backup extension. This backup will be made in addition
+                      to the timestamped backup.
+
+  -force              Restore the snapshot even if its lineage doesn't
+                      match the current state.
+
+  -state=statefile    Path to a Terraform state file to use to look
+                      up Terraform-managed resources. By default it will
+                      use the state "terraform.tfstate" if it exists.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateRestoreCommand) Synopsis() string {
+	return "Restore the state from a named snapshot"
+}
+
+const errStateRestoreLineage = `
+The lineages do not match! The snapshot will not be restored.
+
+The "lineage" is a unique identifier given to a state on creation. The
+snapshot was taken from a different state than the current one, so restoring
+it could lose track of the resources in the current state.
+
+Please verify you're restoring the correct snapshot. If you're sure you are,
+you can force the behavior with the "-force" flag.
+`
+
+const errStateRestorePersist = `Error saving the restored state: %s
+
+The snapshot may not have been fully restored. A backup of the state as
+it was before the restore was created next to the state file. Please
+resolve the issue above and try again.`
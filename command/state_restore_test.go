@@ -0,0 +1,163 @@
+package command
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+func TestStateRestore(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	original := testState()
+	original.Serial = 3
+	statePath := testStateFileDefault(t, original)
+
+	// Take a snapshot
+	p := testProvider()
+	ui := new(cli.MockUi)
+	snapshot := &StateSnapshotCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+	args := []string{
+		"-state", statePath,
+		"before",
+	}
+	if code := snapshot.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Modify the state after the snapshot
+	modified := original.DeepCopy()
+	modified.Serial = 4
+	modified.RootModule().Resources["test_instance.bar"] = &terraform.ResourceState{
+		Type: "test_instance",
+		Primary: &terraform.InstanceState{
+			ID: "baz",
+		},
+	}
+	testStateFileDefault(t, modified)
+
+	// Restore the snapshot
+	ui = new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := testStateRead(t, statePath)
+	if _, ok := actual.RootModule().Resources["test_instance.bar"]; ok {
+		t.Fatalf("bad: %s", actual)
+	}
+	if _, ok := actual.RootModule().Resources["test_instance.foo"]; !ok {
+		t.Fatalf("bad: %s", actual)
+	}
+	if actual.Lineage != original.Lineage {
+		t.Fatalf("bad lineage: %q", actual.Lineage)
+	}
+	if actual.Serial <= modified.Serial {
+		t.Fatalf("serial should be higher than %d: %d", modified.Serial, actual.Serial)
+	}
+
+	// The modified state was backed up
+	backups := testStateBackups(t, tmp)
+	if len(backups) != 1 {
+		t.Fatalf("bad: %#v", backups)
+	}
+	if backup := testStateRead(t, backups[0]); !backup.Equal(modified) {
+		t.Fatalf("bad: %s", backup)
+	}
+}
+
+func TestStateRestore_lineage(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	snapshotState := testState()
+	snapshotState.Lineage = "snapshot"
+	testStateFileDefault(t, snapshotState)
+	if err := os.Rename(DefaultStateFilename, "before.tfstate.snapshot"); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	current := testState()
+	current.Lineage = "current"
+	statePath := testStateFileDefault(t, current)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"before",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "lineages do not match") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+	if actual := testStateRead(t, statePath); actual.Lineage != "current" {
+		t.Fatalf("state should not be modified: %s", actual.Lineage)
+	}
+
+	// With -force the snapshot is restored anyway
+	ui = new(cli.MockUi)
+	c.Meta.Ui = ui
+	args = []string{
+		"-state", statePath,
+		"-force",
+		"before",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if actual := testStateRead(t, statePath); actual.Lineage != "snapshot" {
+		t.Fatalf("bad: %s", actual.Lineage)
+	}
+}
+
+func TestStateRestore_missing(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	statePath := testStateFileDefault(t, testState())
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRestoreCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"nope",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Error reading snapshot") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
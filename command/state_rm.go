@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/hashicorp/terraform/terraform"
 	"github.com/mitchellh/cli"
 )
 
@@ -24,6 +25,11 @@ func (c *StateRmCommand) Run(args []string) int {
 	}
 	args = cmdFlags.Args()
 
+	if len(args) == 0 {
+		c.Ui.Error("At least one address is required.")
+		return 1
+	}
+
 	state, err := c.StateMeta.State(&c.Meta)
 	if err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
@@ -40,10 +46,30 @@ func (c *StateRmCommand) Run(args []string) int {
 		return 1
 	}
 
+	// Make sure every address matches something before removing anything,
+	// so that a mistyped address doesn't leave the state partially edited.
+	var missing []string
+	for _, addr := range args {
+		results, err := (&terraform.StateFilter{State: stateReal}).Filter(addr)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf(errStateRm, err))
+			return 1
+		}
+		if len(results) == 0 {
+			missing = append(missing, addr)
+		}
+	}
+	if len(missing) > 0 {
+		c.Ui.Error(fmt.Sprintf(errStateRm, fmt.Sprintf(
+			"no items in the state match: %s", strings.Join(missing, ", "))))
+		return 1
+	}
+
 	if err := stateReal.Remove(args...); err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateRm, err))
 		return 1
 	}
+	stateReal.Prune()
 
 	if err := state.WriteState(stateReal); err != nil {
 		c.Ui.Error(fmt.Sprintf(errStateRmPersist, err))
@@ -66,9 +92,13 @@ Usage: terraform state rm [options] ADDRESS...
   Remove one or more items from the Terraform state.
 
   This command removes one or more items from the Terraform state based
-  on the address given. You can view and list the available resources
+  on the addresses given. You can view and list the available resources
   with "terraform state list".
 
+  All the items are removed in a single change to the state. If any of
+  the addresses is invalid or doesn't match anything in the state, nothing
+  is removed.
+
   This command creates a timestamped backup of the state on every invocation.
   This can't be disabled. Due to the destructive nature of this command,
   the backup is ensured by Terraform for safety reasons.
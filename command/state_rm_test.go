@@ -3,6 +3,7 @@ package command
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/terraform"
@@ -71,6 +72,60 @@ func TestStateRm(t *testing.T) {
 	testStateOutput(t, backups[0], testStateRmOutputOriginal)
 }
 
+func TestStateRm_dataSource(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+						},
+					},
+
+					"data.test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "baz",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cases := map[string]string{
+		"data.test_instance.foo": testStateRmDataOutput,
+		"test_instance.foo":      testStateRmManagedOutput,
+	}
+
+	for addr, expected := range cases {
+		statePath := testStateFile(t, state)
+
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &StateRmCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-state", statePath,
+			addr,
+		}
+		if code := c.Run(args); code != 0 {
+			t.Fatalf("%s: bad: %d\n\n%s", addr, code, ui.ErrorWriter.String())
+		}
+
+		// Only the resource with the matching mode is removed
+		testStateOutput(t, statePath, expected)
+	}
+}
+
 func TestStateRm_backupExplicit(t *testing.T) {
 	td := tempDir(t)
 	defer os.RemoveAll(td)
@@ -139,6 +194,199 @@ func TestStateRm_backupExplicit(t *testing.T) {
 	testStateOutput(t, backupPath, testStateRmOutputOriginal)
 }
 
+func TestStateRm_pruneModule(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.bar": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "foo",
+							Attributes: map[string]string{
+								"foo": "value",
+								"bar": "value",
+							},
+						},
+					},
+				},
+			},
+
+			&terraform.ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRmCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"module.child.test_instance.foo",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// The now-empty child module should be gone
+	testStateOutput(t, statePath, testStateRmOutput)
+}
+
+func TestStateRm_multiple(t *testing.T) {
+	statePath := testStateFile(t, testStateRmMultipleState())
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRmCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+		"module.child",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	// Test it is correct
+	testStateOutput(t, statePath, testStateRmOutput)
+
+	// Test there is a single backup of the original state
+	backups := testStateBackups(t, filepath.Dir(statePath))
+	if len(backups) != 1 {
+		t.Fatalf("bad: %#v", backups)
+	}
+	testStateOutput(t, backups[0], testStateRmMultipleOutputOriginal)
+}
+
+func TestStateRm_multipleInvalid(t *testing.T) {
+	cases := map[string]string{
+		"invalid":   "test_instance.foo[bad",
+		"not found": "test_instance.missing",
+	}
+
+	for name, addr := range cases {
+		statePath := testStateFile(t, testStateRmMultipleState())
+
+		p := testProvider()
+		ui := new(cli.MockUi)
+		c := &StateRmCommand{
+			Meta: Meta{
+				ContextOpts: testCtxConfig(p),
+				Ui:          ui,
+			},
+		}
+
+		args := []string{
+			"-state", statePath,
+			"test_instance.foo",
+			addr,
+			"module.child",
+		}
+		if code := c.Run(args); code != 1 {
+			t.Fatalf("%s: bad: %d\n\n%s", name, code, ui.OutputWriter.String())
+		}
+		if !strings.Contains(ui.ErrorWriter.String(), "Error removing items") {
+			t.Fatalf("%s: bad: %s", name, ui.ErrorWriter.String())
+		}
+
+		// Nothing is removed and no backup is written
+		testStateOutput(t, statePath, testStateRmMultipleOutputOriginal)
+		if backups := testStateBackups(t, filepath.Dir(statePath)); len(backups) != 0 {
+			t.Fatalf("%s: bad: %#v", name, backups)
+		}
+	}
+}
+
+func TestStateRm_noAddress(t *testing.T) {
+	statePath := testStateFile(t, testStateRmMultipleState())
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateRmCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+	}
+	if code := c.Run(args); code == 0 {
+		t.Fatalf("should fail: %s", ui.OutputWriter.String())
+	}
+
+	testStateOutput(t, statePath, testStateRmMultipleOutputOriginal)
+}
+
+func testStateRmMultipleState() *terraform.State {
+	return &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+							Attributes: map[string]string{
+								"foo": "value",
+								"bar": "value",
+							},
+						},
+					},
+
+					"test_instance.bar": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "foo",
+							Attributes: map[string]string{
+								"foo": "value",
+								"bar": "value",
+							},
+						},
+					},
+				},
+			},
+			&terraform.ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.baz": &terraform.ResourceState{
+						Type: "test_instance",
+						Primary: &terraform.InstanceState{
+							ID: "baz",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func TestStateRm_noState(t *testing.T) {
 	tmp, cwd := testCwd(t)
 	defer testFixCwd(t, tmp, cwd)
@@ -169,9 +417,34 @@ test_instance.foo:
   foo = value
 `
 
+const testStateRmMultipleOutputOriginal = `
+test_instance.bar:
+  ID = foo
+  bar = value
+  foo = value
+test_instance.foo:
+  ID = bar
+  bar = value
+  foo = value
+
+module.child:
+  test_instance.baz:
+    ID = baz
+`
+
 const testStateRmOutput = `
 test_instance.bar:
   ID = foo
   bar = value
   foo = value
 `
+
+const testStateRmDataOutput = `
+test_instance.foo:
+  ID = bar
+`
+
+const testStateRmManagedOutput = `
+data.test_instance.foo:
+  ID = baz
+`
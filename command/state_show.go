@@ -94,6 +94,14 @@ func (c *StateShowCommand) Run(args []string) int {
 	config := columnize.DefaultConfig()
 	config.Glue = " = "
 	c.Ui.Output(columnize.Format(output, config))
+
+	// Show the provider metadata recorded for the resource, if any
+	if instance.Parent != nil {
+		if rs, ok := instance.Parent.Value.(*terraform.ResourceState); ok && rs.ProviderMeta != "" {
+			c.Ui.Output(fmt.Sprintf("\nProvider metadata: %s", rs.ProviderMeta))
+		}
+	}
+
 	return 0
 }
 
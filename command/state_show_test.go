@@ -56,6 +56,54 @@ func TestStateShow(t *testing.T) {
 	}
 }
 
+func TestStateShow_providerMeta(t *testing.T) {
+	state := &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.foo": &terraform.ResourceState{
+						Type:         "test_instance",
+						ProviderMeta: "1.2.3",
+						Primary: &terraform.InstanceState{
+							ID: "bar",
+							Attributes: map[string]string{
+								"foo": "value",
+								"bar": "value",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	statePath := testStateFile(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateShowCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"test_instance.foo",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	expected := strings.TrimSpace(testStateShowProviderMetaOutput) + "\n"
+	actual := ui.OutputWriter.String()
+	if actual != expected {
+		t.Fatalf("Expected:\n%q\n\nTo equal: %q", actual, expected)
+	}
+}
+
 func TestStateShow_multi(t *testing.T) {
 	state := &terraform.State{
 		Modules: []*terraform.ModuleState{
@@ -182,3 +230,11 @@ id  = bar
 bar = value
 foo = value
 `
+
+const testStateShowProviderMetaOutput = `
+id  = bar
+bar = value
+foo = value
+
+Provider metadata: 1.2.3
+`
@@ -0,0 +1,133 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/terraform/terraform"
+	"github.com/mitchellh/cli"
+)
+
+// StateSnapshotExtension is the extension added to the name of a state
+// snapshot to get the path of the file it is stored in.
+const StateSnapshotExtension = ".tfstate.snapshot"
+
+// StateSnapshotCommand is a Command implementation that saves a copy of
+// the current state under a name so that it can later be restored with
+// "terraform state restore".
+type StateSnapshotCommand struct {
+	Meta
+	StateMeta
+}
+
+func (c *StateSnapshotCommand) Run(args []string) int {
+	args = c.Meta.process(args, true)
+
+	var flagForce bool
+	cmdFlags := c.Meta.flagSet("state snapshot")
+	cmdFlags.BoolVar(&flagForce, "force", false, "")
+	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
+	if err := cmdFlags.Parse(args); err != nil {
+		return cli.RunResultHelp
+	}
+	args = cmdFlags.Args()
+
+	if len(args) != 1 {
+		c.Ui.Error("Exactly one argument expected: name of the snapshot")
+		return cli.RunResultHelp
+	}
+	path, err := stateSnapshotPath(args[0])
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	if !flagForce {
+		if _, err := os.Stat(path); err == nil {
+			c.Ui.Error(fmt.Sprintf(errStateSnapshotExists, path))
+			return 1
+		}
+	}
+
+	state, err := c.StateMeta.State(&c.Meta)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf(errStateLoadingState, err))
+		return cli.RunResultHelp
+	}
+	if err := state.RefreshState(); err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
+		return 1
+	}
+
+	stateReal := state.State()
+	if stateReal == nil {
+		c.Ui.Error(fmt.Sprintf(errStateNotFound))
+		return 1
+	}
+
+	// The state is written as-is so that the serial and lineage of the
+	// snapshot match the state it was taken from.
+	f, err := os.Create(path)
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write snapshot: %s", err))
+		return 1
+	}
+	err = terraform.WriteState(stateReal, f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to write snapshot: %s", err))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf("State snapshot written to %s.", path))
+	return 0
+}
+
+func (c *StateSnapshotCommand) Help() string {
+	helpText := `
+Usage: terraform state snapshot [options] NAME
+
+  Save a copy of the current state as a named snapshot.
+
+  The state is written unmodified to NAME.tfstate.snapshot in the current
+  directory, including its serial and lineage. Use "terraform state restore"
+  to load the snapshot back into the state at a later time.
+
+  Snapshots are separate from the backups Terraform creates automatically
+  and are never removed or overwritten by other commands.
+
+Options:
+
+  -force              Overwrite the snapshot if one with the same name
+                      already exists.
+
+  -state=statefile    Path to a Terraform state file to use to look
+                      up Terraform-managed resources. By default it will
+                      use the state "terraform.tfstate" if it exists.
+
+`
+	return strings.TrimSpace(helpText)
+}
+
+func (c *StateSnapshotCommand) Synopsis() string {
+	return "Save the current state as a named snapshot"
+}
+
+// stateSnapshotPath returns the path of the file used for the snapshot
+// with the given name.
+func stateSnapshotPath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("Invalid snapshot name %q: the name must not be empty "+
+			"or contain path separators", name)
+	}
+
+	return name + StateSnapshotExtension, nil
+}
+
+const errStateSnapshotExists = `A snapshot already exists at %s!
+
+Terraform will not overwrite an existing snapshot. Please choose another
+name or specify the "-force" flag to replace it.`
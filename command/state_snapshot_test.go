@@ -0,0 +1,165 @@
+package command
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestStateSnapshot(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	state := testState()
+	state.Serial = 5
+	statePath := testStateFileDefault(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateSnapshotCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"before",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	actual := testStateRead(t, "before.tfstate.snapshot")
+	if !actual.Equal(state) {
+		t.Fatalf("bad: %s", actual)
+	}
+	if actual.Serial != state.Serial {
+		t.Fatalf("bad serial: %d", actual.Serial)
+	}
+	if actual.Lineage != state.Lineage {
+		t.Fatalf("bad lineage: %q", actual.Lineage)
+	}
+
+	// The snapshot is not a modification so no backup is made
+	if backups := testStateBackups(t, tmp); len(backups) != 0 {
+		t.Fatalf("bad: %#v", backups)
+	}
+}
+
+func TestStateSnapshot_exists(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	statePath := testStateFileDefault(t, testState())
+	if err := ioutil.WriteFile("before.tfstate.snapshot", []byte("existing"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateSnapshotCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"before",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "already exists") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	data, err := ioutil.ReadFile("before.tfstate.snapshot")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(data) != "existing" {
+		t.Fatalf("snapshot was overwritten: %s", data)
+	}
+
+	// With -force the snapshot is replaced
+	ui = new(cli.MockUi)
+	c.Meta.Ui = ui
+	args = []string{
+		"-state", statePath,
+		"-force",
+		"before",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	if actual := testStateRead(t, "before.tfstate.snapshot"); !actual.Equal(testState()) {
+		t.Fatalf("bad: %s", actual)
+	}
+}
+
+func TestStateSnapshot_stateName(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	state := testState()
+	statePath := testStateFileDefault(t, state)
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateSnapshotCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	// A snapshot named after the state must not replace it
+	args := []string{
+		"-state", statePath,
+		"-force",
+		"terraform",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	if actual := testStateRead(t, "terraform.tfstate.snapshot"); !actual.Equal(state) {
+		t.Fatalf("bad: %s", actual)
+	}
+	if actual := testStateRead(t, statePath); actual.Serial != state.Serial {
+		t.Fatalf("state was modified: %s", actual)
+	}
+}
+
+func TestStateSnapshot_badName(t *testing.T) {
+	tmp, cwd := testCwd(t)
+	defer testFixCwd(t, tmp, cwd)
+
+	statePath := testStateFileDefault(t, testState())
+
+	p := testProvider()
+	ui := new(cli.MockUi)
+	c := &StateSnapshotCommand{
+		Meta: Meta{
+			ContextOpts: testCtxConfig(p),
+			Ui:          ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"../before",
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "Invalid snapshot name") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+}
@@ -1,11 +1,16 @@
 package command
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
+	"github.com/hashicorp/hil"
+	"github.com/hashicorp/hil/ast"
 	clistate "github.com/hashicorp/terraform/command/state"
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/state"
 	"github.com/hashicorp/terraform/terraform"
 )
@@ -19,22 +24,48 @@ type TaintCommand struct {
 func (c *TaintCommand) Run(args []string) int {
 	args = c.Meta.process(args, false)
 
-	var allowMissing bool
-	var module string
+	var allowMissing, autoApprove bool
+	var module, selector string
 	cmdFlags := c.Meta.flagSet("taint")
 	cmdFlags.BoolVar(&allowMissing, "allow-missing", false, "module")
+	cmdFlags.BoolVar(&autoApprove, "auto-approve", false, "auto-approve")
 	cmdFlags.StringVar(&module, "module", "", "module")
+	cmdFlags.StringVar(&selector, "selector", "", "selector")
 	cmdFlags.StringVar(&c.Meta.statePath, "state", DefaultStateFilename, "path")
 	cmdFlags.StringVar(&c.Meta.stateOutPath, "state-out", "", "path")
 	cmdFlags.StringVar(&c.Meta.backupPath, "backup", "", "path")
 	cmdFlags.BoolVar(&c.Meta.stateLock, "lock", true, "lock state")
+	cmdFlags.DurationVar(&c.Meta.stateLockTimeout, "lock-timeout", 0, "lock timeout")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
 
-	// Require the one argument for the resource to taint
 	args = cmdFlags.Args()
+	if module == "" {
+		module = "root"
+	} else {
+		module = "root." + module
+	}
+
+	// With a selector, the resources to taint are found by evaluating
+	// the selector against the state rather than given by name.
+	if selector != "" {
+		if len(args) > 1 {
+			c.Ui.Error("The taint command expects at most one resource type with -selector.")
+			cmdFlags.Usage()
+			return 1
+		}
+
+		var resourceType string
+		if len(args) == 1 {
+			resourceType = args[0]
+		}
+
+		return c.runSelector(selector, resourceType, module, allowMissing, autoApprove)
+	}
+
+	// Require the one argument for the resource to taint
 	if len(args) != 1 {
 		c.Ui.Error("The taint command expects exactly one argument.")
 		cmdFlags.Usage()
@@ -42,11 +73,6 @@ func (c *TaintCommand) Run(args []string) int {
 	}
 
 	name := args[0]
-	if module == "" {
-		module = "root"
-	} else {
-		module = "root." + module
-	}
 
 	rsk, err := terraform.ParseResourceStateKey(name)
 	if err != nil {
@@ -59,36 +85,13 @@ func (c *TaintCommand) Run(args []string) int {
 		return 1
 	}
 
-	// Load the backend
-	b, err := c.Backend(nil)
+	// Load and lock the state
+	st, unlock, err := c.lockedState()
 	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Failed to load backend: %s", err))
+		c.Ui.Error(err.Error())
 		return 1
 	}
-
-	// Get the state
-	env := c.Env()
-	st, err := b.State(env)
-	if err != nil {
-		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
-		return 1
-	}
-	if err := st.RefreshState(); err != nil {
-		c.Ui.Error(fmt.Sprintf("Failed to load state: %s", err))
-		return 1
-	}
-
-	if c.Meta.stateLock {
-		lockInfo := state.NewLockInfo()
-		lockInfo.Operation = "taint"
-		lockID, err := clistate.Lock(st, lockInfo, c.Ui, c.Colorize())
-		if err != nil {
-			c.Ui.Error(fmt.Sprintf("Error locking state: %s", err))
-			return 1
-		}
-
-		defer clistate.Unlock(st, lockID, c.Ui, c.Colorize())
-	}
+	defer unlock()
 
 	// Get the actual state structure
 	s := st.State()
@@ -148,13 +151,8 @@ func (c *TaintCommand) Run(args []string) int {
 	// Taint the resource
 	rs.Taint()
 
-	log.Printf("[INFO] Writing state output to: %s", c.Meta.StateOutPath())
-	if err := st.WriteState(s); err != nil {
-		c.Ui.Error(fmt.Sprintf("Error writing state file: %s", err))
-		return 1
-	}
-	if err := st.PersistState(); err != nil {
-		c.Ui.Error(fmt.Sprintf("Error writing state file: %s", err))
+	if err := c.writeState(st, s); err != nil {
+		c.Ui.Error(err.Error())
 		return 1
 	}
 
@@ -164,9 +162,207 @@ func (c *TaintCommand) Run(args []string) int {
 	return 0
 }
 
+// runSelector taints all the resources in the module whose attributes
+// match the selector expression, optionally limited to a single type.
+func (c *TaintCommand) runSelector(selector, resourceType, module string, allowMissing, autoApprove bool) int {
+	root, err := hil.Parse(fmt.Sprintf("${%s}", selector))
+	if err != nil {
+		c.Ui.Error(fmt.Sprintf("Failed to parse selector: %s", err))
+		return 1
+	}
+
+	// Load and lock the state
+	st, unlock, err := c.lockedState()
+	if err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+	defer unlock()
+
+	// Find the matching resources in the module
+	s := st.State()
+	var mod *terraform.ModuleState
+	if !s.Empty() {
+		mod = s.ModuleByPath(strings.Split(module, "."))
+	}
+
+	var names []string
+	if mod != nil {
+		for name, rs := range mod.Resources {
+			rsk, err := terraform.ParseResourceStateKey(name)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf("Failed to parse resource name: %s", err))
+				return 1
+			}
+			if !rsk.Mode.Taintable() || rs.Primary == nil {
+				continue
+			}
+			if resourceType != "" && rsk.Type != resourceType {
+				continue
+			}
+
+			ok, err := taintSelectorMatch(root, rs.Primary.Attributes)
+			if err != nil {
+				c.Ui.Error(fmt.Sprintf(
+					"Error evaluating selector for %s: %s", name, err))
+				return 1
+			}
+			if ok {
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	if len(names) == 0 {
+		if allowMissing {
+			c.Ui.Output(fmt.Sprintf(
+				"No resources in the module %s matched the selector, but\n"+
+					"-allow-missing is set, so we're exiting successfully.",
+				module))
+			return 0
+		}
+
+		c.Ui.Error(fmt.Sprintf(
+			"No resources in the module %s matched the selector. There is nothing to taint.",
+			module))
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf(
+		"The following resources in the module %s matched the selector:\n\n  %s\n",
+		module, strings.Join(names, "\n  ")))
+
+	if !autoApprove {
+		ok, err := c.confirm(&terraform.InputOpts{
+			Id:          "taint",
+			Query:       "Do you really want to taint these resources?",
+			Description: "Only 'yes' or 'no' will be accepted.",
+		})
+		if err != nil {
+			c.Ui.Error(err.Error())
+			return 1
+		}
+		if !ok {
+			c.Ui.Error("Taint cancelled.")
+			return 1
+		}
+	}
+
+	for _, name := range names {
+		mod.Resources[name].Taint()
+	}
+
+	if err := c.writeState(st, s); err != nil {
+		c.Ui.Error(err.Error())
+		return 1
+	}
+
+	c.Ui.Output(fmt.Sprintf(
+		"%d resource(s) in the module %s have been marked as tainted!",
+		len(names), module))
+	return 0
+}
+
+// lockedState loads the state from the backend and locks it if locking
+// is enabled. The returned function releases the lock.
+func (c *TaintCommand) lockedState() (state.State, func(), error) {
+	// Load the backend
+	b, err := c.Backend(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to load backend: %s", err)
+	}
+
+	// Get the state
+	env := c.Env()
+	st, err := b.State(env)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Failed to load state: %s", err)
+	}
+	if err := st.RefreshState(); err != nil {
+		return nil, nil, fmt.Errorf("Failed to load state: %s", err)
+	}
+
+	unlock := func() {}
+	if c.Meta.stateLock {
+		lockInfo := state.NewLockInfo()
+		lockInfo.Operation = "taint"
+		lockCtx, cancel := context.WithTimeout(context.Background(), c.Meta.stateLockTimeout)
+		defer cancel()
+
+		lockID, err := clistate.Lock(lockCtx, st, lockInfo, c.Ui, c.Colorize())
+		if err != nil {
+			return nil, nil, fmt.Errorf("Error locking state: %s", err)
+		}
+
+		unlock = func() {
+			clistate.Unlock(st, lockID, c.Ui, c.Colorize())
+		}
+	}
+
+	return st, unlock, nil
+}
+
+// writeState writes the tainted state s to st and persists it.
+func (c *TaintCommand) writeState(st state.State, s *terraform.State) error {
+	log.Printf("[INFO] Writing state output to: %s", c.Meta.StateOutPath())
+	if err := st.WriteState(s); err != nil {
+		return fmt.Errorf("Error writing state file: %s", err)
+	}
+	if err := st.PersistState(); err != nil {
+		return fmt.Errorf("Error writing state file: %s", err)
+	}
+
+	return nil
+}
+
+// taintSelectorMatch evaluates the parsed selector against the flattened
+// attributes of a resource. Every variable in the selector refers to an
+// attribute by its flattened key, such as "tags.env". Attributes that
+// don't exist evaluate to an empty string.
+func taintSelectorMatch(root ast.Node, attrs map[string]string) (bool, error) {
+	vars := make(map[string]ast.Variable)
+	root.Accept(func(n ast.Node) ast.Node {
+		if v, ok := n.(*ast.VariableAccess); ok {
+			vars[v.Name] = ast.Variable{
+				Type:  ast.TypeString,
+				Value: attrs[v.Name],
+			}
+		}
+
+		return n
+	})
+
+	result, err := hil.Eval(root, &hil.EvalConfig{
+		GlobalScope: &ast.BasicScope{
+			VarMap:  vars,
+			FuncMap: config.Funcs(),
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+
+	switch result.Type {
+	case hil.TypeBool:
+		return result.Value.(bool), nil
+	case hil.TypeString:
+		// Bools are converted to strings by the string interpolation
+		switch result.Value.(string) {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+	}
+
+	return false, fmt.Errorf("selector must evaluate to true or false, got %v", result.Value)
+}
+
 func (c *TaintCommand) Help() string {
 	helpText := `
 Usage: terraform taint [options] name
+       terraform taint [options] -selector=EXPR [type]
 
   Manually mark a resource as tainted, forcing a destroy and recreate
   on the next plan/apply.
@@ -177,23 +373,38 @@ Usage: terraform taint [options] name
   its own will not modify infrastructure. This command can be undone by
   reverting the state backup file that is created.
 
+  With -selector, every resource in the module whose attributes in the
+  state match the expression is tainted instead, optionally limited to
+  resources of the given type. The expression uses the interpolation
+  syntax, with attributes referenced by their flattened names, for
+  example: -selector='tags.env == "stage"'. The matching resources are
+  listed and must be confirmed before they're tainted.
+
 Options:
 
   -allow-missing      If specified, the command will succeed (exit code 0)
                       even if the resource is missing.
 
+  -auto-approve       Skip interactive approval of the resources matched
+                      by -selector.
+
   -backup=path        Path to backup the existing state file before
                       modifying. Defaults to the "-state-out" path with
                       ".backup" extension. Set to "-" to disable backup.
 
   -lock=true          Lock the state file when locking is supported.
 
+  -lock-timeout=0s    Duration to retry a state lock.
+
   -module=path        The module path where the resource lives. By
                       default this will be root. Child modules can be specified
                       by names. Ex. "consul" or "consul.vpc" (nested modules).
 
   -no-color           If specified, output won't contain any color.
 
+  -selector=expr      Taint all resources whose attributes match the
+                      expression rather than a single named resource.
+
   -state=path         Path to read and save state (unless state-out
                       is specified). Defaults to "terraform.tfstate".
 
@@ -391,6 +391,191 @@ func TestTaint_module(t *testing.T) {
 	testStateOutput(t, statePath, testTaintModuleStr)
 }
 
+func testTaintSelectorState() *terraform.State {
+	instance := func(id, env string) *terraform.ResourceState {
+		return &terraform.ResourceState{
+			Type: "test_instance",
+			Primary: &terraform.InstanceState{
+				ID: id,
+				Attributes: map[string]string{
+					"id":       id,
+					"tags.%":   "1",
+					"tags.env": env,
+				},
+			},
+		}
+	}
+
+	return &terraform.State{
+		Modules: []*terraform.ModuleState{
+			&terraform.ModuleState{
+				Path: []string{"root"},
+				Resources: map[string]*terraform.ResourceState{
+					"test_instance.a": instance("a", "stage"),
+					"test_instance.b": instance("b", "prod"),
+					"test_instance.c": instance("c", "stage"),
+					"other_instance.d": &terraform.ResourceState{
+						Type: "other_instance",
+						Primary: &terraform.InstanceState{
+							ID: "d",
+							Attributes: map[string]string{
+								"id":       "d",
+								"tags.%":   "1",
+								"tags.env": "stage",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTaint_selector(t *testing.T) {
+	statePath := testStateFile(t, testTaintSelectorState())
+
+	ui := new(cli.MockUi)
+	c := &TaintCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-auto-approve",
+		"-selector", `tags.env == "stage"`,
+		"test_instance",
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	testStateOutput(t, statePath, testTaintSelectorStr)
+
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "test_instance.a\n  test_instance.c\n") {
+		t.Fatalf("matches should be listed: %s", output)
+	}
+	if strings.Contains(output, "test_instance.b") || strings.Contains(output, "other_instance.d") {
+		t.Fatalf("only matches should be listed: %s", output)
+	}
+}
+
+func TestTaint_selectorAllTypes(t *testing.T) {
+	statePath := testStateFile(t, testTaintSelectorState())
+
+	ui := new(cli.MockUi)
+	c := &TaintCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-auto-approve",
+		"-selector", `tags.env != "prod" && id != "c"`,
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	state := testStateRead(t, statePath)
+	for name, rs := range state.RootModule().Resources {
+		expected := name == "test_instance.a" || name == "other_instance.d"
+		if rs.Primary.Tainted != expected {
+			t.Fatalf("%s: expected tainted to be %t", name, expected)
+		}
+	}
+}
+
+func TestTaint_selectorConfirm(t *testing.T) {
+	statePath := testStateFile(t, testTaintSelectorState())
+
+	ui := new(cli.MockUi)
+	c := &TaintCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-selector", `tags.env == "stage"`,
+		"test_instance",
+	}
+
+	// Declining leaves the state alone
+	defer testInteractiveInput(t, []string{"no"})()
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	testStateOutput(t, statePath, testTaintSelectorDefaultStr)
+
+	// Accepting taints the matches
+	testInputResponse = []string{"yes"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+	testStateOutput(t, statePath, testTaintSelectorStr)
+}
+
+func TestTaint_selectorNoMatch(t *testing.T) {
+	statePath := testStateFile(t, testTaintSelectorState())
+
+	ui := new(cli.MockUi)
+	c := &TaintCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-auto-approve",
+		"-selector", `tags.env == "dev"`,
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+
+	// Allowed with -allow-missing
+	ui = new(cli.MockUi)
+	c.Meta.Ui = ui
+	args = append([]string{"-allow-missing"}, args...)
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.ErrorWriter.String())
+	}
+
+	testStateOutput(t, statePath, testTaintSelectorDefaultStr)
+}
+
+func TestTaint_selectorInvalid(t *testing.T) {
+	statePath := testStateFile(t, testTaintSelectorState())
+
+	ui := new(cli.MockUi)
+	c := &TaintCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{
+		"-state", statePath,
+		"-auto-approve",
+		"-selector", `tags.env`,
+	}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n\n%s", code, ui.OutputWriter.String())
+	}
+	if !strings.Contains(ui.ErrorWriter.String(), "true or false") {
+		t.Fatalf("bad: %s", ui.ErrorWriter.String())
+	}
+
+	testStateOutput(t, statePath, testTaintSelectorDefaultStr)
+}
+
 const testTaintStr = `
 test_instance.foo: (tainted)
   ID = bar
@@ -409,3 +594,41 @@ module.child:
   test_instance.blah: (tainted)
     ID = blah
 `
+
+const testTaintSelectorDefaultStr = `
+other_instance.d:
+  ID = d
+  tags.% = 1
+  tags.env = stage
+test_instance.a:
+  ID = a
+  tags.% = 1
+  tags.env = stage
+test_instance.b:
+  ID = b
+  tags.% = 1
+  tags.env = prod
+test_instance.c:
+  ID = c
+  tags.% = 1
+  tags.env = stage
+`
+
+const testTaintSelectorStr = `
+other_instance.d:
+  ID = d
+  tags.% = 1
+  tags.env = stage
+test_instance.a: (tainted)
+  ID = a
+  tags.% = 1
+  tags.env = stage
+test_instance.b:
+  ID = b
+  tags.% = 1
+  tags.env = prod
+test_instance.c: (tainted)
+  ID = c
+  tags.% = 1
+  tags.env = stage
+`
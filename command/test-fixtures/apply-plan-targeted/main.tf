@@ -0,0 +1,7 @@
+resource "test_instance" "foo" {
+  ami = "foo"
+}
+
+resource "test_instance" "bar" {
+  ami = "bar"
+}
@@ -0,0 +1,7 @@
+resource "test_instance" "foo" {
+    ami = "${test_instance.bar.ami}"
+}
+
+resource "test_instance" "bar" {
+    ami = "${test_instance.foo.ami}"
+}
@@ -0,0 +1,11 @@
+variable "used" {
+  default = "bar"
+}
+
+variable "unused" {
+  default = "baz"
+}
+
+resource "test_instance" "foo" {
+  ami = "${var.used}"
+}
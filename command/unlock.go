@@ -19,21 +19,28 @@ func (c *UnlockCommand) Run(args []string) int {
 	args = c.Meta.process(args, false)
 
 	force := false
+	info := false
 	cmdFlags := c.Meta.flagSet("force-unlock")
 	cmdFlags.BoolVar(&force, "force", false, "force")
+	cmdFlags.BoolVar(&info, "info", false, "info")
 	cmdFlags.Usage = func() { c.Ui.Error(c.Help()) }
 	if err := cmdFlags.Parse(args); err != nil {
 		return 1
 	}
 
 	args = cmdFlags.Args()
-	if len(args) == 0 {
-		c.Ui.Error("unlock requires a lock id argument")
-		return cli.RunResultHelp
-	}
 
-	lockID := args[0]
-	args = args[1:]
+	// Only showing the lock doesn't need to know the lock id
+	var lockID string
+	if !info {
+		if len(args) == 0 {
+			c.Ui.Error("unlock requires a lock id argument")
+			return cli.RunResultHelp
+		}
+
+		lockID = args[0]
+		args = args[1:]
+	}
 
 	// assume everything is initialized. The user can manually init if this is
 	// required.
@@ -66,6 +73,21 @@ func (c *UnlockCommand) Run(args []string) int {
 		return 1
 	}
 
+	if info {
+		holder, err := state.LockHolder(s)
+		if err != nil {
+			c.Ui.Error(fmt.Sprintf("Failed to check for the current lock: %s", err))
+			return 1
+		}
+		if holder == nil {
+			c.Ui.Error("The state is not locked.")
+			return 1
+		}
+
+		c.Ui.Output(strings.TrimSpace(holder.String()))
+		return 0
+	}
+
 	isLocal := false
 	switch s := st.(type) {
 	case *state.BackupState:
@@ -114,7 +136,8 @@ func (c *UnlockCommand) Run(args []string) int {
 
 func (c *UnlockCommand) Help() string {
 	helpText := `
-Usage: terraform force-unlock LOCK_ID [DIR]
+Usage: terraform force-unlock [options] LOCK_ID [DIR]
+       terraform force-unlock -info [DIR]
 
   Manually unlock the state for the defined configuration.
 
@@ -123,9 +146,18 @@ Usage: terraform force-unlock LOCK_ID [DIR]
   on the backend being used. Local state files cannot be unlocked by another
   process.
 
+  With -info, the lock isn't removed. Instead the information about the
+  current lock, such as its ID, who holds it and when it was acquired, is
+  shown. It is an error if the state isn't locked. Local state and the consul
+  and s3 backends store this information next to the state, so it is read
+  as-is. For other backends, -info briefly acquires the lock to check for a
+  holder and releases it right away if the state wasn't locked.
+
 Options:
 
   -force                 Don't ask for input for unlock confirmation.
+
+  -info                  Show the current lock without removing it.
 `
 	return strings.TrimSpace(helpText)
 }
@@ -2,6 +2,7 @@ package command
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/terraform/helper/copy"
@@ -100,3 +101,86 @@ func TestUnlock_inmemBackend(t *testing.T) {
 	}
 
 }
+
+func TestUnlock_info(t *testing.T) {
+	td := tempDir(t)
+	copy.CopyDir(testFixturePath("backend-inmem-locked"), td)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	// init backend
+	ui := new(cli.MockUi)
+	ci := &InitCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := ci.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n%s", code, ui.ErrorWriter)
+	}
+
+	ui = new(cli.MockUi)
+	c := &UnlockCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"-info"}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n%s\n%s", code, ui.OutputWriter.String(), ui.ErrorWriter.String())
+	}
+
+	// lockID set in the test fixture
+	output := ui.OutputWriter.String()
+	if !strings.Contains(output, "2b6a6738-5dd5-50d6-c0ae-f6352977666b") {
+		t.Fatalf("expected the lock id in the output:\n%s", output)
+	}
+	if !strings.Contains(output, "Created:") {
+		t.Fatalf("expected the lock creation time in the output:\n%s", output)
+	}
+
+	// The lock must still be held
+	ui = new(cli.MockUi)
+	c = &UnlockCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := c.Run(args); code != 0 {
+		t.Fatalf("bad: %d\n%s\n%s", code, ui.OutputWriter.String(), ui.ErrorWriter.String())
+	}
+}
+
+func TestUnlock_infoNotLocked(t *testing.T) {
+	td := tempDir(t)
+	copy.CopyDir(testFixturePath("backend-inmem"), td)
+	defer os.RemoveAll(td)
+	defer testChdir(t, td)()
+
+	// init backend
+	ui := new(cli.MockUi)
+	ci := &InitCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+	if code := ci.Run(nil); code != 0 {
+		t.Fatalf("bad: %d\n%s", code, ui.ErrorWriter)
+	}
+
+	ui = new(cli.MockUi)
+	c := &UnlockCommand{
+		Meta: Meta{
+			Ui: ui,
+		},
+	}
+
+	args := []string{"-info"}
+	if code := c.Run(args); code != 1 {
+		t.Fatalf("bad: %d\n%s\n%s", code, ui.OutputWriter.String(), ui.ErrorWriter.String())
+	}
+	if errOut := ui.ErrorWriter.String(); !strings.Contains(errOut, "not locked") {
+		t.Fatalf("bad: %s", errOut)
+	}
+}
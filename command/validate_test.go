@@ -1,6 +1,7 @@
 package command
 
 import (
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -70,9 +71,13 @@ func TestSameModuleMultipleTimesShouldFail(t *testing.T) {
 	if code != 1 {
 		t.Fatalf("Should have failed: %d\n\n%s", code, ui.ErrorWriter.String())
 	}
-	if !strings.HasSuffix(strings.TrimSpace(ui.ErrorWriter.String()), "multi_module: module repeated multiple times") {
+	if !strings.Contains(ui.ErrorWriter.String(), "multi_module: module repeated multiple times") {
 		t.Fatalf("Should have failed: %d\n\n'%s'", code, ui.ErrorWriter.String())
 	}
+	if !strings.HasSuffix(strings.TrimSpace(ui.ErrorWriter.String()), "main.tf:1:8, declared again at "+
+		filepath.Join(testFixturePath("validate-invalid/multiple_modules"), "main.tf")+":4:8)") {
+		t.Fatalf("Should have reported both declarations: '%s'", ui.ErrorWriter.String())
+	}
 }
 
 func TestSameResourceMultipleTimesShouldFail(t *testing.T) {
@@ -80,9 +85,13 @@ func TestSameResourceMultipleTimesShouldFail(t *testing.T) {
 	if code != 1 {
 		t.Fatalf("Should have failed: %d\n\n%s", code, ui.ErrorWriter.String())
 	}
-	if !strings.HasSuffix(strings.TrimSpace(ui.ErrorWriter.String()), "aws_instance.web: resource repeated multiple times") {
+	if !strings.Contains(ui.ErrorWriter.String(), "aws_instance.web: resource repeated multiple times") {
 		t.Fatalf("Should have failed: %d\n\n'%s'", code, ui.ErrorWriter.String())
 	}
+	if !strings.HasSuffix(strings.TrimSpace(ui.ErrorWriter.String()), "main.tf:1:10, declared again at "+
+		filepath.Join(testFixturePath("validate-invalid/multiple_resources"), "main.tf")+":4:10)") {
+		t.Fatalf("Should have reported both declarations: '%s'", ui.ErrorWriter.String())
+	}
 }
 
 func TestOutputWithoutValueShouldFail(t *testing.T) {
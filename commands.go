@@ -225,6 +225,12 @@ func init() {
 			}, nil
 		},
 
+		"state restore": func() (cli.Command, error) {
+			return &command.StateRestoreCommand{
+				Meta: meta,
+			}, nil
+		},
+
 		"state rm": func() (cli.Command, error) {
 			return &command.StateRmCommand{
 				Meta: meta,
@@ -254,6 +260,12 @@ func init() {
 				Meta: meta,
 			}, nil
 		},
+
+		"state snapshot": func() (cli.Command, error) {
+			return &command.StateSnapshotCommand{
+				Meta: meta,
+			}, nil
+		},
 	}
 }
 
@@ -70,6 +70,13 @@ func Append(c1, c2 *Config) (*Config, error) {
 		c.Resources = append(c.Resources, c2.Resources...)
 	}
 
+	if len(c1.Moved) > 0 || len(c2.Moved) > 0 {
+		c.Moved = make(
+			[]*Moved, 0, len(c1.Moved)+len(c2.Moved))
+		c.Moved = append(c.Moved, c1.Moved...)
+		c.Moved = append(c.Moved, c2.Moved...)
+	}
+
 	if len(c1.Variables) > 0 || len(c2.Variables) > 0 {
 		c.Variables = make(
 			[]*Variable, 0, len(c1.Variables)+len(c2.Variables))
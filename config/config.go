@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/hcl/hcl/token"
 	"github.com/hashicorp/hil"
 	"github.com/hashicorp/hil/ast"
 	"github.com/hashicorp/terraform/helper/hilmapstructure"
@@ -19,6 +20,10 @@ import (
 // resources, etc.) must follow.
 var NameRegexp = regexp.MustCompile(`(?i)\A[A-Z0-9_][A-Z0-9\-\_]*\z`)
 
+// providerNameRegexp matches a provider name with an optional alias,
+// such as "aws" or "aws.west".
+var providerNameRegexp = regexp.MustCompile(`(?i)\A[A-Z0-9_][A-Z0-9\-\_]*(\.[A-Z0-9_][A-Z0-9\-\_]*)?\z`)
+
 // Config is the configuration that comes from loading a collection
 // of Terraform templates.
 type Config struct {
@@ -34,6 +39,7 @@ type Config struct {
 	Resources       []*Resource
 	Variables       []*Variable
 	Outputs         []*Output
+	Moved           []*Moved
 
 	// The fields below can be filled in by loaders for validation
 	// purposes.
@@ -55,6 +61,34 @@ type Module struct {
 	Name      string
 	Source    string
 	RawConfig *RawConfig
+
+	// RawCount is the number of instances of the module to create, or
+	// nil if no count was given. Each instance is named by
+	// ModuleInstanceName once the module tree is expanded.
+	RawCount *RawConfig
+
+	// Providers maps the names of providers within the module to the
+	// names of providers in this configuration that they inherit their
+	// configuration from, such as "aws" => "aws.west". Providers that
+	// aren't listed inherit from the provider of the same name.
+	Providers map[string]string
+
+	// Pos is the position of the module block in the configuration it
+	// was loaded from. It is only used to report errors.
+	Pos token.Pos
+}
+
+// Moved records that a resource or module was renamed in the
+// configuration, so that its state is moved to the new address during
+// the plan rather than it being destroyed and created again. The
+// addresses are relative to the module the block is in.
+type Moved struct {
+	From string
+	To   string
+
+	// Pos is the position of the moved block in the configuration it
+	// was loaded from. It is only used to report errors.
+	Pos token.Pos
 }
 
 // ProviderConfig is the configuration for a resource provider.
@@ -81,6 +115,21 @@ type Resource struct {
 	Provider     string
 	DependsOn    []string
 	Lifecycle    ResourceLifecycle
+
+	// Providers is set when the provider of the resource is given as a
+	// list. The instances of the resource are distributed across these
+	// providers round-robin by count index. Provider is set to the first
+	// element of the list.
+	Providers []string
+
+	// RawReplaceTriggers holds the references in the replace_triggered_by
+	// lifecycle argument under the "replace_triggered_by" key. It is nil
+	// if the argument isn't set.
+	RawReplaceTriggers *RawConfig
+
+	// Pos is the position of the resource block in the configuration it
+	// was loaded from. It is only used to report errors.
+	Pos token.Pos
 }
 
 // Copy returns a copy of this Resource. Helpful for avoiding shared
@@ -97,6 +146,13 @@ func (r *Resource) Copy() *Resource {
 		Provider:     r.Provider,
 		DependsOn:    make([]string, len(r.DependsOn)),
 		Lifecycle:    *r.Lifecycle.Copy(),
+
+		RawReplaceTriggers: r.RawReplaceTriggers.Copy(),
+		Pos:                r.Pos,
+	}
+	if r.Providers != nil {
+		n.Providers = make([]string, len(r.Providers))
+		copy(n.Providers, r.Providers)
 	}
 	for _, p := range r.Provisioners {
 		n.Provisioners = append(n.Provisioners, p.Copy())
@@ -111,6 +167,11 @@ type ResourceLifecycle struct {
 	CreateBeforeDestroy bool     `mapstructure:"create_before_destroy"`
 	PreventDestroy      bool     `mapstructure:"prevent_destroy"`
 	IgnoreChanges       []string `mapstructure:"ignore_changes"`
+
+	// ReplaceTriggeredBy is the list of values that force the resource to
+	// be replaced when any of them change. The interpolations in these
+	// are available as Resource.RawReplaceTriggers.
+	ReplaceTriggeredBy []string `mapstructure:"replace_triggered_by"`
 }
 
 // Copy returns a copy of this ResourceLifecycle
@@ -121,6 +182,10 @@ func (r *ResourceLifecycle) Copy() *ResourceLifecycle {
 		IgnoreChanges:       make([]string, len(r.IgnoreChanges)),
 	}
 	copy(n.IgnoreChanges, r.IgnoreChanges)
+	if r.ReplaceTriggeredBy != nil {
+		n.ReplaceTriggeredBy = make([]string, len(r.ReplaceTriggeredBy))
+		copy(n.ReplaceTriggeredBy, r.ReplaceTriggeredBy)
+	}
 	return n
 }
 
@@ -151,6 +216,22 @@ type Variable struct {
 	DeclaredType string `mapstructure:"type"`
 	Default      interface{}
 	Description  string
+
+	// Sensitive, if true, makes any output whose value is derived from
+	// this variable sensitive, including the outputs of parent modules.
+	Sensitive bool
+
+	// Validation is the constraint from the "validation" block of the
+	// variable, if any.
+	Validation *VariableValidation
+}
+
+// VariableValidation is a constraint that the value of a variable must
+// satisfy, in addition to its type.
+type VariableValidation struct {
+	// Regex is a regular expression that the value of a string variable
+	// must match.
+	Regex string
 }
 
 // Output is an output defined within the configuration. An output is
@@ -209,6 +290,50 @@ func (r *Module) Id() string {
 	return fmt.Sprintf("%s", r.Name)
 }
 
+// Count returns the count of this module. RawCount must be set and
+// already interpolated.
+func (r *Module) Count() (int, error) {
+	raw := r.RawCount.Value()
+	count, ok := raw.(string)
+	if !ok {
+		return 0, fmt.Errorf(
+			"expected count to be a string or int, got %T", raw)
+	}
+
+	v, err := strconv.ParseInt(count, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("count can't be negative, got %d", v)
+	}
+
+	return int(v), nil
+}
+
+// ModuleInstanceName returns the name of the instance with the given
+// index of a module with a count, such as "foo[1]".
+func ModuleInstanceName(name string, index int) string {
+	return fmt.Sprintf("%s[%d]", name, index)
+}
+
+// ParseModuleInstanceName is the inverse of ModuleInstanceName. For a name
+// that isn't the name of an instance it returns the name itself and an
+// index of -1.
+func ParseModuleInstanceName(s string) (string, int) {
+	idx := strings.IndexByte(s, '[')
+	if idx == -1 || !strings.HasSuffix(s, "]") {
+		return s, -1
+	}
+
+	index, err := strconv.ParseInt(s[idx+1:len(s)-1], 10, 0)
+	if err != nil {
+		return s, -1
+	}
+
+	return s[:idx], int(index)
+}
+
 // Count returns the count of this resource.
 func (r *Resource) Count() (int, error) {
 	raw := r.RawCount.Value()
@@ -226,6 +351,19 @@ func (r *Resource) Count() (int, error) {
 	return int(v), nil
 }
 
+// ProviderForIndex returns the provider for the instance of this resource
+// with the given count index. An index of -1 (no count) is treated as 0.
+func (r *Resource) ProviderForIndex(i int) string {
+	if len(r.Providers) == 0 {
+		return r.Provider
+	}
+	if i < 0 {
+		i = 0
+	}
+
+	return r.Providers[i%len(r.Providers)]
+}
+
 // A unique identifier for this resource.
 func (r *Resource) Id() string {
 	switch r.Mode {
@@ -356,19 +494,26 @@ func (c *Config) Validate() error {
 		providerSet[name] = struct{}{}
 	}
 
+	// Collect the providers configured here so we can check that the
+	// providers passed to modules exist.
+	providers := make(map[string]struct{})
+	for _, p := range c.ProviderConfigs {
+		name := p.Name
+		if p.Alias != "" {
+			name += "." + p.Alias
+		}
+
+		providers[name] = struct{}{}
+	}
+
 	// Check that all references to modules are valid
 	modules := make(map[string]*Module)
-	dupped := make(map[string]struct{})
 	for _, m := range c.Modules {
 		// Check for duplicates
-		if _, ok := modules[m.Id()]; ok {
-			if _, ok := dupped[m.Id()]; !ok {
-				dupped[m.Id()] = struct{}{}
-
-				errs = append(errs, fmt.Errorf(
-					"%s: module repeated multiple times",
-					m.Id()))
-			}
+		if orig, ok := modules[m.Id()]; ok {
+			errs = append(errs, fmt.Errorf(
+				"%s: module repeated multiple times%s",
+				m.Id(), DuplicatePosStr(orig.Pos, m.Pos)))
 
 			// Already seen this module, just skip it
 			continue
@@ -398,6 +543,29 @@ func (c *Config) Validate() error {
 				m.Id()))
 		}
 
+		// Check that the providers passed to the module are valid. An
+		// aliased provider must be configured in this module, but the
+		// default provider of a type always exists implicitly.
+		for k, v := range m.Providers {
+			if !providerNameRegexp.MatchString(k) {
+				errs = append(errs, fmt.Errorf(
+					"%s: providers: invalid provider name %q", m.Id(), k))
+			}
+			if !providerNameRegexp.MatchString(v) {
+				errs = append(errs, fmt.Errorf(
+					"%s: providers: invalid provider name %q for %s", m.Id(), v, k))
+				continue
+			}
+
+			if strings.Contains(v, ".") {
+				if _, ok := providers[v]; !ok {
+					errs = append(errs, fmt.Errorf(
+						"%s: providers: provider %s passed as %s is not configured",
+						m.Id(), v, k))
+				}
+			}
+		}
+
 		// Check that the configuration can all be strings, lists or maps
 		raw := make(map[string]interface{})
 		for k, v := range m.RawConfig.Raw {
@@ -428,14 +596,48 @@ func (c *Config) Validate() error {
 		for _, v := range m.RawConfig.Variables {
 			switch v.(type) {
 			case *CountVariable:
-				errs = append(errs, fmt.Errorf(
-					"%s: count variables are only valid within resources", m.Name))
+				if m.RawCount == nil {
+					errs = append(errs, fmt.Errorf(
+						"%s: count variables are only valid within resources "+
+							"and modules with a count", m.Name))
+				}
 			case *SelfVariable:
 				errs = append(errs, fmt.Errorf(
 					"%s: self variables are only valid within resources", m.Name))
 			}
 		}
 
+		// The count of a module must be known before the plan, since the
+		// instances are created when the module tree is expanded. So it
+		// can only reference variables.
+		if m.RawCount != nil {
+			for _, v := range m.RawCount.Variables {
+				if _, ok := v.(*UserVariable); !ok {
+					errs = append(errs, fmt.Errorf(
+						"%s: module count can only reference variables, not %s",
+						m.Id(), v.FullKey()))
+				}
+			}
+
+			// Interpolate with a fixed number to verify that its a number.
+			m.RawCount.interpolate(func(root ast.Node) (interface{}, error) {
+				result, err := hil.Eval(
+					hil.FixedValueTransform(
+						root, &ast.LiteralNode{Value: "5", Typex: ast.TypeString}),
+					nil)
+				if err != nil {
+					return "", err
+				}
+
+				return result.Value, nil
+			})
+			if _, err := m.Count(); err != nil {
+				errs = append(errs, fmt.Errorf(
+					"%s: module count must be a non-negative integer", m.Id()))
+			}
+			m.RawCount.init()
+		}
+
 		// Update the raw configuration to only contain the string values
 		m.RawConfig, err = NewRawConfig(raw)
 		if err != nil {
@@ -444,7 +646,6 @@ func (c *Config) Validate() error {
 				m.Id(), err))
 		}
 	}
-	dupped = nil
 
 	// Check that all variables for modules reference modules that
 	// exist.
@@ -455,32 +656,42 @@ func (c *Config) Validate() error {
 				continue
 			}
 
-			if _, ok := modules[mv.Name]; !ok {
+			m, ok := modules[mv.Name]
+			if !ok {
 				errs = append(errs, fmt.Errorf(
 					"%s: unknown module referenced: %s",
 					source,
 					mv.Name))
+				continue
+			}
+
+			// Modules with a count must be referenced by instance, and
+			// only those can be.
+			if m.RawCount != nil && !mv.Multi {
+				errs = append(errs, fmt.Errorf(
+					"%s: module %s has a count, reference an instance with "+
+						"module.%s.N.%s or all of them with module.%s.*.%s",
+					source, mv.Name, mv.Name, mv.Field, mv.Name, mv.Field))
+			}
+			if m.RawCount == nil && mv.Multi {
+				errs = append(errs, fmt.Errorf(
+					"%s: module %s doesn't have a count: %s",
+					source, mv.Name, mv.FullKey()))
 			}
 		}
 	}
 
 	// Check that all references to resources are valid
 	resources := make(map[string]*Resource)
-	dupped = make(map[string]struct{})
 	for _, r := range c.Resources {
-		if _, ok := resources[r.Id()]; ok {
-			if _, ok := dupped[r.Id()]; !ok {
-				dupped[r.Id()] = struct{}{}
-
-				errs = append(errs, fmt.Errorf(
-					"%s: resource repeated multiple times",
-					r.Id()))
-			}
+		if orig, ok := resources[r.Id()]; ok {
+			errs = append(errs, fmt.Errorf(
+				"%s: resource repeated multiple times%s",
+				r.Id(), DuplicatePosStr(orig.Pos, r.Pos)))
 		}
 
 		resources[r.Id()] = r
 	}
-	dupped = nil
 
 	// Validate resources
 	for n, r := range resources {
@@ -604,6 +815,25 @@ func (c *Config) Validate() error {
 				n))
 		}
 
+		// Verify replace_triggered_by doesn't reference the resource itself
+		if r.RawReplaceTriggers != nil {
+			for _, v := range r.RawReplaceTriggers.Variables {
+				switch v := v.(type) {
+				case *SelfVariable:
+					errs = append(errs, fmt.Errorf(
+						"%s: lifecycle replace_triggered_by can't reference self: %s",
+						n, v.FullKey()))
+				case *ResourceVariable:
+					if v.ResourceId() == r.Id() {
+						errs = append(errs, fmt.Errorf(
+							"%s: lifecycle replace_triggered_by can't reference "+
+								"the resource itself: %s",
+							n, v.FullKey()))
+					}
+				}
+			}
+		}
+
 		// If it is a data source then it can't have provisioners
 		if r.Mode == DataResourceMode {
 			if _, ok := r.RawConfig.Raw["provisioner"]; ok {
@@ -698,6 +928,34 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Check that the moved blocks are valid
+	movedFrom := make(map[string]struct{})
+	for _, m := range c.Moved {
+		if m.From == "" || m.To == "" {
+			errs = append(errs, fmt.Errorf(
+				"moved block at %s: both from and to must be set", m.Pos))
+			continue
+		}
+		if strings.Contains(m.From, "${") || strings.Contains(m.To, "${") {
+			errs = append(errs, fmt.Errorf(
+				"moved block at %s: from and to must be addresses, "+
+					"interpolations are not allowed", m.Pos))
+			continue
+		}
+		if m.From == m.To {
+			errs = append(errs, fmt.Errorf(
+				"moved block at %s: from and to are both %s", m.Pos, m.From))
+			continue
+		}
+		if _, ok := movedFrom[m.From]; ok {
+			errs = append(errs, fmt.Errorf(
+				"moved block at %s: %s is already moved by another moved block",
+				m.Pos, m.From))
+			continue
+		}
+		movedFrom[m.From] = struct{}{}
+	}
+
 	// Check that all variables are in the proper context
 	for source, rc := range c.rawConfigs() {
 		walker := &interpolationWalker{
@@ -745,6 +1003,18 @@ func (c *Config) InterpolatedVariables() map[string][]InterpolatedVariable {
 	return result
 }
 
+// DuplicatePosStr returns the suffix for an error about a block that is
+// declared twice, naming the positions of both declarations. It is empty
+// if the positions are unknown, such as for configurations not loaded
+// from a file.
+func DuplicatePosStr(first, dup token.Pos) string {
+	if !first.IsValid() || !dup.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf(" (first declared at %s, declared again at %s)", first, dup)
+}
+
 // rawConfigs returns all of the RawConfigs that are available keyed by
 // a human-friendly source.
 func (c *Config) rawConfigs() map[string]*RawConfig {
@@ -752,6 +1022,9 @@ func (c *Config) rawConfigs() map[string]*RawConfig {
 	for _, m := range c.Modules {
 		source := fmt.Sprintf("module '%s'", m.Name)
 		result[source] = m.RawConfig
+		if m.RawCount != nil {
+			result[source+" count"] = m.RawCount
+		}
 	}
 
 	for _, pc := range c.ProviderConfigs {
@@ -763,6 +1036,9 @@ func (c *Config) rawConfigs() map[string]*RawConfig {
 		source := fmt.Sprintf("resource '%s'", rc.Id())
 		result[source+" count"] = rc.RawCount
 		result[source+" config"] = rc.RawConfig
+		if rc.RawReplaceTriggers != nil {
+			result[source+" replace_triggered_by"] = rc.RawReplaceTriggers
+		}
 
 		for i, p := range rc.Provisioners {
 			subsource := fmt.Sprintf(
@@ -887,6 +1163,9 @@ func (m *Module) mergerMerge(other merger) merger {
 	if m2.Source != "" {
 		result.Source = m2.Source
 	}
+	if m2.RawCount != nil {
+		result.RawCount = m2.RawCount
+	}
 
 	return &result
 }
@@ -979,6 +1258,12 @@ func (v *Variable) Merge(v2 *Variable) *Variable {
 	if v2.Description != "" {
 		result.Description = v2.Description
 	}
+	if v2.Sensitive {
+		result.Sensitive = true
+	}
+	if v2.Validation != nil {
+		result.Validation = v2.Validation
+	}
 
 	return &result
 }
@@ -1014,6 +1299,10 @@ func (v *Variable) ValidateTypeAndDefault() error {
 		}
 	}
 
+	if err := v.validateValidation(); err != nil {
+		return err
+	}
+
 	if v.DeclaredType == "" || v.Default == nil {
 		return nil
 	}
@@ -1026,6 +1315,63 @@ func (v *Variable) ValidateTypeAndDefault() error {
 	return nil
 }
 
+// validateValidation checks that the validation block of the variable, if
+// any, makes sense for its type and that the default value satisfies it.
+func (v *Variable) validateValidation() error {
+	if v.Validation == nil || v.Validation.Regex == "" {
+		return nil
+	}
+
+	if _, err := regexp.Compile(v.Validation.Regex); err != nil {
+		return fmt.Errorf("Variable '%s' has an invalid validation regex: %s", v.Name, err)
+	}
+	if t := v.Type(); t != VariableTypeString {
+		return fmt.Errorf(
+			"Variable '%s' has a validation regex, which is only valid for variables of type string (got '%s')",
+			v.Name, t.Printable())
+	}
+
+	if v.Default != nil {
+		if err := v.ValidateValue(v.Default); err != nil {
+			return fmt.Errorf("Variable '%s' has an invalid default value: %s", v.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// ValidateValue checks the given value for the variable against the
+// constraints of its validation block. The value itself is only part of
+// the error if the variable isn't sensitive.
+//
+// Values that aren't strings, including unknown values, aren't checked
+// so that type mismatches are reported as such.
+func (v *Variable) ValidateValue(value interface{}) error {
+	if v.Validation == nil || v.Validation.Regex == "" {
+		return nil
+	}
+
+	str, ok := value.(string)
+	if !ok || str == UnknownVariableValue {
+		return nil
+	}
+
+	re, err := regexp.Compile(v.Validation.Regex)
+	if err != nil {
+		return err
+	}
+	if re.MatchString(str) {
+		return nil
+	}
+
+	shown := fmt.Sprintf("%q", str)
+	if v.Sensitive {
+		shown = "(sensitive)"
+	}
+	return fmt.Errorf("value %s does not match the validation regex %q",
+		shown, v.Validation.Regex)
+}
+
 func (v *Variable) mergerName() string {
 	return v.Name
 }
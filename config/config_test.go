@@ -330,16 +330,55 @@ func TestConfigValidate_dependsOnVar(t *testing.T) {
 
 func TestConfigValidate_dupModule(t *testing.T) {
 	c := testConfig(t, "validate-dup-module")
-	if err := c.Validate(); err == nil {
+	err := c.Validate()
+	if err == nil {
 		t.Fatal("should not be valid")
 	}
+
+	path := filepath.Join(fixtureDir, "validate-dup-module", "main.tf")
+	expected := fmt.Sprintf(
+		"aws_instance: module repeated multiple times "+
+			"(first declared at %s:1:8, declared again at %s:5:8)", path, path)
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected %q in error, got: %s", expected, err)
+	}
 }
 
 func TestConfigValidate_dupResource(t *testing.T) {
 	c := testConfig(t, "validate-dup-resource")
-	if err := c.Validate(); err == nil {
+	err := c.Validate()
+	if err == nil {
 		t.Fatal("should not be valid")
 	}
+
+	path := filepath.Join(fixtureDir, "validate-dup-resource", "main.tf")
+	expected := fmt.Sprintf(
+		"aws_instance.web: resource repeated multiple times "+
+			"(first declared at %s:1:10, declared again at %s:5:10)", path, path)
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected %q in error, got: %s", expected, err)
+	}
+}
+
+func TestConfigValidate_dupResourceFiles(t *testing.T) {
+	dir := filepath.Join(fixtureDir, "validate-dup-resource-files")
+	c, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err = c.Validate()
+	if err == nil {
+		t.Fatal("should not be valid")
+	}
+
+	expected := fmt.Sprintf(
+		"aws_instance.web: resource repeated multiple times "+
+			"(first declared at %s:1:10, declared again at %s:3:10)",
+		filepath.Join(dir, "a.tf"), filepath.Join(dir, "b.tf"))
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected %q in error, got: %s", expected, err)
+	}
 }
 
 func TestConfigValidate_ignoreChanges(t *testing.T) {
@@ -363,6 +402,20 @@ func TestConfigValidate_ignoreChangesInterpolate(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_replaceTriggeredBy(t *testing.T) {
+	c := testConfig(t, "validate-replace-triggered-by")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestConfigValidate_replaceTriggeredBySelf(t *testing.T) {
+	c := testConfig(t, "validate-replace-triggered-by-self")
+	if err := c.Validate(); err == nil {
+		t.Fatal("should not be valid")
+	}
+}
+
 func TestConfigValidate_moduleNameBad(t *testing.T) {
 	c := testConfig(t, "validate-module-name-bad")
 	if err := c.Validate(); err == nil {
@@ -377,6 +430,33 @@ func TestConfigValidate_moduleSourceVar(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_moduleCount(t *testing.T) {
+	c := testConfig(t, "validate-module-count")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("should be valid: %s", err)
+	}
+}
+
+func TestConfigValidate_moduleCountBad(t *testing.T) {
+	cases := map[string]string{
+		"validate-module-count-resource-var": "module count can only reference variables",
+		"validate-module-count-not-int":      "module count must be a non-negative integer",
+		"validate-module-count-ref-no-index": "module foo has a count",
+		"validate-module-count-ref-no-count": "module foo doesn't have a count",
+	}
+
+	for fixture, expected := range cases {
+		c := testConfig(t, fixture)
+		err := c.Validate()
+		if err == nil {
+			t.Fatalf("%s: should not be valid", fixture)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expected: %q,\nto contain: %q", fixture, err, expected)
+		}
+	}
+}
+
 func TestConfigValidate_moduleVarInt(t *testing.T) {
 	c := testConfig(t, "validate-module-var-int")
 	if err := c.Validate(); err != nil {
@@ -398,6 +478,20 @@ func TestConfigValidate_moduleVarList(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_moduleProviders(t *testing.T) {
+	c := testConfig(t, "validate-module-providers")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("should be valid: %s", err)
+	}
+}
+
+func TestConfigValidate_moduleProvidersNotConfigured(t *testing.T) {
+	c := testConfig(t, "validate-module-providers-bad")
+	if err := c.Validate(); err == nil {
+		t.Fatal("should not be valid")
+	}
+}
+
 func TestConfigValidate_moduleVarSelf(t *testing.T) {
 	c := testConfig(t, "validate-module-var-self")
 	if err := c.Validate(); err == nil {
@@ -572,6 +666,32 @@ func TestConfigValidate_varDefaultInterpolate(t *testing.T) {
 	}
 }
 
+func TestConfigValidate_moved(t *testing.T) {
+	c := testConfig(t, "validate-moved-good")
+	if err := c.Validate(); err != nil {
+		t.Fatalf("should be valid: %s", err)
+	}
+}
+
+func TestConfigValidate_movedBad(t *testing.T) {
+	cases := map[string]string{
+		"validate-moved-missing":     "both from and to must be set",
+		"validate-moved-same":        "are both aws_instance.a",
+		"validate-moved-dup":         "already moved",
+		"validate-moved-interpolate": "interpolations are not allowed",
+	}
+
+	for name, expected := range cases {
+		err := testConfig(t, name).Validate()
+		if err == nil {
+			t.Fatalf("%s: should not be valid", name)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expected %q in error: %s", name, expected, err)
+		}
+	}
+}
+
 func TestConfigValidate_varDup(t *testing.T) {
 	c := testConfig(t, "validate-var-dup")
 	if err := c.Validate(); err == nil {
@@ -36,7 +36,11 @@ const (
 type ModuleVariable struct {
 	Name  string
 	Field string
-	key   string
+
+	Multi bool // True if referencing an instance of a module with count: module.foo.*.bar
+	Index int  // Index of the instance for multi-variable: module.foo.1.bar == 1
+
+	key string
 }
 
 // A PathVariable is a variable that references path information about the
@@ -147,13 +151,50 @@ func NewModuleVariable(key string) (*ModuleVariable, error) {
 			key)
 	}
 
+	field := parts[2]
+	multi := false
+	var index int
+
+	if idx := strings.Index(field, "."); idx != -1 {
+		indexStr := field[:idx]
+		multi = indexStr == "*"
+		index = -1
+
+		if !multi {
+			indexInt, err := strconv.ParseInt(indexStr, 0, 0)
+			if err == nil {
+				multi = true
+				index = int(indexInt)
+			}
+		}
+
+		if multi {
+			field = field[idx+1:]
+		} else {
+			index = 0
+		}
+	}
+
 	return &ModuleVariable{
 		Name:  parts[1],
-		Field: parts[2],
+		Field: field,
+		Multi: multi,
+		Index: index,
 		key:   key,
 	}, nil
 }
 
+// InstanceName returns the name of the module instance this variable
+// references. This is the name of the module unless a specific instance
+// of a module with a count is referenced.
+func (v *ModuleVariable) InstanceName() string {
+	if v.Multi && v.Index != -1 {
+		return ModuleInstanceName(v.Name, v.Index)
+	}
+
+	return v.Name
+}
+
 func (v *ModuleVariable) FullKey() string {
 	return v.key
 }
@@ -1,21 +1,31 @@
 package config
 
 import (
+	"bytes"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha512"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"net"
+	"os"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
 
 	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/hashicorp/go-uuid"
@@ -52,47 +62,80 @@ func listVariableValueToStringSlice(values []ast.Variable) ([]string, error) {
 // Funcs is the mapping of built-in functions for configuration.
 func Funcs() map[string]ast.Function {
 	return map[string]ast.Function{
-		"base64decode": interpolationFuncBase64Decode(),
-		"base64encode": interpolationFuncBase64Encode(),
-		"base64sha256": interpolationFuncBase64Sha256(),
-		"ceil":         interpolationFuncCeil(),
-		"cidrhost":     interpolationFuncCidrHost(),
-		"cidrnetmask":  interpolationFuncCidrNetmask(),
-		"cidrsubnet":   interpolationFuncCidrSubnet(),
-		"coalesce":     interpolationFuncCoalesce(),
-		"compact":      interpolationFuncCompact(),
-		"concat":       interpolationFuncConcat(),
-		"distinct":     interpolationFuncDistinct(),
-		"element":      interpolationFuncElement(),
-		"file":         interpolationFuncFile(),
-		"floor":        interpolationFuncFloor(),
-		"format":       interpolationFuncFormat(),
-		"formatlist":   interpolationFuncFormatList(),
-		"index":        interpolationFuncIndex(),
-		"join":         interpolationFuncJoin(),
-		"jsonencode":   interpolationFuncJSONEncode(),
-		"length":       interpolationFuncLength(),
-		"list":         interpolationFuncList(),
-		"lower":        interpolationFuncLower(),
-		"map":          interpolationFuncMap(),
-		"max":          interpolationFuncMax(),
-		"md5":          interpolationFuncMd5(),
-		"merge":        interpolationFuncMerge(),
-		"min":          interpolationFuncMin(),
-		"pathexpand":   interpolationFuncPathExpand(),
-		"uuid":         interpolationFuncUUID(),
-		"replace":      interpolationFuncReplace(),
-		"sha1":         interpolationFuncSha1(),
-		"sha256":       interpolationFuncSha256(),
-		"signum":       interpolationFuncSignum(),
-		"slice":        interpolationFuncSlice(),
-		"sort":         interpolationFuncSort(),
-		"split":        interpolationFuncSplit(),
-		"timestamp":    interpolationFuncTimestamp(),
-		"title":        interpolationFuncTitle(),
-		"trimspace":    interpolationFuncTrimSpace(),
-		"upper":        interpolationFuncUpper(),
-		"zipmap":       interpolationFuncZipMap(),
+		"abspath":          interpolationFuncAbsPath(),
+		"base64decode":     interpolationFuncBase64Decode(),
+		"base64encode":     interpolationFuncBase64Encode(),
+		"base64sha256":     interpolationFuncBase64Sha256(),
+		"base64sha512":     interpolationFuncBase64Sha512(),
+		"base64urldecode":  interpolationFuncBase64URLDecode(),
+		"base64urlencode":  interpolationFuncBase64URLEncode(),
+		"ceil":             interpolationFuncCeil(),
+		"cidrcontains":     interpolationFuncCidrContains(),
+		"cidrhost":         interpolationFuncCidrHost(),
+		"cidrnetmask":      interpolationFuncCidrNetmask(),
+		"cidrsubnet":       interpolationFuncCidrSubnet(),
+		"cidrsubnetsfrom":  interpolationFuncCidrSubnetsFrom(),
+		"coalesce":         interpolationFuncCoalesce(),
+		"coalescelist":     interpolationFuncCoalesceList(),
+		"coalescemap":      interpolationFuncCoalesceMap(),
+		"compact":          interpolationFuncCompact(),
+		"concat":           interpolationFuncConcat(),
+		"contains":         interpolationFuncContains(),
+		"deepmerge":        interpolationFuncDeepMerge(),
+		"distinct":         interpolationFuncDistinct(),
+		"element":          interpolationFuncElement(),
+		"file":             interpolationFuncFile(),
+		"filebase64":       interpolationFuncFileBase64(),
+		"floor":            interpolationFuncFloor(),
+		"format":           interpolationFuncFormat(),
+		"formatdate":       interpolationFuncFormatDate(),
+		"formatlist":       interpolationFuncFormatList(),
+		"groupby":          interpolationFuncGroupBy(),
+		"index":            interpolationFuncIndex(),
+		"join":             interpolationFuncJoin(),
+		"jsondecode":       interpolationFuncJSONDecode(),
+		"jsonencode":       interpolationFuncJSONEncode(),
+		"length":           interpolationFuncLength(),
+		"list":             interpolationFuncList(),
+		"lookuplist":       interpolationFuncLookupList(),
+		"lookupmap":        interpolationFuncLookupMap(),
+		"lower":            interpolationFuncLower(),
+		"map":              interpolationFuncMap(),
+		"max":              interpolationFuncMax(),
+		"md5":              interpolationFuncMd5(),
+		"merge":            interpolationFuncMerge(),
+		"mergelist":        interpolationFuncMergeList(),
+		"min":              interpolationFuncMin(),
+		"parseint":         interpolationFuncParseInt(),
+		"pathexpand":       interpolationFuncPathExpand(),
+		"uuid":             interpolationFuncUUID(),
+		"replace":          interpolationFuncReplace(),
+		"reverse":          interpolationFuncReverse(),
+		"setintersection":  interpolationFuncSetIntersection(),
+		"setsubtract":      interpolationFuncSetSubtract(),
+		"setunion":         interpolationFuncSetUnion(),
+		"sha1":             interpolationFuncSha1(),
+		"sha256":           interpolationFuncSha256(),
+		"sha512":           interpolationFuncSha512(),
+		"signum":           interpolationFuncSignum(),
+		"slice":            interpolationFuncSlice(),
+		"sort":             interpolationFuncSort(),
+		"split":            interpolationFuncSplit(),
+		"splitget":         interpolationFuncSplitGet(),
+		"sum":              interpolationFuncSum(),
+		"templatefile":     interpolationFuncTemplateFile(),
+		"textdecodebase64": interpolationFuncTextDecodeBase64(),
+		"timestamp":        interpolationFuncTimestamp(),
+		"title":            interpolationFuncTitle(),
+		"tolist":           interpolationFuncToList(),
+		"tomap":            interpolationFuncToMap(),
+		"toset":            interpolationFuncToSet(),
+		"trim":             interpolationFuncTrim(strings.Trim),
+		"trimleft":         interpolationFuncTrim(strings.TrimLeft),
+		"trimright":        interpolationFuncTrim(strings.TrimRight),
+		"trimspace":        interpolationFuncTrimSpace(),
+		"upper":            interpolationFuncUpper(),
+		"zipmap":           interpolationFuncZipMap(),
 	}
 }
 
@@ -208,6 +251,92 @@ func interpolationFuncCompact() ast.Function {
 	}
 }
 
+// cidrCacheSize is the most prefixes that parseCIDR keeps parsed. Once
+// the cache is full it is emptied, which is rare enough not to matter.
+const cidrCacheSize = 256
+
+// cidrCache holds the networks parsed by parseCIDR, keyed by the prefix
+// they were parsed from.
+var cidrCache struct {
+	sync.Mutex
+	networks map[string]*net.IPNet
+}
+
+// parseCIDR parses a CIDR prefix like net.ParseCIDR, but remembers the
+// networks it parses so that configurations that call the CIDR functions
+// many times with the same prefix only parse it once. The network returned
+// is shared, so it must not be modified.
+func parseCIDR(s string) (*net.IPNet, error) {
+	cidrCache.Lock()
+	network, ok := cidrCache.networks[s]
+	cidrCache.Unlock()
+	if ok {
+		return network, nil
+	}
+
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		return nil, err
+	}
+
+	cidrCache.Lock()
+	defer cidrCache.Unlock()
+	if cidrCache.networks == nil || len(cidrCache.networks) >= cidrCacheSize {
+		cidrCache.networks = make(map[string]*net.IPNet)
+	}
+	cidrCache.networks[s] = network
+
+	return network, nil
+}
+
+// interpolationFuncCidrContains implements the "cidrcontains" function
+// that returns true if an IP address or a CIDR block falls entirely within
+// the given prefix.
+func interpolationFuncCidrContains() ast.Function {
+	return ast.Function{
+		ArgTypes: []ast.Type{
+			ast.TypeString, // CIDR prefix
+			ast.TypeString, // IP address or CIDR block
+		},
+		ReturnType: ast.TypeBool,
+		Variadic:   false,
+		Callback: func(args []interface{}) (interface{}, error) {
+			network, err := parseCIDR(args[0].(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR expression: %s", err)
+			}
+
+			// The subject is either a single address or a block, in which
+			// case the whole block must fit within the prefix.
+			subject := args[1].(string)
+			ip := net.ParseIP(subject)
+			bits := -1
+			if ip == nil {
+				var block *net.IPNet
+				if _, block, err = net.ParseCIDR(subject); err != nil {
+					return nil, fmt.Errorf(
+						"%q is not a valid IP address or CIDR expression", subject)
+				}
+				ip = block.IP
+				bits, _ = block.Mask.Size()
+			}
+
+			if (network.IP.To4() == nil) != (ip.To4() == nil) {
+				return nil, fmt.Errorf(
+					"cannot compare %q with %q: address families don't match",
+					args[0].(string), subject)
+			}
+
+			prefix, _ := network.Mask.Size()
+			if bits >= 0 && bits < prefix {
+				return false, nil
+			}
+
+			return network.Contains(ip), nil
+		},
+	}
+}
+
 // interpolationFuncCidrHost implements the "cidrhost" function that
 // fills in the host part of a CIDR range address to create a single
 // host address
@@ -221,7 +350,7 @@ func interpolationFuncCidrHost() ast.Function {
 		Variadic:   false,
 		Callback: func(args []interface{}) (interface{}, error) {
 			hostNum := args[1].(int)
-			_, network, err := net.ParseCIDR(args[0].(string))
+			network, err := parseCIDR(args[0].(string))
 			if err != nil {
 				return nil, fmt.Errorf("invalid CIDR expression: %s", err)
 			}
@@ -246,7 +375,7 @@ func interpolationFuncCidrNetmask() ast.Function {
 		ReturnType: ast.TypeString,
 		Variadic:   false,
 		Callback: func(args []interface{}) (interface{}, error) {
-			_, network, err := net.ParseCIDR(args[0].(string))
+			network, err := parseCIDR(args[0].(string))
 			if err != nil {
 				return nil, fmt.Errorf("invalid CIDR expression: %s", err)
 			}
@@ -271,7 +400,7 @@ func interpolationFuncCidrSubnet() ast.Function {
 		Callback: func(args []interface{}) (interface{}, error) {
 			extraBits := args[1].(int)
 			subnetNum := args[2].(int)
-			_, network, err := net.ParseCIDR(args[0].(string))
+			network, err := parseCIDR(args[0].(string))
 			if err != nil {
 				return nil, fmt.Errorf("invalid CIDR expression: %s", err)
 			}
@@ -294,6 +423,108 @@ func interpolationFuncCidrSubnet() ast.Function {
 	}
 }
 
+// interpolationFuncCidrSubnetsFrom implements the "cidrsubnetsfrom"
+// function that calculates a list of subnets of an existing IP block
+// expressed in CIDR notation. Each subnet is given as a [newbits, netnum]
+// pair in the same sense as the arguments to "cidrsubnet", so the subnets
+// can be numbered explicitly and leave gaps between them, but no two of
+// them may overlap.
+func interpolationFuncCidrSubnetsFrom() ast.Function {
+	return ast.Function{
+		ArgTypes: []ast.Type{
+			ast.TypeString, // starting CIDR mask
+			ast.TypeList,   // list of [newbits, netnum] pairs
+		},
+		ReturnType: ast.TypeList,
+		Variadic:   false,
+		Callback: func(args []interface{}) (interface{}, error) {
+			network, err := parseCIDR(args[0].(string))
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR expression: %s", err)
+			}
+
+			pairs := args[1].([]ast.Variable)
+			subnets := make([]*net.IPNet, 0, len(pairs))
+			for i, pair := range pairs {
+				extraBits, subnetNum, err := cidrSubnetPair(pair)
+				if err != nil {
+					return nil, fmt.Errorf("element %d: %s", i, err)
+				}
+
+				// Same limit as cidrsubnet, for portability with 32-bit
+				// systems.
+				if extraBits > 32 {
+					return nil, fmt.Errorf(
+						"element %d: may not extend prefix by more than 32 bits", i)
+				}
+
+				subnet, err := cidr.Subnet(network, extraBits, subnetNum)
+				if err != nil {
+					return nil, fmt.Errorf("element %d: %s", i, err)
+				}
+
+				// Subnets are aligned on their own size, so two of them
+				// overlap exactly when one contains the other's network
+				// address.
+				for j, other := range subnets {
+					if subnet.Contains(other.IP) || other.Contains(subnet.IP) {
+						return nil, fmt.Errorf(
+							"element %d: subnet %s overlaps with subnet %s of element %d",
+							i, subnet, other, j)
+					}
+				}
+
+				subnets = append(subnets, subnet)
+			}
+
+			result := make([]string, len(subnets))
+			for i, subnet := range subnets {
+				result[i] = subnet.String()
+			}
+			return stringSliceToVariableValue(result), nil
+		},
+	}
+}
+
+// cidrSubnetPair returns the newbits and netnum of a single element of the
+// list given to "cidrsubnetsfrom". Numbers in lists are usually strings by
+// the time they get here, so both strings and integers are accepted.
+func cidrSubnetPair(pair ast.Variable) (int, int, error) {
+	if pair.Type != ast.TypeList {
+		return 0, 0, fmt.Errorf(
+			"expected a list of [newbits, netnum], got %s", pair.Type.Printable())
+	}
+
+	elems := pair.Value.([]ast.Variable)
+	if len(elems) != 2 {
+		return 0, 0, fmt.Errorf(
+			"expected a list of [newbits, netnum], got %d elements", len(elems))
+	}
+
+	var nums [2]int
+	for i, elem := range elems {
+		switch elem.Type {
+		case ast.TypeInt:
+			nums[i] = elem.Value.(int)
+		case ast.TypeString:
+			n, err := strconv.Atoi(elem.Value.(string))
+			if err != nil {
+				return 0, 0, fmt.Errorf("%q is not a whole number", elem.Value)
+			}
+			nums[i] = n
+		default:
+			return 0, 0, fmt.Errorf(
+				"expected a number, got %s", elem.Type.Printable())
+		}
+
+		if nums[i] < 0 {
+			return 0, 0, fmt.Errorf("%d is negative", nums[i])
+		}
+	}
+
+	return nums[0], nums[1], nil
+}
+
 // interpolationFuncCoalesce implements the "coalesce" function that
 // returns the first non null / empty string from the provided input
 func interpolationFuncCoalesce() ast.Function {
@@ -318,6 +549,56 @@ func interpolationFuncCoalesce() ast.Function {
 	}
 }
 
+// interpolationFuncCoalesceList implements the "coalescelist" function that
+// returns the first non-empty list from the provided input. Interpolation
+// functions have a fixed return type, so lists and maps can't be handled
+// by "coalesce" itself.
+func interpolationFuncCoalesceList() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeList},
+		ReturnType:   ast.TypeList,
+		Variadic:     true,
+		VariadicType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("must provide at least two arguments")
+			}
+			for _, arg := range args {
+				argument := arg.([]ast.Variable)
+
+				if len(argument) > 0 {
+					return argument, nil
+				}
+			}
+			return make([]ast.Variable, 0), nil
+		},
+	}
+}
+
+// interpolationFuncCoalesceMap implements the "coalescemap" function that
+// returns the first non-empty map from the provided input.
+func interpolationFuncCoalesceMap() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeMap},
+		ReturnType:   ast.TypeMap,
+		Variadic:     true,
+		VariadicType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			if len(args) < 2 {
+				return nil, fmt.Errorf("must provide at least two arguments")
+			}
+			for _, arg := range args {
+				argument := arg.(map[string]ast.Variable)
+
+				if len(argument) > 0 {
+					return argument, nil
+				}
+			}
+			return make(map[string]ast.Variable), nil
+		},
+	}
+}
+
 // interpolationFuncConcat implements the "concat" function that concatenates
 // multiple lists.
 func interpolationFuncConcat() ast.Function {
@@ -359,6 +640,41 @@ func interpolationFuncConcat() ast.Function {
 	}
 }
 
+// MaxFileSize is the maximum size in bytes of a file that can be read by
+// the "file", "filebase64" and "templatefile" functions, so that reading a
+// huge file by mistake fails instead of exhausting memory. Zero or less
+// means there is no limit.
+var MaxFileSize int64 = 100 * 1024 * 1024
+
+// readFileLimited reads the file at the given path, failing if it is larger
+// than MaxFileSize.
+func readFileLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	limit := MaxFileSize
+	if limit <= 0 {
+		return ioutil.ReadAll(f)
+	}
+
+	// Read one byte past the limit rather than trusting the size from a
+	// stat, which isn't meaningful for every kind of file.
+	data, err := ioutil.ReadAll(io.LimitReader(f, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf(
+			"file %s is larger than the limit of %d bytes for reading files",
+			path, limit)
+	}
+
+	return data, nil
+}
+
 // interpolationFuncFile implements the "file" function that allows
 // loading contents from a file.
 func interpolationFuncFile() ast.Function {
@@ -370,7 +686,7 @@ func interpolationFuncFile() ast.Function {
 			if err != nil {
 				return "", err
 			}
-			data, err := ioutil.ReadFile(path)
+			data, err := readFileLimited(path)
 			if err != nil {
 				return "", err
 			}
@@ -380,8 +696,72 @@ func interpolationFuncFile() ast.Function {
 	}
 }
 
+// interpolationFuncFileBase64 implements the "filebase64" function that
+// reads the raw bytes of a file and returns them base64-encoded, so that
+// binary content can be passed around as a string.
+func interpolationFuncFileBase64() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			path, err := homedir.Expand(args[0].(string))
+			if err != nil {
+				return "", err
+			}
+			data, err := readFileLimited(path)
+			if err != nil {
+				return "", err
+			}
+
+			return base64.StdEncoding.EncodeToString(data), nil
+		},
+	}
+}
+
+// interpolationFuncTemplateFile implements the "templatefile" function
+// that renders the file at the given path as a template, with the keys of
+// the given map available as variables.
+func interpolationFuncTemplateFile() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeMap},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			path, err := homedir.Expand(args[0].(string))
+			if err != nil {
+				return "", err
+			}
+			data, err := readFileLimited(path)
+			if err != nil {
+				return "", err
+			}
+
+			root, err := hil.Parse(string(data))
+			if err != nil {
+				return "", fmt.Errorf("failed to parse template %s: %s", path, err)
+			}
+
+			result, err := hil.Eval(root, &hil.EvalConfig{
+				GlobalScope: &ast.BasicScope{
+					VarMap:  args[1].(map[string]ast.Variable),
+					FuncMap: Funcs(),
+				},
+			})
+			if err != nil {
+				return "", fmt.Errorf("failed to render template %s: %s", path, err)
+			}
+			if result.Type != hil.TypeString {
+				return "", fmt.Errorf(
+					"template %s must render to a string, got %s", path, result.Type)
+			}
+
+			return result.Value.(string), nil
+		},
+	}
+}
+
 // interpolationFuncFormat implements the "format" function that does
-// string formatting.
+// string formatting. Lists and maps are formatted like ["a", "b"] and
+// {"a": "b"}, with the map keys sorted so that the result is stable.
 func interpolationFuncFormat() ast.Function {
 	return ast.Function{
 		ArgTypes:     []ast.Type{ast.TypeString},
@@ -390,23 +770,101 @@ func interpolationFuncFormat() ast.Function {
 		ReturnType:   ast.TypeString,
 		Callback: func(args []interface{}) (interface{}, error) {
 			format := args[0].(string)
-			return fmt.Sprintf(format, args[1:]...), nil
+			values := make([]interface{}, len(args)-1)
+			for i, arg := range args[1:] {
+				switch arg.(type) {
+				case []ast.Variable, map[string]ast.Variable:
+					values[i] = formatCollection(arg)
+				default:
+					values[i] = arg
+				}
+			}
+
+			return fmt.Sprintf(format, values...), nil
+		},
+	}
+}
+
+// formatCollection returns the representation of a list or map, including
+// any nested lists and maps, used by the "format" function.
+func formatCollection(raw interface{}) string {
+	switch v := raw.(type) {
+	case ast.Variable:
+		return formatCollection(v.Value)
+	case []ast.Variable:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = formatCollection(e)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]ast.Variable:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = fmt.Sprintf("%q: %s", k, formatCollection(v[k]))
+		}
+		return "{" + strings.Join(parts, ", ") + "}"
+	case string:
+		return strconv.Quote(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// interpolationFuncParseInt implements the "parseint" function that parses
+// a string as an integer in the given base, from 2 to 36.
+func interpolationFuncParseInt() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeInt},
+		ReturnType: ast.TypeInt,
+		Callback: func(args []interface{}) (interface{}, error) {
+			s := args[0].(string)
+			base := args[1].(int)
+			if base < 2 || base > 36 {
+				return nil, fmt.Errorf(
+					"parseint: base must be between 2 and 36, got %d", base)
+			}
+
+			v, err := strconv.ParseInt(s, base, 0)
+			if err != nil {
+				if ne, ok := err.(*strconv.NumError); ok && ne.Err == strconv.ErrRange {
+					return nil, fmt.Errorf("parseint: %q is out of range", s)
+				}
+
+				return nil, fmt.Errorf(
+					"parseint: %q is not a valid base %d number", s, base)
+			}
+
+			return int(v), nil
 		},
 	}
 }
 
-// interpolationFuncMax returns the maximum of the numeric arguments
+// interpolationFuncMax returns the maximum of the numeric arguments, or
+// of the elements of a single list argument
 func interpolationFuncMax() ast.Function {
 	return ast.Function{
-		ArgTypes:     []ast.Type{ast.TypeFloat},
+		ArgTypes:     []ast.Type{},
 		ReturnType:   ast.TypeFloat,
 		Variadic:     true,
-		VariadicType: ast.TypeFloat,
+		VariadicType: ast.TypeAny,
 		Callback: func(args []interface{}) (interface{}, error) {
-			max := args[0].(float64)
+			nums, err := interpolationNumbers(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(nums) == 0 {
+				return nil, fmt.Errorf("max requires at least one number")
+			}
 
-			for i := 1; i < len(args); i++ {
-				max = math.Max(max, args[i].(float64))
+			max := nums[0]
+			for _, n := range nums[1:] {
+				max = math.Max(max, n)
 			}
 
 			return max, nil
@@ -414,18 +872,26 @@ func interpolationFuncMax() ast.Function {
 	}
 }
 
-// interpolationFuncMin returns the minimum of the numeric arguments
+// interpolationFuncMin returns the minimum of the numeric arguments, or
+// of the elements of a single list argument
 func interpolationFuncMin() ast.Function {
 	return ast.Function{
-		ArgTypes:     []ast.Type{ast.TypeFloat},
+		ArgTypes:     []ast.Type{},
 		ReturnType:   ast.TypeFloat,
 		Variadic:     true,
-		VariadicType: ast.TypeFloat,
+		VariadicType: ast.TypeAny,
 		Callback: func(args []interface{}) (interface{}, error) {
-			min := args[0].(float64)
+			nums, err := interpolationNumbers(args)
+			if err != nil {
+				return nil, err
+			}
+			if len(nums) == 0 {
+				return nil, fmt.Errorf("min requires at least one number")
+			}
 
-			for i := 1; i < len(args); i++ {
-				min = math.Min(min, args[i].(float64))
+			min := nums[0]
+			for _, n := range nums[1:] {
+				min = math.Min(min, n)
 			}
 
 			return min, nil
@@ -433,6 +899,63 @@ func interpolationFuncMin() ast.Function {
 	}
 }
 
+// interpolationFuncSum returns the sum of the elements of a list of
+// numbers. The sum of an empty list is zero.
+func interpolationFuncSum() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeList},
+		ReturnType: ast.TypeFloat,
+		Callback: func(args []interface{}) (interface{}, error) {
+			nums, err := interpolationNumbers(args)
+			if err != nil {
+				return nil, err
+			}
+
+			var sum float64
+			for _, n := range nums {
+				sum += n
+			}
+
+			return sum, nil
+		},
+	}
+}
+
+// interpolationNumbers converts the arguments of a numeric aggregation
+// function to floats. Lists are flattened into their elements and strings
+// are parsed as numbers.
+func interpolationNumbers(args []interface{}) ([]float64, error) {
+	var result []float64
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case []ast.Variable:
+			for _, elem := range v {
+				nums, err := interpolationNumbers([]interface{}{elem.Value})
+				if err != nil {
+					return nil, err
+				}
+
+				result = append(result, nums...)
+			}
+		case int:
+			result = append(result, float64(v))
+		case float64:
+			result = append(result, v)
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+
+			result = append(result, f)
+		default:
+			return nil, fmt.Errorf("unexpected type %T, expected a number", v)
+		}
+	}
+
+	return result, nil
+}
+
 // interpolationFuncPathExpand will expand any `~`'s found with the full file path
 func interpolationFuncPathExpand() ast.Function {
 	return ast.Function{
@@ -444,6 +967,18 @@ func interpolationFuncPathExpand() ast.Function {
 	}
 }
 
+// interpolationFuncAbsPath returns an absolute representation of the given
+// path. Relative paths are resolved against the current working directory.
+func interpolationFuncAbsPath() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			return filepath.Abs(args[0].(string))
+		},
+	}
+}
+
 // interpolationFuncCeil returns the the least integer value greater than or equal to the argument
 func interpolationFuncCeil() ast.Function {
 	return ast.Function{
@@ -499,6 +1034,189 @@ func interpolationFuncZipMap() ast.Function {
 	}
 }
 
+// interpolationFuncGroupBy implements the "groupby" function that works
+// like zipmap but collects the values of every key into a list, so that no
+// values are lost when a key is repeated.
+func interpolationFuncGroupBy() ast.Function {
+	return ast.Function{
+		ArgTypes: []ast.Type{
+			ast.TypeList, // Keys
+			ast.TypeList, // Values
+		},
+		ReturnType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			keys := args[0].([]ast.Variable)
+			values := args[1].([]ast.Variable)
+
+			if len(keys) != len(values) {
+				return nil, fmt.Errorf("count of keys (%d) does not match count of values (%d)",
+					len(keys), len(values))
+			}
+
+			for i, val := range keys {
+				if val.Type != ast.TypeString {
+					return nil, fmt.Errorf("keys must be strings. value at position %d is %s",
+						i, val.Type.Printable())
+				}
+			}
+
+			groups := map[string][]ast.Variable{}
+			for i := 0; i < len(keys); i++ {
+				k := keys[i].Value.(string)
+				groups[k] = append(groups[k], values[i])
+			}
+
+			result := make(map[string]ast.Variable, len(groups))
+			for k, vs := range groups {
+				result[k] = ast.Variable{
+					Type:  ast.TypeList,
+					Value: vs,
+				}
+			}
+
+			return result, nil
+		},
+	}
+}
+
+// interpolationFuncFormatDate implements the "formatdate" function that
+// parses an RFC 3339 timestamp and formats it according to a specifier
+// string such as "YYYY-MM-DD hh:mm". Literal text can be included by
+// wrapping it in single quotes; two single quotes produce a literal quote.
+func interpolationFuncFormatDate() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			format := args[0].(string)
+			t, err := time.Parse(time.RFC3339, args[1].(string))
+			if err != nil {
+				return nil, fmt.Errorf("formatdate: invalid RFC 3339 timestamp: %s", err)
+			}
+
+			return formatDate(format, t)
+		},
+	}
+}
+
+// formatDate formats t according to the formatdate specifier syntax.
+func formatDate(format string, t time.Time) (string, error) {
+	var buf bytes.Buffer
+	rs := []rune(format)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+
+		// Quoted literal text
+		if r == '\'' {
+			j := i + 1
+			if j < len(rs) && rs[j] == '\'' {
+				buf.WriteRune('\'')
+				i += 2
+				continue
+			}
+
+			for ; j < len(rs); j++ {
+				if rs[j] == '\'' {
+					// A doubled quote inside a literal is an escaped quote
+					if j+1 < len(rs) && rs[j+1] == '\'' {
+						buf.WriteRune('\'')
+						j++
+						continue
+					}
+
+					break
+				}
+
+				buf.WriteRune(rs[j])
+			}
+			if j == len(rs) {
+				return "", fmt.Errorf("formatdate: unterminated literal in format %q", format)
+			}
+
+			i = j + 1
+			continue
+		}
+
+		if !unicode.IsLetter(r) {
+			buf.WriteRune(r)
+			i++
+			continue
+		}
+
+		// Consume a run of the same letter as a single specifier
+		j := i
+		for j < len(rs) && rs[j] == r {
+			j++
+		}
+		spec := string(rs[i:j])
+		i = j
+
+		switch spec {
+		case "YYYY":
+			fmt.Fprintf(&buf, "%04d", t.Year())
+		case "YY":
+			fmt.Fprintf(&buf, "%02d", t.Year()%100)
+		case "MMMM":
+			buf.WriteString(t.Month().String())
+		case "MMM":
+			buf.WriteString(t.Month().String()[:3])
+		case "MM":
+			fmt.Fprintf(&buf, "%02d", int(t.Month()))
+		case "M":
+			fmt.Fprintf(&buf, "%d", int(t.Month()))
+		case "DD":
+			fmt.Fprintf(&buf, "%02d", t.Day())
+		case "D":
+			fmt.Fprintf(&buf, "%d", t.Day())
+		case "EEEE":
+			buf.WriteString(t.Weekday().String())
+		case "EEE":
+			buf.WriteString(t.Weekday().String()[:3])
+		case "hh":
+			fmt.Fprintf(&buf, "%02d", t.Hour())
+		case "h":
+			fmt.Fprintf(&buf, "%d", t.Hour())
+		case "HH":
+			fmt.Fprintf(&buf, "%02d", hour12(t))
+		case "H":
+			fmt.Fprintf(&buf, "%d", hour12(t))
+		case "AA":
+			buf.WriteString(t.Format("PM"))
+		case "aa":
+			buf.WriteString(t.Format("pm"))
+		case "mm":
+			fmt.Fprintf(&buf, "%02d", t.Minute())
+		case "m":
+			fmt.Fprintf(&buf, "%d", t.Minute())
+		case "ss":
+			fmt.Fprintf(&buf, "%02d", t.Second())
+		case "s":
+			fmt.Fprintf(&buf, "%d", t.Second())
+		case "ZZZZZ":
+			buf.WriteString(t.Format("-07:00"))
+		case "ZZZZ":
+			buf.WriteString(t.Format("-0700"))
+		case "ZZZ":
+			buf.WriteString(t.Format("MST"))
+		case "Z":
+			buf.WriteString(t.Format("Z07:00"))
+		default:
+			return "", fmt.Errorf("formatdate: invalid format specifier %q", spec)
+		}
+	}
+
+	return buf.String(), nil
+}
+
+// hour12 returns the hour of t on a 12-hour clock.
+func hour12(t time.Time) int {
+	h := t.Hour() % 12
+	if h == 0 {
+		h = 12
+	}
+	return h
+}
+
 // interpolationFuncFormatList implements the "formatlist" function that does
 // string formatting on lists.
 func interpolationFuncFormatList() ast.Function {
@@ -599,8 +1317,49 @@ func interpolationFuncIndex() ast.Function {
 	}
 }
 
+// interpolationFuncContains implements the "contains" function that
+// returns true if a list contains the given element. Lists and maps are
+// compared structurally.
+func interpolationFuncContains() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeList, ast.TypeAny},
+		ReturnType: ast.TypeBool,
+		Callback: func(args []interface{}) (interface{}, error) {
+			haystack := args[0].([]ast.Variable)
+
+			var needle ast.Variable
+			switch v := args[1].(type) {
+			case []ast.Variable:
+				needle = ast.Variable{Type: ast.TypeList, Value: v}
+			case map[string]ast.Variable:
+				needle = ast.Variable{Type: ast.TypeMap, Value: v}
+			default:
+				// Scalars are compared as strings, the same as index()
+				needle = ast.Variable{
+					Type:  ast.TypeString,
+					Value: fmt.Sprintf("%v", v),
+				}
+			}
+
+			for _, element := range haystack {
+				equal, err := variablesEqual(element, needle)
+				if err != nil {
+					return nil, err
+				}
+				if equal {
+					return true, nil
+				}
+			}
+
+			return false, nil
+		},
+	}
+}
+
 // interpolationFuncDistinct implements the "distinct" function that
-// removes duplicate elements from a list.
+// removes duplicate elements from a list. The first occurrence of each
+// element is kept, so the result is in the order of the input. Lists of
+// lists or maps are compared structurally.
 func interpolationFuncDistinct() ast.Function {
 	return ast.Function{
 		ArgTypes:     []ast.Type{ast.TypeList},
@@ -608,36 +1367,65 @@ func interpolationFuncDistinct() ast.Function {
 		Variadic:     true,
 		VariadicType: ast.TypeList,
 		Callback: func(args []interface{}) (interface{}, error) {
-			var list []string
+			list := []ast.Variable{}
 
 			if len(args) != 1 {
 				return nil, fmt.Errorf("accepts only one argument.")
 			}
 
 			if argument, ok := args[0].([]ast.Variable); ok {
+			ELEMENTS:
 				for _, element := range argument {
-					if element.Type != ast.TypeString {
+					switch element.Type {
+					case ast.TypeString, ast.TypeList, ast.TypeMap:
+					default:
 						return nil, fmt.Errorf(
-							"only works for flat lists, this list contains elements of %s",
+							"only works for lists of strings, lists or maps, "+
+								"this list contains elements of %s",
 							element.Type.Printable())
 					}
-					list = appendIfMissing(list, element.Value.(string))
+
+					for _, existing := range list {
+						equal, err := variablesEqual(existing, element)
+						if err != nil {
+							return nil, err
+						}
+						if equal {
+							continue ELEMENTS
+						}
+					}
+
+					list = append(list, element)
 				}
 			}
 
-			return stringSliceToVariableValue(list), nil
+			return list, nil
 		},
 	}
 }
 
-// helper function to add an element to a list, if it does not already exsit
-func appendIfMissing(slice []string, element string) []string {
-	for _, ele := range slice {
-		if ele == element {
-			return slice
+// variablesEqual returns true if a and b have the same type and value.
+// Lists and maps are compared element by element.
+func variablesEqual(a, b ast.Variable) (bool, error) {
+	if a.Type != b.Type {
+		return false, nil
+	}
+
+	switch a.Type {
+	case ast.TypeList, ast.TypeMap:
+		av, err := hil.VariableToInterface(a)
+		if err != nil {
+			return false, err
 		}
+		bv, err := hil.VariableToInterface(b)
+		if err != nil {
+			return false, err
+		}
+
+		return reflect.DeepEqual(av, bv), nil
+	default:
+		return a.Value == b.Value, nil
 	}
-	return append(slice, element)
 }
 
 // interpolationFuncJoin implements the "join" function that allows
@@ -726,8 +1514,51 @@ func interpolationFuncJSONEncode() ast.Function {
 	}
 }
 
+// interpolationFuncJSONDecode implements the "jsondecode" function that
+// decodes a JSON object into a map. Since interpolation values are typed
+// statically, only objects are supported. Values must be strings, numbers
+// or booleans and are all converted to strings, mirroring what jsonencode
+// accepts.
+func interpolationFuncJSONDecode() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			var decoded map[string]interface{}
+			if err := json.Unmarshal([]byte(args[0].(string)), &decoded); err != nil {
+				return nil, fmt.Errorf("failed to decode JSON data: %s", err)
+			}
+			if decoded == nil {
+				return nil, fmt.Errorf("JSON data must be an object")
+			}
+
+			result := make(map[string]ast.Variable, len(decoded))
+			for k, v := range decoded {
+				var s string
+				switch typedV := v.(type) {
+				case string:
+					s = typedV
+				case float64:
+					s = strconv.FormatFloat(typedV, 'f', -1, 64)
+				case bool:
+					s = strconv.FormatBool(typedV)
+				default:
+					return nil, fmt.Errorf(
+						"value for key %q must be a string, number or boolean", k)
+				}
+
+				result[k] = ast.Variable{Type: ast.TypeString, Value: s}
+			}
+
+			return result, nil
+		},
+	}
+}
+
 // interpolationFuncReplace implements the "replace" function that does
-// string replacement.
+// string replacement. When the search is a regular expression, the
+// replacement is expanded with Regexp.ReplaceAllString semantics so that
+// it can reference numbered and named capture groups.
 func interpolationFuncReplace() ast.Function {
 	return ast.Function{
 		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString, ast.TypeString},
@@ -748,11 +1579,34 @@ func interpolationFuncReplace() ast.Function {
 				return re.ReplaceAllString(s, replace), nil
 			}
 
+			// Anything else is a literal search, which doesn't need the
+			// regexp machinery at all and is much faster on large strings.
 			return strings.Replace(s, search, replace, -1), nil
 		},
 	}
 }
 
+// interpolationFuncReverse implements the "reverse" function that returns
+// a copy of the given list with its elements in reverse order.
+func interpolationFuncReverse() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeList},
+		ReturnType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			inputList := args[0].([]ast.Variable)
+
+			// Build a new list rather than reversing in place so that
+			// the caller's list is left untouched.
+			reversed := make([]ast.Variable, len(inputList))
+			for i, v := range inputList {
+				reversed[len(inputList)-1-i] = v
+			}
+
+			return reversed, nil
+		},
+	}
+}
+
 func interpolationFuncLength() ast.Function {
 	return ast.Function{
 		ArgTypes:   []ast.Type{ast.TypeAny},
@@ -858,8 +1712,123 @@ func interpolationFuncSort() ast.Function {
 	}
 }
 
+// interpolationFuncSetUnion implements the "setunion" function that
+// returns the sorted set of strings present in any of the given lists.
+func interpolationFuncSetUnion() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeList},
+		ReturnType:   ast.TypeList,
+		Variadic:     true,
+		VariadicType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			result := make(map[string]struct{})
+			for _, arg := range args {
+				set, err := listToStringSet("setunion", arg.([]ast.Variable))
+				if err != nil {
+					return nil, err
+				}
+
+				for k := range set {
+					result[k] = struct{}{}
+				}
+			}
+
+			return stringSetToVariableValue(result), nil
+		},
+	}
+}
+
+// interpolationFuncSetIntersection implements the "setintersection"
+// function that returns the sorted set of strings present in all of the
+// given lists.
+func interpolationFuncSetIntersection() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeList},
+		ReturnType:   ast.TypeList,
+		Variadic:     true,
+		VariadicType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			result, err := listToStringSet("setintersection", args[0].([]ast.Variable))
+			if err != nil {
+				return nil, err
+			}
+
+			for _, arg := range args[1:] {
+				set, err := listToStringSet("setintersection", arg.([]ast.Variable))
+				if err != nil {
+					return nil, err
+				}
+
+				for k := range result {
+					if _, ok := set[k]; !ok {
+						delete(result, k)
+					}
+				}
+			}
+
+			return stringSetToVariableValue(result), nil
+		},
+	}
+}
+
+// interpolationFuncSetSubtract implements the "setsubtract" function that
+// returns the sorted set of strings in the first list that aren't in the
+// second.
+func interpolationFuncSetSubtract() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeList, ast.TypeList},
+		ReturnType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			result, err := listToStringSet("setsubtract", args[0].([]ast.Variable))
+			if err != nil {
+				return nil, err
+			}
+
+			remove, err := listToStringSet("setsubtract", args[1].([]ast.Variable))
+			if err != nil {
+				return nil, err
+			}
+
+			for k := range remove {
+				delete(result, k)
+			}
+
+			return stringSetToVariableValue(result), nil
+		},
+	}
+}
+
+// listToStringSet converts a list of strings to a set, returning an error
+// naming the calling function if the list contains any other type.
+func listToStringSet(name string, list []ast.Variable) (map[string]struct{}, error) {
+	set := make(map[string]struct{}, len(list))
+	for i, val := range list {
+		if val.Type != ast.TypeString {
+			return nil, fmt.Errorf(
+				"%s() may only be used with lists of strings - %s at index %d",
+				name, val.Type.String(), i)
+		}
+
+		set[val.Value.(string)] = struct{}{}
+	}
+
+	return set, nil
+}
+
+// stringSetToVariableValue converts a set of strings to a sorted list.
+func stringSetToVariableValue(set map[string]struct{}) []ast.Variable {
+	members := make([]string, 0, len(set))
+	for k := range set {
+		members = append(members, k)
+	}
+
+	sort.Strings(members)
+	return stringSliceToVariableValue(members)
+}
+
 // interpolationFuncSplit implements the "split" function that allows
-// strings to split into multi-variable values
+// strings to split into multi-variable values. Splitting an empty string
+// results in an empty list rather than a list with one empty element.
 func interpolationFuncSplit() ast.Function {
 	return ast.Function{
 		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString},
@@ -867,8 +1836,47 @@ func interpolationFuncSplit() ast.Function {
 		Callback: func(args []interface{}) (interface{}, error) {
 			sep := args[0].(string)
 			s := args[1].(string)
+			if s == "" {
+				return []ast.Variable{}, nil
+			}
+
+			elements := strings.Split(s, sep)
+			return stringSliceToVariableValue(elements), nil
+		},
+	}
+}
+
+// interpolationFuncSplitGet implements the "splitget" function, which is
+// a shorthand for getting one element of the result of "split". Unlike
+// "element", an index past the end of the list is an error rather than
+// wrapping around, since that is almost always a malformed string.
+func interpolationFuncSplitGet() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString, ast.TypeInt},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			sep := args[0].(string)
+			s := args[1].(string)
+			index := args[2].(int)
+
+			if index < 0 {
+				return nil, fmt.Errorf("splitget() index must not be negative, got %d", index)
+			}
+
+			// Like split, an empty string has no elements at all
+			if s == "" {
+				return nil, fmt.Errorf(
+					"splitget() can't get element %d of an empty string", index)
+			}
+
 			elements := strings.Split(s, sep)
-			return stringSliceToVariableValue(elements), nil
+			if index >= len(elements) {
+				return nil, fmt.Errorf(
+					"splitget() can't get element %d of %q: splitting by %q "+
+						"gives only %d elements", index, s, sep, len(elements))
+			}
+
+			return elements[index], nil
 		},
 	}
 }
@@ -894,7 +1902,7 @@ func interpolationFuncLookup(vs map[string]ast.Variable) ast.Function {
 			index := args[1].(string)
 			mapVar := args[0].(map[string]ast.Variable)
 
-			v, ok := mapVar[index]
+			v, ok := lookupPath(mapVar, index)
 			if !ok {
 				if defaultValueSet {
 					return defaultValue, nil
@@ -915,17 +1923,130 @@ func interpolationFuncLookup(vs map[string]ast.Variable) ast.Function {
 	}
 }
 
+// interpolationFuncLookupList implements the "lookuplist" function that
+// looks up a list in a map of lists. Interpolation functions have a fixed
+// return type, so lists can't be returned by "lookup" itself.
+func interpolationFuncLookupList() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeMap, ast.TypeString},
+		ReturnType:   ast.TypeList,
+		Variadic:     true,
+		VariadicType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			return lookupTyped("lookuplist", ast.TypeList, args)
+		},
+	}
+}
+
+// interpolationFuncLookupMap implements the "lookupmap" function that
+// looks up a map in a map of maps.
+func interpolationFuncLookupMap() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeMap, ast.TypeString},
+		ReturnType:   ast.TypeMap,
+		Variadic:     true,
+		VariadicType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			return lookupTyped("lookupmap", ast.TypeMap, args)
+		},
+	}
+}
+
+// lookupTyped implements the lookup functions that return a value of the
+// given type. The arguments are the map, the key and an optional default
+// of that type, which is returned as-is if the key isn't found.
+func lookupTyped(name string, typ ast.Type, args []interface{}) (interface{}, error) {
+	if len(args) > 3 {
+		return nil, fmt.Errorf("%s() takes no more than three arguments", name)
+	}
+	index := args[1].(string)
+	mapVar := args[0].(map[string]ast.Variable)
+
+	v, ok := lookupPath(mapVar, index)
+	if !ok {
+		if len(args) > 2 {
+			return args[2], nil
+		}
+
+		return nil, fmt.Errorf("%s failed to find '%s'", name, index)
+	}
+	if v.Type != typ {
+		return nil, fmt.Errorf(
+			"%s() may only be used with maps of %s, this map contains elements of %s",
+			name, typ.Printable(), v.Type.Printable())
+	}
+
+	return v.Value, nil
+}
+
+// lookupPath looks up key in the given map. If the key isn't found as-is
+// and contains dots, it is treated as a path of keys into nested maps,
+// so "a.b" looks up "b" in the map found at "a". Keys that exist as-is
+// always win, so top-level keys containing dots keep working, but keys
+// of nested maps can't contain dots.
+func lookupPath(m map[string]ast.Variable, key string) (ast.Variable, bool) {
+	if v, ok := m[key]; ok || !strings.Contains(key, ".") {
+		return v, ok
+	}
+
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		v, ok := m[part]
+		if !ok {
+			return ast.Variable{}, false
+		}
+
+		if i == len(parts)-1 {
+			return v, true
+		}
+
+		if v.Type != ast.TypeMap {
+			return ast.Variable{}, false
+		}
+		m = v.Value.(map[string]ast.Variable)
+	}
+
+	return ast.Variable{}, false
+}
+
 // interpolationFuncElement implements the "element" function that allows
 // a specific index to be looked up in a multi-variable value. Note that this will
 // wrap if the index is larger than the number of elements in the multi-variable value.
+//
+// The index of a map is the index of its values when the keys are sorted, as
+// returned by the "values" function.
 func interpolationFuncElement() ast.Function {
 	return ast.Function{
-		ArgTypes:   []ast.Type{ast.TypeList, ast.TypeString},
+		ArgTypes:   []ast.Type{ast.TypeAny, ast.TypeString},
 		ReturnType: ast.TypeString,
 		Callback: func(args []interface{}) (interface{}, error) {
-			list := args[0].([]ast.Variable)
-			if len(list) == 0 {
-				return nil, fmt.Errorf("element() may not be used with an empty list")
+			var list []ast.Variable
+			kind := "list"
+			switch v := args[0].(type) {
+			case []ast.Variable:
+				list = v
+				if len(list) == 0 {
+					return nil, fmt.Errorf("element() may not be used with an empty list")
+				}
+			case map[string]ast.Variable:
+				keys := make([]string, 0, len(v))
+				for k := range v {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+
+				kind = "map"
+				list = make([]ast.Variable, len(keys))
+				for i, k := range keys {
+					list[i] = v[k]
+				}
+				if len(list) == 0 {
+					return nil, fmt.Errorf("element() may not be used with an empty map")
+				}
+			default:
+				return nil, fmt.Errorf(
+					"element() may only be used with a list or a map, got %s",
+					collectionTypeName(args[0]))
 			}
 
 			index, err := strconv.Atoi(args[1].(string))
@@ -939,8 +2060,8 @@ func interpolationFuncElement() ast.Function {
 			v := list[resolvedIndex]
 			if v.Type != ast.TypeString {
 				return nil, fmt.Errorf(
-					"element() may only be used with flat lists, this list contains elements of %s",
-					v.Type.Printable())
+					"element() may only be used with flat lists or maps, this %s contains elements of %s",
+					kind, v.Type.Printable())
 			}
 			return v.Value, nil
 		},
@@ -1035,6 +2156,149 @@ func interpolationFuncBase64Decode() ast.Function {
 	}
 }
 
+// interpolationFuncBase64URLEncode implements the "base64urlencode"
+// function that allows Base64 encoding with the URL-safe alphabet and
+// without padding.
+func interpolationFuncBase64URLEncode() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			s := args[0].(string)
+			return base64.RawURLEncoding.EncodeToString([]byte(s)), nil
+		},
+	}
+}
+
+// interpolationFuncBase64URLDecode implements the "base64urldecode"
+// function that allows decoding of Base64 in the URL-safe alphabet. Any
+// padding is optional.
+func interpolationFuncBase64URLDecode() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			s := args[0].(string)
+			sDec, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+			if err != nil {
+				return "", fmt.Errorf("failed to decode base64url data '%s'", s)
+			}
+			return string(sDec), nil
+		},
+	}
+}
+
+// interpolationFuncTextDecodeBase64 implements the "textdecodebase64"
+// function that decodes Base64 data holding text in the given character
+// encoding and returns it as UTF-8.
+func interpolationFuncTextDecodeBase64() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			s := args[0].(string)
+			enc := args[1].(string)
+
+			decode, ok := textDecoders[strings.ToLower(enc)]
+			if !ok {
+				return "", fmt.Errorf("unsupported text encoding %q", enc)
+			}
+
+			sDec, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return "", fmt.Errorf("failed to decode base64 data '%s'", s)
+			}
+
+			result, err := decode(sDec)
+			if err != nil {
+				return "", fmt.Errorf("failed to decode base64 data '%s' as %s: %s", s, enc, err)
+			}
+			return result, nil
+		},
+	}
+}
+
+// textDecoders are the character encodings supported by textdecodebase64,
+// keyed by lowercase name. Each converts the raw bytes to a UTF-8 string.
+var textDecoders = map[string]func([]byte) (string, error){
+	"utf-8":        textDecodeUTF8,
+	"utf-16le":     func(b []byte) (string, error) { return textDecodeUTF16(b, false) },
+	"utf-16be":     func(b []byte) (string, error) { return textDecodeUTF16(b, true) },
+	"iso-8859-1":   textDecodeLatin1,
+	"latin1":       textDecodeLatin1,
+	"us-ascii":     textDecodeASCII,
+	"windows-1252": textDecodeWindows1252,
+}
+
+func textDecodeUTF8(b []byte) (string, error) {
+	if !utf8.Valid(b) {
+		return "", fmt.Errorf("invalid UTF-8")
+	}
+
+	return string(b), nil
+}
+
+func textDecodeUTF16(b []byte, bigEndian bool) (string, error) {
+	if len(b)%2 != 0 {
+		return "", fmt.Errorf("odd number of bytes in UTF-16 data")
+	}
+
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+		} else {
+			units[i] = uint16(b[2*i+1])<<8 | uint16(b[2*i])
+		}
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+func textDecodeLatin1(b []byte) (string, error) {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+
+	return string(runes), nil
+}
+
+func textDecodeASCII(b []byte) (string, error) {
+	for _, c := range b {
+		if c >= utf8.RuneSelf {
+			return "", fmt.Errorf("byte 0x%02x is not valid US-ASCII", c)
+		}
+	}
+
+	return string(b), nil
+}
+
+// windows1252High maps the bytes 0x80 through 0x9F of Windows-1252, which
+// differ from ISO-8859-1. Zero entries are unassigned.
+var windows1252High = [32]rune{
+	'\u20AC', 0, '\u201A', '\u0192', '\u201E', '\u2026', '\u2020', '\u2021',
+	'\u02C6', '\u2030', '\u0160', '\u2039', '\u0152', 0, '\u017D', 0,
+	0, '\u2018', '\u2019', '\u201C', '\u201D', '\u2022', '\u2013', '\u2014',
+	'\u02DC', '\u2122', '\u0161', '\u203A', '\u0153', 0, '\u017E', '\u0178',
+}
+
+func textDecodeWindows1252(b []byte) (string, error) {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+		if c >= 0x80 && c <= 0x9F {
+			r := windows1252High[c-0x80]
+			if r == 0 {
+				return "", fmt.Errorf("byte 0x%02x is not valid windows-1252", c)
+			}
+			runes[i] = r
+		}
+	}
+
+	return string(runes), nil
+}
+
 // interpolationFuncLower implements the "lower" function that does
 // string lower casing.
 func interpolationFuncLower() ast.Function {
@@ -1082,6 +2346,80 @@ func interpolationFuncMerge() ast.Function {
 	}
 }
 
+// interpolationFuncMergeList implements the "mergelist" function that
+// merges a single list of maps like "merge", consuming the maps in list
+// order. An empty list results in an empty map.
+func interpolationFuncMergeList() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeList},
+		ReturnType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			outputMap := make(map[string]ast.Variable)
+
+			for i, v := range args[0].([]ast.Variable) {
+				if v.Type != ast.TypeMap {
+					return nil, fmt.Errorf(
+						"mergelist only works on a list of maps, element %d is %s",
+						i, v.Type.Printable())
+				}
+
+				for k, v := range v.Value.(map[string]ast.Variable) {
+					outputMap[k] = v
+				}
+			}
+
+			return outputMap, nil
+		},
+	}
+}
+
+// interpolationFuncDeepMerge implements the "deepmerge" function that
+// merges maps like "merge", except that nested maps present in more than
+// one argument are merged recursively. Any other conflicting values,
+// including lists, are replaced by the last argument.
+func interpolationFuncDeepMerge() ast.Function {
+	return ast.Function{
+		ArgTypes:     []ast.Type{ast.TypeMap},
+		ReturnType:   ast.TypeMap,
+		Variadic:     true,
+		VariadicType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			outputMap := make(map[string]ast.Variable)
+
+			for _, arg := range args {
+				outputMap = deepMergeMaps(outputMap, arg.(map[string]ast.Variable))
+			}
+
+			return outputMap, nil
+		},
+	}
+}
+
+// deepMergeMaps returns a new map with the contents of src merged into
+// dst. Neither argument is modified.
+func deepMergeMaps(dst, src map[string]ast.Variable) map[string]ast.Variable {
+	result := make(map[string]ast.Variable, len(dst)+len(src))
+	for k, v := range dst {
+		result[k] = v
+	}
+
+	for k, v := range src {
+		existing, ok := result[k]
+		if ok && existing.Type == ast.TypeMap && v.Type == ast.TypeMap {
+			v = ast.Variable{
+				Type: ast.TypeMap,
+				Value: deepMergeMaps(
+					existing.Value.(map[string]ast.Variable),
+					v.Value.(map[string]ast.Variable)),
+			}
+		}
+
+		result[k] = v
+	}
+
+	return result
+}
+
 // interpolationFuncUpper implements the "upper" function that does
 // string upper casing.
 func interpolationFuncUpper() ast.Function {
@@ -1124,6 +2462,34 @@ func interpolationFuncSha256() ast.Function {
 	}
 }
 
+// hexadecimal representation of sha512 sum
+func interpolationFuncSha512() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			s := args[0].(string)
+			h := sha512.New()
+			h.Write([]byte(s))
+			hash := hex.EncodeToString(h.Sum(nil))
+			return hash, nil
+		},
+	}
+}
+
+// interpolationFuncTrim implements the "trim", "trimleft" and "trimright"
+// functions that remove the characters in a cutset from a string using the
+// given trim function, such as strings.Trim.
+func interpolationFuncTrim(trim func(string, string) string) ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString, ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			return trim(args[0].(string), args[1].(string)), nil
+		},
+	}
+}
+
 func interpolationFuncTrimSpace() ast.Function {
 	return ast.Function{
 		ArgTypes:   []ast.Type{ast.TypeString},
@@ -1150,6 +2516,21 @@ func interpolationFuncBase64Sha256() ast.Function {
 	}
 }
 
+func interpolationFuncBase64Sha512() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeString},
+		ReturnType: ast.TypeString,
+		Callback: func(args []interface{}) (interface{}, error) {
+			s := args[0].(string)
+			h := sha512.New()
+			h.Write([]byte(s))
+			shaSum := h.Sum(nil)
+			encoded := base64.StdEncoding.EncodeToString(shaSum[:])
+			return encoded, nil
+		},
+	}
+}
+
 func interpolationFuncUUID() ast.Function {
 	return ast.Function{
 		ArgTypes:   []ast.Type{},
@@ -1171,6 +2552,120 @@ func interpolationFuncTimestamp() ast.Function {
 	}
 }
 
+// interpolationFuncToList implements the "tolist" function that converts
+// its argument to a list. A list is returned as-is and any other primitive
+// value is wrapped in a list of one string element.
+func interpolationFuncToList() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeAny},
+		ReturnType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			return toList(args[0])
+		},
+	}
+}
+
+// interpolationFuncToSet implements the "toset" function that converts its
+// argument to a list as "tolist" does and then removes duplicate elements
+// and sorts it. Only strings can be members of a set.
+func interpolationFuncToSet() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeAny},
+		ReturnType: ast.TypeList,
+		Callback: func(args []interface{}) (interface{}, error) {
+			list, err := toList(args[0])
+			if err != nil {
+				return nil, err
+			}
+
+			seen := make(map[string]struct{}, len(list))
+			members := make([]string, 0, len(list))
+			for i, v := range list {
+				if v.Type != ast.TypeString {
+					return nil, fmt.Errorf(
+						"cannot convert to a set: element %d is a %s, only strings "+
+							"may be members of a set", i, v.Type.Printable())
+				}
+
+				member := v.Value.(string)
+				if _, ok := seen[member]; ok {
+					continue
+				}
+				seen[member] = struct{}{}
+				members = append(members, member)
+			}
+
+			sort.Strings(members)
+			return stringSliceToVariableValue(members), nil
+		},
+	}
+}
+
+// interpolationFuncToMap implements the "tomap" function that converts its
+// argument to a map. A map is returned as-is and an empty list is converted
+// to an empty map, since an empty collection can't otherwise be told apart.
+// Anything else can't be converted.
+func interpolationFuncToMap() ast.Function {
+	return ast.Function{
+		ArgTypes:   []ast.Type{ast.TypeAny},
+		ReturnType: ast.TypeMap,
+		Callback: func(args []interface{}) (interface{}, error) {
+			switch v := args[0].(type) {
+			case map[string]ast.Variable:
+				return v, nil
+			case []ast.Variable:
+				if len(v) == 0 {
+					return map[string]ast.Variable{}, nil
+				}
+
+				return nil, fmt.Errorf(
+					"cannot convert a list with %d elements to a map", len(v))
+			default:
+				return nil, fmt.Errorf("cannot convert %s to a map", collectionTypeName(v))
+			}
+		},
+	}
+}
+
+// toList converts a value given to a TypeAny argument to a list, as
+// described for "tolist".
+func toList(raw interface{}) ([]ast.Variable, error) {
+	switch v := raw.(type) {
+	case []ast.Variable:
+		return v, nil
+	case string:
+		return stringSliceToVariableValue([]string{v}), nil
+	case int:
+		return stringSliceToVariableValue([]string{strconv.Itoa(v)}), nil
+	case float64:
+		return stringSliceToVariableValue(
+			[]string{strconv.FormatFloat(v, 'f', -1, 64)}), nil
+	case bool:
+		return stringSliceToVariableValue([]string{strconv.FormatBool(v)}), nil
+	default:
+		return nil, fmt.Errorf("cannot convert %s to a list", collectionTypeName(v))
+	}
+}
+
+// collectionTypeName returns the name of the type of a value given to a
+// TypeAny argument for use in conversion errors.
+func collectionTypeName(raw interface{}) string {
+	switch raw.(type) {
+	case []ast.Variable:
+		return "a list"
+	case map[string]ast.Variable:
+		return "a map"
+	case string:
+		return "a string"
+	case int, float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return fmt.Sprintf("a value of type %T", raw)
+	}
+}
+
 // interpolationFuncTitle implements the "title" function that returns a copy of the
 // string in which first characters of all the words are capitalized.
 func interpolationFuncTitle() ast.Function {
@@ -3,13 +3,18 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"reflect"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"path/filepath"
 
+	"github.com/apparentlymart/go-cidr/cidr"
 	"github.com/hashicorp/hil"
 	"github.com/hashicorp/hil/ast"
 	"github.com/mitchellh/go-homedir"
@@ -124,6 +129,64 @@ func TestInterpolateFuncZipMap(t *testing.T) {
 	})
 }
 
+func TestInterpolateFuncGroupBy(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Duplicate keys collect all their values in order
+			{
+				`${groupby(var.keys, var.values)}`,
+				map[string]interface{}{
+					"web": []interface{}{"a", "c"},
+					"db":  []interface{}{"b"},
+				},
+				false,
+			},
+			// Unique keys still get a list
+			{
+				`${groupby(list("x", "y"), list("1", "2"))}`,
+				map[string]interface{}{
+					"x": []interface{}{"1"},
+					"y": []interface{}{"2"},
+				},
+				false,
+			},
+			{
+				`${groupby(list(), list())}`,
+				map[string]interface{}{},
+				false,
+			},
+			{
+				`${groupby(var.keys, list("a"))}`,
+				nil,
+				true,
+			},
+			{
+				`${groupby(list(list("a")), list("a"))}`,
+				nil,
+				true,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.keys": {
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeString, Value: "web"},
+					{Type: ast.TypeString, Value: "db"},
+					{Type: ast.TypeString, Value: "web"},
+				},
+			},
+			"var.values": {
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeString, Value: "a"},
+					{Type: ast.TypeString, Value: "b"},
+					{Type: ast.TypeString, Value: "c"},
+				},
+			},
+		},
+	})
+}
+
 func TestInterpolateFuncList(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -264,6 +327,39 @@ func TestInterpolateFuncMax(t *testing.T) {
 				"-1",
 				false,
 			},
+
+			{
+				`${max(1.5, 2.25)}`,
+				"2.25",
+				false,
+			},
+
+			{
+				`${max(var.list)}`,
+				"3.5",
+				false,
+			},
+
+			{
+				`${max(split(",", "-4,-1,-7"))}`,
+				"-1",
+				false,
+			},
+
+			{
+				`${max(list())}`,
+				nil,
+				true,
+			},
+
+			{
+				`${max(list("1", "foo"))}`,
+				nil,
+				true,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.list": interfaceToVariableSwallowError([]string{"1", "3.5", "-2"}),
 		},
 	})
 }
@@ -306,6 +402,78 @@ func TestInterpolateFuncMin(t *testing.T) {
 				"-1",
 				false,
 			},
+
+			{
+				`${min(1.5, 2.25)}`,
+				"1.5",
+				false,
+			},
+
+			{
+				`${min(var.list)}`,
+				"-2",
+				false,
+			},
+
+			{
+				`${min(split(",", "0.5,0.25,1"))}`,
+				"0.25",
+				false,
+			},
+
+			{
+				`${min(list())}`,
+				nil,
+				true,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.list": interfaceToVariableSwallowError([]string{"1", "3.5", "-2"}),
+		},
+	})
+}
+
+func TestInterpolateFuncSum(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${sum(var.list)}`,
+				"2.5",
+				false,
+			},
+
+			{
+				`${sum(split(",", "-1,-2.5"))}`,
+				"-3.5",
+				false,
+			},
+
+			{
+				`${sum(list("4"))}`,
+				"4",
+				false,
+			},
+
+			{
+				`${sum(list())}`,
+				"0",
+				false,
+			},
+
+			{
+				`${sum(list("1", "foo"))}`,
+				nil,
+				true,
+			},
+
+			{
+				`${sum("1")}`,
+				nil,
+				true,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.list": interfaceToVariableSwallowError([]string{"1", "3.5", "-2"}),
 		},
 	})
 }
@@ -506,6 +674,97 @@ func TestInterpolateFuncCidrHost(t *testing.T) {
 	})
 }
 
+func TestInterpolateFuncCidrContains(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Contained
+			{
+				`${cidrcontains("10.0.0.0/8", "10.1.2.3")}`,
+				"true",
+				false,
+			},
+			{
+				`${cidrcontains("10.0.0.0/8", "10.1.0.0/16")}`,
+				"true",
+				false,
+			},
+			{
+				`${cidrcontains("fd00::/8", "fd12:3456::1")}`,
+				"true",
+				false,
+			},
+			{
+				`${cidrcontains("fd00::/8", "fd12::/16")}`,
+				"true",
+				false,
+			},
+
+			// Not contained
+			{
+				`${cidrcontains("10.0.0.0/8", "192.168.1.1")}`,
+				"false",
+				false,
+			},
+			{
+				`${cidrcontains("10.0.0.0/16", "10.0.0.0/8")}`,
+				"false",
+				false,
+			},
+			{
+				`${cidrcontains("10.0.0.0/16", "10.1.0.0/24")}`,
+				"false",
+				false,
+			},
+			{
+				`${cidrcontains("fd00::/8", "fe80::1")}`,
+				"false",
+				false,
+			},
+
+			// Exact match
+			{
+				`${cidrcontains("10.0.0.0/16", "10.0.0.0/16")}`,
+				"true",
+				false,
+			},
+			{
+				`${cidrcontains("10.0.0.1/32", "10.0.0.1")}`,
+				"true",
+				false,
+			},
+			{
+				`${cidrcontains("fd00::/8", "fd00::/8")}`,
+				"true",
+				false,
+			},
+
+			// Family mismatch
+			{
+				`${cidrcontains("10.0.0.0/8", "fd00::1")}`,
+				nil,
+				true,
+			},
+			{
+				`${cidrcontains("::/0", "10.0.0.0/8")}`,
+				nil,
+				true,
+			},
+
+			// Invalid input
+			{
+				`${cidrcontains("not-a-cidr", "10.0.0.1")}`,
+				nil,
+				true,
+			},
+			{
+				`${cidrcontains("10.0.0.0/8", "not-an-ip")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
 func TestInterpolateFuncCidrNetmask(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -545,6 +804,108 @@ func TestInterpolateFuncCidrNetmask(t *testing.T) {
 	})
 }
 
+func TestParseCIDR(t *testing.T) {
+	prefixes := []string{
+		"10.0.0.0/8",
+		"10.1.2.3/16",
+		"192.168.1.0/24",
+		"fe80::/48",
+		"2001:db8::1/64",
+	}
+
+	// Parse each prefix twice, so that the second time comes from the cache
+	for i := 0; i < 2; i++ {
+		for _, prefix := range prefixes {
+			_, expected, err := net.ParseCIDR(prefix)
+			if err != nil {
+				t.Fatalf("%s: err: %s", prefix, err)
+			}
+
+			actual, err := parseCIDR(prefix)
+			if err != nil {
+				t.Fatalf("%s: err: %s", prefix, err)
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("%s: bad: %s, expected %s", prefix, actual, expected)
+			}
+		}
+	}
+
+	// Errors are the same as net.ParseCIDR's, and aren't cached
+	for i := 0; i < 2; i++ {
+		_, _, expected := net.ParseCIDR("10.256.0.0/8")
+		if _, err := parseCIDR("10.256.0.0/8"); err == nil || err.Error() != expected.Error() {
+			t.Fatalf("bad: %v, expected %s", err, expected)
+		}
+	}
+}
+
+func TestParseCIDR_bounded(t *testing.T) {
+	for i := 0; i < cidrCacheSize*2+1; i++ {
+		prefix := fmt.Sprintf("10.%d.%d.0/24", i/256, i%256)
+		if _, err := parseCIDR(prefix); err != nil {
+			t.Fatalf("%s: err: %s", prefix, err)
+		}
+	}
+
+	cidrCache.Lock()
+	defer cidrCache.Unlock()
+	if n := len(cidrCache.networks); n > cidrCacheSize {
+		t.Fatalf("cache has %d networks, more than %d", n, cidrCacheSize)
+	}
+}
+
+func TestParseCIDR_concurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				prefix := fmt.Sprintf("10.%d.%d.0/24", i, j)
+				network, err := parseCIDR(prefix)
+				if err != nil {
+					t.Errorf("%s: err: %s", prefix, err)
+					return
+				}
+				if network.String() != prefix {
+					t.Errorf("bad: %s, expected %s", network, prefix)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func BenchmarkInterpolateFuncCidrSubnet(b *testing.B) {
+	callback := interpolationFuncCidrSubnet().Callback
+
+	b.Run("cached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := callback([]interface{}{"10.0.0.0/8", 8, i % 256}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	// For comparison, the cost of parsing the prefix on each call as the
+	// function did before the cache
+	b.Run("parse", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, network, err := net.ParseCIDR("10.0.0.0/8")
+			if err != nil {
+				b.Fatal(err)
+			}
+			subnet, err := cidr.Subnet(network, 8, i%256)
+			if err != nil {
+				b.Fatal(err)
+			}
+			_ = subnet.String()
+		}
+	})
+}
+
 func TestInterpolateFuncCidrSubnet(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -588,71 +949,232 @@ func TestInterpolateFuncCidrSubnet(t *testing.T) {
 	})
 }
 
-func TestInterpolateFuncCoalesce(t *testing.T) {
+func TestInterpolateFuncCidrSubnetsFrom(t *testing.T) {
 	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.pairs": interfaceToVariableSwallowError([]interface{}{
+				[]interface{}{"8", "1"},
+				[]interface{}{"4", "15"},
+			}),
+		},
 		Cases: []testFunctionCase{
 			{
-				`${coalesce("first", "second", "third")}`,
-				"first",
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("8", "0"), list("8", "2"), list("8", "7")))}`,
+				[]interface{}{"10.0.0.0/24", "10.0.2.0/24", "10.0.7.0/24"},
 				false,
 			},
 			{
-				`${coalesce("", "second", "third")}`,
-				"second",
+				// Different sizes, out of order and with gaps
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("4", "15"), list("8", "1"), list("2", "1")))}`,
+				[]interface{}{"10.0.240.0/20", "10.0.1.0/24", "10.0.64.0/18"},
 				false,
 			},
 			{
-				`${coalesce("", "", "")}`,
-				"",
+				`${cidrsubnetsfrom("10.0.0.0/16", var.pairs)}`,
+				[]interface{}{"10.0.1.0/24", "10.0.240.0/20"},
 				false,
 			},
 			{
-				`${coalesce("foo")}`,
+				`${cidrsubnetsfrom("fe80::/48", list(list("16", "6"), list("16", "1024")))}`,
+				[]interface{}{"fe80:0:0:6::/64", "fe80:0:0:400::/64"},
+				false,
+			},
+			{
+				`${cidrsubnetsfrom("10.0.0.0/16", list())}`,
+				[]interface{}{},
+				false,
+			},
+			{
+				// The /24 is within the /20
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("4", "1"), list("8", "17")))}`,
 				nil,
 				true,
 			},
-		},
-	})
-}
-
-func TestInterpolateFuncConcat(t *testing.T) {
-	testFunction(t, testFunctionConfig{
-		Cases: []testFunctionCase{
-			// String + list
-			// no longer supported, now returns an error
 			{
-				`${concat("a", split(",", "b,c"))}`,
+				// The /20 contains the /24
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("8", "17"), list("4", "1")))}`,
 				nil,
 				true,
 			},
-
-			// List + string
-			// no longer supported, now returns an error
 			{
-				`${concat(split(",", "a,b"), "c")}`,
+				// The same subnet twice
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("8", "3"), list("8", "3")))}`,
 				nil,
 				true,
 			},
-
-			// Single list
 			{
-				`${concat(split(",", ",foo,"))}`,
-				[]interface{}{"", "foo", ""},
-				false,
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("2", "4")))}`,
+				nil,
+				true, // can't encode 4 in 2 bits
 			},
 			{
-				`${concat(split(",", "a,b,c"))}`,
-				[]interface{}{"a", "b", "c"},
-				false,
+				`${cidrsubnetsfrom("10.0.0.0/30", list(list("4", "0")))}`,
+				nil,
+				true, // not enough bits left
 			},
-
-			// Two lists
 			{
-				`${concat(split(",", "a,b,c"), split(",", "d,e"))}`,
-				[]interface{}{"a", "b", "c", "d", "e"},
-				false,
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("8")))}`,
+				nil,
+				true, // not a pair
 			},
-			// Two lists with different separators
+			{
+				`${cidrsubnetsfrom("10.0.0.0/16", list("8", "0"))}`,
+				nil,
+				true, // not a list of pairs
+			},
+			{
+				`${cidrsubnetsfrom("10.0.0.0/16", list(list("8", "x")))}`,
+				nil,
+				true, // not a number
+			},
+			{
+				`${cidrsubnetsfrom("not-a-cidr", list(list("8", "0")))}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncCoalesce(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${coalesce("first", "second", "third")}`,
+				"first",
+				false,
+			},
+			{
+				`${coalesce("", "second", "third")}`,
+				"second",
+				false,
+			},
+			{
+				`${coalesce("", "", "")}`,
+				"",
+				false,
+			},
+			{
+				`${coalesce("", 42, "third")}`,
+				"42",
+				false,
+			},
+			{
+				`${coalesce("foo")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncCoalesceList(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.empty": interfaceToVariableSwallowError([]interface{}{}),
+			"var.first": interfaceToVariableSwallowError([]interface{}{"a", "b"}),
+			"var.other": interfaceToVariableSwallowError([]interface{}{"c"}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${coalescelist(var.first, var.other)}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+			{
+				`${coalescelist(var.empty, var.other, var.first)}`,
+				[]interface{}{"c"},
+				false,
+			},
+			{
+				`${coalescelist(var.empty, var.empty)}`,
+				[]interface{}{},
+				false,
+			},
+			{
+				`${coalescelist(var.first)}`,
+				nil,
+				true,
+			},
+			{
+				`${coalescelist(var.empty, "foo")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncCoalesceMap(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.empty": interfaceToVariableSwallowError(map[string]interface{}{}),
+			"var.first": interfaceToVariableSwallowError(map[string]interface{}{"a": "1"}),
+			"var.other": interfaceToVariableSwallowError(map[string]interface{}{"b": "2"}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${coalescemap(var.first, var.other)}`,
+				map[string]interface{}{"a": "1"},
+				false,
+			},
+			{
+				`${coalescemap(var.empty, var.other)}`,
+				map[string]interface{}{"b": "2"},
+				false,
+			},
+			{
+				`${coalescemap(var.empty, var.empty)}`,
+				map[string]interface{}{},
+				false,
+			},
+			{
+				`${coalescemap(var.first)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncConcat(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// String + list
+			// no longer supported, now returns an error
+			{
+				`${concat("a", split(",", "b,c"))}`,
+				nil,
+				true,
+			},
+
+			// List + string
+			// no longer supported, now returns an error
+			{
+				`${concat(split(",", "a,b"), "c")}`,
+				nil,
+				true,
+			},
+
+			// Single list
+			{
+				`${concat(split(",", ",foo,"))}`,
+				[]interface{}{"", "foo", ""},
+				false,
+			},
+			{
+				`${concat(split(",", "a,b,c"))}`,
+				[]interface{}{"a", "b", "c"},
+				false,
+			},
+
+			// Two lists
+			{
+				`${concat(split(",", "a,b,c"), split(",", "d,e"))}`,
+				[]interface{}{"a", "b", "c", "d", "e"},
+				false,
+			},
+			// Two lists with different separators
 			{
 				`${concat(split(",", "a,b,c"), split(" ", "d e"))}`,
 				[]interface{}{"a", "b", "c", "d", "e"},
@@ -755,6 +1277,95 @@ func TestInterpolateFuncConcat(t *testing.T) {
 	})
 }
 
+func TestInterpolateFuncDeepMerge(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// nested maps are merged
+			{
+				`${deepmerge(var.base, var.override)}`,
+				map[string]interface{}{
+					"name": "override",
+					"tags": map[string]interface{}{
+						"env":   "prod",
+						"owner": "ops",
+						"team":  "web",
+					},
+					"zones": []interface{}{"c"},
+					"keep":  "yes",
+				},
+				false,
+			},
+
+			// scalars are replaced by the last argument
+			{
+				`${deepmerge(map("a", "b"), map("a", "c"), map("a", "d"))}`,
+				map[string]interface{}{"a": "d"},
+				false,
+			},
+
+			// a map replaces a scalar and vice versa
+			{
+				`${deepmerge(map("a", "b"), map("a", map("c", "d")))}`,
+				map[string]interface{}{"a": map[string]interface{}{"c": "d"}},
+				false,
+			},
+			{
+				`${deepmerge(map("a", map("c", "d")), map("a", "b"))}`,
+				map[string]interface{}{"a": "b"},
+				false,
+			},
+
+			// lists are replaced, not concatenated
+			{
+				`${deepmerge(map("a", list("b", "c")), map("a", list("d")))}`,
+				map[string]interface{}{"a": []interface{}{"d"}},
+				false,
+			},
+
+			// shallow merge is unaffected
+			{
+				`${merge(var.base, var.override)}`,
+				map[string]interface{}{
+					"name": "override",
+					"tags": map[string]interface{}{
+						"owner": "ops",
+						"env":   "prod",
+					},
+					"zones": []interface{}{"c"},
+					"keep":  "yes",
+				},
+				false,
+			},
+
+			// only accept maps
+			{
+				`${deepmerge(map("a", "b"), list("c", "d"))}`,
+				nil,
+				true,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.base": interfaceToVariableSwallowError(map[string]interface{}{
+				"name": "base",
+				"keep": "yes",
+				"tags": map[string]interface{}{
+					"team": "web",
+					"env":  "dev",
+				},
+				"zones": []interface{}{"a", "b"},
+			}),
+			"var.override": interfaceToVariableSwallowError(map[string]interface{}{
+				"name": "override",
+				"tags": map[string]interface{}{
+					"owner": "ops",
+					"env":   "prod",
+				},
+				"zones": []interface{}{"c"},
+			}),
+		},
+	})
+}
+
 func TestInterpolateFuncMerge(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -852,65 +1463,56 @@ func TestInterpolateFuncMerge(t *testing.T) {
 
 }
 
-func TestInterpolateFuncDistinct(t *testing.T) {
+func TestInterpolateFuncMergeList(t *testing.T) {
 	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.maps": interfaceToVariableSwallowError([]interface{}{
+				map[string]interface{}{"a": "1", "b": "1"},
+				map[string]interface{}{"b": "2", "c": "2"},
+				map[string]interface{}{"c": "3", "d": []interface{}{"3"}},
+			}),
+			"var.empty": interfaceToVariableSwallowError([]interface{}{}),
+			"var.strings": interfaceToVariableSwallowError([]interface{}{
+				"a", "b",
+			}),
+		},
 		Cases: []testFunctionCase{
-			// 3 duplicates
+			// later maps win on overlapping keys
 			{
-				`${distinct(concat(split(",", "user1,user2,user3"), split(",", "user1,user2,user3")))}`,
-				[]interface{}{"user1", "user2", "user3"},
+				`${mergelist(var.maps)}`,
+				map[string]interface{}{
+					"a": "1",
+					"b": "2",
+					"c": "3",
+					"d": []interface{}{"3"},
+				},
 				false,
 			},
-			// 1 duplicate
+
+			// a list of a single map
 			{
-				`${distinct(concat(split(",", "user1,user2,user3"), split(",", "user1,user4")))}`,
-				[]interface{}{"user1", "user2", "user3", "user4"},
+				`${mergelist(list(map("a", "b")))}`,
+				map[string]interface{}{"a": "b"},
 				false,
 			},
-			// too many args
-			{
-				`${distinct(concat(split(",", "user1,user2,user3"), split(",", "user1,user4")), "foo")}`,
-				nil,
-				true,
-			},
-			// non-flat list is an error
-			{
-				`${distinct(list(list("a"), list("a")))}`,
-				nil,
-				true,
-			},
-		},
-	})
-}
-
-func TestInterpolateFuncFile(t *testing.T) {
-	tf, err := ioutil.TempFile("", "tf")
-	if err != nil {
-		t.Fatalf("err: %s", err)
-	}
-	path := tf.Name()
-	tf.Write([]byte("foo"))
-	tf.Close()
-	defer os.Remove(path)
 
-	testFunction(t, testFunctionConfig{
-		Cases: []testFunctionCase{
+			// an empty list gives an empty map
 			{
-				fmt.Sprintf(`${file("%s")}`, path),
-				"foo",
+				`${mergelist(var.empty)}`,
+				map[string]interface{}{},
 				false,
 			},
 
-			// Invalid path
+			// only accept a list of maps
 			{
-				`${file("/i/dont/exist")}`,
+				`${mergelist(var.strings)}`,
 				nil,
 				true,
 			},
 
-			// Too many args
+			// only accept a list
 			{
-				`${file("foo", "bar")}`,
+				`${mergelist(map("a", "b"))}`,
 				nil,
 				true,
 			},
@@ -918,559 +1520,1617 @@ func TestInterpolateFuncFile(t *testing.T) {
 	})
 }
 
-func TestInterpolateFuncFormat(t *testing.T) {
+func TestInterpolateFuncDistinct(t *testing.T) {
 	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.maps": interfaceToVariableSwallowError([]interface{}{
+				map[string]interface{}{"a": []interface{}{"1"}},
+				map[string]interface{}{"a": []interface{}{"1", "2"}},
+				map[string]interface{}{"a": []interface{}{"1"}},
+			}),
+		},
 		Cases: []testFunctionCase{
+			// 3 duplicates
 			{
-				`${format("hello")}`,
-				"hello",
+				`${distinct(concat(split(",", "user1,user2,user3"), split(",", "user1,user2,user3")))}`,
+				[]interface{}{"user1", "user2", "user3"},
 				false,
 			},
-
+			// 1 duplicate
 			{
-				`${format("hello %s", "world")}`,
-				"hello world",
+				`${distinct(concat(split(",", "user1,user2,user3"), split(",", "user1,user4")))}`,
+				[]interface{}{"user1", "user2", "user3", "user4"},
 				false,
 			},
-
+			// first occurrences keep their order
 			{
-				`${format("hello %d", 42)}`,
-				"hello 42",
+				`${distinct(split(",", "c,a,c,b,a,d,b,c"))}`,
+				[]interface{}{"c", "a", "b", "d"},
 				false,
 			},
-
+			// no duplicates leaves the list unchanged
 			{
-				`${format("hello %05d", 42)}`,
-				"hello 00042",
+				`${distinct(split(",", "z,b,y,a"))}`,
+				[]interface{}{"z", "b", "y", "a"},
 				false,
 			},
-
+			// too many args
 			{
-				`${format("hello %05d", 12345)}`,
-				"hello 12345",
+				`${distinct(concat(split(",", "user1,user2,user3"), split(",", "user1,user4")), "foo")}`,
+				nil,
+				true,
+			},
+			// lists are compared structurally
+			{
+				`${distinct(list(list("a"), list("a"), list("a", "b")))}`,
+				[]interface{}{
+					[]interface{}{"a"},
+					[]interface{}{"a", "b"},
+				},
 				false,
 			},
-		},
+			// maps are compared structurally
+			{
+				`${distinct(list(map("a", "1"), map("a", "2"), map("a", "1")))}`,
+				[]interface{}{
+					map[string]interface{}{"a": "1"},
+					map[string]interface{}{"a": "2"},
+				},
+				false,
+			},
+			{
+				`${distinct(var.maps)}`,
+				[]interface{}{
+					map[string]interface{}{
+						"a": []interface{}{"1"},
+					},
+					map[string]interface{}{
+						"a": []interface{}{"1", "2"},
+					},
+				},
+				false,
+			},
+		},
 	})
 }
 
-func TestInterpolateFuncFormatList(t *testing.T) {
+func TestInterpolateFuncContains(t *testing.T) {
 	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.maps": interfaceToVariableSwallowError([]interface{}{
+				map[string]interface{}{"name": "a", "port": "80"},
+				map[string]interface{}{"name": "b", "port": "443"},
+			}),
+		},
 		Cases: []testFunctionCase{
-			// formatlist requires at least one list
 			{
-				`${formatlist("hello")}`,
-				nil,
-				true,
-			},
-			{
-				`${formatlist("hello %s", "world")}`,
-				nil,
-				true,
+				`${contains(list("a", "b"), "b")}`,
+				"true",
+				false,
 			},
-			// formatlist applies to each list element in turn
 			{
-				`${formatlist("<%s>", split(",", "A,B"))}`,
-				[]interface{}{"<A>", "<B>"},
+				`${contains(list("a", "b"), "c")}`,
+				"false",
 				false,
 			},
-			// formatlist repeats scalar elements
 			{
-				`${join(", ", formatlist("%s=%s", "x", split(",", "A,B,C")))}`,
-				"x=A, x=B, x=C",
+				`${contains(list("1", "2"), 2)}`,
+				"true",
 				false,
 			},
-			// Multiple lists are walked in parallel
 			{
-				`${join(", ", formatlist("%s=%s", split(",", "A,B,C"), split(",", "1,2,3")))}`,
-				"A=1, B=2, C=3",
+				`${contains(var.maps, map("name", "b", "port", "443"))}`,
+				"true",
 				false,
 			},
-			// Mismatched list lengths generate an error
 			{
-				`${formatlist("%s=%2s", split(",", "A,B,C,D"), split(",", "1,2,3"))}`,
-				nil,
-				true,
+				`${contains(var.maps, map("name", "b"))}`,
+				"false",
+				false,
 			},
-			// Works with lists of length 1 [GH-2240]
 			{
-				`${formatlist("%s.id", split(",", "demo-rest-elb"))}`,
-				[]interface{}{"demo-rest-elb.id"},
+				`${contains(list(list("a"), list("b")), list("b"))}`,
+				"true",
 				false,
 			},
-			// Works with empty lists [GH-7607]
+			// a list is never equal to a string
 			{
-				`${formatlist("%s", var.emptylist)}`,
-				[]interface{}{},
+				`${contains(list(list("a")), "a")}`,
+				"false",
 				false,
 			},
-		},
-		Vars: map[string]ast.Variable{
-			"var.emptylist": {
-				Type:  ast.TypeList,
-				Value: []ast.Variable{},
+			{
+				`${contains(list("a"))}`,
+				nil,
+				true,
 			},
 		},
 	})
 }
 
-func TestInterpolateFuncIndex(t *testing.T) {
+func TestInterpolateFuncFile(t *testing.T) {
+	tf, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	path := tf.Name()
+	tf.Write([]byte("foo"))
+	tf.Close()
+	defer os.Remove(path)
+
 	testFunction(t, testFunctionConfig{
-		Vars: map[string]ast.Variable{
-			"var.list1": interfaceToVariableSwallowError([]string{"notfoo", "stillnotfoo", "bar"}),
-			"var.list2": interfaceToVariableSwallowError([]string{"foo"}),
-			"var.list3": interfaceToVariableSwallowError([]string{"foo", "spam", "bar", "eggs"}),
-		},
 		Cases: []testFunctionCase{
 			{
-				`${index("test", "")}`,
-				nil,
-				true,
+				fmt.Sprintf(`${file("%s")}`, path),
+				"foo",
+				false,
 			},
 
+			// Invalid path
 			{
-				`${index(var.list1, "foo")}`,
+				`${file("/i/dont/exist")}`,
 				nil,
 				true,
 			},
 
+			// Too many args
 			{
-				`${index(var.list2, "foo")}`,
-				"0",
-				false,
-			},
-
-			{
-				`${index(var.list3, "bar")}`,
-				"2",
-				false,
+				`${file("foo", "bar")}`,
+				nil,
+				true,
 			},
 		},
 	})
 }
 
-func TestInterpolateFuncJoin(t *testing.T) {
+func TestInterpolateFuncFile_maxSize(t *testing.T) {
+	defer func(old int64) { MaxFileSize = old }(MaxFileSize)
+	MaxFileSize = 8
+
+	td, err := ioutil.TempDir("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	defer os.RemoveAll(td)
+
+	small := filepath.Join(td, "small")
+	if err := ioutil.WriteFile(small, []byte("12345678"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	big := filepath.Join(td, "big")
+	if err := ioutil.WriteFile(big, []byte("123456789"), 0644); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
 	testFunction(t, testFunctionConfig{
-		Vars: map[string]ast.Variable{
-			"var.a_list":        interfaceToVariableSwallowError([]string{"foo"}),
-			"var.a_longer_list": interfaceToVariableSwallowError([]string{"foo", "bar", "baz"}),
-			"var.list_of_lists": interfaceToVariableSwallowError([]interface{}{[]string{"foo"}, []string{"bar"}, []string{"baz"}}),
-		},
 		Cases: []testFunctionCase{
 			{
-				`${join(",")}`,
-				nil,
-				true,
+				fmt.Sprintf(`${file("%s")}`, small),
+				"12345678",
+				false,
 			},
-
 			{
-				`${join(",", var.a_list)}`,
-				"foo",
+				fmt.Sprintf(`${filebase64("%s")}`, small),
+				"MTIzNDU2Nzg=",
 				false,
 			},
-
 			{
-				`${join(".", var.a_longer_list)}`,
-				"foo.bar.baz",
+				fmt.Sprintf(`${templatefile("%s", map())}`, small),
+				"12345678",
 				false,
 			},
-
 			{
-				`${join(".", var.list_of_lists)}`,
+				fmt.Sprintf(`${file("%s")}`, big),
 				nil,
 				true,
 			},
 			{
-				`${join(".", list(list("nested")))}`,
+				fmt.Sprintf(`${filebase64("%s")}`, big),
+				nil,
+				true,
+			},
+			{
+				fmt.Sprintf(`${templatefile("%s", map())}`, big),
 				nil,
 				true,
 			},
 		},
 	})
+
+	_, err = readFileLimited(big)
+	if err == nil || !strings.Contains(err.Error(), "larger than the limit of 8 bytes") {
+		t.Fatalf("bad: %v", err)
+	}
+
+	// No limit
+	MaxFileSize = 0
+	data, err := readFileLimited(big)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if string(data) != "123456789" {
+		t.Fatalf("bad: %q", data)
+	}
 }
 
-func TestInterpolateFuncJSONEncode(t *testing.T) {
-	testFunction(t, testFunctionConfig{
-		Vars: map[string]ast.Variable{
-			"easy": ast.Variable{
-				Value: "test",
-				Type:  ast.TypeString,
-			},
-			"hard": ast.Variable{
-				Value: " foo \\ \n \t \" bar ",
-				Type:  ast.TypeString,
-			},
-			"list": interfaceToVariableSwallowError([]string{"foo", "bar\tbaz"}),
-			// XXX can't use InterfaceToVariable as it converts empty slice into empty
-			// map.
-			"emptylist": ast.Variable{
-				Value: []ast.Variable{},
-				Type:  ast.TypeList,
-			},
-			"map": interfaceToVariableSwallowError(map[string]string{
-				"foo":     "bar",
-				"ba \n z": "q\\x",
-			}),
-			"emptymap": interfaceToVariableSwallowError(map[string]string{}),
+func TestInterpolateFuncFileBase64(t *testing.T) {
+	// Binary data that isn't valid UTF-8, which file() can't represent
+	binary, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	binaryPath := binary.Name()
+	binary.Write([]byte("\x89PNG\r\n\x1a\n\x00\xff\xfe"))
+	binary.Close()
+	defer os.Remove(binaryPath)
 
-			// Not yet supported (but it would be nice)
-			"nestedlist": interfaceToVariableSwallowError([][]string{{"foo"}}),
-			"nestedmap":  interfaceToVariableSwallowError(map[string][]string{"foo": {"bar"}}),
-		},
+	// Control characters and NUL bytes that survive a round trip
+	control, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	controlPath := control.Name()
+	control.Write([]byte("a\x00b\x01\x7f\u2713"))
+	control.Close()
+	defer os.Remove(controlPath)
+
+	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
 			{
-				`${jsonencode("test")}`,
-				`"test"`,
-				false,
-			},
-			{
-				`${jsonencode(easy)}`,
-				`"test"`,
-				false,
-			},
-			{
-				`${jsonencode(hard)}`,
-				`" foo \\ \n \t \" bar "`,
+				fmt.Sprintf(`${filebase64("%s")}`, binaryPath),
+				"iVBORw0KGgoA//4=",
 				false,
 			},
+
 			{
-				`${jsonencode("")}`,
-				`""`,
+				fmt.Sprintf(`${base64decode(filebase64("%s"))}`, controlPath),
+				"a\x00b\x01\x7f\u2713",
 				false,
 			},
+
+			// Invalid path
 			{
-				`${jsonencode()}`,
+				`${filebase64("/i/dont/exist")}`,
 				nil,
 				true,
 			},
+
+			// Too many args
 			{
-				`${jsonencode(list)}`,
-				`["foo","bar\tbaz"]`,
-				false,
+				`${filebase64("foo", "bar")}`,
+				nil,
+				true,
 			},
+		},
+	})
+}
+
+func TestInterpolateFuncTemplateFile(t *testing.T) {
+	tf, err := ioutil.TempFile("", "tf")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	path := tf.Name()
+	tf.Write([]byte(`hello ${name}, ${upper(greeting)}`))
+	tf.Close()
+	defer os.Remove(path)
+
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
 			{
-				`${jsonencode(emptylist)}`,
-				`[]`,
+				fmt.Sprintf(`${templatefile("%s", map("name", "world", "greeting", "hi"))}`, path),
+				"hello world, HI",
 				false,
 			},
+
 			{
-				`${jsonencode(map)}`,
-				`{"ba \n z":"q\\x","foo":"bar"}`,
+				fmt.Sprintf(`${templatefile("%s", var.vars)}`, path),
+				"hello vars, BYE",
 				false,
 			},
+
+			// Undefined template variable
 			{
-				`${jsonencode(emptymap)}`,
-				`{}`,
-				false,
+				fmt.Sprintf(`${templatefile("%s", map("name", "world"))}`, path),
+				nil,
+				true,
 			},
+
+			// Invalid path
 			{
-				`${jsonencode(nestedlist)}`,
+				`${templatefile("/i/dont/exist", map("name", "world"))}`,
 				nil,
 				true,
 			},
+
+			// Vars must be a map
 			{
-				`${jsonencode(nestedmap)}`,
+				fmt.Sprintf(`${templatefile("%s", "world")}`, path),
 				nil,
 				true,
 			},
 		},
+		Vars: map[string]ast.Variable{
+			"var.vars": interfaceToVariableSwallowError(map[string]interface{}{
+				"name":     "vars",
+				"greeting": "bye",
+			}),
+		},
 	})
 }
 
-func TestInterpolateFuncReplace(t *testing.T) {
+func TestInterpolateFuncFormat(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
-			// Regular search and replace
 			{
-				`${replace("hello", "hel", "bel")}`,
-				"bello",
+				`${format("hello")}`,
+				"hello",
 				false,
 			},
 
-			// Search string doesn't match
 			{
-				`${replace("hello", "nope", "bel")}`,
-				"hello",
+				`${format("hello %s", "world")}`,
+				"hello world",
 				false,
 			},
 
-			// Regular expression
 			{
-				`${replace("hello", "/l/", "L")}`,
-				"heLLo",
+				`${format("hello %d", 42)}`,
+				"hello 42",
 				false,
 			},
 
 			{
-				`${replace("helo", "/(l)/", "$1$1")}`,
-				"hello",
+				`${format("hello %05d", 42)}`,
+				"hello 00042",
 				false,
 			},
 
-			// Bad regexp
 			{
-				`${replace("helo", "/(l/", "$1$1")}`,
-				nil,
-				true,
+				`${format("hello %05d", 12345)}`,
+				"hello 12345",
+				false,
 			},
-		},
-	})
-}
 
-func TestInterpolateFuncLength(t *testing.T) {
-	testFunction(t, testFunctionConfig{
-		Cases: []testFunctionCase{
-			// Raw strings
 			{
-				`${length("")}`,
-				"0",
+				`${format("web-%03d", parseint("1f", 16))}`,
+				"web-031",
 				false,
 			},
+
+			// lists and maps
 			{
-				`${length("a")}`,
-				"1",
+				`${format("zones: %v", list("a", "b"))}`,
+				`zones: ["a", "b"]`,
 				false,
 			},
 			{
-				`${length(" ")}`,
-				"1",
+				`${format("tags: %s", map("b", "2", "a", "1"))}`,
+				`tags: {"a": "1", "b": "2"}`,
 				false,
 			},
 			{
-				`${length(" a ,")}`,
-				"4",
+				`${format("%s has %d zones %v and tags %v", "web", 2, var.zones, var.tags)}`,
+				`web has 2 zones ["a", "b"] and tags {"env": "prod", "ports": ["80", "443"]}`,
 				false,
 			},
 			{
-				`${length("aaa")}`,
-				"3",
+				`${format("empty: %v %v", list(), map())}`,
+				`empty: [] {}`,
 				false,
 			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.zones": interfaceToVariableSwallowError([]interface{}{"a", "b"}),
+			"var.tags": interfaceToVariableSwallowError(map[string]interface{}{
+				"env":   "prod",
+				"ports": []interface{}{"80", "443"},
+			}),
+		},
+	})
+}
 
-			// Lists
+func TestInterpolateFuncParseInt(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
 			{
-				`${length(split(",", "a"))}`,
-				"1",
+				`${parseint("ff", 16)}`,
+				"255",
 				false,
 			},
 			{
-				`${length(split(",", "foo,"))}`,
-				"2",
+				`${parseint("FF", 16)}`,
+				"255",
 				false,
 			},
 			{
-				`${length(split(",", ",foo,"))}`,
-				"3",
+				`${parseint("1010", 2)}`,
+				"10",
 				false,
 			},
 			{
-				`${length(split(",", "foo,bar"))}`,
-				"2",
+				`${parseint("-42", 10)}`,
+				"-42",
 				false,
 			},
 			{
-				`${length(split(".", "one.two.three.four.five"))}`,
-				"5",
+				`${parseint("zz", 36)}`,
+				"1295",
 				false,
 			},
-			// Want length 0 if we split an empty string then compact
 			{
-				`${length(compact(split(",", "")))}`,
-				"0",
-				false,
+				`${parseint("12", 2)}`,
+				nil,
+				true, // 2 isn't a binary digit
 			},
-			// Works for maps
 			{
-				`${length(map("k", "v"))}`,
-				"1",
-				false,
+				`${parseint("0xff", 16)}`,
+				nil,
+				true, // no prefixes
 			},
 			{
-				`${length(map("k1", "v1", "k2", "v2"))}`,
-				"2",
-				false,
+				`${parseint("", 10)}`,
+				nil,
+				true,
+			},
+			{
+				`${parseint("1", 1)}`,
+				nil,
+				true,
+			},
+			{
+				`${parseint("1", 37)}`,
+				nil,
+				true,
 			},
 		},
 	})
 }
 
-func TestInterpolateFuncSignum(t *testing.T) {
+func TestInterpolateFuncFormatDate(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
 			{
-				`${signum()}`,
-				nil,
-				true,
+				`${formatdate("YYYY-MM-DD", "2017-03-04T05:06:07Z")}`,
+				"2017-03-04",
+				false,
 			},
-
 			{
-				`${signum("")}`,
-				nil,
-				true,
+				`${formatdate("YYYY-MM-DD hh:mm", "2017-03-04T15:06:07Z")}`,
+				"2017-03-04 15:06",
+				false,
 			},
-
 			{
-				`${signum(0)}`,
-				"0",
+				`${formatdate("EEE, DD MMM YYYY hh:mm:ss ZZZ", "2017-03-04T05:06:07Z")}`,
+				"Sat, 04 Mar 2017 05:06:07 UTC",
+				false,
+			},
+			{
+				`${formatdate("EEEE, MMMM D, YY H:mmaa", "2017-03-04T15:06:07Z")}`,
+				"Saturday, March 4, 17 3:06pm",
+				false,
+			},
+			{
+				`${formatdate("HH AA", "2017-03-04T00:06:07Z")}`,
+				"12 AM",
+				false,
+			},
+			{
+				`${formatdate("M/D h:m:s", "2017-03-04T05:06:07Z")}`,
+				"3/4 5:6:7",
 				false,
 			},
 
+			// Timezone offsets
 			{
-				`${signum(15)}`,
-				"1",
+				`${formatdate("hh:mm ZZZZZ", "2017-03-04T05:06:07+05:30")}`,
+				"05:06 +05:30",
+				false,
+			},
+			{
+				`${formatdate("hh:mm ZZZZ", "2017-03-04T05:06:07-08:00")}`,
+				"05:06 -0800",
+				false,
+			},
+			{
+				`${formatdate("YYYY-MM-DD'T'hh:mm:ssZ", "2017-03-04T05:06:07-08:00")}`,
+				"2017-03-04T05:06:07-08:00",
+				false,
+			},
+			{
+				`${formatdate("YYYY-MM-DD'T'hh:mm:ssZ", "2017-03-04T05:06:07Z")}`,
+				"2017-03-04T05:06:07Z",
 				false,
 			},
 
+			// Quoted literals
 			{
-				`${signum(-29)}`,
-				"-1",
+				`${formatdate("'Day' D 'o''clock'", "2017-03-04T05:06:07Z")}`,
+				"Day 4 o'clock",
+				false,
+			},
+			{
+				`${formatdate("''hh''", "2017-03-04T05:06:07Z")}`,
+				"'05'",
 				false,
 			},
+
+			// Invalid timestamps
+			{
+				`${formatdate("YYYY", "2017-03-04")}`,
+				nil,
+				true,
+			},
+			{
+				`${formatdate("YYYY", "not a timestamp")}`,
+				nil,
+				true,
+			},
+
+			// Invalid specifiers
+			{
+				`${formatdate("YYY", "2017-03-04T05:06:07Z")}`,
+				nil,
+				true,
+			},
+			{
+				`${formatdate("YYYY-QQ", "2017-03-04T05:06:07Z")}`,
+				nil,
+				true,
+			},
+			{
+				`${formatdate("'unterminated", "2017-03-04T05:06:07Z")}`,
+				nil,
+				true,
+			},
 		},
 	})
 }
 
-func TestInterpolateFuncSlice(t *testing.T) {
+func TestInterpolateFuncFormatList(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
-			// Negative from index
+			// formatlist requires at least one list
 			{
-				`${slice(list("a"), -1, 0)}`,
+				`${formatlist("hello")}`,
 				nil,
 				true,
 			},
-			// From index > to index
 			{
-				`${slice(list("a", "b", "c"), 2, 1)}`,
+				`${formatlist("hello %s", "world")}`,
 				nil,
 				true,
 			},
-			// To index too large
+			// formatlist applies to each list element in turn
 			{
-				`${slice(var.list_of_strings, 1, 4)}`,
-				nil,
-				true,
+				`${formatlist("<%s>", split(",", "A,B"))}`,
+				[]interface{}{"<A>", "<B>"},
+				false,
 			},
-			// Empty slice
+			// formatlist repeats scalar elements
 			{
-				`${slice(var.list_of_strings, 1, 1)}`,
-				[]interface{}{},
+				`${join(", ", formatlist("%s=%s", "x", split(",", "A,B,C")))}`,
+				"x=A, x=B, x=C",
 				false,
 			},
+			// Multiple lists are walked in parallel
 			{
-				`${slice(var.list_of_strings, 1, 2)}`,
-				[]interface{}{"b"},
+				`${join(", ", formatlist("%s=%s", split(",", "A,B,C"), split(",", "1,2,3")))}`,
+				"A=1, B=2, C=3",
 				false,
 			},
+			// Mismatched list lengths generate an error
 			{
-				`${slice(var.list_of_strings, 0, length(var.list_of_strings) - 1)}`,
-				[]interface{}{"a", "b"},
+				`${formatlist("%s=%2s", split(",", "A,B,C,D"), split(",", "1,2,3"))}`,
+				nil,
+				true,
+			},
+			// Works with lists of length 1 [GH-2240]
+			{
+				`${formatlist("%s.id", split(",", "demo-rest-elb"))}`,
+				[]interface{}{"demo-rest-elb.id"},
+				false,
+			},
+			// Works with empty lists [GH-7607]
+			{
+				`${formatlist("%s", var.emptylist)}`,
+				[]interface{}{},
 				false,
 			},
 		},
 		Vars: map[string]ast.Variable{
-			"var.list_of_strings": {
-				Type: ast.TypeList,
-				Value: []ast.Variable{
-					{
-						Type:  ast.TypeString,
-						Value: "a",
-					},
-					{
-						Type:  ast.TypeString,
-						Value: "b",
-					},
-					{
-						Type:  ast.TypeString,
-						Value: "c",
-					},
-				},
+			"var.emptylist": {
+				Type:  ast.TypeList,
+				Value: []ast.Variable{},
 			},
 		},
 	})
 }
 
-func TestInterpolateFuncSort(t *testing.T) {
+func TestInterpolateFuncIndex(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Vars: map[string]ast.Variable{
-			"var.strings": ast.Variable{
-				Type: ast.TypeList,
-				Value: []ast.Variable{
-					{Type: ast.TypeString, Value: "c"},
-					{Type: ast.TypeString, Value: "a"},
-					{Type: ast.TypeString, Value: "b"},
-				},
-			},
-			"var.notstrings": ast.Variable{
-				Type: ast.TypeList,
-				Value: []ast.Variable{
-					{Type: ast.TypeList, Value: []ast.Variable{}},
-					{Type: ast.TypeString, Value: "b"},
-				},
-			},
+			"var.list1": interfaceToVariableSwallowError([]string{"notfoo", "stillnotfoo", "bar"}),
+			"var.list2": interfaceToVariableSwallowError([]string{"foo"}),
+			"var.list3": interfaceToVariableSwallowError([]string{"foo", "spam", "bar", "eggs"}),
 		},
 		Cases: []testFunctionCase{
 			{
-				`${sort(var.strings)}`,
-				[]interface{}{"a", "b", "c"},
-				false,
-			},
-			{
-				`${sort(var.notstrings)}`,
+				`${index("test", "")}`,
 				nil,
 				true,
 			},
-		},
-	})
-}
 
-func TestInterpolateFuncSplit(t *testing.T) {
-	testFunction(t, testFunctionConfig{
-		Cases: []testFunctionCase{
 			{
-				`${split(",")}`,
+				`${index(var.list1, "foo")}`,
 				nil,
 				true,
 			},
 
 			{
-				`${split(",", "")}`,
-				[]interface{}{""},
+				`${index(var.list2, "foo")}`,
+				"0",
 				false,
 			},
 
 			{
-				`${split(",", "foo")}`,
-				[]interface{}{"foo"},
+				`${index(var.list3, "bar")}`,
+				"2",
 				false,
 			},
+		},
+	})
+}
 
+func TestInterpolateFuncJoin(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.a_list":        interfaceToVariableSwallowError([]string{"foo"}),
+			"var.a_longer_list": interfaceToVariableSwallowError([]string{"foo", "bar", "baz"}),
+			"var.list_of_lists": interfaceToVariableSwallowError([]interface{}{[]string{"foo"}, []string{"bar"}, []string{"baz"}}),
+		},
+		Cases: []testFunctionCase{
 			{
-				`${split(",", ",,,")}`,
-				[]interface{}{"", "", "", ""},
-				false,
+				`${join(",")}`,
+				nil,
+				true,
 			},
 
 			{
-				`${split(",", "foo,")}`,
-				[]interface{}{"foo", ""},
+				`${join(",", var.a_list)}`,
+				"foo",
 				false,
 			},
 
 			{
-				`${split(",", ",foo,")}`,
-				[]interface{}{"", "foo", ""},
+				`${join(".", var.a_longer_list)}`,
+				"foo.bar.baz",
 				false,
 			},
 
 			{
-				`${split(".", "foo.bar.baz")}`,
-				[]interface{}{"foo", "bar", "baz"},
-				false,
+				`${join(".", var.list_of_lists)}`,
+				nil,
+				true,
+			},
+			{
+				`${join(".", list(list("nested")))}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncJSONEncode(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"easy": ast.Variable{
+				Value: "test",
+				Type:  ast.TypeString,
+			},
+			"hard": ast.Variable{
+				Value: " foo \\ \n \t \" bar ",
+				Type:  ast.TypeString,
+			},
+			"list": interfaceToVariableSwallowError([]string{"foo", "bar\tbaz"}),
+			// XXX can't use InterfaceToVariable as it converts empty slice into empty
+			// map.
+			"emptylist": ast.Variable{
+				Value: []ast.Variable{},
+				Type:  ast.TypeList,
+			},
+			"map": interfaceToVariableSwallowError(map[string]string{
+				"foo":     "bar",
+				"ba \n z": "q\\x",
+			}),
+			"emptymap": interfaceToVariableSwallowError(map[string]string{}),
+
+			// Not yet supported (but it would be nice)
+			"nestedlist": interfaceToVariableSwallowError([][]string{{"foo"}}),
+			"nestedmap":  interfaceToVariableSwallowError(map[string][]string{"foo": {"bar"}}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${jsonencode("test")}`,
+				`"test"`,
+				false,
+			},
+			{
+				`${jsonencode(easy)}`,
+				`"test"`,
+				false,
+			},
+			{
+				`${jsonencode(hard)}`,
+				`" foo \\ \n \t \" bar "`,
+				false,
+			},
+			{
+				`${jsonencode("")}`,
+				`""`,
+				false,
+			},
+			{
+				`${jsonencode()}`,
+				nil,
+				true,
+			},
+			{
+				`${jsonencode(list)}`,
+				`["foo","bar\tbaz"]`,
+				false,
+			},
+			{
+				`${jsonencode(emptylist)}`,
+				`[]`,
+				false,
+			},
+			{
+				`${jsonencode(map)}`,
+				`{"ba \n z":"q\\x","foo":"bar"}`,
+				false,
+			},
+			{
+				`${jsonencode(emptymap)}`,
+				`{}`,
+				false,
+			},
+			{
+				`${jsonencode(nestedlist)}`,
+				nil,
+				true,
+			},
+			{
+				`${jsonencode(nestedmap)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncJSONDecode(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"object": ast.Variable{
+				Value: `{"foo":"bar","ba \n z":"q\\x"}`,
+				Type:  ast.TypeString,
+			},
+			"scalars": ast.Variable{
+				Value: `{"count":3,"ratio":0.5,"enabled":true}`,
+				Type:  ast.TypeString,
+			},
+			"empty":     ast.Variable{Value: `{}`, Type: ast.TypeString},
+			"list":      ast.Variable{Value: `["foo"]`, Type: ast.TypeString},
+			"nested":    ast.Variable{Value: `{"foo":{"bar":"baz"}}`, Type: ast.TypeString},
+			"null":      ast.Variable{Value: `null`, Type: ast.TypeString},
+			"malformed": ast.Variable{Value: `{"foo":`, Type: ast.TypeString},
+			"map": interfaceToVariableSwallowError(map[string]string{
+				"foo":     "bar",
+				"ba \n z": "q\\x",
+			}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${jsondecode(object)}`,
+				map[string]interface{}{
+					"foo":     "bar",
+					"ba \n z": "q\\x",
+				},
+				false,
+			},
+			{
+				`${jsondecode(scalars)}`,
+				map[string]interface{}{
+					"count":   "3",
+					"ratio":   "0.5",
+					"enabled": "true",
+				},
+				false,
+			},
+			{
+				`${jsondecode(empty)}`,
+				map[string]interface{}{},
+				false,
+			},
+
+			// Round trips, keys are always encoded in sorted order
+			{
+				`${jsonencode(jsondecode(object))}`,
+				`{"ba \n z":"q\\x","foo":"bar"}`,
+				false,
+			},
+			{
+				`${jsondecode(jsonencode(map))}`,
+				map[string]interface{}{
+					"foo":     "bar",
+					"ba \n z": "q\\x",
+				},
+				false,
+			},
+
+			{
+				`${jsondecode(malformed)}`,
+				nil,
+				true,
+			},
+			{
+				`${jsondecode(list)}`,
+				nil,
+				true,
+			},
+			{
+				`${jsondecode(nested)}`,
+				nil,
+				true,
+			},
+			{
+				`${jsondecode(null)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncReplace(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Regular search and replace
+			{
+				`${replace("hello", "hel", "bel")}`,
+				"bello",
+				false,
+			},
+
+			// Search string doesn't match
+			{
+				`${replace("hello", "nope", "bel")}`,
+				"hello",
+				false,
+			},
+
+			// Regular expression
+			{
+				`${replace("hello", "/l/", "L")}`,
+				"heLLo",
+				false,
+			},
+
+			{
+				`${replace("helo", "/(l)/", "$1$1")}`,
+				"hello",
+				false,
+			},
+
+			// Numbered groups
+			{
+				`${replace("foo-bar", "/(\\w+)-(\\w+)/", "$2-$1")}`,
+				"bar-foo",
+				false,
+			},
+
+			// Named groups
+			{
+				`${replace("foo-bar", "/(?P<first>\\w+)-(?P<second>\\w+)/", "$${second}_$${first}")}`,
+				"bar_foo",
+				false,
+			},
+
+			// Braces separate a group from the text that follows it
+			{
+				`${replace("v1", "/v(\\d)/", "$${1}0")}`,
+				"10",
+				false,
+			},
+
+			// An escaped dollar sign is literal. Interpolation already
+			// unescapes $$, so the regexp escape $$ is written $$$$.
+			{
+				`${replace("10", "/(\\d+)/", "$$$$$1")}`,
+				"$10",
+				false,
+			},
+
+			// Literal mode doesn't expand groups
+			{
+				`${replace("foo", "o", "$1")}`,
+				"f$1$1",
+				false,
+			},
+
+			// Bad regexp
+			{
+				`${replace("helo", "/(l/", "$1$1")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+// TestInterpolateFuncReplace_literal checks that a literal search gives
+// the same result as the equivalent regular expression, as long as the
+// replacement has no group references.
+func TestInterpolateFuncReplace_literal(t *testing.T) {
+	cases := []struct {
+		S, Search, Replace string
+	}{
+		{"hello", "l", "L"},
+		{"a.b.c", ".", "-"},
+		{"(a)[b]{c}", "(a)", "x"},
+		{"a/b/c", "/", "\\"},
+		{"x^2 + y^2", "^2", "²"},
+		{"aaaa", "aa", "b"},
+		{"nothing here", "nope", "yes"},
+		{strings.Repeat("#!/bin/sh\necho ${1}\n", 100), "echo", "printf"},
+	}
+
+	callback := interpolationFuncReplace().Callback
+	for _, tc := range cases {
+		actual, err := callback([]interface{}{tc.S, tc.Search, tc.Replace})
+		if err != nil {
+			t.Fatalf("%q: err: %s", tc.Search, err)
+		}
+
+		expected := regexp.MustCompile(regexp.QuoteMeta(tc.Search)).
+			ReplaceAllLiteralString(tc.S, tc.Replace)
+		if actual != expected {
+			t.Fatalf("%q: bad: %q, expected %q", tc.Search, actual, expected)
+		}
+	}
+}
+
+func BenchmarkInterpolateFuncReplace(b *testing.B) {
+	s := strings.Repeat("#!/bin/sh\nexport NAME=__NAME__\necho $NAME\n", 1000)
+	callback := interpolationFuncReplace().Callback
+
+	b.Run("literal", func(b *testing.B) {
+		args := []interface{}{s, "__NAME__", "web"}
+		for i := 0; i < b.N; i++ {
+			if _, err := callback(args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("regexp", func(b *testing.B) {
+		args := []interface{}{s, "/__NAME__/", "web"}
+		for i := 0; i < b.N; i++ {
+			if _, err := callback(args); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestInterpolateFuncReverse(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.list": interfaceToVariableSwallowError([]string{"a", "b", "c"}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${reverse(var.list)}`,
+				[]interface{}{"c", "b", "a"},
+				false,
+			},
+
+			// The input list must not be modified
+			{
+				`${var.list}`,
+				[]interface{}{"a", "b", "c"},
+				false,
+			},
+
+			{
+				`${reverse(list("a"))}`,
+				[]interface{}{"a"},
+				false,
+			},
+
+			{
+				`${reverse(list())}`,
+				[]interface{}{},
+				false,
+			},
+
+			// Not a list
+			{
+				`${reverse("foo")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncLength(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Raw strings
+			{
+				`${length("")}`,
+				"0",
+				false,
+			},
+			{
+				`${length("a")}`,
+				"1",
+				false,
+			},
+			{
+				`${length(" ")}`,
+				"1",
+				false,
+			},
+			{
+				`${length(" a ,")}`,
+				"4",
+				false,
+			},
+			{
+				`${length("aaa")}`,
+				"3",
+				false,
+			},
+
+			// Lists
+			{
+				`${length(split(",", "a"))}`,
+				"1",
+				false,
+			},
+			{
+				`${length(split(",", "foo,"))}`,
+				"2",
+				false,
+			},
+			{
+				`${length(split(",", ",foo,"))}`,
+				"3",
+				false,
+			},
+			{
+				`${length(split(",", "foo,bar"))}`,
+				"2",
+				false,
+			},
+			{
+				`${length(split(".", "one.two.three.four.five"))}`,
+				"5",
+				false,
+			},
+			// Want length 0 if we split an empty string then compact
+			{
+				`${length(compact(split(",", "")))}`,
+				"0",
+				false,
+			},
+			// Works for maps
+			{
+				`${length(map("k", "v"))}`,
+				"1",
+				false,
+			},
+			{
+				`${length(map("k1", "v1", "k2", "v2"))}`,
+				"2",
+				false,
+			},
+
+			{
+				`${length(var.empty_list)}`,
+				"0",
+				false,
+			},
+			{
+				`${length(var.str)}`,
+				"3",
+				false,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.empty_list": {
+				Type:  ast.TypeList,
+				Value: []ast.Variable{},
+			},
+			"var.str": {
+				Type:  ast.TypeString,
+				Value: "foo",
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSignum(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${signum()}`,
+				nil,
+				true,
+			},
+
+			{
+				`${signum("")}`,
+				nil,
+				true,
+			},
+
+			{
+				`${signum(0)}`,
+				"0",
+				false,
+			},
+
+			{
+				`${signum(15)}`,
+				"1",
+				false,
+			},
+
+			{
+				`${signum(-29)}`,
+				"-1",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSlice(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Negative from index
+			{
+				`${slice(list("a"), -1, 0)}`,
+				nil,
+				true,
+			},
+			// From index > to index
+			{
+				`${slice(list("a", "b", "c"), 2, 1)}`,
+				nil,
+				true,
+			},
+			// To index too large
+			{
+				`${slice(var.list_of_strings, 1, 4)}`,
+				nil,
+				true,
+			},
+			// Empty slice
+			{
+				`${slice(var.list_of_strings, 1, 1)}`,
+				[]interface{}{},
+				false,
+			},
+			{
+				`${slice(var.list_of_strings, 1, 2)}`,
+				[]interface{}{"b"},
+				false,
+			},
+			{
+				`${slice(var.list_of_strings, 0, length(var.list_of_strings) - 1)}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+		},
+		Vars: map[string]ast.Variable{
+			"var.list_of_strings": {
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{
+						Type:  ast.TypeString,
+						Value: "a",
+					},
+					{
+						Type:  ast.TypeString,
+						Value: "b",
+					},
+					{
+						Type:  ast.TypeString,
+						Value: "c",
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSort(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.strings": ast.Variable{
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeString, Value: "c"},
+					{Type: ast.TypeString, Value: "a"},
+					{Type: ast.TypeString, Value: "b"},
+				},
+			},
+			"var.notstrings": ast.Variable{
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeList, Value: []ast.Variable{}},
+					{Type: ast.TypeString, Value: "b"},
+				},
+			},
+		},
+		Cases: []testFunctionCase{
+			{
+				`${sort(var.strings)}`,
+				[]interface{}{"a", "b", "c"},
+				false,
+			},
+			{
+				`${sort(var.notstrings)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSetUnion(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.empty": ast.Variable{
+				Type:  ast.TypeList,
+				Value: []ast.Variable{},
+			},
+			"var.notstrings": ast.Variable{
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeList, Value: []ast.Variable{}},
+				},
+			},
+		},
+		Cases: []testFunctionCase{
+			// Overlapping
+			{
+				`${setunion(list("c", "a", "b"), list("b", "d", "a"))}`,
+				[]interface{}{"a", "b", "c", "d"},
+				false,
+			},
+
+			// Disjoint
+			{
+				`${setunion(list("b", "a"), list("d"), list("c"))}`,
+				[]interface{}{"a", "b", "c", "d"},
+				false,
+			},
+
+			// Duplicates within a list are removed
+			{
+				`${setunion(list("a", "a"))}`,
+				[]interface{}{"a"},
+				false,
+			},
+
+			// Empty
+			{
+				`${setunion(var.empty, list("b", "a"))}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+			{
+				`${setunion(var.empty, var.empty)}`,
+				[]interface{}{},
+				false,
+			},
+
+			{
+				`${setunion(var.notstrings, list("a"))}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSetIntersection(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.empty": ast.Variable{
+				Type:  ast.TypeList,
+				Value: []ast.Variable{},
+			},
+			"var.notstrings": ast.Variable{
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeList, Value: []ast.Variable{}},
+				},
+			},
+		},
+		Cases: []testFunctionCase{
+			// Overlapping
+			{
+				`${setintersection(list("c", "a", "b"), list("b", "d", "a", "a"))}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+			{
+				`${setintersection(list("a", "b", "c"), list("b", "c"), list("c", "b", "d"))}`,
+				[]interface{}{"b", "c"},
+				false,
+			},
+
+			// Disjoint
+			{
+				`${setintersection(list("a", "b"), list("c", "d"))}`,
+				[]interface{}{},
+				false,
+			},
+
+			// A single list is its own set
+			{
+				`${setintersection(list("b", "a", "b"))}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+
+			// Empty
+			{
+				`${setintersection(var.empty, list("a"))}`,
+				[]interface{}{},
+				false,
+			},
+
+			{
+				`${setintersection(list("a"), var.notstrings)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSetSubtract(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.empty": ast.Variable{
+				Type:  ast.TypeList,
+				Value: []ast.Variable{},
+			},
+			"var.notstrings": ast.Variable{
+				Type: ast.TypeList,
+				Value: []ast.Variable{
+					{Type: ast.TypeList, Value: []ast.Variable{}},
+				},
+			},
+		},
+		Cases: []testFunctionCase{
+			// Overlapping
+			{
+				`${setsubtract(list("c", "a", "b", "a"), list("b", "d"))}`,
+				[]interface{}{"a", "c"},
+				false,
+			},
+
+			// Disjoint
+			{
+				`${setsubtract(list("b", "a"), list("c", "d"))}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+
+			// Empty
+			{
+				`${setsubtract(list("b", "a"), var.empty)}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+			{
+				`${setsubtract(var.empty, list("a"))}`,
+				[]interface{}{},
+				false,
+			},
+
+			{
+				`${setsubtract(var.notstrings, list("a"))}`,
+				nil,
+				true,
+			},
+
+			{
+				`${setsubtract(list("a"))}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSplit(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${split(",")}`,
+				nil,
+				true,
+			},
+
+			{
+				`${split(",", "")}`,
+				[]interface{}{},
+				false,
 			},
-		},
-	})
-}
+
+			{
+				`${length(split(",", ""))}`,
+				"0",
+				false,
+			},
+
+			{
+				`${split(",", "foo")}`,
+				[]interface{}{"foo"},
+				false,
+			},
+
+			{
+				`${split(",", ",,,")}`,
+				[]interface{}{"", "", "", ""},
+				false,
+			},
+
+			{
+				`${split(",", "foo,")}`,
+				[]interface{}{"foo", ""},
+				false,
+			},
+
+			{
+				`${split(",", ",foo,")}`,
+				[]interface{}{"", "foo", ""},
+				false,
+			},
+
+			{
+				`${split(".", "foo.bar.baz")}`,
+				[]interface{}{"foo", "bar", "baz"},
+				false,
+			},
+
+			// Multi-character separators
+			{
+				`${split("::", "foo::bar")}`,
+				[]interface{}{"foo", "bar"},
+				false,
+			},
+
+			{
+				`${split("::", "::foo")}`,
+				[]interface{}{"", "foo"},
+				false,
+			},
+
+			{
+				`${split("::", "foo::")}`,
+				[]interface{}{"foo", ""},
+				false,
+			},
+
+			{
+				`${split("::", "foo::::bar")}`,
+				[]interface{}{"foo", "", "bar"},
+				false,
+			},
+
+			{
+				`${split("::", "::")}`,
+				[]interface{}{"", ""},
+				false,
+			},
+
+			{
+				`${split("::", "foo:bar")}`,
+				[]interface{}{"foo:bar"},
+				false,
+			},
+
+			{
+				`${join("::", split("::", "::foo::::bar::"))}`,
+				"::foo::::bar::",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncSplitGet(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.index": ast.Variable{
+				Value: "2",
+				Type:  ast.TypeString,
+			},
+		},
+		Cases: []testFunctionCase{
+			{
+				`${splitget(":", "host:8080", 1)}`,
+				"8080",
+				false,
+			},
+
+			{
+				`${splitget(":", "host:8080", 0)}`,
+				"host",
+				false,
+			},
+
+			// Empty elements are kept, like split
+			{
+				`${splitget(",", "a,,c", 1)}`,
+				"",
+				false,
+			},
+
+			// The separator is matched as a whole
+			{
+				`${splitget("::", "a::b:c", 1)}`,
+				"b:c",
+				false,
+			},
+
+			// No separator gives the whole string
+			{
+				`${splitget(",", "foo", 0)}`,
+				"foo",
+				false,
+			},
+
+			{
+				`${splitget(".", "a.b.c", var.index)}`,
+				"c",
+				false,
+			},
+
+			// Out of range doesn't wrap around
+			{
+				`${splitget(":", "host:8080", 2)}`,
+				nil,
+				true,
+			},
+
+			{
+				`${splitget(":", "host:8080", -1)}`,
+				nil,
+				true,
+			},
+
+			// An empty string has no elements
+			{
+				`${splitget(",", "", 0)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
 
 func TestInterpolateFuncLookup(t *testing.T) {
 	testFunction(t, testFunctionConfig{
@@ -1498,54 +3158,240 @@ func TestInterpolateFuncLookup(t *testing.T) {
 					},
 				},
 			},
+			"var.nested": interfaceToVariableSwallowError(map[string]interface{}{
+				"a": map[string]interface{}{
+					"b": map[string]interface{}{
+						"c": "deep",
+					},
+					"x": "shallow",
+				},
+				"t2.micro": "dotted",
+			}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${lookup(var.foo, "bar")}`,
+				"baz",
+				false,
+			},
+
+			// Nested key paths
+			{
+				`${lookup(var.nested, "a.b.c")}`,
+				"deep",
+				false,
+			},
+			{
+				`${lookup(var.nested, "a.x", "default")}`,
+				"shallow",
+				false,
+			},
+
+			// Missing intermediate key
+			{
+				`${lookup(var.nested, "a.nope.c", "default")}`,
+				"default",
+				false,
+			},
+			{
+				`${lookup(var.nested, "a.nope.c")}`,
+				nil,
+				true,
+			},
+
+			// Path through a non-map value
+			{
+				`${lookup(var.nested, "a.x.y", "default")}`,
+				"default",
+				false,
+			},
+
+			// Path ending at a map
+			{
+				`${lookup(var.nested, "a.b")}`,
+				nil,
+				true,
+			},
+
+			// Keys containing dots are still found as-is
+			{
+				`${lookup(var.nested, "t2.micro")}`,
+				"dotted",
+				false,
+			},
+
+			// Invalid key
+			{
+				`${lookup(var.foo, "baz")}`,
+				nil,
+				true,
+			},
+
+			// Supplied default with valid key
+			{
+				`${lookup(var.foo, "bar", "")}`,
+				"baz",
+				false,
+			},
+
+			// Supplied default with invalid key
+			{
+				`${lookup(var.foo, "zip", "")}`,
+				"",
+				false,
+			},
+
+			// Too many args
+			{
+				`${lookup(var.foo, "bar", "", "abc")}`,
+				nil,
+				true,
+			},
+
+			// Cannot lookup into map of lists
+			{
+				`${lookup(var.map_of_lists, "bar")}`,
+				nil,
+				true,
+			},
+
+			// The default must be a string, even for a map of lists or a
+			// map of maps, because lookup always returns a string. See
+			// lookuplist and lookupmap for those.
+			{
+				`${lookup(var.map_of_lists, "zip", list("a"))}`,
+				nil,
+				true,
+			},
+			{
+				`${lookup(var.nested, "zip", map("a", "b"))}`,
+				nil,
+				true,
+			},
+
+			// Non-empty default
+			{
+				`${lookup(var.foo, "zap", "xyz")}`,
+				"xyz",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncLookupList(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.map_of_lists": interfaceToVariableSwallowError(map[string]interface{}{
+				"bar": []interface{}{"baz"},
+			}),
+			"var.flat": interfaceToVariableSwallowError(map[string]interface{}{
+				"bar": "baz",
+			}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${lookuplist(var.map_of_lists, "bar")}`,
+				[]interface{}{"baz"},
+				false,
+			},
+
+			// The default is returned as-is
+			{
+				`${lookuplist(var.map_of_lists, "zip", list("a", "b"))}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+			{
+				`${lookuplist(var.map_of_lists, "zip", list())}`,
+				[]interface{}{},
+				false,
+			},
+
+			// Missing key without a default
+			{
+				`${lookuplist(var.map_of_lists, "zip")}`,
+				nil,
+				true,
+			},
+
+			// The default must be a list
+			{
+				`${lookuplist(var.map_of_lists, "zip", "a")}`,
+				nil,
+				true,
+			},
+
+			// The values must be lists
+			{
+				`${lookuplist(var.flat, "bar")}`,
+				nil,
+				true,
+			},
+
+			// Too many arguments
+			{
+				`${lookuplist(var.map_of_lists, "zip", list("a"), list("b"))}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncLookupMap(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.map_of_maps": interfaceToVariableSwallowError(map[string]interface{}{
+				"bar": map[string]interface{}{"a": "b"},
+				"nested": map[string]interface{}{
+					"inner": map[string]interface{}{"c": "d"},
+				},
+			}),
+			"var.flat": interfaceToVariableSwallowError(map[string]interface{}{
+				"bar": "baz",
+			}),
 		},
 		Cases: []testFunctionCase{
 			{
-				`${lookup(var.foo, "bar")}`,
-				"baz",
+				`${lookupmap(var.map_of_maps, "bar")}`,
+				map[string]interface{}{"a": "b"},
 				false,
 			},
 
-			// Invalid key
-			{
-				`${lookup(var.foo, "baz")}`,
-				nil,
-				true,
-			},
-
-			// Supplied default with valid key
+			// Nested key paths
 			{
-				`${lookup(var.foo, "bar", "")}`,
-				"baz",
+				`${lookupmap(var.map_of_maps, "nested.inner")}`,
+				map[string]interface{}{"c": "d"},
 				false,
 			},
 
-			// Supplied default with invalid key
+			// The default is returned as-is
 			{
-				`${lookup(var.foo, "zip", "")}`,
-				"",
+				`${lookupmap(var.map_of_maps, "zip", map("x", "y"))}`,
+				map[string]interface{}{"x": "y"},
 				false,
 			},
 
-			// Too many args
+			// Missing key without a default
 			{
-				`${lookup(var.foo, "bar", "", "abc")}`,
+				`${lookupmap(var.map_of_maps, "zip")}`,
 				nil,
 				true,
 			},
 
-			// Cannot lookup into map of lists
+			// The default must be a map
 			{
-				`${lookup(var.map_of_lists, "bar")}`,
+				`${lookupmap(var.map_of_maps, "zip", list("a"))}`,
 				nil,
 				true,
 			},
 
-			// Non-empty default
+			// The values must be maps
 			{
-				`${lookup(var.foo, "zap", "xyz")}`,
-				"xyz",
-				false,
+				`${lookupmap(var.flat, "bar")}`,
+				nil,
+				true,
 			},
 		},
 	})
@@ -1696,113 +3542,397 @@ func TestInterpolateFuncValues(t *testing.T) {
 				true,
 			},
 
-			// Not a map
+			// Not a map
+			{
+				`${values(var.str)}`,
+				nil,
+				true,
+			},
+
+			// Map of lists
+			{
+				`${values(map("one", list()))}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func interfaceToVariableSwallowError(input interface{}) ast.Variable {
+	variable, _ := hil.InterfaceToVariable(input)
+	return variable
+}
+
+// Functions given a computed value, or a list or map containing one,
+// must produce a computed result rather than an error so that plans can
+// complete.
+func TestInterpolateFuncs_unknown(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.unknown": ast.Variable{
+				Type:  ast.TypeUnknown,
+				Value: UnknownVariableValue,
+			},
+			"var.partial_list": interfaceToVariableSwallowError(
+				[]interface{}{"foo", UnknownVariableValue}),
+			"var.partial_map": interfaceToVariableSwallowError(
+				map[string]interface{}{"foo": "bar", "baz": UnknownVariableValue}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${element(var.unknown, 0)}`,
+				UnknownVariableValue,
+				false,
+			},
+			{
+				`${element(var.partial_list, 0)}`,
+				UnknownVariableValue,
+				false,
+			},
+			{
+				`${length(var.unknown)}`,
+				UnknownVariableValue,
+				false,
+			},
+			{
+				`${length(var.partial_list)}`,
+				UnknownVariableValue,
+				false,
+			},
+			{
+				`${lookup(var.unknown, "foo")}`,
+				UnknownVariableValue,
+				false,
+			},
+			{
+				`${lookup(var.partial_map, "foo")}`,
+				UnknownVariableValue,
+				false,
+			},
+
+			// Nested calls stay computed
+			{
+				`${upper(element(split(",", var.unknown), 0))}`,
+				UnknownVariableValue,
+				false,
+			},
+
+			// Errors that don't depend on the value are still reported
+			{
+				`${element(var.unknown, 0, 1)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncElement(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.a_list":        interfaceToVariableSwallowError([]string{"foo", "baz"}),
+			"var.a_short_list":  interfaceToVariableSwallowError([]string{"foo"}),
+			"var.empty_list":    interfaceToVariableSwallowError([]interface{}{}),
+			"var.a_nested_list": interfaceToVariableSwallowError([]interface{}{[]string{"foo"}, []string{"baz"}}),
+			"var.a_map": interfaceToVariableSwallowError(map[string]interface{}{
+				"c": "three",
+				"a": "one",
+				"b": "two",
+			}),
+			"var.empty_map": interfaceToVariableSwallowError(map[string]interface{}{}),
+			"var.a_nested_map": interfaceToVariableSwallowError(map[string]interface{}{
+				"a": []string{"foo"},
+			}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${element(var.a_list, "1")}`,
+				"baz",
+				false,
+			},
+
+			{
+				`${element(var.a_short_list, "0")}`,
+				"foo",
+				false,
+			},
+
+			// Invalid index should wrap vs. out-of-bounds
+			{
+				`${element(var.a_list, "2")}`,
+				"foo",
+				false,
+			},
+
+			// Negative number should fail
+			{
+				`${element(var.a_short_list, "-1")}`,
+				nil,
+				true,
+			},
+
+			// Empty list should fail
+			{
+				`${element(var.empty_list, 0)}`,
+				nil,
+				true,
+			},
+
+			// Too many args
+			{
+				`${element(var.a_list, "0", "2")}`,
+				nil,
+				true,
+			},
+
+			// Only works on single-level lists
+			{
+				`${element(var.a_nested_list, "0")}`,
+				nil,
+				true,
+			},
+
+			// Maps are indexed by their sorted keys
+			{
+				`${element(var.a_map, "0")}`,
+				"one",
+				false,
+			},
+
+			{
+				`${element(var.a_map, "2")}`,
+				"three",
+				false,
+			},
+
+			// Wraps around like lists
+			{
+				`${element(var.a_map, "3")}`,
+				"one",
+				false,
+			},
+
+			{
+				`${element(var.a_map, "7")}`,
+				"two",
+				false,
+			},
+
+			{
+				`${element(var.a_map, "-1")}`,
+				nil,
+				true,
+			},
+
+			{
+				`${element(var.empty_map, "0")}`,
+				nil,
+				true,
+			},
+
+			{
+				`${element(var.a_nested_map, "0")}`,
+				nil,
+				true,
+			},
+
+			// Not a collection
+			{
+				`${element("foo", "0")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncBase64Encode(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Regular base64 encoding
+			{
+				`${base64encode("abc123!?$*&()'-=@~")}`,
+				"YWJjMTIzIT8kKiYoKSctPUB+",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncBase64Decode(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			// Regular base64 decoding
+			{
+				`${base64decode("YWJjMTIzIT8kKiYoKSctPUB+")}`,
+				"abc123!?$*&()'-=@~",
+				false,
+			},
+
+			// Invalid base64 data decoding
+			{
+				`${base64decode("this-is-an-invalid-base64-data")}`,
+				nil,
+				true,
+			},
+
+			// Truncated base64 data
+			{
+				`${base64decode("YWJjMTIz")}`,
+				"abc123",
+				false,
+			},
+			{
+				`${base64decode("YWJjMTI")}`,
+				nil,
+				true,
+			},
+
+			// Binary data that isn't UTF-8 is returned as it is
+			{
+				`${base64decode("aOlsbG8=")}`,
+				"h\xe9llo",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncBase64URLEncode(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${base64urlencode("<<??>>")}`,
+				"PDw_Pz4-",
+				false,
+			},
+
+			// No padding
+			{
+				`${base64urlencode("<<???>>")}`,
+				"PDw_Pz8-Pg",
+				false,
+			},
+
+			// Differs from the standard alphabet
+			{
+				`${base64encode("<<??>>")}`,
+				"PDw/Pz4+",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncBase64URLDecode(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${base64urldecode("PDw_Pz4-")}`,
+				"<<??>>",
+				false,
+			},
+
+			// Padding is optional
+			{
+				`${base64urldecode("PDw_Pz8-Pg")}`,
+				"<<???>>",
+				false,
+			},
+			{
+				`${base64urldecode("PDw_Pz8-Pg==")}`,
+				"<<???>>",
+				false,
+			},
+
+			// Round trip
+			{
+				`${base64urldecode(base64urlencode("a+b/c?d>e"))}`,
+				"a+b/c?d>e",
+				false,
+			},
+
+			// The standard alphabet isn't accepted
 			{
-				`${values(var.str)}`,
+				`${base64urldecode("PDw/Pz4+")}`,
 				nil,
 				true,
 			},
 
-			// Map of lists
+			// Binary data that isn't UTF-8 is returned as it is
 			{
-				`${values(map("one", list()))}`,
-				nil,
-				true,
+				`${base64urldecode("aOlsbG8")}`,
+				"h\xe9llo",
+				false,
 			},
 		},
 	})
 }
 
-func interfaceToVariableSwallowError(input interface{}) ast.Variable {
-	variable, _ := hil.InterfaceToVariable(input)
-	return variable
-}
-
-func TestInterpolateFuncElement(t *testing.T) {
+func TestInterpolateFuncTextDecodeBase64(t *testing.T) {
 	testFunction(t, testFunctionConfig{
-		Vars: map[string]ast.Variable{
-			"var.a_list":        interfaceToVariableSwallowError([]string{"foo", "baz"}),
-			"var.a_short_list":  interfaceToVariableSwallowError([]string{"foo"}),
-			"var.empty_list":    interfaceToVariableSwallowError([]interface{}{}),
-			"var.a_nested_list": interfaceToVariableSwallowError([]interface{}{[]string{"foo"}, []string{"baz"}}),
-		},
 		Cases: []testFunctionCase{
 			{
-				`${element(var.a_list, "1")}`,
-				"baz",
+				`${textdecodebase64("aMOpbGxv", "UTF-8")}`,
+				"h\u00e9llo",
 				false,
 			},
 
 			{
-				`${element(var.a_short_list, "0")}`,
-				"foo",
+				`${textdecodebase64("aOlsbG8=", "ISO-8859-1")}`,
+				"h\u00e9llo",
 				false,
 			},
 
-			// Invalid index should wrap vs. out-of-bounds
 			{
-				`${element(var.a_list, "2")}`,
-				"foo",
+				`${textdecodebase64("gDUgliBjYWbp", "windows-1252")}`,
+				"\u20ac5 \u2013 caf\u00e9",
 				false,
 			},
 
-			// Negative number should fail
 			{
-				`${element(var.a_short_list, "-1")}`,
-				nil,
-				true,
+				`${textdecodebase64("aADpAGwAbABvAA==", "UTF-16LE")}`,
+				"h\u00e9llo",
+				false,
 			},
 
-			// Empty list should fail
 			{
-				`${element(var.empty_list, 0)}`,
-				nil,
-				true,
+				`${textdecodebase64("AGgA6QBsAGwAbw==", "utf-16be")}`,
+				"h\u00e9llo",
+				false,
 			},
 
-			// Too many args
 			{
-				`${element(var.a_list, "0", "2")}`,
-				nil,
-				true,
+				`${textdecodebase64("aGVsbG8=", "US-ASCII")}`,
+				"hello",
+				false,
 			},
 
-			// Only works on single-level lists
+			// Not ASCII
 			{
-				`${element(var.a_nested_list, "0")}`,
+				`${textdecodebase64("aOlsbG8=", "US-ASCII")}`,
 				nil,
 				true,
 			},
-		},
-	})
-}
 
-func TestInterpolateFuncBase64Encode(t *testing.T) {
-	testFunction(t, testFunctionConfig{
-		Cases: []testFunctionCase{
-			// Regular base64 encoding
+			// Not UTF-8
 			{
-				`${base64encode("abc123!?$*&()'-=@~")}`,
-				"YWJjMTIzIT8kKiYoKSctPUB+",
-				false,
+				`${textdecodebase64("aOlsbG8=", "UTF-8")}`,
+				nil,
+				true,
 			},
-		},
-	})
-}
 
-func TestInterpolateFuncBase64Decode(t *testing.T) {
-	testFunction(t, testFunctionConfig{
-		Cases: []testFunctionCase{
-			// Regular base64 decoding
+			// Invalid base64
 			{
-				`${base64decode("YWJjMTIzIT8kKiYoKSctPUB+")}`,
-				"abc123!?$*&()'-=@~",
-				false,
+				`${textdecodebase64("this-is-an-invalid-base64-data", "UTF-8")}`,
+				nil,
+				true,
 			},
 
-			// Invalid base64 data decoding
+			// Unknown encoding
 			{
-				`${base64decode("this-is-an-invalid-base64-data")}`,
+				`${textdecodebase64("aGVsbG8=", "EBCDIC")}`,
 				nil,
 				true,
 			},
@@ -1882,6 +4012,144 @@ func TestInterpolateFuncSha256(t *testing.T) {
 	})
 }
 
+func TestInterpolateFuncSha512(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{ // hexadecimal representation of sha512 sum
+				`${sha512("test")}`,
+				"ee26b0dd4af7e749aa1a8ee3c10ae9923f618980772e473f8819a5d4940e0db27ac185f8a0e1d5f84f88bc887fd67b143732c304cc5fa9ad8e6f57f50028a8ff",
+				false,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncToList(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.list": interfaceToVariableSwallowError([]string{"b", "a", "b"}),
+			"var.map":  interfaceToVariableSwallowError(map[string]string{"foo": "bar"}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${tolist(var.list)}`,
+				[]interface{}{"b", "a", "b"},
+				false,
+			},
+
+			// A set converts back to a list
+			{
+				`${tolist(toset(var.list))}`,
+				[]interface{}{"a", "b"},
+				false,
+			},
+
+			// Primitives are wrapped
+			{
+				`${tolist("foo")}`,
+				[]interface{}{"foo"},
+				false,
+			},
+
+			{
+				`${tolist(42)}`,
+				[]interface{}{"42"},
+				false,
+			},
+
+			{
+				`${tolist(var.map)}`,
+				nil,
+				true,
+			},
+
+			{
+				`${tolist()}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncToSet(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.list":        interfaceToVariableSwallowError([]string{"b", "a", "c", "a", "b"}),
+			"var.empty_list":  interfaceToVariableSwallowError([]interface{}{}),
+			"var.nested_list": interfaceToVariableSwallowError([]interface{}{[]string{"a"}}),
+			"var.map":         interfaceToVariableSwallowError(map[string]string{"foo": "bar"}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${toset(var.list)}`,
+				[]interface{}{"a", "b", "c"},
+				false,
+			},
+
+			{
+				`${toset(var.empty_list)}`,
+				[]interface{}{},
+				false,
+			},
+
+			{
+				`${toset("foo")}`,
+				[]interface{}{"foo"},
+				false,
+			},
+
+			{
+				`${toset(var.nested_list)}`,
+				nil,
+				true,
+			},
+
+			{
+				`${toset(var.map)}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
+func TestInterpolateFuncToMap(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Vars: map[string]ast.Variable{
+			"var.map":        interfaceToVariableSwallowError(map[string]string{"foo": "bar"}),
+			"var.list":       interfaceToVariableSwallowError([]string{"foo"}),
+			"var.empty_list": interfaceToVariableSwallowError([]interface{}{}),
+		},
+		Cases: []testFunctionCase{
+			{
+				`${tomap(var.map)}`,
+				map[string]interface{}{"foo": "bar"},
+				false,
+			},
+
+			{
+				`${tomap(var.empty_list)}`,
+				map[string]interface{}{},
+				false,
+			},
+
+			{
+				`${tomap(var.list)}`,
+				nil,
+				true,
+			},
+
+			// A scalar can't be a map
+			{
+				`${tomap("foo")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
 func TestInterpolateFuncTitle(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -1912,6 +4180,61 @@ func TestInterpolateFuncTitle(t *testing.T) {
 	})
 }
 
+func TestInterpolateFuncTrim(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${trim("?!hello?!", "!?")}`,
+				"hello",
+				false,
+			},
+			{
+				`${trimleft("?!hello?!", "!?")}`,
+				"hello?!",
+				false,
+			},
+			{
+				`${trimright("?!hello?!", "!?")}`,
+				"?!hello",
+				false,
+			},
+
+			// an empty cutset leaves the string as is
+			{
+				`${trim(" hello ", "")}`,
+				" hello ",
+				false,
+			},
+
+			// characters in the middle are kept
+			{
+				`${trim("--a-b--", "-")}`,
+				"a-b",
+				false,
+			},
+
+			// unicode characters
+			{
+				`${trim("«héllo»", "«»")}`,
+				"héllo",
+				false,
+			},
+			{
+				`${trimright("héllo…", "…")}`,
+				"héllo",
+				false,
+			},
+
+			// a cutset is required
+			{
+				`${trim("hello")}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
+
 func TestInterpolateFuncTrimSpace(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -1941,6 +4264,23 @@ func TestInterpolateFuncBase64Sha256(t *testing.T) {
 	})
 }
 
+func TestInterpolateFuncBase64Sha512(t *testing.T) {
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${base64sha512("test")}`,
+				"7iaw3Ur350mqGo7jwQrpkj9hiYB3Lkc/iBml1JQODbJ6wYX4oOHV+E+IvIh/1nsUNzLDBMxfqa2Ob1f1ACio/w==",
+				false,
+			},
+			{ // This will differ because we're base64-encoding hex represantiation, not raw bytes
+				`${base64encode(sha512("test"))}`,
+				"ZWUyNmIwZGQ0YWY3ZTc0OWFhMWE4ZWUzYzEwYWU5OTIzZjYxODk4MDc3MmU0NzNmODgxOWE1ZDQ5NDBlMGRiMjdhYzE4NWY4YTBlMWQ1Zjg0Zjg4YmM4ODdmZDY3YjE0MzczMmMzMDRjYzVmYTlhZDhlNmY1N2Y1MDAyOGE4ZmY=",
+				false,
+			},
+		},
+	})
+}
+
 func TestInterpolateFuncMd5(t *testing.T) {
 	testFunction(t, testFunctionConfig{
 		Cases: []testFunctionCase{
@@ -2063,6 +4403,16 @@ func TestInterpolateFuncPathExpand(t *testing.T) {
 				"/",
 				false,
 			},
+			{
+				`${pathexpand("relative/file")}`,
+				"relative/file",
+				false,
+			},
+			{
+				`${pathexpand("~otheruser/file")}`,
+				nil,
+				true,
+			},
 			{
 				`${pathexpand()}`,
 				nil,
@@ -2071,3 +4421,45 @@ func TestInterpolateFuncPathExpand(t *testing.T) {
 		},
 	})
 }
+
+func TestInterpolateFuncAbsPath(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// An absolute path that works on every platform. It is written with
+	// forward slashes so that it can be used inside a string literal.
+	root := os.TempDir()
+	rootSlash := filepath.ToSlash(root)
+
+	testFunction(t, testFunctionConfig{
+		Cases: []testFunctionCase{
+			{
+				`${abspath("test-file")}`,
+				filepath.Join(wd, "test-file"),
+				false,
+			},
+			{
+				`${abspath("./nested/../test-file")}`,
+				filepath.Join(wd, "test-file"),
+				false,
+			},
+			{
+				fmt.Sprintf(`${abspath("%s/file")}`, rootSlash),
+				filepath.Join(root, "file"),
+				false,
+			},
+			{
+				fmt.Sprintf(`${abspath("%s/nested/../file")}`, rootSlash),
+				filepath.Join(root, "file"),
+				false,
+			},
+			{
+				`${abspath()}`,
+				nil,
+				true,
+			},
+		},
+	})
+}
@@ -31,6 +31,28 @@ func TestNewInterpolatedVariable(t *testing.T) {
 			},
 			false,
 		},
+		{
+			"module.foo.1.bar",
+			&ModuleVariable{
+				Name:  "foo",
+				Field: "bar",
+				Multi: true,
+				Index: 1,
+				key:   "module.foo.1.bar",
+			},
+			false,
+		},
+		{
+			"module.foo.*.bar",
+			&ModuleVariable{
+				Name:  "foo",
+				Field: "bar",
+				Multi: true,
+				Index: -1,
+				key:   "module.foo.*.bar",
+			},
+			false,
+		},
 		{
 			"count.index",
 			&CountVariable{
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -147,6 +150,33 @@ func IsEmptyDir(root string) (bool, error) {
 	return len(fs) == 0 && len(os) == 0, nil
 }
 
+// DirChecksum returns a checksum of the Terraform configuration files
+// in the given directory. Any change to the contents or names of the files
+// that LoadDir would load changes the checksum.
+func DirChecksum(root string) (string, error) {
+	files, overrides, err := dirFiles(root)
+	if err != nil {
+		return "", err
+	}
+
+	// Sort the same way as LoadDir so we have a deterministic order
+	sort.Strings(files)
+	sort.Strings(overrides)
+
+	h := sha256.New()
+	for _, f := range append(files, overrides...) {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(f), len(data))
+		h.Write(data)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
 // Ext returns the Terraform configuration extension of the given
 // path, or a blank string if it is an invalid function.
 func ext(path string) string {
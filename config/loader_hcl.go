@@ -22,6 +22,7 @@ func (t *hclConfigurable) Config() (*Config, error) {
 		"atlas":     struct{}{},
 		"data":      struct{}{},
 		"module":    struct{}{},
+		"moved":     struct{}{},
 		"output":    struct{}{},
 		"provider":  struct{}{},
 		"resource":  struct{}{},
@@ -107,6 +108,15 @@ func (t *hclConfigurable) Config() (*Config, error) {
 		config.Resources = append(config.Resources, managedResources...)
 	}
 
+	// Record the file that modules and resources were declared in so
+	// that errors about them can point at the declaration.
+	for _, m := range config.Modules {
+		m.Pos.Filename = t.File
+	}
+	for _, r := range config.Resources {
+		r.Pos.Filename = t.File
+	}
+
 	// Build the outputs
 	if outputs := list.Filter("output"); len(outputs.Items) > 0 {
 		var err error
@@ -116,6 +126,18 @@ func (t *hclConfigurable) Config() (*Config, error) {
 		}
 	}
 
+	// Build the moved blocks
+	if moved := list.Filter("moved"); len(moved.Items) > 0 {
+		var err error
+		config.Moved, err = loadMovedHcl(moved)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range config.Moved {
+			m.Pos.Filename = t.File
+		}
+	}
+
 	// Check for invalid keys
 	for _, item := range list.Items {
 		if len(item.Keys) == 0 {
@@ -344,6 +366,8 @@ func loadModulesHcl(list *ast.ObjectList) ([]*Module, error) {
 
 		// Remove the fields we handle specially
 		delete(config, "source")
+		delete(config, "providers")
+		delete(config, "count")
 
 		rawConfig, err := NewRawConfig(config)
 		if err != nil {
@@ -365,10 +389,49 @@ func loadModulesHcl(list *ast.ObjectList) ([]*Module, error) {
 			}
 		}
 
+		// Read the providers passed to the module, if any
+		var providers map[string]string
+		if o := listVal.Filter("providers"); len(o.Items) > 0 {
+			err = hcl.DecodeObject(&providers, o.Items[0].Val)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Error parsing providers for %s: %s",
+					k,
+					err)
+			}
+		}
+
+		// Read the count, if any
+		var countConfig *RawConfig
+		if o := listVal.Filter("count"); len(o.Items) > 0 {
+			var count string
+			err = hcl.DecodeObject(&count, o.Items[0].Val)
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Error parsing count for %s: %s",
+					k,
+					err)
+			}
+
+			countConfig, err = NewRawConfig(map[string]interface{}{
+				"count": count,
+			})
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Error reading count for %s: %s",
+					k,
+					err)
+			}
+			countConfig.Key = "count"
+		}
+
 		result = append(result, &Module{
 			Name:      k,
 			Source:    source,
 			RawConfig: rawConfig,
+			RawCount:  countConfig,
+			Providers: providers,
+			Pos:       item.Pos(),
 		})
 	}
 
@@ -448,6 +511,7 @@ func loadVariablesHcl(list *ast.ObjectList) ([]*Variable, error) {
 		DeclaredType string `hcl:"type"`
 		Default      interface{}
 		Description  string
+		Sensitive    bool
 		Fields       []string `hcl:",decodedFields"`
 	}
 
@@ -472,7 +536,7 @@ func loadVariablesHcl(list *ast.ObjectList) ([]*Variable, error) {
 		}
 
 		// Check for invalid keys
-		valid := []string{"type", "default", "description"}
+		valid := []string{"type", "default", "description", "sensitive", "validation"}
 		if err := checkHCLKeys(item.Val, valid); err != nil {
 			return nil, multierror.Prefix(err, fmt.Sprintf(
 				"variable[%s]:", n))
@@ -484,6 +548,11 @@ func loadVariablesHcl(list *ast.ObjectList) ([]*Variable, error) {
 			return nil, err
 		}
 
+		validation, err := loadVariableValidationHcl(n, item)
+		if err != nil {
+			return nil, err
+		}
+
 		// Defaults turn into a slice of map[string]interface{} and
 		// we need to make sure to convert that down into the
 		// proper type for Config.
@@ -504,6 +573,8 @@ func loadVariablesHcl(list *ast.ObjectList) ([]*Variable, error) {
 			DeclaredType: hclVar.DeclaredType,
 			Default:      hclVar.Default,
 			Description:  hclVar.Description,
+			Sensitive:    hclVar.Sensitive,
+			Validation:   validation,
 		}
 		if err := newVar.ValidateTypeAndDefault(); err != nil {
 			return nil, err
@@ -515,6 +586,73 @@ func loadVariablesHcl(list *ast.ObjectList) ([]*Variable, error) {
 	return result, nil
 }
 
+// loadVariableValidationHcl loads the "validation" block of the variable
+// with the given name, if it has one.
+func loadVariableValidationHcl(n string, item *ast.ObjectItem) (*VariableValidation, error) {
+	ot, ok := item.Val.(*ast.ObjectType)
+	if !ok {
+		return nil, nil
+	}
+
+	list := ot.List.Filter("validation")
+	if len(list.Items) == 0 {
+		return nil, nil
+	}
+	if len(list.Items) > 1 {
+		return nil, fmt.Errorf(
+			"variable[%s]: only one 'validation' block is allowed", n)
+	}
+
+	valid := []string{"regex"}
+	if err := checkHCLKeys(list.Items[0].Val, valid); err != nil {
+		return nil, multierror.Prefix(err, fmt.Sprintf(
+			"variable[%s] validation:", n))
+	}
+
+	var result VariableValidation
+	if err := hcl.DecodeObject(&result, list.Items[0].Val); err != nil {
+		return nil, fmt.Errorf(
+			"Error reading validation for variable %s: %s", n, err)
+	}
+
+	return &result, nil
+}
+
+// loadMovedHcl turns the "moved" blocks in the given HCL object into
+// their configuration.
+func loadMovedHcl(list *ast.ObjectList) ([]*Moved, error) {
+	result := make([]*Moved, 0, len(list.Items))
+	for _, item := range list.Items {
+		if len(item.Keys) > 0 {
+			return nil, fmt.Errorf(
+				"moved block at %s: moved blocks don't have a name", item.Pos())
+		}
+
+		valid := []string{"from", "to"}
+		if err := checkHCLKeys(item.Val, valid); err != nil {
+			return nil, multierror.Prefix(err, fmt.Sprintf(
+				"moved block at %s:", item.Pos()))
+		}
+
+		var m struct {
+			From string
+			To   string
+		}
+		if err := hcl.DecodeObject(&m, item.Val); err != nil {
+			return nil, fmt.Errorf(
+				"Error reading moved block at %s: %s", item.Pos(), err)
+		}
+
+		result = append(result, &Moved{
+			From: m.From,
+			To:   m.To,
+			Pos:  item.Pos(),
+		})
+	}
+
+	return result, nil
+}
+
 // LoadProvidersHcl recurses into the given HCL object and turns
 // it into a mapping of provider configs.
 func loadProvidersHcl(list *ast.ObjectList) ([]*ProviderConfig, error) {
@@ -664,8 +802,10 @@ func loadDataResourcesHcl(list *ast.ObjectList) ([]*Resource, error) {
 
 		// If we have a provider, then parse it out
 		var provider string
+		var providers []string
 		if o := listVal.Filter("provider"); len(o.Items) > 0 {
-			err := hcl.DecodeObject(&provider, o.Items[0].Val)
+			var err error
+			provider, providers, err = loadResourceProviderHcl(o.Items[0].Val)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"Error reading provider for %s[%s]: %s",
@@ -682,9 +822,11 @@ func loadDataResourcesHcl(list *ast.ObjectList) ([]*Resource, error) {
 			RawCount:     countConfig,
 			RawConfig:    rawConfig,
 			Provider:     provider,
+			Providers:    providers,
 			Provisioners: []*Provisioner{},
 			DependsOn:    dependsOn,
 			Lifecycle:    ResourceLifecycle{},
+			Pos:          item.Pos(),
 		})
 	}
 
@@ -825,8 +967,10 @@ func loadManagedResourcesHcl(list *ast.ObjectList) ([]*Resource, error) {
 
 		// If we have a provider, then parse it out
 		var provider string
+		var providers []string
 		if o := listVal.Filter("provider"); len(o.Items) > 0 {
-			err := hcl.DecodeObject(&provider, o.Items[0].Val)
+			var err error
+			provider, providers, err = loadResourceProviderHcl(o.Items[0].Val)
 			if err != nil {
 				return nil, fmt.Errorf(
 					"Error reading provider for %s[%s]: %s",
@@ -847,7 +991,10 @@ func loadManagedResourcesHcl(list *ast.ObjectList) ([]*Resource, error) {
 			}
 
 			// Check for invalid keys
-			valid := []string{"create_before_destroy", "ignore_changes", "prevent_destroy"}
+			valid := []string{
+				"create_before_destroy", "ignore_changes", "prevent_destroy",
+				"replace_triggered_by",
+			}
 			if err := checkHCLKeys(o.Items[0].Val, valid); err != nil {
 				return nil, multierror.Prefix(err, fmt.Sprintf(
 					"%s[%s]:", t, k))
@@ -871,6 +1018,28 @@ func loadManagedResourcesHcl(list *ast.ObjectList) ([]*Resource, error) {
 			}
 		}
 
+		// The replacement triggers can reference other values, so they are
+		// kept as a RawConfig for interpolation.
+		var triggersConfig *RawConfig
+		if lifecycle.ReplaceTriggeredBy != nil {
+			triggers := make([]interface{}, len(lifecycle.ReplaceTriggeredBy))
+			for i, v := range lifecycle.ReplaceTriggeredBy {
+				triggers[i] = v
+			}
+
+			triggersConfig, err = NewRawConfig(map[string]interface{}{
+				"replace_triggered_by": triggers,
+			})
+			if err != nil {
+				return nil, fmt.Errorf(
+					"Error parsing replace_triggered_by for %s[%s]: %s",
+					t,
+					k,
+					err)
+			}
+			triggersConfig.Key = "replace_triggered_by"
+		}
+
 		result = append(result, &Resource{
 			Mode:         ManagedResourceMode,
 			Name:         k,
@@ -879,14 +1048,42 @@ func loadManagedResourcesHcl(list *ast.ObjectList) ([]*Resource, error) {
 			RawConfig:    rawConfig,
 			Provisioners: provisioners,
 			Provider:     provider,
+			Providers:    providers,
 			DependsOn:    dependsOn,
 			Lifecycle:    lifecycle,
+
+			RawReplaceTriggers: triggersConfig,
+			Pos:                item.Pos(),
 		})
 	}
 
 	return result, nil
 }
 
+// loadResourceProviderHcl decodes the provider of a resource. This is
+// either a single provider name or a list of names, in which case the
+// instances of the resource are spread across them by count index.
+func loadResourceProviderHcl(n ast.Node) (string, []string, error) {
+	if _, ok := n.(*ast.ListType); !ok {
+		var provider string
+		err := hcl.DecodeObject(&provider, n)
+		return provider, nil, err
+	}
+
+	var providers []string
+	if err := hcl.DecodeObject(&providers, n); err != nil {
+		return "", nil, err
+	}
+	switch len(providers) {
+	case 0:
+		return "", nil, fmt.Errorf("provider list must not be empty")
+	case 1:
+		return providers[0], nil, nil
+	}
+
+	return providers[0], providers, nil
+}
+
 func loadProvisionersHcl(list *ast.ObjectList, connInfo map[string]interface{}) ([]*Provisioner, error) {
 	list = list.Children()
 	if len(list.Items) == 0 {
@@ -42,6 +42,47 @@ func TestIsEmptyDir_noConfigs(t *testing.T) {
 	}
 }
 
+func TestDirChecksum(t *testing.T) {
+	basic, err := DirChecksum(filepath.Join(fixtureDir, "dir-basic"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if basic == "" {
+		t.Fatal("checksum should not be empty")
+	}
+
+	// The checksum must be stable
+	again, err := DirChecksum(filepath.Join(fixtureDir, "dir-basic"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if again != basic {
+		t.Fatalf("checksum changed: %s != %s", again, basic)
+	}
+
+	// A different configuration has a different checksum
+	override, err := DirChecksum(filepath.Join(fixtureDir, "dir-override"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if override == basic {
+		t.Fatal("checksums of different configurations should differ")
+	}
+
+	// Temporary files aren't part of the configuration
+	temporary, err := DirChecksum(filepath.Join(fixtureDir, "dir-temporary-files"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	empty, err := DirChecksum(filepath.Join(fixtureDir, "dir-empty"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if temporary != empty {
+		t.Fatalf("temporary files should be ignored: %s != %s", temporary, empty)
+	}
+}
+
 func TestLoadFile_badType(t *testing.T) {
 	_, err := LoadFile(filepath.Join(fixtureDir, "bad_type.tf.nope"))
 	if err == nil {
@@ -314,6 +355,59 @@ func TestLoadFileBasic_modules(t *testing.T) {
 	}
 }
 
+func TestLoadFile_moduleProviders(t *testing.T) {
+	c, err := LoadFile(filepath.Join(fixtureDir, "module-providers.tf"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(c.Modules) != 1 {
+		t.Fatalf("bad: %#v", c.Modules)
+	}
+
+	m := c.Modules[0]
+	expected := map[string]string{"aws": "aws.west"}
+	if !reflect.DeepEqual(m.Providers, expected) {
+		t.Fatalf("bad: %#v", m.Providers)
+	}
+
+	// The providers shouldn't be treated as a module variable
+	if _, ok := m.RawConfig.Raw["providers"]; ok {
+		t.Fatalf("bad: %#v", m.RawConfig.Raw)
+	}
+}
+
+func TestLoadFile_resourceProviderList(t *testing.T) {
+	c, err := LoadFile(filepath.Join(fixtureDir, "resource-provider-list.tf"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(c.Resources) != 2 {
+		t.Fatalf("bad: %#v", c.Resources)
+	}
+
+	web := c.Resources[0]
+	if web.Provider != "aws.east" {
+		t.Fatalf("bad: %#v", web.Provider)
+	}
+	expected := []string{"aws.east", "aws.west"}
+	if !reflect.DeepEqual(web.Providers, expected) {
+		t.Fatalf("bad: %#v", web.Providers)
+	}
+	for i, p := range []string{"aws.east", "aws.west", "aws.east", "aws.west"} {
+		if actual := web.ProviderForIndex(i); actual != p {
+			t.Fatalf("%d: expected %q, got %q", i, p, actual)
+		}
+	}
+
+	// A single element list is the same as a string
+	db := c.Resources[1]
+	if db.Provider != "aws.east" || db.Providers != nil {
+		t.Fatalf("bad: %#v %#v", db.Provider, db.Providers)
+	}
+}
+
 func TestLoadFile_outputDependsOn(t *testing.T) {
 	c, err := LoadFile(filepath.Join(fixtureDir, "output-depends-on.tf"))
 	if err != nil {
@@ -523,6 +617,99 @@ func TestLoadFile_variables(t *testing.T) {
 	}
 }
 
+func TestLoadFile_variableSensitive(t *testing.T) {
+	c, err := LoadFile(filepath.Join(fixtureDir, "variable-sensitive.tf"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	vars := make(map[string]*Variable)
+	for _, v := range c.Variables {
+		vars[v.Name] = v
+	}
+	if !vars["password"].Sensitive {
+		t.Fatal("password should be sensitive")
+	}
+	if vars["region"].Sensitive {
+		t.Fatal("region should not be sensitive")
+	}
+}
+
+func TestLoadFile_variableValidation(t *testing.T) {
+	for _, name := range []string{"variable-validation.tf", "variable-validation.tf.json"} {
+		c, err := LoadFile(filepath.Join(fixtureDir, name))
+		if err != nil {
+			t.Fatalf("%s: err: %s", name, err)
+		}
+
+		vars := make(map[string]*Variable)
+		for _, v := range c.Variables {
+			vars[v.Name] = v
+		}
+		if v := vars["env"].Validation; v == nil || v.Regex != "^prod-" {
+			t.Fatalf("%s: bad: %#v", name, v)
+		}
+		if v := vars["region"]; v != nil && v.Validation != nil {
+			t.Fatalf("%s: region should have no validation: %#v", name, v.Validation)
+		}
+	}
+}
+
+func TestLoadFile_variableValidationBad(t *testing.T) {
+	cases := map[string]string{
+		"variable-validation-bad-regex.tf":   "invalid validation regex",
+		"variable-validation-bad-type.tf":    "only valid for variables of type string",
+		"variable-validation-bad-default.tf": "invalid default value",
+		"variable-validation-bad-key.tf":     "pattern",
+	}
+
+	for name, expected := range cases {
+		_, err := LoadFile(filepath.Join(fixtureDir, name))
+		if err == nil {
+			t.Fatalf("%s: should error", name)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expected %q in error: %s", name, expected, err)
+		}
+	}
+}
+
+func TestLoadFile_moved(t *testing.T) {
+	for _, name := range []string{"moved.tf", "moved.tf.json"} {
+		c, err := LoadFile(filepath.Join(fixtureDir, name))
+		if err != nil {
+			t.Fatalf("%s: err: %s", name, err)
+		}
+
+		if len(c.Moved) != 2 {
+			t.Fatalf("%s: bad: %#v", name, c.Moved)
+		}
+		if m := c.Moved[0]; m.From != "aws_instance.a" || m.To != "aws_instance.b" {
+			t.Fatalf("%s: bad: %#v", name, m)
+		}
+		if m := c.Moved[1]; m.From != "module.old" || m.To != "module.new" {
+			t.Fatalf("%s: bad: %#v", name, m)
+		}
+	}
+}
+
+func TestLoadFile_movedBad(t *testing.T) {
+	cases := map[string]string{
+		"moved-bad-key.tf":  "into",
+		"moved-bad-name.tf": "don't have a name",
+	}
+
+	for name, expected := range cases {
+		_, err := LoadFile(filepath.Join(fixtureDir, name))
+		if err == nil {
+			t.Fatalf("%s: should error", name)
+		}
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("%s: expected %q in error: %s", name, expected, err)
+		}
+	}
+}
+
 func TestLoadDir_basic(t *testing.T) {
 	dir := filepath.Join(fixtureDir, "dir-basic")
 	c, err := LoadDir(dir)
@@ -794,6 +981,30 @@ func TestLoadFile_createBeforeDestroy(t *testing.T) {
 	}
 }
 
+func TestLoadFile_replaceTriggeredBy(t *testing.T) {
+	c, err := LoadFile(filepath.Join(fixtureDir, "replace-triggered-by.tf"))
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	r := c.Resources[0]
+	expected := []string{"${var.version}"}
+	if !reflect.DeepEqual(r.Lifecycle.ReplaceTriggeredBy, expected) {
+		t.Fatalf("bad: %#v", r.Lifecycle.ReplaceTriggeredBy)
+	}
+	if r.RawReplaceTriggers == nil || len(r.RawReplaceTriggers.Variables) != 1 {
+		t.Fatalf("bad: %#v", r.RawReplaceTriggers)
+	}
+	if _, ok := r.RawReplaceTriggers.Variables["var.version"]; !ok {
+		t.Fatalf("bad: %#v", r.RawReplaceTriggers.Variables)
+	}
+
+	// Resources without the argument have no triggers
+	if r := c.Resources[1]; r.RawReplaceTriggers != nil {
+		t.Fatalf("bad: %#v", r.RawReplaceTriggers)
+	}
+}
+
 func TestLoadFile_ignoreChanges(t *testing.T) {
 	c, err := LoadFile(filepath.Join(fixtureDir, "ignore-changes.tf"))
 	if err != nil {
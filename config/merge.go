@@ -133,6 +133,13 @@ func Merge(c1, c2 *Config) (*Config, error) {
 		}
 	}
 
+	// Moved blocks have no name to merge by, so they're just appended
+	if len(c1.Moved) > 0 || len(c2.Moved) > 0 {
+		c.Moved = make([]*Moved, 0, len(c1.Moved)+len(c2.Moved))
+		c.Moved = append(c.Moved, c1.Moved...)
+		c.Moved = append(c.Moved, c2.Moved...)
+	}
+
 	return c, nil
 }
 
@@ -0,0 +1,6 @@
+variable "size" {}
+
+module "bar" {
+    source = "./bar"
+    count = "${var.size}"
+}
@@ -0,0 +1,7 @@
+variable "num" {}
+
+module "foo" {
+    source = "./foo"
+    count = "${var.num}"
+    size = "${count.index + 1}"
+}
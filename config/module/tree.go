@@ -145,13 +145,17 @@ func (t *Tree) Load(s getter.Storage, mode GetMode) error {
 
 	modules := t.Modules()
 	children := make(map[string]*Tree)
+	declared := make(map[string]int)
 
 	// Go through all the modules and get the directory for them.
-	for _, m := range modules {
-		if _, ok := children[m.Name]; ok {
+	for i, m := range modules {
+		if first, ok := declared[m.Name]; ok {
 			return fmt.Errorf(
-				"module %s: duplicated. module names must be unique", m.Name)
+				"module %s: duplicated. module names must be unique%s",
+				m.Name, config.DuplicatePosStr(
+					t.config.Modules[first].Pos, t.config.Modules[i].Pos))
 		}
+		declared[m.Name] = i
 
 		// Determine the path to this child
 		path := make([]string, len(t.path), len(t.path)+1)
@@ -316,6 +320,15 @@ func (t *Tree) Validate() error {
 		requiredMap := make(map[string]struct{})
 		varMap := make(map[string]struct{})
 		for _, v := range tree.config.Variables {
+			// count is the number of instances of a module, so it can't
+			// be passed to the module as a variable.
+			if v.Name == "count" {
+				newErr.Add(fmt.Errorf(
+					"module %s: variable \"count\" is not allowed, count is "+
+						"reserved for the number of instances of a module",
+					m.Name))
+			}
+
 			varMap[v.Name] = struct{}{}
 
 			if v.Required() {
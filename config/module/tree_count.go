@@ -0,0 +1,215 @@
+package module
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hil"
+	"github.com/hashicorp/hil/ast"
+	"github.com/hashicorp/terraform/config"
+)
+
+// ExpandCount returns a copy of the tree in which every module that has a
+// count is replaced by its instances, named with config.ModuleInstanceName.
+// Each instance is a separate child with its own copy of the module's
+// configuration and children, so that the rest of Terraform can treat it
+// like any other module.
+//
+// vars are the values of the variables of the root module. The count of
+// a module can only reference variables, so counts in child modules must
+// depend only on variables that are passed down from the root or have a
+// default.
+//
+// If no module in the tree has a count, the tree itself is returned.
+func (t *Tree) ExpandCount(vars map[string]interface{}) (*Tree, error) {
+	if !t.Loaded() {
+		return nil, fmt.Errorf("tree must be loaded before calling ExpandCount")
+	}
+
+	if !t.hasCount() {
+		return t, nil
+	}
+
+	return t.expandCount(t.name, t.path, vars)
+}
+
+// hasCount returns true if any module in the tree has a count.
+func (t *Tree) hasCount() bool {
+	for _, m := range t.config.Modules {
+		if m.RawCount != nil {
+			return true
+		}
+	}
+
+	for _, c := range t.Children() {
+		if c.hasCount() {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *Tree) expandCount(
+	name string, path []string, vars map[string]interface{}) (*Tree, error) {
+	children := t.Children()
+
+	c := *t.config
+	c.Modules = make([]*config.Module, 0, len(t.config.Modules))
+	result := &Tree{
+		name:     name,
+		config:   &c,
+		children: make(map[string]*Tree),
+		path:     path,
+	}
+
+	for _, m := range t.config.Modules {
+		child, ok := children[m.Name]
+		if !ok {
+			return nil, fmt.Errorf("module %s: not loaded", m.Name)
+		}
+
+		// A module without a count is kept as it is, apart from the
+		// expansion of its own children.
+		if m.RawCount == nil {
+			expanded, err := child.expandCount(
+				m.Name, expandPath(path, m.Name),
+				moduleCallVariables(m, child.config, vars, -1))
+			if err != nil {
+				return nil, err
+			}
+
+			c.Modules = append(c.Modules, m)
+			result.children[m.Name] = expanded
+			continue
+		}
+
+		count, err := moduleCount(m, vars)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := 0; i < count; i++ {
+			instance := *m
+			instance.Name = config.ModuleInstanceName(m.Name, i)
+			instance.RawCount = nil
+
+			expanded, err := child.expandCount(
+				instance.Name, expandPath(path, instance.Name),
+				moduleCallVariables(m, child.config, vars, i))
+			if err != nil {
+				return nil, err
+			}
+
+			c.Modules = append(c.Modules, &instance)
+			result.children[instance.Name] = expanded
+		}
+	}
+
+	return result, nil
+}
+
+func expandPath(path []string, name string) []string {
+	result := make([]string, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, name)
+}
+
+// moduleCount determines the count of the module m, which is called from
+// a module with the given variable values.
+func moduleCount(m *config.Module, vars map[string]interface{}) (int, error) {
+	rc := m.RawCount.Copy()
+	vs, ok := knownVariables(rc, vars, -1)
+	if !ok {
+		return 0, fmt.Errorf(
+			"module %s: the count must be known before planning, so it can "+
+				"only use variables given directly or with a default", m.Name)
+	}
+
+	if err := rc.Interpolate(vs); err != nil {
+		return 0, fmt.Errorf("module %s: count: %s", m.Name, err)
+	}
+
+	count, err := (&config.Module{RawCount: rc}).Count()
+	if err != nil {
+		return 0, fmt.Errorf("module %s: count: %s", m.Name, err)
+	}
+
+	return count, nil
+}
+
+// moduleCallVariables returns the values of the variables of the module
+// with the given configuration that can be determined from the call m
+// of the module, made from a module with the given variable values. These
+// are the variables that are set to values built only from variables,
+// and the defaults of the others. index is the count index of the
+// instance of the module, or -1.
+func moduleCallVariables(
+	m *config.Module,
+	c *config.Config,
+	vars map[string]interface{},
+	index int) map[string]interface{} {
+	result := make(map[string]interface{})
+	for _, v := range c.Variables {
+		if v.Default != nil {
+			result[v.Name] = v.Default
+		}
+	}
+
+	for k, raw := range m.RawConfig.Raw {
+		rc, err := config.NewRawConfig(map[string]interface{}{k: raw})
+		if err != nil {
+			continue
+		}
+
+		vs, ok := knownVariables(rc, vars, index)
+		if !ok {
+			delete(result, k)
+			continue
+		}
+		if err := rc.Interpolate(vs); err != nil {
+			delete(result, k)
+			continue
+		}
+
+		result[k] = rc.Config()[k]
+	}
+
+	return result
+}
+
+// knownVariables returns the values of the variables that rc references,
+// or false if any of them isn't known.
+func knownVariables(
+	rc *config.RawConfig,
+	vars map[string]interface{},
+	index int) (map[string]ast.Variable, bool) {
+	result := make(map[string]ast.Variable)
+	for _, v := range rc.Variables {
+		switch v := v.(type) {
+		case *config.CountVariable:
+			if v.Type != config.CountValueIndex || index < 0 {
+				return nil, false
+			}
+
+			result[v.FullKey()] = ast.Variable{
+				Type:  ast.TypeInt,
+				Value: index,
+			}
+		case *config.UserVariable:
+			value, ok := vars[v.Name]
+			if !ok || v.Elem != "" {
+				return nil, false
+			}
+
+			av, err := hil.InterfaceToVariable(value)
+			if err != nil {
+				return nil, false
+			}
+			result[v.FullKey()] = av
+		default:
+			return nil, false
+		}
+	}
+
+	return result, true
+}
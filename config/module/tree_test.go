@@ -3,6 +3,7 @@ package module
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -99,6 +100,84 @@ func TestTreeLoad(t *testing.T) {
 	}
 }
 
+func TestTreeExpandCount(t *testing.T) {
+	storage := testStorage(t)
+	tree := NewTree("", testConfig(t, "expand-count"))
+	if err := tree.Load(storage, GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expanded, err := tree.ExpandCount(map[string]interface{}{"num": "2"})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Every instance gets the number of children given by its index
+	expected := map[string][]string{
+		"foo[0]": []string{"bar[0]"},
+		"foo[1]": []string{"bar[0]", "bar[1]"},
+	}
+	children := expanded.Children()
+	if len(children) != len(expected) {
+		t.Fatalf("bad: %#v", children)
+	}
+	for name, grandchildren := range expected {
+		child := expanded.Child([]string{name})
+		if child == nil {
+			t.Fatalf("%s: not found", name)
+		}
+		if !reflect.DeepEqual(child.Path(), []string{name}) {
+			t.Fatalf("%s: bad path: %#v", name, child.Path())
+		}
+		if len(child.Children()) != len(grandchildren) {
+			t.Fatalf("%s: bad: %#v", name, child.Children())
+		}
+		for _, gc := range grandchildren {
+			path := []string{name, gc}
+			if c := expanded.Child(path); c == nil || !reflect.DeepEqual(c.Path(), path) {
+				t.Fatalf("%s: bad: %#v", path, c)
+			}
+		}
+	}
+
+	// The original tree isn't modified
+	if _, ok := tree.Children()["foo"]; !ok || len(tree.Children()) != 1 {
+		t.Fatalf("bad: %#v", tree.Children())
+	}
+}
+
+func TestTreeExpandCount_unknown(t *testing.T) {
+	storage := testStorage(t)
+	tree := NewTree("", testConfig(t, "expand-count"))
+	if err := tree.Load(storage, GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, err := tree.ExpandCount(nil)
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "module foo: the count must be known") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestTreeExpandCount_none(t *testing.T) {
+	storage := testStorage(t)
+	tree := NewTree("", testConfig(t, "basic"))
+	if err := tree.Load(storage, GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expanded, err := tree.ExpandCount(nil)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if expanded != tree {
+		t.Fatal("should return the same tree")
+	}
+}
+
 func TestTreeLoad_duplicate(t *testing.T) {
 	storage := testStorage(t)
 	tree := NewTree("", testConfig(t, "dup"))
@@ -108,9 +187,17 @@ func TestTreeLoad_duplicate(t *testing.T) {
 	}
 
 	// This should get things
-	if err := tree.Load(storage, GetModeGet); err == nil {
+	err := tree.Load(storage, GetModeGet)
+	if err == nil {
 		t.Fatalf("should error")
 	}
+
+	path := filepath.Join(fixtureDir, "dup", "main.tf")
+	expected := fmt.Sprintf(
+		"(first declared at %s:1:8, declared again at %s:5:8)", path, path)
+	if !strings.Contains(err.Error(), expected) {
+		t.Fatalf("expected %q in error, got: %s", expected, err)
+	}
 }
 
 func TestTreeLoad_copyable(t *testing.T) {
@@ -403,6 +490,22 @@ func TestTreeValidate_notLoaded(t *testing.T) {
 	}
 }
 
+func TestTreeValidate_countVar(t *testing.T) {
+	tree := NewTree("", testConfig(t, "validate-count-var"))
+
+	if err := tree.Load(testStorage(t), GetModeGet); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	err := tree.Validate()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), `variable "count" is not allowed`) {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
 func TestTreeValidate_requiredChildVar(t *testing.T) {
 	tree := NewTree("", testConfig(t, "validate-required-var"))
 
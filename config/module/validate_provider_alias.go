@@ -88,6 +88,9 @@ func (t *Tree) buildProviderAliasGraph(g *dag.AcyclicGraph, parent dag.Vertex) {
 		if r.Provider != "" {
 			used[r.Provider] = struct{}{}
 		}
+		for _, p := range r.Providers {
+			used[p] = struct{}{}
+		}
 	}
 
 	// Add it to the graph
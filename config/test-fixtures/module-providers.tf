@@ -0,0 +1,12 @@
+provider "aws" {
+    alias = "west"
+}
+
+module "bar" {
+    source = "baz"
+    memory = "1G"
+
+    providers = {
+        aws = "aws.west"
+    }
+}
@@ -0,0 +1,5 @@
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+  into = "aws_instance.c"
+}
@@ -0,0 +1,4 @@
+moved "a" {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+}
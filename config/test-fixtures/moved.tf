@@ -0,0 +1,11 @@
+resource "aws_instance" "b" {}
+
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+}
+
+moved {
+  from = "module.old"
+  to   = "module.new"
+}
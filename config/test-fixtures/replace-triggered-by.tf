@@ -0,0 +1,13 @@
+variable "version" {}
+
+resource "aws_instance" "web" {
+    ami = "foo"
+
+    lifecycle {
+        replace_triggered_by = ["${var.version}"]
+    }
+}
+
+resource "aws_instance" "bar" {
+    ami = "foo"
+}
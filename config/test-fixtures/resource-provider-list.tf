@@ -0,0 +1,8 @@
+resource "aws_instance" "web" {
+  count    = 4
+  provider = ["aws.east", "aws.west"]
+}
+
+resource "aws_instance" "db" {
+  provider = ["aws.east"]
+}
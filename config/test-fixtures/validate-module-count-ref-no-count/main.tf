@@ -0,0 +1,7 @@
+module "foo" {
+    source = "./foo"
+}
+
+output "name" {
+    value = "${module.foo.0.name}"
+}
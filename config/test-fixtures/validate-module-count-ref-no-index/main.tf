@@ -0,0 +1,8 @@
+module "foo" {
+    source = "./foo"
+    count = 2
+}
+
+output "name" {
+    value = "${module.foo.name}"
+}
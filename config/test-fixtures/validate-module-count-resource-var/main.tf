@@ -0,0 +1,6 @@
+resource "aws_instance" "web" {}
+
+module "foo" {
+    source = "./foo"
+    count = "${aws_instance.web.id}"
+}
@@ -0,0 +1,22 @@
+variable "num" {
+    default = 2
+}
+
+module "foo" {
+    source = "./foo"
+    count = "${var.num}"
+    name = "foo-${count.index}"
+}
+
+module "bar" {
+    source = "./bar"
+    count = 3
+}
+
+output "names" {
+    value = "${module.foo.*.name}"
+}
+
+output "first" {
+    value = "${module.bar.0.name}"
+}
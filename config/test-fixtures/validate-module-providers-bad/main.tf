@@ -0,0 +1,7 @@
+module "foo" {
+    source = "./foo"
+
+    providers = {
+        aws = "aws.west"
+    }
+}
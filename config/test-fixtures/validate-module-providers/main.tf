@@ -0,0 +1,12 @@
+provider "aws" {
+    alias = "west"
+}
+
+module "foo" {
+    source = "./foo"
+
+    providers = {
+        aws = "aws.west"
+        "aws.east" = "aws"
+    }
+}
@@ -0,0 +1,9 @@
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+}
+
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.c"
+}
@@ -0,0 +1,6 @@
+resource "aws_instance" "b" {}
+
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+}
@@ -0,0 +1,6 @@
+variable "name" {}
+
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.${var.name}"
+}
@@ -0,0 +1,3 @@
+moved {
+  from = "aws_instance.a"
+}
@@ -0,0 +1,7 @@
+resource "aws_instance" "web" {
+    ami = "foo"
+
+    lifecycle {
+        replace_triggered_by = ["${aws_instance.web.ami}"]
+    }
+}
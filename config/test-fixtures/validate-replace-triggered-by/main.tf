@@ -0,0 +1,9 @@
+variable "version" {}
+
+resource "aws_instance" "web" {}
+
+resource "aws_instance" "app" {
+    lifecycle {
+        replace_triggered_by = ["${var.version}", "${aws_instance.web.id}"]
+    }
+}
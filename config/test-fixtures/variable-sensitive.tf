@@ -0,0 +1,7 @@
+variable "password" {
+    sensitive = true
+}
+
+variable "region" {
+    default = "us-east-1"
+}
@@ -0,0 +1,7 @@
+variable "env" {
+    default = "dev-1"
+
+    validation {
+        regex = "^prod-"
+    }
+}
@@ -0,0 +1,5 @@
+variable "env" {
+    validation {
+        regex = "prod-("
+    }
+}
@@ -0,0 +1,7 @@
+variable "envs" {
+    type = "list"
+
+    validation {
+        regex = "^prod-"
+    }
+}
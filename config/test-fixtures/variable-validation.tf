@@ -0,0 +1,9 @@
+variable "env" {
+    validation {
+        regex = "^prod-"
+    }
+}
+
+variable "region" {
+    default = "us-east-1"
+}
@@ -59,6 +59,80 @@ func (g *AcyclicGraph) Descendents(v Vertex) (*Set, error) {
 	return s, nil
 }
 
+// AncestorsOrdered returns the same vertices as Ancestors, ordered so
+// that every vertex comes after the vertices it has edges to. Vertices
+// that don't depend on each other are ordered by name.
+func (g *AcyclicGraph) AncestorsOrdered(v Vertex) ([]Vertex, error) {
+	s, err := g.Ancestors(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.dependencyOrder(s)
+}
+
+// DescendentsOrdered returns the same vertices as Descendents, in the
+// same order as AncestorsOrdered.
+func (g *AcyclicGraph) DescendentsOrdered(v Vertex) ([]Vertex, error) {
+	s, err := g.Descendents(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.dependencyOrder(s)
+}
+
+// DependencyOrder returns all the vertices of the graph in the same order
+// as AncestorsOrdered, so that every vertex comes after the vertices it
+// has edges to.
+func (g *AcyclicGraph) DependencyOrder() ([]Vertex, error) {
+	s := new(Set)
+	for _, v := range g.Vertices() {
+		s.Add(v)
+	}
+
+	return g.dependencyOrder(s)
+}
+
+// dependencyOrder returns the vertices in s ordered so that every vertex
+// comes after the vertices in s that it has edges to. Only the edges
+// between vertices in s are considered.
+func (g *AcyclicGraph) dependencyOrder(s *Set) ([]Vertex, error) {
+	// Count the dependencies of each vertex within the set
+	remaining := make(map[Vertex]int, s.Len())
+	var ready []Vertex
+	for _, v := range AsVertexList(s) {
+		n := g.DownEdges(v).Intersection(s).Len()
+		remaining[v] = n
+		if n == 0 {
+			ready = append(ready, v)
+		}
+	}
+
+	result := make([]Vertex, 0, s.Len())
+	for len(ready) > 0 {
+		// Take the ready vertices in a consistent order
+		sort.Sort(byVertexName(ready))
+		v := ready[0]
+		ready = ready[1:]
+		result = append(result, v)
+
+		for _, raw := range g.UpEdges(v).Intersection(s).List() {
+			up := raw.(Vertex)
+			remaining[up]--
+			if remaining[up] == 0 {
+				ready = append(ready, up)
+			}
+		}
+	}
+
+	if len(result) != s.Len() {
+		return nil, fmt.Errorf("the vertices have a cycle")
+	}
+
+	return result, nil
+}
+
 // Root returns the root of the DAG, or an error.
 //
 // Complexity: O(V)
@@ -207,6 +207,81 @@ func TestAcyclicGraphDescendents(t *testing.T) {
 	}
 }
 
+func TestAcyclicGraphAncestorsOrdered(t *testing.T) {
+	g := testDiamondGraph()
+
+	actual, err := g.AncestorsOrdered(0)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []Vertex{4, 2, 3, 1}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestAcyclicGraphDescendentsOrdered(t *testing.T) {
+	g := testDiamondGraph()
+
+	actual, err := g.DescendentsOrdered(4)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []Vertex{2, 3, 1, 0}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// The starting vertex itself isn't included
+	actual, err = g.DescendentsOrdered(2)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected = []Vertex{1, 0}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestAcyclicGraphDependencyOrder(t *testing.T) {
+	g := testDiamondGraph()
+
+	actual, err := g.DependencyOrder()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []Vertex{4, 2, 3, 1, 0}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// A cycle is an error
+	g.Connect(BasicEdge(4, 0))
+	if _, err := g.DependencyOrder(); err == nil {
+		t.Fatal("should error")
+	}
+}
+
+// testDiamondGraph returns a graph where 1 depends on both 2 and 3, which
+// both depend on 4, and 0 depends on 1.
+func testDiamondGraph() *AcyclicGraph {
+	var g AcyclicGraph
+	for i := 0; i <= 4; i++ {
+		g.Add(i)
+	}
+	g.Connect(BasicEdge(0, 1))
+	g.Connect(BasicEdge(1, 2))
+	g.Connect(BasicEdge(1, 3))
+	g.Connect(BasicEdge(2, 4))
+	g.Connect(BasicEdge(3, 4))
+
+	return &g
+}
+
 func TestAcyclicGraphWalk(t *testing.T) {
 	var g AcyclicGraph
 	g.Add(1)
@@ -106,6 +106,17 @@ func (v *marshalVertex) dot(g *marshalGraph, opts *DotOpts) []byte {
 		attrs = newAttrs
 	}
 
+	if opts.DrawCycles && g.cycle(v.ID) >= 0 {
+		newAttrs := make(map[string]string)
+		for k, v := range attrs {
+			newAttrs[k] = v
+		}
+		newAttrs["color"] = "red"
+		newAttrs["penwidth"] = "2.0"
+
+		attrs = newAttrs
+	}
+
 	buf.WriteString(fmt.Sprintf(`"[%s] %s"`, graphName, name))
 	writeAttrs(&buf, attrs)
 	buf.WriteByte('\n')
@@ -133,6 +144,27 @@ func cycleDot(e *marshalEdge, g *marshalGraph) string {
 	return e.dot(g) + ` [color = "red", penwidth = "2.0"]`
 }
 
+// cycle returns the index in g.Cycles of the cycle that the vertex with
+// the given ID is part of, or -1 if it isn't part of a cycle.
+func (g *marshalGraph) cycle(id string) int {
+	for i, c := range g.Cycles {
+		for _, v := range c {
+			if v.ID == id {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// cycleEdge returns true if the edge connects two vertices of the same
+// cycle.
+func (g *marshalGraph) cycleEdge(e *marshalEdge) bool {
+	i := g.cycle(e.Source)
+	return i >= 0 && i == g.cycle(e.Target)
+}
+
 // Write the subgraph body. The is recursive, and the depth argument is used to
 // record the current depth of iteration.
 func (g *marshalGraph) writeSubgraph(sg *marshalGraph, opts *DotOpts, depth int, w *indentWriter) {
@@ -162,12 +194,10 @@ func (g *marshalGraph) writeBody(opts *DotOpts, w *indentWriter) {
 		w.WriteString(as + "\n")
 	}
 
-	// list of Vertices that aren't to be included in the dot output
-	skip := map[string]bool{}
-
 	for _, v := range g.Vertices {
+		// Vertices that aren't GraphNodeDotters aren't included in the
+		// dot output
 		if v.graphNodeDotter == nil {
-			skip[v.ID] = true
 			continue
 		}
 
@@ -175,38 +205,12 @@ func (g *marshalGraph) writeBody(opts *DotOpts, w *indentWriter) {
 	}
 
 	var dotEdges []string
-
-	if opts.DrawCycles {
-		for _, c := range g.Cycles {
-			if len(c) < 2 {
-				continue
-			}
-
-			for i, j := 0, 1; i < len(c); i, j = i+1, j+1 {
-				if j >= len(c) {
-					j = 0
-				}
-				src := c[i]
-				tgt := c[j]
-
-				if skip[src.ID] || skip[tgt.ID] {
-					continue
-				}
-
-				e := &marshalEdge{
-					Name:   fmt.Sprintf("%s|%s", src.Name, tgt.Name),
-					Source: src.ID,
-					Target: tgt.ID,
-					Attrs:  make(map[string]string),
-				}
-
-				dotEdges = append(dotEdges, cycleDot(e, g))
-				src = tgt
-			}
+	for _, e := range g.Edges {
+		if opts.DrawCycles && g.cycleEdge(e) {
+			dotEdges = append(dotEdges, cycleDot(e, g))
+			continue
 		}
-	}
 
-	for _, e := range g.Edges {
 		dotEdges = append(dotEdges, e.dot(g))
 	}
 
@@ -190,6 +190,22 @@ func (g *Graph) UpEdges(v Vertex) *Set {
 	return g.upEdges[hashcode(v)]
 }
 
+// Reverse returns a new graph with the same vertices as this one and the
+// direction of every edge flipped. The reversed edges are BasicEdges, so
+// any custom Edge implementations are not preserved. This graph is not
+// modified.
+func (g *Graph) Reverse() *Graph {
+	result := new(Graph)
+	for _, v := range g.Vertices() {
+		result.Add(v)
+	}
+	for _, e := range g.Edges() {
+		result.Connect(BasicEdge(e.Target(), e.Source()))
+	}
+
+	return result
+}
+
 // Connect adds an edge with the given source and target. This is safe to
 // call multiple times with the same value. Note that the same value is
 // verified through pointer equality of the vertices, not through the
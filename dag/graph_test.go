@@ -48,6 +48,48 @@ func TestGraph_remove(t *testing.T) {
 	}
 }
 
+func TestGraph_reverse(t *testing.T) {
+	cases := map[string]struct {
+		Edges    []Edge
+		Expected string
+	}{
+		"chain": {
+			[]Edge{BasicEdge(1, 2), BasicEdge(2, 3)},
+			testGraphReverseChainStr,
+		},
+
+		"diamond": {
+			[]Edge{
+				BasicEdge(1, 2),
+				BasicEdge(1, 3),
+				BasicEdge(2, 4),
+				BasicEdge(3, 4),
+			},
+			testGraphReverseDiamondStr,
+		},
+	}
+
+	for name, tc := range cases {
+		var g Graph
+		for _, e := range tc.Edges {
+			g.Add(e.Source())
+			g.Add(e.Target())
+			g.Connect(e)
+		}
+		original := g.String()
+
+		actual := strings.TrimSpace(g.Reverse().String())
+		expected := strings.TrimSpace(tc.Expected)
+		if actual != expected {
+			t.Fatalf("%s: bad: %s", name, actual)
+		}
+
+		if g.String() != original {
+			t.Fatalf("%s: original modified: %s", name, g.String())
+		}
+	}
+}
+
 func TestGraph_replace(t *testing.T) {
 	var g Graph
 	g.Add(1)
@@ -215,3 +257,22 @@ const testGraphReplaceSelfStr = `
   3
 3
 `
+
+const testGraphReverseChainStr = `
+1
+2
+  1
+3
+  2
+`
+
+const testGraphReverseDiamondStr = `
+1
+2
+  1
+3
+  1
+4
+  2
+  3
+`
@@ -81,6 +81,23 @@ func (s *Set) Difference(other *Set) *Set {
 	return result
 }
 
+// Union returns a set with the elements of both s and other.
+func (s *Set) Union(other *Set) *Set {
+	result := new(Set)
+	if s != nil {
+		for _, v := range s.m {
+			result.Add(v)
+		}
+	}
+	if other != nil {
+		for _, v := range other.m {
+			result.Add(v)
+		}
+	}
+
+	return result
+}
+
 // Len is the number of items in the set.
 func (s *Set) Len() int {
 	if s == nil {
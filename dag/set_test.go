@@ -31,26 +31,194 @@ func TestSetDifference(t *testing.T) {
 			[]interface{}{3, 2, 1, 4},
 			[]interface{}{},
 		},
+
+		{
+			"disjoint",
+			[]interface{}{1, 2},
+			[]interface{}{3, 4},
+			[]interface{}{1, 2},
+		},
+
+		{
+			"A is empty",
+			[]interface{}{},
+			[]interface{}{1, 2},
+			[]interface{}{},
+		},
+
+		{
+			"B is empty",
+			[]interface{}{1, 2},
+			[]interface{}{},
+			[]interface{}{1, 2},
+		},
 	}
 
 	for i, tc := range cases {
 		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
-			var one, two, expected Set
-			for _, v := range tc.A {
-				one.Add(v)
-			}
-			for _, v := range tc.B {
-				two.Add(v)
-			}
-			for _, v := range tc.Expected {
-				expected.Add(v)
-			}
-
-			actual := one.Difference(&two)
-			match := actual.Intersection(&expected)
-			if match.Len() != expected.Len() {
-				t.Fatalf("bad: %#v", actual.List())
-			}
+			testSetOperation(t, tc.A, tc.B, tc.Expected, (*Set).Difference)
 		})
 	}
 }
+
+func TestSetIntersection(t *testing.T) {
+	cases := []struct {
+		Name     string
+		A, B     []interface{}
+		Expected []interface{}
+	}{
+		{
+			"same",
+			[]interface{}{1, 2, 3},
+			[]interface{}{3, 1, 2},
+			[]interface{}{1, 2, 3},
+		},
+
+		{
+			"overlapping",
+			[]interface{}{1, 2, 3},
+			[]interface{}{2, 3, 4},
+			[]interface{}{2, 3},
+		},
+
+		{
+			"disjoint",
+			[]interface{}{1, 2},
+			[]interface{}{3, 4},
+			[]interface{}{},
+		},
+
+		{
+			"A is empty",
+			[]interface{}{},
+			[]interface{}{1, 2},
+			[]interface{}{},
+		},
+
+		{
+			"B is empty",
+			[]interface{}{1, 2},
+			[]interface{}{},
+			[]interface{}{},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			testSetOperation(t, tc.A, tc.B, tc.Expected, (*Set).Intersection)
+		})
+	}
+}
+
+func TestSetUnion(t *testing.T) {
+	cases := []struct {
+		Name     string
+		A, B     []interface{}
+		Expected []interface{}
+	}{
+		{
+			"same",
+			[]interface{}{1, 2, 3},
+			[]interface{}{3, 1, 2},
+			[]interface{}{1, 2, 3},
+		},
+
+		{
+			"overlapping",
+			[]interface{}{1, 2, 3},
+			[]interface{}{2, 3, 4},
+			[]interface{}{1, 2, 3, 4},
+		},
+
+		{
+			"disjoint",
+			[]interface{}{1, 2},
+			[]interface{}{3, 4},
+			[]interface{}{1, 2, 3, 4},
+		},
+
+		{
+			"A is empty",
+			[]interface{}{},
+			[]interface{}{1, 2},
+			[]interface{}{1, 2},
+		},
+
+		{
+			"both empty",
+			[]interface{}{},
+			[]interface{}{},
+			[]interface{}{},
+		},
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d-%s", i, tc.Name), func(t *testing.T) {
+			testSetOperation(t, tc.A, tc.B, tc.Expected, (*Set).Union)
+		})
+	}
+}
+
+func TestSetOperations_nil(t *testing.T) {
+	var nilSet *Set
+	var one Set
+	one.Add(1)
+
+	if l := nilSet.Union(&one).Len(); l != 1 {
+		t.Fatalf("bad union: %d", l)
+	}
+	if l := one.Union(nil).Len(); l != 1 {
+		t.Fatalf("bad union: %d", l)
+	}
+	if l := nilSet.Intersection(&one).Len(); l != 0 {
+		t.Fatalf("bad intersection: %d", l)
+	}
+	if l := one.Intersection(nil).Len(); l != 0 {
+		t.Fatalf("bad intersection: %d", l)
+	}
+	if l := nilSet.Difference(&one).Len(); l != 0 {
+		t.Fatalf("bad difference: %d", l)
+	}
+	if l := one.Difference(nil).Len(); l != 1 {
+		t.Fatalf("bad difference: %d", l)
+	}
+}
+
+// testSetOperation builds sets from a and b, applies op and verifies both
+// the result and that neither input set was modified.
+func testSetOperation(
+	t *testing.T,
+	a, b, expected []interface{},
+	op func(*Set, *Set) *Set) {
+	var one, two Set
+	for _, v := range a {
+		one.Add(v)
+	}
+	for _, v := range b {
+		two.Add(v)
+	}
+
+	actual := op(&one, &two)
+	if actual.Len() != len(expected) {
+		t.Fatalf("bad: %#v", actual.List())
+	}
+	for _, v := range expected {
+		if !actual.Include(v) {
+			t.Fatalf("missing %#v: %#v", v, actual.List())
+		}
+	}
+
+	if one.Len() != len(a) || two.Len() != len(b) {
+		t.Fatalf("inputs were modified: %#v, %#v", one.List(), two.List())
+	}
+	for _, v := range a {
+		if !one.Include(v) {
+			t.Fatalf("receiver was modified: %#v", one.List())
+		}
+	}
+	for _, v := range b {
+		if !two.Include(v) {
+			t.Fatalf("argument was modified: %#v", two.List())
+		}
+	}
+}
@@ -50,6 +50,15 @@ type Provider struct {
 	// See the ConfigureFunc documentation for more information.
 	ConfigureFunc ConfigureFunc
 
+	// EquivalentFunc, if set, is called to check whether the old and new
+	// values of the attribute with the given key are equivalent even
+	// though they are written differently, such as JSON documents with their
+	// keys in a different order. The diff of an attribute between equivalent
+	// values is dropped from the plan. The key is that of the flattened
+	// attribute, such as "policy" or "tags.Name", of any resource of the
+	// provider.
+	EquivalentFunc func(k, old, new string) bool
+
 	// MetaReset is called by TestReset to reset any state stored in the meta
 	// interface.  This is especially important if the StopContext is stored by
 	// the provider.
@@ -267,6 +276,16 @@ func (p *Provider) Refresh(
 	return r.Refresh(s, p.meta)
 }
 
+// Equivalent implementation of terraform.ResourceProviderEquivalence
+// interface.
+func (p *Provider) Equivalent(k, old, new string) bool {
+	if p.EquivalentFunc == nil {
+		return false
+	}
+
+	return p.EquivalentFunc(k, old, new)
+}
+
 // Resources implementation of terraform.ResourceProvider interface.
 func (p *Provider) Resources() []terraform.ResourceType {
 	keys := make([]string, 0, len(p.ResourcesMap))
@@ -285,9 +304,22 @@ func (p *Provider) Resources() []terraform.ResourceType {
 			resource = &Resource{}
 		}
 
+		var attrs []string
+		if len(resource.Schema) > 0 || resource.Timeouts != nil {
+			attrs = make([]string, 0, len(resource.Schema)+1)
+			for a, _ := range resource.Schema {
+				attrs = append(attrs, a)
+			}
+			if resource.Timeouts != nil {
+				attrs = append(attrs, TimeoutsConfigKey)
+			}
+			sort.Strings(attrs)
+		}
+
 		result = append(result, terraform.ResourceType{
 			Name:       k,
 			Importable: resource.Importer != nil,
+			Attributes: attrs,
 		})
 	}
 
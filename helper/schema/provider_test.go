@@ -3,6 +3,7 @@ package schema
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 
 func TestProvider_impl(t *testing.T) {
 	var _ terraform.ResourceProvider = new(Provider)
+	var _ terraform.ResourceProviderEquivalence = new(Provider)
 }
 
 func TestProviderConfigure(t *testing.T) {
@@ -123,6 +125,42 @@ func TestProviderResources(t *testing.T) {
 				terraform.ResourceType{Name: "foo"},
 			},
 		},
+		{
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"foo": &Resource{
+						Schema: map[string]*Schema{
+							"name": &Schema{Type: TypeString, Optional: true},
+							"ami":  &Schema{Type: TypeString, Required: true},
+						},
+					},
+				},
+			},
+			Result: []terraform.ResourceType{
+				terraform.ResourceType{
+					Name:       "foo",
+					Attributes: []string{"ami", "name"},
+				},
+			},
+		},
+		{
+			P: &Provider{
+				ResourcesMap: map[string]*Resource{
+					"foo": &Resource{
+						Schema: map[string]*Schema{
+							"ami": &Schema{Type: TypeString, Required: true},
+						},
+						Timeouts: &ResourceTimeout{},
+					},
+				},
+			},
+			Result: []terraform.ResourceType{
+				terraform.ResourceType{
+					Name:       "foo",
+					Attributes: []string{"ami", "timeouts"},
+				},
+			},
+		},
 	}
 
 	for i, tc := range cases {
@@ -133,6 +171,87 @@ func TestProviderResources(t *testing.T) {
 	}
 }
 
+func TestProviderResources_unknownAttributes(t *testing.T) {
+	cases := []struct {
+		Config map[string]interface{}
+		Err    string
+	}{
+		// Rejected by the schema, so there's no separate warning.
+		{
+			map[string]interface{}{
+				"ami": "bar",
+				"baz": "qux",
+			},
+			"invalid or unknown key: baz",
+		},
+
+		// The timeouts block isn't part of the schema but is known.
+		{
+			map[string]interface{}{
+				"ami": "bar",
+				"timeouts": []map[string]interface{}{
+					map[string]interface{}{"create": "10m"},
+				},
+			},
+			"",
+		},
+	}
+
+	for i, tc := range cases {
+		var p terraform.ResourceProvider = &Provider{
+			ResourcesMap: map[string]*Resource{
+				"foo": &Resource{
+					Schema: map[string]*Schema{
+						"ami": &Schema{Type: TypeString, Optional: true},
+					},
+					Timeouts: &ResourceTimeout{
+						Create: DefaultTimeout(20 * time.Minute),
+					},
+				},
+			},
+		}
+
+		rc, err := config.NewRawConfig(tc.Config)
+		if err != nil {
+			t.Fatalf("%d: err: %s", i, err)
+		}
+		c := terraform.NewResourceConfig(rc)
+
+		node := &terraform.EvalValidateResource{
+			Provider:     &p,
+			Config:       &c,
+			ResourceName: "bar",
+			ResourceType: "foo",
+			ResourceMode: config.ManagedResourceMode,
+		}
+		_, err = node.Eval(new(terraform.MockEvalContext))
+		if tc.Err == "" {
+			if err != nil {
+				t.Fatalf("%d: err: %s", i, err)
+			}
+			continue
+		}
+
+		verr, ok := err.(*terraform.EvalValidateError)
+		if !ok {
+			t.Fatalf("%d: bad: %#v", i, err)
+		}
+
+		found := false
+		for _, e := range verr.Errors {
+			if strings.Contains(e.Error(), tc.Err) {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("%d: expected error %q: %#v", i, tc.Err, verr.Errors)
+		}
+		if len(verr.Warnings) > 0 {
+			t.Fatalf("%d: should not warn: %#v", i, verr.Warnings)
+		}
+	}
+}
+
 func TestProviderDataSources(t *testing.T) {
 	cases := []struct {
 		P      *Provider
@@ -317,6 +436,23 @@ func TestProviderImportState_setsType(t *testing.T) {
 	}
 }
 
+func TestProviderEquivalent(t *testing.T) {
+	p := new(Provider)
+	if p.Equivalent("foo", "a", "A") {
+		t.Fatal("should not be equivalent without EquivalentFunc")
+	}
+
+	p.EquivalentFunc = func(k, old, new string) bool {
+		return k == "foo" && strings.EqualFold(old, new)
+	}
+	if !p.Equivalent("foo", "a", "A") {
+		t.Fatal("should be equivalent")
+	}
+	if p.Equivalent("bar", "a", "A") {
+		t.Fatal("should not be equivalent")
+	}
+}
+
 func TestProviderMeta(t *testing.T) {
 	p := new(Provider)
 	if v := p.Meta(); v != nil {
@@ -3,6 +3,10 @@ package variables
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/hashicorp/hcl"
 	"github.com/mitchellh/go-homedir"
@@ -10,6 +14,10 @@ import (
 
 // FlagFile is a flag.Value implementation for parsing user variables
 // from the command line in the form of files. i.e. '-var-file=foo'
+//
+// The value may also be a directory, in which case all the .tfvars and
+// .tfvars.json files within it are loaded, or a glob pattern. Multiple
+// files are loaded in lexical order with later files overriding earlier.
 type FlagFile map[string]interface{}
 
 func (v *FlagFile) String() string {
@@ -17,15 +25,73 @@ func (v *FlagFile) String() string {
 }
 
 func (v *FlagFile) Set(raw string) error {
-	vs, err := loadKVFile(raw)
+	paths, err := kvFilePaths(raw)
 	if err != nil {
 		return err
 	}
 
-	*v = Merge(*v, vs)
+	for _, path := range paths {
+		vs, err := loadKVFile(path)
+		if err != nil {
+			return err
+		}
+
+		*v = Merge(*v, vs)
+	}
+
 	return nil
 }
 
+// kvFilePaths returns the files to load for the given -var-file value,
+// sorted in the order they should be loaded.
+func kvFilePaths(rawPath string) ([]string, error) {
+	path, err := homedir.Expand(rawPath)
+	if err != nil {
+		return nil, fmt.Errorf(
+			"Error expanding path: %s", err)
+	}
+
+	// A path that exists is used as-is, even if it contains characters
+	// that have a meaning in glob patterns.
+	var patterns []string
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		patterns = []string{
+			filepath.Join(path, "*.tfvars"),
+			filepath.Join(path, "*.tfvars.json"),
+		}
+	} else if err != nil && strings.ContainsAny(path, "*?[") {
+		patterns = []string{path}
+	} else {
+		// A single file. If it doesn't exist that is reported when
+		// loading it.
+		return []string{path}, nil
+	}
+
+	var paths []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Error matching %s: %s", rawPath, err)
+		}
+
+		for _, match := range matches {
+			if fi, err := os.Stat(match); err == nil && fi.IsDir() {
+				continue
+			}
+
+			paths = append(paths, match)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf(
+			"No variable files found for %s", rawPath)
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
 func loadKVFile(rawPath string) (map[string]interface{}, error) {
 	path, err := homedir.Expand(rawPath)
 	if err != nil {
@@ -4,6 +4,8 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -105,3 +107,111 @@ foo = {
 		})
 	}
 }
+
+func TestFlagFile_dir(t *testing.T) {
+	td := testTempDir(t)
+	defer os.RemoveAll(td)
+
+	files := map[string]string{
+		"10-base.tfvars":         `foo = "base"` + "\n" + `bar = "base"` + "\n" + `baz = "base"`,
+		"20-env.tfvars.json":     `{"foo": "env", "bar": "env"}`,
+		"30-override.tfvars":     `foo = "override"`,
+		"notes.txt":              `not a vars file {`,
+		"sub/40-nested.tfvars":   `foo = "nested"`,
+		"literal/prod[1].tfvars": `foo = "literal"`,
+	}
+	for name, contents := range files {
+		path := filepath.Join(td, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+	}
+
+	cases := []struct {
+		Input  string
+		Output map[string]interface{}
+		Error  bool
+	}{
+		// A directory loads every vars file in it, in order
+		{
+			td,
+			map[string]interface{}{
+				"foo": "override",
+				"bar": "env",
+				"baz": "base",
+			},
+			false,
+		},
+
+		// A trailing separator is the same
+		{
+			td + string(filepath.Separator),
+			map[string]interface{}{
+				"foo": "override",
+				"bar": "env",
+				"baz": "base",
+			},
+			false,
+		},
+
+		// A glob only loads the matches
+		{
+			filepath.Join(td, "*.tfvars"),
+			map[string]interface{}{
+				"foo": "override",
+				"bar": "base",
+				"baz": "base",
+			},
+			false,
+		},
+
+		// A file that exists is loaded as-is, even if its name looks
+		// like a glob
+		{
+			filepath.Join(td, "literal", "prod[1].tfvars"),
+			map[string]interface{}{
+				"foo": "literal",
+			},
+			false,
+		},
+
+		// A directory without vars files
+		{
+			filepath.Join(td, "empty"),
+			nil,
+			true,
+		},
+
+		// A glob without matches
+		{
+			filepath.Join(td, "*.nope"),
+			nil,
+			true,
+		},
+	}
+
+	if err := os.Mkdir(filepath.Join(td, "empty"), 0755); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for i, tc := range cases {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			f := new(FlagFile)
+			err := f.Set(tc.Input)
+			if err != nil != tc.Error {
+				t.Fatalf("bad error. Input: %s, err: %s", tc.Input, err)
+			}
+			if tc.Error {
+				return
+			}
+
+			actual := map[string]interface{}(*f)
+			if !reflect.DeepEqual(actual, tc.Output) {
+				t.Fatalf("bad: %#v", actual)
+			}
+		})
+	}
+}
@@ -143,6 +143,9 @@ func (p *ResourceProvider) Apply(
 	}
 	if resp.Error != nil {
 		err = resp.Error
+		if resp.Retryable {
+			err = terraform.NewRetryableError(err)
+		}
 	}
 
 	return resp.State, err
@@ -245,6 +248,38 @@ func (p *ResourceProvider) Resources() []terraform.ResourceType {
 	return result
 }
 
+// ProviderMeta implements terraform.ResourceProviderMeta. Plugins that
+// don't implement it, or that predate it, report no metadata.
+func (p *ResourceProvider) ProviderMeta() string {
+	var result string
+
+	err := p.Client.Call("Plugin.ProviderMeta", new(interface{}), &result)
+	if err != nil {
+		return ""
+	}
+
+	return result
+}
+
+// Equivalent implements terraform.ResourceProviderEquivalence. Plugins
+// that don't implement it, or that predate it, consider no values
+// equivalent.
+func (p *ResourceProvider) Equivalent(attr, old, new string) bool {
+	var result bool
+	args := &ResourceProviderEquivalentArgs{
+		Attr: attr,
+		Old:  old,
+		New:  new,
+	}
+
+	err := p.Client.Call("Plugin.Equivalent", args, &result)
+	if err != nil {
+		return false
+	}
+
+	return result
+}
+
 func (p *ResourceProvider) ReadDataDiff(
 	info *terraform.InstanceInfo,
 	c *terraform.ResourceConfig) (*terraform.InstanceDiff, error) {
@@ -333,8 +368,9 @@ type ResourceProviderApplyArgs struct {
 }
 
 type ResourceProviderApplyResponse struct {
-	State *terraform.InstanceState
-	Error *plugin.BasicError
+	State     *terraform.InstanceState
+	Error     *plugin.BasicError
+	Retryable bool
 }
 
 type ResourceProviderDiffArgs struct {
@@ -358,6 +394,12 @@ type ResourceProviderRefreshResponse struct {
 	Error *plugin.BasicError
 }
 
+type ResourceProviderEquivalentArgs struct {
+	Attr string
+	Old  string
+	New  string
+}
+
 type ResourceProviderImportStateArgs struct {
 	Info *terraform.InstanceInfo
 	Id   string
@@ -487,8 +529,9 @@ func (s *ResourceProviderServer) Apply(
 	result *ResourceProviderApplyResponse) error {
 	state, err := s.Provider.Apply(args.Info, args.State, args.Diff)
 	*result = ResourceProviderApplyResponse{
-		State: state,
-		Error: plugin.NewBasicError(err),
+		State:     state,
+		Error:     plugin.NewBasicError(err),
+		Retryable: terraform.IsRetryableError(err),
 	}
 	return nil
 }
@@ -533,6 +576,24 @@ func (s *ResourceProviderServer) Resources(
 	return nil
 }
 
+func (s *ResourceProviderServer) ProviderMeta(
+	nothing interface{},
+	result *string) error {
+	if p, ok := s.Provider.(terraform.ResourceProviderMeta); ok {
+		*result = p.ProviderMeta()
+	}
+	return nil
+}
+
+func (s *ResourceProviderServer) Equivalent(
+	args *ResourceProviderEquivalentArgs,
+	result *bool) error {
+	if p, ok := s.Provider.(terraform.ResourceProviderEquivalence); ok {
+		*result = p.Equivalent(args.Attr, args.Old, args.New)
+	}
+	return nil
+}
+
 func (s *ResourceProviderServer) ValidateDataSource(
 	args *ResourceProviderValidateResourceArgs,
 	reply *ResourceProviderValidateResourceResponse) error {
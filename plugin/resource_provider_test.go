@@ -3,15 +3,19 @@ package plugin
 import (
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/hashicorp/go-plugin"
+	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/terraform"
 )
 
 func TestResourceProvider_impl(t *testing.T) {
 	var _ plugin.Plugin = new(ResourceProviderPlugin)
 	var _ terraform.ResourceProvider = new(ResourceProvider)
+	var _ terraform.ResourceProviderMeta = new(ResourceProvider)
+	var _ terraform.ResourceProviderEquivalence = new(ResourceProvider)
 }
 
 func TestResourceProvider_stop(t *testing.T) {
@@ -249,6 +253,40 @@ func TestResourceProvider_apply(t *testing.T) {
 	}
 }
 
+func TestResourceProvider_applyRetryable(t *testing.T) {
+	p := new(terraform.MockResourceProvider)
+
+	// Create a mock provider
+	client, _ := plugin.TestPluginRPCConn(t, pluginMap(&ServeOpts{
+		ProviderFunc: testProviderFixed(p),
+	}))
+	defer client.Close()
+
+	// Request the provider
+	raw, err := client.Dispense(ProviderPluginName)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	provider := raw.(terraform.ResourceProvider)
+
+	p.ApplyReturnError = terraform.NewRetryableError(errors.New("timeout"))
+
+	// Apply
+	info := &terraform.InstanceInfo{}
+	state := &terraform.InstanceState{}
+	diff := &terraform.InstanceDiff{}
+	_, err = provider.Apply(info, state, diff)
+	if err == nil {
+		t.Fatal("should have error")
+	}
+	if err.Error() != "timeout" {
+		t.Fatalf("bad: %s", err)
+	}
+	if !terraform.IsRetryableError(err) {
+		t.Fatalf("should be retryable: %#v", err)
+	}
+}
+
 func TestResourceProvider_diff(t *testing.T) {
 	p := new(terraform.MockResourceProvider)
 
@@ -444,6 +482,85 @@ func TestResourceProvider_resources(t *testing.T) {
 	}
 }
 
+func TestResourceProvider_providerMeta(t *testing.T) {
+	p := new(terraform.MockResourceProvider)
+
+	// Create a mock provider
+	client, _ := plugin.TestPluginRPCConn(t, pluginMap(&ServeOpts{
+		ProviderFunc: testProviderFixed(p),
+	}))
+	defer client.Close()
+
+	// Request the provider
+	raw, err := client.Dispense(ProviderPluginName)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	provider := raw.(terraform.ResourceProviderMeta)
+
+	p.ProviderMetaReturn = "1.2.3"
+
+	result := provider.ProviderMeta()
+	if !p.ProviderMetaCalled {
+		t.Fatal("meta should be called")
+	}
+	if result != "1.2.3" {
+		t.Fatalf("bad: %#v", result)
+	}
+}
+
+func TestResourceProvider_equivalent(t *testing.T) {
+	p := &schema.Provider{
+		EquivalentFunc: func(k, old, new string) bool {
+			return k == "policy" && strings.TrimSpace(old) == strings.TrimSpace(new)
+		},
+	}
+
+	// Create a mock provider
+	client, _ := plugin.TestPluginRPCConn(t, pluginMap(&ServeOpts{
+		ProviderFunc: testProviderFixed(p),
+	}))
+	defer client.Close()
+
+	// Request the provider
+	raw, err := client.Dispense(ProviderPluginName)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	provider := raw.(terraform.ResourceProviderEquivalence)
+
+	if !provider.Equivalent("policy", "{}", " {}\n") {
+		t.Fatal("should be equivalent")
+	}
+	if provider.Equivalent("policy", "{}", "[]") {
+		t.Fatal("should not be equivalent")
+	}
+	if provider.Equivalent("name", "{}", " {}\n") {
+		t.Fatal("should not be equivalent")
+	}
+}
+
+func TestResourceProvider_equivalentUnsupported(t *testing.T) {
+	p := new(terraform.MockResourceProvider)
+
+	// Create a mock provider
+	client, _ := plugin.TestPluginRPCConn(t, pluginMap(&ServeOpts{
+		ProviderFunc: testProviderFixed(p),
+	}))
+	defer client.Close()
+
+	// Request the provider
+	raw, err := client.Dispense(ProviderPluginName)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	provider := raw.(terraform.ResourceProviderEquivalence)
+
+	if provider.Equivalent("foo", "a", "a") {
+		t.Fatal("should not be equivalent")
+	}
+}
+
 func TestResourceProvider_readdataapply(t *testing.T) {
 	p := new(terraform.MockResourceProvider)
 
@@ -0,0 +1,36 @@
+package repl
+
+// InputComplete returns whether the given input is a complete expression
+// that can be handed to Session.Handle, or whether more lines are needed
+// because some parentheses or brackets haven't been closed yet. Brackets
+// within quoted strings are ignored.
+func InputComplete(input string) bool {
+	depth := 0
+	inString := false
+	escaped := false
+	for _, r := range input {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case r == '\\':
+				escaped = true
+			case r == '"':
+				inString = false
+			}
+
+			continue
+		}
+
+		switch r {
+		case '"':
+			inString = true
+		case '(', '[':
+			depth++
+		case ')', ']':
+			depth--
+		}
+	}
+
+	return depth <= 0
+}
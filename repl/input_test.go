@@ -0,0 +1,30 @@
+package repl
+
+import (
+	"testing"
+)
+
+func TestInputComplete(t *testing.T) {
+	cases := []struct {
+		Input    string
+		Complete bool
+	}{
+		{"", true},
+		{"1 + 5", true},
+		{"list(1, 2)", true},
+		{"list(1,", false},
+		{"map(\n\"a\", list(1,\n", false},
+		{"map(\n\"a\", list(1,\n2))", true},
+		{"var.foo[", false},
+		{"\"(\"", true},
+		{"format(\"%s)\",", false},
+		{"format(\"\\\"(\",", false},
+		{"1)", true},
+	}
+
+	for _, tc := range cases {
+		if actual := InputComplete(tc.Input); actual != tc.Complete {
+			t.Errorf("%q: got %t, want %t", tc.Input, actual, tc.Complete)
+		}
+	}
+}
@@ -13,6 +13,10 @@ import (
 // from Handle to signal a graceful exit.
 var ErrSessionExit = errors.New("session exit")
 
+// UnknownOutput is the result shown for an expression that references
+// values that aren't known yet, such as attributes missing from the state.
+const UnknownOutput = "<computed>"
+
 // Session represents the state for a single REPL session.
 type Session struct {
 	// Interpolater is used for calculating interpolations
@@ -64,9 +68,10 @@ func (s *Session) handleEval(line string) (string, error) {
 		return "", err
 	}
 
-	// If we have any unknown keys, let the user know.
+	// If we have any unknown keys, such as an attribute that isn't in
+	// the state, the value can't be computed yet.
 	if ks := raw.UnknownKeys(); len(ks) > 0 {
-		return "", fmt.Errorf("unknown values referenced, can't compute value")
+		return UnknownOutput, nil
 	}
 
 	// Read the value
@@ -67,6 +67,22 @@ func TestSession_basicState(t *testing.T) {
 		})
 	})
 
+	t.Run("missing attribute", func(t *testing.T) {
+		testSession(t, testSessionTest{
+			State: state,
+			Inputs: []testSessionInput{
+				{
+					Input:  "test_instance.foo.nope",
+					Output: "<computed>",
+				},
+				{
+					Input:  "\"${test_instance.foo.id}-${test_instance.foo.nope}\"",
+					Output: "<computed>",
+				},
+			},
+		})
+	})
+
 	t.Run("missing resource", func(t *testing.T) {
 		testSession(t, testSessionTest{
 			State: state,
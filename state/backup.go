@@ -56,6 +56,15 @@ func (s *BackupState) Unlock(id string) error {
 	return nil
 }
 
+// LockInfo reads the lock of the real state without acquiring it if the
+// real state supports it. See LockHolder.
+func (s *BackupState) LockInfo() (*LockInfo, error) {
+	if s, ok := s.Real.(Locker); ok {
+		return LockHolder(s)
+	}
+	return nil, nil
+}
+
 func (s *BackupState) backup() error {
 	state := s.Real.State()
 	if state == nil {
@@ -8,6 +8,7 @@ import (
 
 func TestBackupState_locker(t *testing.T) {
 	var _ Locker = new(BackupState)
+	var _ LockInfoReader = new(BackupState)
 }
 
 func TestBackupState(t *testing.T) {
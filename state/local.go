@@ -245,6 +245,17 @@ func (s *LocalState) lockInfoPath() string {
 	return filepath.Join(stateDir, fmt.Sprintf(".%s.lock.info", stateName))
 }
 
+// LockInfo returns the data in the lock info file, which only exists
+// while the state is locked.
+func (s *LocalState) LockInfo() (*LockInfo, error) {
+	info, err := s.lockInfo()
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	return info, err
+}
+
 // lockInfo returns the data in a lock info file
 func (s *LocalState) lockInfo() (*LockInfo, error) {
 	path := s.lockInfoPath()
@@ -46,6 +46,15 @@ func TestLocalStateLocks(t *testing.T) {
 		t.Fatalf("invalid lock info %#v\n", lockInfo)
 	}
 
+	// the lock holder can be read without locking
+	holder, err := LockHolder(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder == nil || holder.ID != lockID {
+		t.Fatalf("invalid lock holder %#v\n", holder)
+	}
+
 	// a noop, since we unlock on exit
 	if err := s.Unlock(lockID); err != nil {
 		t.Fatal(err)
@@ -123,11 +132,25 @@ func TestLocalState_nonExist(t *testing.T) {
 	}
 }
 
+func TestLocalState_lockInfoUnlocked(t *testing.T) {
+	s := testLocalState(t)
+	defer os.Remove(s.Path)
+
+	info, err := s.LockInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info != nil {
+		t.Fatalf("expected no lock, got: %#v", info)
+	}
+}
+
 func TestLocalState_impl(t *testing.T) {
 	var _ StateReader = new(LocalState)
 	var _ StateWriter = new(LocalState)
 	var _ StatePersister = new(LocalState)
 	var _ StateRefresher = new(LocalState)
+	var _ LockInfoReader = new(LocalState)
 }
 
 func testLocalState(t *testing.T) *LocalState {
@@ -245,6 +245,16 @@ func (c *S3Client) Lock(info *state.LockInfo) (string, error) {
 	return info.ID, nil
 }
 
+// LockInfo returns the lock info stored in the lock table, or nil if the
+// state isn't locked or no lock table is used.
+func (c *S3Client) LockInfo() (*state.LockInfo, error) {
+	if c.lockTable == "" {
+		return nil, nil
+	}
+
+	return c.getLockInfo()
+}
+
 func (c *S3Client) getLockInfo() (*state.LockInfo, error) {
 	getParams := &dynamodb.GetItemInput{
 		Key: map[string]*dynamodb.AttributeValue{
@@ -263,6 +273,10 @@ func (c *S3Client) getLockInfo() (*state.LockInfo, error) {
 	if v, ok := resp.Item["Info"]; ok && v.S != nil {
 		infoData = *v.S
 	}
+	if infoData == "" {
+		// There is no lock
+		return nil, nil
+	}
 
 	lockInfo := &state.LockInfo{}
 	err = json.Unmarshal([]byte(infoData), lockInfo)
@@ -288,6 +302,10 @@ func (c *S3Client) Unlock(id string) error {
 		lockErr.Err = fmt.Errorf("failed to retrieve lock info: %s", err)
 		return lockErr
 	}
+	if lockInfo == nil {
+		lockErr.Err = fmt.Errorf("state is not locked")
+		return lockErr
+	}
 	lockErr.Info = lockInfo
 
 	if lockInfo.ID != id {
@@ -70,6 +70,15 @@ func (s *State) Lock(info *state.LockInfo) (string, error) {
 	return "", nil
 }
 
+// LockInfo reads the Client's lock without acquiring it if the Client
+// supports it. See state.LockHolder.
+func (s *State) LockInfo() (*state.LockInfo, error) {
+	if c, ok := s.Client.(ClientLocker); ok {
+		return state.LockHolder(c)
+	}
+	return nil, nil
+}
+
 // Unlock calls the Client's Unlock method if it's implemented.
 func (s *State) Unlock(id string) error {
 	if c, ok := s.Client.(ClientLocker); ok {
@@ -2,6 +2,7 @@ package state
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -72,6 +73,82 @@ type Locker interface {
 	Unlock(id string) error
 }
 
+// LockInfoReader is implemented by Lockers that can report the current
+// lock without acquiring it, such as those that store the LockInfo in a
+// file or object alongside the state.
+type LockInfoReader interface {
+	// LockInfo returns the LockInfo of the current lock, or nil if the
+	// state isn't locked.
+	LockInfo() (*LockInfo, error)
+}
+
+// The delay between attempts to acquire a lock in LockWithContext doubles
+// after each attempt, up to lockRetryMaxDelay.
+var (
+	lockRetryDelay    = time.Second
+	lockRetryMaxDelay = 16 * time.Second
+)
+
+// LockWithContext locks the given state, retrying while the lock is held
+// by someone else until the context is done. If the lock couldn't be
+// acquired, the error from the last attempt is returned so that the
+// LockInfo of the current lock holder is available to the caller.
+//
+// Errors that aren't a *LockError are returned immediately since there is
+// no lock holder to wait for.
+func LockWithContext(ctx context.Context, s Locker, info *LockInfo) (string, error) {
+	delay := lockRetryDelay
+	for {
+		id, err := s.Lock(info)
+		if err == nil {
+			return id, nil
+		}
+
+		if _, ok := err.(*LockError); !ok {
+			return "", err
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", err
+		case <-time.After(delay):
+			if delay < lockRetryMaxDelay {
+				delay *= 2
+			}
+		}
+	}
+}
+
+// LockHolder returns the LockInfo of the lock currently held on the given
+// state, or nil if it isn't locked.
+//
+// If the Locker is a LockInfoReader, the lock is read as-is. Otherwise the
+// only way to find the lock is to try to acquire it: if that succeeds, the
+// lock is released again right away.
+func LockHolder(s Locker) (*LockInfo, error) {
+	if r, ok := s.(LockInfoReader); ok {
+		return r.LockInfo()
+	}
+
+	info := NewLockInfo()
+	info.Operation = "LockHolder"
+	info.Info = "checking for the current lock holder"
+
+	id, err := s.Lock(info)
+	if err == nil {
+		if err := s.Unlock(id); err != nil {
+			return nil, fmt.Errorf("error releasing the lock taken to check for the lock holder: %s", err)
+		}
+		return nil, nil
+	}
+
+	if lockErr, ok := err.(*LockError); ok && lockErr.Info != nil {
+		return lockErr.Info, nil
+	}
+
+	return nil, err
+}
+
 // Generate a LockInfo structure, populating the required fields.
 func NewLockInfo() *LockInfo {
 	// this doesn't need to be cryptographically secure, just unique.
@@ -1,12 +1,16 @@
 package state
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"io/ioutil"
 	"log"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hashicorp/terraform/helper/logging"
 )
@@ -50,3 +54,190 @@ func TestNewLockInfo(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestLockWithContext(t *testing.T) {
+	defer testLockRetryDelay(10 * time.Millisecond)()
+
+	holder := NewLockInfo()
+	holder.Operation = "test"
+	l := &testLocker{Holder: holder}
+
+	// Release the lock partway through the timeout
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		l.Release()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	info := NewLockInfo()
+	id, err := LockWithContext(ctx, l, info)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if id != info.ID {
+		t.Fatalf("bad: %q", id)
+	}
+	if l.Attempts < 2 {
+		t.Fatalf("expected lock to be retried, got %d attempts", l.Attempts)
+	}
+}
+
+func TestLockWithContext_timeout(t *testing.T) {
+	defer testLockRetryDelay(10 * time.Millisecond)()
+
+	holder := NewLockInfo()
+	holder.Operation = "test"
+	l := &testLocker{Holder: holder}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := LockWithContext(ctx, l, NewLockInfo())
+	lockErr, ok := err.(*LockError)
+	if !ok {
+		t.Fatalf("expected a LockError, got: %#v", err)
+	}
+	if lockErr.Info == nil || lockErr.Info.ID != holder.ID {
+		t.Fatalf("expected info of the lock holder, got: %#v", lockErr.Info)
+	}
+}
+
+func TestLockWithContext_error(t *testing.T) {
+	l := &testLocker{Err: errors.New("broken")}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := LockWithContext(ctx, l, NewLockInfo())
+	if err == nil || err.Error() != "broken" {
+		t.Fatalf("bad: %v", err)
+	}
+	if l.Attempts != 1 {
+		t.Fatalf("expected a single attempt, got %d", l.Attempts)
+	}
+}
+
+func TestLockHolder(t *testing.T) {
+	holder := NewLockInfo()
+	holder.Operation = "test"
+	l := &testLocker{Holder: holder}
+
+	info, err := LockHolder(l)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if info == nil || info.ID != holder.ID {
+		t.Fatalf("expected info of the lock holder, got: %#v", info)
+	}
+	if l.Holder != holder {
+		t.Fatalf("lock holder changed: %#v", l.Holder)
+	}
+}
+
+func TestLockHolder_unlocked(t *testing.T) {
+	l := &testLocker{}
+
+	info, err := LockHolder(l)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if info != nil {
+		t.Fatalf("expected no lock holder, got: %#v", info)
+	}
+	if l.Holder != nil {
+		t.Fatalf("state left locked by: %#v", l.Holder)
+	}
+}
+
+func TestLockHolder_error(t *testing.T) {
+	l := &testLocker{Err: errors.New("broken")}
+
+	_, err := LockHolder(l)
+	if err == nil || err.Error() != "broken" {
+		t.Fatalf("bad: %v", err)
+	}
+}
+
+func TestLockHolder_reader(t *testing.T) {
+	holder := NewLockInfo()
+	holder.Operation = "test"
+	l := &testLockInfoReader{Info: holder}
+
+	info, err := LockHolder(l)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if info == nil || info.ID != holder.ID {
+		t.Fatalf("expected info of the lock holder, got: %#v", info)
+	}
+
+	// The lock is read, not acquired
+	if l.Attempts != 0 {
+		t.Fatalf("lock attempted %d times", l.Attempts)
+	}
+}
+
+// testLockRetryDelay sets the delay between lock attempts for the duration
+// of a test. The returned function restores the previous values.
+func testLockRetryDelay(d time.Duration) func() {
+	delay, maxDelay := lockRetryDelay, lockRetryMaxDelay
+	lockRetryDelay, lockRetryMaxDelay = d, d
+	return func() {
+		lockRetryDelay, lockRetryMaxDelay = delay, maxDelay
+	}
+}
+
+// testLocker is a Locker that is held by Holder until Release is called.
+// If Err is set, every attempt to lock fails with that error.
+type testLocker struct {
+	Holder   *LockInfo
+	Err      error
+	Attempts int
+
+	mu sync.Mutex
+}
+
+func (l *testLocker) Lock(info *LockInfo) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Attempts++
+	if l.Err != nil {
+		return "", l.Err
+	}
+	if l.Holder != nil {
+		return "", &LockError{
+			Info: l.Holder,
+			Err:  errors.New("state locked"),
+		}
+	}
+
+	l.Holder = info
+	return info.ID, nil
+}
+
+func (l *testLocker) Unlock(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.Holder = nil
+	return nil
+}
+
+func (l *testLocker) Release() {
+	l.Unlock("")
+}
+
+// testLockInfoReader is a testLocker that reports the lock it holds
+// without acquiring it.
+type testLockInfoReader struct {
+	testLocker
+
+	Info *LockInfo
+}
+
+func (l *testLockInfoReader) LockInfo() (*LockInfo, error) {
+	return l.Info, nil
+}
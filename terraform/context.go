@@ -60,8 +60,54 @@ type ContextOpts struct {
 	Providers          map[string]ResourceProviderFactory
 	Provisioners       map[string]ResourceProvisionerFactory
 	Shadow             bool
-	Targets            []string
-	Variables          map[string]interface{}
+
+	// ProviderResolver, if set, chooses the providers to use in place of
+	// the ones in Providers, such as StubProviderResolver to plan without
+	// real providers in tests.
+	ProviderResolver ProviderResolver
+
+	Targets   []string
+	Variables map[string]interface{}
+
+	// TargetDepth, if non-nil, limits targeting to dependencies at most
+	// this many resources away from a target. Zero means only the targets
+	// themselves. If nil, all dependencies of the targets are included.
+	TargetDepth *int
+
+	// TargetModules are module addresses, such as "module.foo", whose
+	// resources are all targeted along with those of their child modules.
+	TargetModules []string
+
+	// WarnUnusedVariables, if true, makes Validate warn about variables
+	// declared in the root or a child module that nothing in that module
+	// references.
+	WarnUnusedVariables bool
+
+	// StopOnError, if true, makes Apply stop starting new resource
+	// operations as soon as one fails. Operations already in progress are
+	// allowed to complete, and outputs are still written. By default, Apply continues with everything that doesn't
+	// depend on the failed operation.
+	StopOnError bool
+
+	// ModuleBarriers, if true, makes Apply finish all the resources of a
+	// child module before starting on those of the next one, even when
+	// they don't depend on each other.
+	ModuleBarriers bool
+
+	// RetryFailed is the number of times Apply retries after a walk in
+	// which every failure was a RetryableError. Each retry plans again
+	// from the updated state and applies what is left, so resources that
+	// depended on the failed ones are applied as well.
+	RetryFailed int
+
+	// RecordApplyTime, if true, makes Apply record the time each resource
+	// is created or updated in its state as "last_applied".
+	RecordApplyTime bool
+
+	// PreserveOutputs, if true, makes Refresh keep the last known value of
+	// an output when its value can't be determined, such as when a resource
+	// it depends on failed to refresh. See RefreshGraphBuilder.
+	PreserveOutputs bool
 
 	UIInput UIInput
 }
@@ -84,20 +130,34 @@ type Context struct {
 	// that newShadowContext still does the right thing. Tests should
 	// fail regardless but putting this note here as well.
 
-	components contextComponentFactory
-	destroy    bool
-	diff       *Diff
-	diffLock   sync.RWMutex
-	hooks      []Hook
-	meta       *ContextMeta
-	module     *module.Tree
-	sh         *stopHook
-	shadow     bool
-	state      *State
-	stateLock  sync.RWMutex
-	targets    []string
-	uiInput    UIInput
-	variables  map[string]interface{}
+	components  contextComponentFactory
+	destroy     bool
+	diff        *Diff
+	diffLock    sync.RWMutex
+	hooks       []Hook
+	meta        *ContextMeta
+	module      *module.Tree
+	modBarriers bool
+	keepOutputs bool
+	recordTime  bool
+	retryFailed int
+	sh          *stopHook
+	shadow      bool
+	state       *State
+	stateLock   sync.RWMutex
+	stopOnError bool
+	targets     []string
+	targetDepth *int
+	targetMods  []string
+	uiInput     UIInput
+	variables   map[string]interface{}
+	warnUnused  bool
+
+	// loadedModule is the module tree as it was given, while module has
+	// modules with a count expanded into their instances once an operation
+	// that needs them runs, see expandModule. Operations run against
+	// module, but it's loadedModule that is validated and stored in plans.
+	loadedModule *module.Tree
 
 	l                   sync.Mutex // Lock acquired during any task
 	parallelSem         Semaphore
@@ -180,21 +240,36 @@ func NewContext(opts *ContextOpts) (*Context, error) {
 		diff = &Diff{}
 	}
 
+	providers := opts.Providers
+	if opts.ProviderResolver != nil {
+		providers = resolveProviders(opts.ProviderResolver, providers, opts.Module, state)
+	}
+
 	return &Context{
 		components: &basicComponentFactory{
-			providers:    opts.Providers,
+			providers:    providers,
 			provisioners: opts.Provisioners,
 		},
-		destroy:   opts.Destroy,
-		diff:      diff,
-		hooks:     hooks,
-		meta:      opts.Meta,
-		module:    opts.Module,
-		shadow:    opts.Shadow,
-		state:     state,
-		targets:   opts.Targets,
-		uiInput:   opts.UIInput,
-		variables: variables,
+		destroy:     opts.Destroy,
+		diff:        diff,
+		hooks:       hooks,
+		meta:        opts.Meta,
+		module:      opts.Module,
+		modBarriers: opts.ModuleBarriers,
+		keepOutputs: opts.PreserveOutputs,
+		recordTime:  opts.RecordApplyTime,
+		retryFailed: opts.RetryFailed,
+		shadow:      opts.Shadow,
+		state:       state,
+		stopOnError: opts.StopOnError,
+		targets:     opts.Targets,
+		targetDepth: opts.TargetDepth,
+		targetMods:  opts.TargetModules,
+		uiInput:     opts.UIInput,
+		variables:   variables,
+		warnUnused:  opts.WarnUnusedVariables,
+
+		loadedModule: opts.Module,
 
 		parallelSem:         NewSemaphore(par),
 		providerInputConfig: make(map[string]map[string]interface{}),
@@ -208,6 +283,11 @@ type ContextGraphOpts struct {
 
 	// Legacy graphs only: won't prune the graph
 	Verbose bool
+
+	// If true, the transitive reduction of the graph is skipped so that
+	// the graph contains every dependency edge, not just the ones needed
+	// to order the walk.
+	DisableReduce bool
 }
 
 // Graph returns the graph used for the given operation type.
@@ -222,14 +302,19 @@ func (c *Context) Graph(typ GraphType, opts *ContextGraphOpts) (*Graph, error) {
 	switch typ {
 	case GraphTypeApply:
 		return (&ApplyGraphBuilder{
-			Module:       c.module,
-			Diff:         c.diff,
-			State:        c.state,
-			Providers:    c.components.ResourceProviders(),
-			Provisioners: c.components.ResourceProvisioners(),
-			Targets:      c.targets,
-			Destroy:      c.destroy,
-			Validate:     opts.Validate,
+			Module:          c.module,
+			Diff:            c.diff,
+			State:           c.state,
+			Providers:       c.components.ResourceProviders(),
+			Provisioners:    c.components.ResourceProvisioners(),
+			Targets:         c.targets,
+			TargetDepth:     c.targetDepth,
+			TargetModules:   c.targetMods,
+			Destroy:         c.destroy,
+			Validate:        opts.Validate,
+			ModuleBarriers:  c.modBarriers,
+			RecordApplyTime: c.recordTime,
+			DisableReduce:   opts.DisableReduce,
 		}).Build(RootModulePath)
 
 	case GraphTypeInput:
@@ -241,11 +326,14 @@ func (c *Context) Graph(typ GraphType, opts *ContextGraphOpts) (*Graph, error) {
 	case GraphTypePlan:
 		// Create the plan graph builder
 		p := &PlanGraphBuilder{
-			Module:    c.module,
-			State:     c.state,
-			Providers: c.components.ResourceProviders(),
-			Targets:   c.targets,
-			Validate:  opts.Validate,
+			Module:        c.module,
+			State:         c.state,
+			Providers:     c.components.ResourceProviders(),
+			Targets:       c.targets,
+			TargetDepth:   c.targetDepth,
+			TargetModules: c.targetMods,
+			Validate:      opts.Validate,
+			DisableReduce: opts.DisableReduce,
 		}
 
 		// Some special cases for other graph types shared with plan currently
@@ -256,6 +344,7 @@ func (c *Context) Graph(typ GraphType, opts *ContextGraphOpts) (*Graph, error) {
 		case GraphTypeValidate:
 			// We need to set the provisioners so those can be validated
 			p.Provisioners = c.components.ResourceProvisioners()
+			p.ReportUnusedVariables = c.warnUnused
 
 			b = ValidateGraphBuilder(p)
 		}
@@ -264,19 +353,25 @@ func (c *Context) Graph(typ GraphType, opts *ContextGraphOpts) (*Graph, error) {
 
 	case GraphTypePlanDestroy:
 		return (&DestroyPlanGraphBuilder{
-			Module:   c.module,
-			State:    c.state,
-			Targets:  c.targets,
-			Validate: opts.Validate,
+			Module:        c.module,
+			State:         c.state,
+			Targets:       c.targets,
+			TargetDepth:   c.targetDepth,
+			TargetModules: c.targetMods,
+			Validate:      opts.Validate,
 		}).Build(RootModulePath)
 
 	case GraphTypeRefresh:
 		return (&RefreshGraphBuilder{
-			Module:    c.module,
-			State:     c.state,
-			Providers: c.components.ResourceProviders(),
-			Targets:   c.targets,
-			Validate:  opts.Validate,
+			Module:          c.module,
+			State:           c.state,
+			Providers:       c.components.ResourceProviders(),
+			Targets:         c.targets,
+			TargetDepth:     c.targetDepth,
+			TargetModules:   c.targetMods,
+			PreserveOutputs: c.keepOutputs,
+			Validate:        opts.Validate,
+			DisableReduce:   opts.DisableReduce,
 		}).Build(RootModulePath)
 	}
 
@@ -320,12 +415,21 @@ func (c *Context) State() *State {
 // Interpolater returns an Interpolater built on a copy of the state
 // that can be used to test interpolation values.
 func (c *Context) Interpolater() *Interpolater {
+	// Expand the modules with a count if the counts are known, so that
+	// the outputs of all of their instances can be referenced.
+	mod := c.module
+	if c.loadedModule != nil {
+		if expanded, err := c.loadedModule.ExpandCount(c.variables); err == nil {
+			mod = expanded
+		}
+	}
+
 	var varLock sync.Mutex
 	var stateLock sync.RWMutex
 	return &Interpolater{
-		Operation:          walkApply,
+		Operation:          walkEval,
 		Meta:               c.meta,
-		Module:             c.module,
+		Module:             mod,
 		State:              c.state.DeepCopy(),
 		StateLock:          &stateLock,
 		VariableValues:     c.variables,
@@ -435,6 +539,12 @@ func (c *Context) Input(mode InputMode) error {
 	}
 
 	if mode&InputModeProvider != 0 {
+		// The counts of modules may use the variables that were just
+		// asked for, so expand them only now.
+		if err := c.expandModule(); err != nil {
+			return err
+		}
+
 		// Build the graph
 		graph, err := c.Graph(GraphTypeInput, nil)
 		if err != nil {
@@ -458,6 +568,10 @@ func (c *Context) Input(mode InputMode) error {
 func (c *Context) Apply() (*State, error) {
 	defer c.acquireRun("apply")()
 
+	if err := c.expandModule(); err != nil {
+		return nil, err
+	}
+
 	// Copy our own state
 	c.state = c.state.DeepCopy()
 
@@ -475,7 +589,36 @@ func (c *Context) Apply() (*State, error) {
 
 	// Walk the graph
 	walker, err := c.walk(graph, graph, operation)
-	if len(walker.ValidationErrors) > 0 {
+
+	// Retry if everything that failed may succeed on another attempt. The
+	// shadow graph only verifies the first walk of a run, so it is disabled
+	// for the retries.
+	if c.retryFailed > 0 {
+		shadow := c.shadow
+		c.shadow = false
+		defer func() { c.shadow = shadow }()
+	}
+	for i := 0; i < c.retryFailed && walker != nil && walker.retryable(); i++ {
+		log.Printf("[INFO] terraform: retrying failed resources (attempt %d): %s", i+1, err)
+
+		// Plan again from the updated state so that only what is left
+		// is applied. If that fails, the errors of the last apply are
+		// kept along with the planning error.
+		if _, planErr := c.plan(); planErr != nil {
+			err = multierror.Append(err, fmt.Errorf(
+				"Error planning the retry of failed resources: %s", planErr))
+			break
+		}
+
+		graph, err = c.Graph(GraphTypeApply, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		walker, err = c.walk(graph, graph, operation)
+	}
+
+	if walker != nil && len(walker.ValidationErrors) > 0 {
 		err = multierror.Append(err, walker.ValidationErrors...)
 	}
 
@@ -495,11 +638,30 @@ func (c *Context) Apply() (*State, error) {
 func (c *Context) Plan() (*Plan, error) {
 	defer c.acquireRun("plan")()
 
+	if err := c.expandModule(); err != nil {
+		return nil, err
+	}
+
+	return c.plan()
+}
+
+// plan is the implementation of Plan. The caller must hold the run lock.
+func (c *Context) plan() (*Plan, error) {
+	// Move anything the configuration says was moved first, so that the
+	// plan and the apply after it see it at its new address.
+	moved, err := c.moveState()
+	if err != nil {
+		return nil, err
+	}
+
 	p := &Plan{
-		Module:  c.module,
-		Vars:    c.variables,
-		State:   c.state,
-		Targets: c.targets,
+		Module:        c.loadedModule,
+		Vars:          c.variables,
+		State:         c.state,
+		Targets:       c.targets,
+		TargetDepth:   c.targetDepth,
+		TargetModules: c.targetMods,
+		Moved:         moved,
 	}
 
 	var operation walkOperation
@@ -585,6 +747,10 @@ func (c *Context) Plan() (*Plan, error) {
 func (c *Context) Refresh() (*State, error) {
 	defer c.acquireRun("refresh")()
 
+	if err := c.expandModule(); err != nil {
+		return nil, err
+	}
+
 	// Copy our own state
 	c.state = c.state.DeepCopy()
 
@@ -641,7 +807,7 @@ func (c *Context) Validate() ([]string, []error) {
 	var errs error
 
 	// Validate the configuration itself
-	if err := c.module.Validate(); err != nil {
+	if err := c.loadedModule.Validate(); err != nil {
 		errs = multierror.Append(errs, err)
 	}
 
@@ -688,7 +854,7 @@ func (c *Context) Validate() ([]string, []error) {
 
 // Module returns the module tree associated with this context.
 func (c *Context) Module() *module.Tree {
-	return c.module
+	return c.loadedModule
 }
 
 // Variables will return the mapping of variables that were defined
@@ -757,6 +923,66 @@ func (c *Context) releaseRun() {
 	c.runContext = nil
 }
 
+// expandModule sets the module tree of the context to the loaded tree with
+// the modules that have a count expanded into their instances, using the
+// current values of the variables. This isn't done in NewContext so that
+// the counts can use variables that are only set by Input. Validate doesn't
+// need it, it validates the tree as it was loaded.
+func (c *Context) expandModule() error {
+	if c.loadedModule == nil {
+		return nil
+	}
+
+	mod, err := c.loadedModule.ExpandCount(c.variables)
+	if err != nil {
+		return err
+	}
+
+	c.module = mod
+	return nil
+}
+
+// moveState moves the items in the state that the moved blocks of the
+// configuration say were moved, and returns the moves that were made as
+// a map from the old address to the new one. The state is copied before
+// anything is moved, so the state the context was created with isn't
+// modified.
+func (c *Context) moveState() (map[string]string, error) {
+	moves, err := stateMoves(c.module)
+	if err != nil {
+		return nil, err
+	}
+	if len(moves) == 0 || c.state == nil {
+		return nil, nil
+	}
+
+	state := c.state.DeepCopy()
+	result := make(map[string]string)
+	for _, m := range moves {
+		ok, err := state.Move(m.From, m.To)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		result[m.From] = m.To
+		for _, h := range c.hooks {
+			if _, err := h.PostStateMove(m.From, m.To); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, nil
+	}
+
+	c.state = state
+	return result, nil
+}
+
 func (c *Context) walk(
 	graph, shadow *Graph, operation walkOperation) (*ContextGraphWalker, error) {
 	// Keep track of the "real" context which is the context that does
@@ -786,10 +1012,15 @@ func (c *Context) walk(
 
 	log.Printf("[DEBUG] Starting graph walk: %s", operation.String())
 
+	// Only applying is able to stop early on errors
+	stopOnError := c.stopOnError &&
+		(operation == walkApply || operation == walkDestroy)
+
 	walker := &ContextGraphWalker{
 		Context:     realCtx,
 		Operation:   operation,
 		StopContext: c.runContext,
+		StopOnError: stopOnError,
 	}
 
 	// Watch for a stop so we can call the provider Stop() API.
@@ -821,8 +1052,9 @@ func (c *Context) walk(
 		// we just want panics to be normal errors rather than to crash
 		// Terraform.
 		shadowWalker := GraphWalkerPanicwrap(&ContextGraphWalker{
-			Context:   shadowCtx,
-			Operation: operation,
+			Context:     shadowCtx,
+			Operation:   operation,
+			StopOnError: stopOnError,
 		})
 
 		// Kick off the shadow walk. This will block on any operations
@@ -140,6 +140,89 @@ test = []`)
 	}
 }
 
+func TestContext2Apply_countOneBareRef(t *testing.T) {
+	m := testModule(t, "apply-count-one-bare-ref")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(state.String())
+	expected := strings.TrimSpace(`aws_instance.bar:
+  ID = foo
+  foo = foo
+  type = aws_instance
+
+  Dependencies:
+    aws_instance.foo
+aws_instance.foo:
+  ID = foo
+  foo = foo
+  type = aws_instance
+
+Outputs:
+
+id = foo`)
+	if actual != expected {
+		t.Fatalf("expected: \n%s\n\ngot: \n%s\n", expected, actual)
+	}
+}
+
+func TestContext2Apply_countZeroBareRef(t *testing.T) {
+	m := testModule(t, "apply-count-zero-bare-ref")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(state.String())
+	expected := strings.TrimSpace(`aws_instance.bar:
+  ID = foo
+  bar = x
+  foo = 
+  type = aws_instance
+
+  Dependencies:
+    aws_instance.foo
+    aws_instance.foo.*
+
+Outputs:
+
+id = 
+ids =`)
+	if actual != expected {
+		t.Fatalf("expected: \n%s\n\ngot: \n%s\n", expected, actual)
+	}
+}
+
 func TestContext2Apply_resourceDependsOnModule(t *testing.T) {
 	m := testModule(t, "apply-resource-depends-on-module")
 	p := testProvider("aws")
@@ -578,6 +661,48 @@ func TestContext2Apply_providerAlias(t *testing.T) {
 	}
 }
 
+func TestContext2Apply_providerAliasCount(t *testing.T) {
+	m := testModule(t, "apply-provider-alias-count")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	mod := state.RootModule()
+	expected := map[string]string{
+		"aws_instance.foo.0": "aws.east",
+		"aws_instance.foo.1": "aws.west",
+		"aws_instance.foo.2": "aws.east",
+		"aws_instance.foo.3": "aws.west",
+	}
+	if len(mod.Resources) != len(expected) {
+		t.Fatalf("bad: %#v", mod.Resources)
+	}
+	for k, v := range expected {
+		rs, ok := mod.Resources[k]
+		if !ok {
+			t.Fatalf("missing %s: %#v", k, mod.Resources)
+		}
+		if rs.Provider != v {
+			t.Fatalf("%s: expected provider %q, got %q", k, v, rs.Provider)
+		}
+	}
+}
+
 // Two providers that are configured should both be configured prior to apply
 func TestContext2Apply_providerAliasConfigure(t *testing.T) {
 	m := testModule(t, "apply-provider-alias-configure")
@@ -1137,6 +1262,82 @@ aws_instance.bar:
 	`)
 }
 
+func TestContext2Apply_destroyDeposed(t *testing.T) {
+	m := testModule(t, "empty")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Deposed: []*InstanceState{
+							&InstanceState{
+								ID: "foo",
+							},
+						},
+					},
+				},
+			},
+			&ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.bar": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+						Deposed: []*InstanceState{
+							&InstanceState{
+								ID: "baz",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State:   state,
+		Destroy: true,
+	})
+
+	// The resource with only a deposed instance is destroyed too
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.Diff.String())
+	expected := strings.TrimSpace(`
+DESTROY: aws_instance.foo (deposed only)
+
+module.child:
+  DESTROY: aws_instance.bar`)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+
+	state, err = ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	checkStateString(t, state, `
+<no state>
+module.child:
+  <no state>`)
+}
+
 func TestContext2Apply_destroyComputed(t *testing.T) {
 	m := testModule(t, "apply-destroy-computed")
 	p := testProvider("aws")
@@ -2465,6 +2666,56 @@ module.child:
 	`)
 }
 
+func TestContext2Apply_moduleProviderPassedAlias(t *testing.T) {
+	m := testModule(t, "apply-module-provider-passed-alias")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+
+	var configured bool
+	var lock sync.Mutex
+	p.ConfigureFn = func(c *ResourceConfig) error {
+		if _, ok := c.Get("root"); ok {
+			return fmt.Errorf("child should not get root")
+		}
+
+		if _, ok := c.Get("west"); ok {
+			lock.Lock()
+			defer lock.Unlock()
+			configured = true
+		}
+
+		return nil
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !configured {
+		t.Fatal("child provider should inherit the aws.west configuration")
+	}
+
+	checkStateString(t, state, `
+<no state>
+module.child:
+  aws_instance.foo:
+    ID = foo
+	`)
+}
+
 func TestContext2Apply_moduleOrphanInheritAlias(t *testing.T) {
 	m := testModule(t, "apply-module-provider-inherit-alias-orphan")
 	p := testProvider("aws")
@@ -2927,6 +3178,30 @@ func TestContext2Apply_moduleBool(t *testing.T) {
 	}
 }
 
+func TestContext2Apply_moduleCount(t *testing.T) {
+	m := testModule(t, "apply-module-count")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	checkStateString(t, state, testTerraformApplyModuleCountStr)
+}
+
 // Tests that a module can be targeted and everything is properly created.
 // This adds to the plan test to also just verify that apply works.
 func TestContext2Apply_moduleTarget(t *testing.T) {
@@ -4775,14 +5050,31 @@ func TestContext2Apply_provisionerDestroyRef(t *testing.T) {
 	}
 }
 
-// Test that a destroy provisioner referencing an invalid key errors.
-func TestContext2Apply_provisionerDestroyRefInvalid(t *testing.T) {
-	m := testModule(t, "apply-provisioner-destroy-ref")
+// Test that the destroy provisioner of a resource runs only after the
+// resources that depend on it have been destroyed.
+func TestContext2Apply_provisionerDestroyOrder(t *testing.T) {
+	m := testModule(t, "apply-provisioner-destroy-order")
 	p := testProvider("aws")
 	pr := testProvisioner()
-	p.ApplyFn = testApplyFn
 	p.DiffFn = testDiffFn
+
+	var order []string
+	var orderLock sync.Mutex
+	p.ApplyFn = func(
+		info *InstanceInfo,
+		is *InstanceState,
+		id *InstanceDiff) (*InstanceState, error) {
+		orderLock.Lock()
+		defer orderLock.Unlock()
+
+		order = append(order, "destroy "+is.ID)
+		return nil, nil
+	}
 	pr.ApplyFn = func(rs *InstanceState, c *ResourceConfig) error {
+		orderLock.Lock()
+		defer orderLock.Unlock()
+
+		order = append(order, "provision "+rs.ID)
 		return nil
 	}
 
@@ -4791,11 +5083,163 @@ func TestContext2Apply_provisionerDestroyRefInvalid(t *testing.T) {
 			&ModuleState{
 				Path: rootModulePath,
 				Resources: map[string]*ResourceState{
-					"aws_instance.bar": &ResourceState{
-						Type: "aws_instance",
+					"aws_instance.a": &ResourceState{
+						Type:         "aws_instance",
+						Dependencies: []string{"aws_instance.b"},
 						Primary: &InstanceState{
-							ID: "bar",
-						},
+							ID: "a",
+							Attributes: map[string]string{
+								"foo": "b",
+							},
+						},
+					},
+
+					"aws_instance.b": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "b",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module:  m,
+		State:   state,
+		Destroy: true,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Provisioners: map[string]ResourceProvisionerFactory{
+			"shell": testProvisionerFuncFixed(pr),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	checkStateString(t, state, `<no state>`)
+
+	expected := []string{"destroy a", "provision b", "destroy b"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("bad: %#v", order)
+	}
+}
+
+// Same as TestContext2Apply_provisionerDestroyOrder, but with the
+// dependent resource in a child module that gets the ID through a variable.
+func TestContext2Apply_provisionerDestroyOrderModule(t *testing.T) {
+	m := testModule(t, "apply-provisioner-destroy-order-module")
+	p := testProvider("aws")
+	pr := testProvisioner()
+	p.DiffFn = testDiffFn
+
+	var order []string
+	var orderLock sync.Mutex
+	p.ApplyFn = func(
+		info *InstanceInfo,
+		is *InstanceState,
+		id *InstanceDiff) (*InstanceState, error) {
+		orderLock.Lock()
+		defer orderLock.Unlock()
+
+		order = append(order, "destroy "+is.ID)
+		return nil, nil
+	}
+	pr.ApplyFn = func(rs *InstanceState, c *ResourceConfig) error {
+		orderLock.Lock()
+		defer orderLock.Unlock()
+
+		order = append(order, "provision "+rs.ID)
+		return nil
+	}
+
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.b": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "b",
+						},
+					},
+				},
+			},
+
+			&ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.a": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "a",
+							Attributes: map[string]string{
+								"foo": "b",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module:  m,
+		State:   state,
+		Destroy: true,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Provisioners: map[string]ResourceProvisionerFactory{
+			"shell": testProvisionerFuncFixed(pr),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := ctx.Apply(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{"destroy a", "provision b", "destroy b"}
+	if !reflect.DeepEqual(order, expected) {
+		t.Fatalf("bad: %#v", order)
+	}
+}
+
+// Test that a destroy provisioner referencing an invalid key errors.
+func TestContext2Apply_provisionerDestroyRefInvalid(t *testing.T) {
+	m := testModule(t, "apply-provisioner-destroy-ref")
+	p := testProvider("aws")
+	pr := testProvisioner()
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	pr.ApplyFn = func(rs *InstanceState, c *ResourceConfig) error {
+		return nil
+	}
+
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.bar": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
 					},
 
 					"aws_instance.foo": &ResourceState{
@@ -4830,6 +5274,94 @@ func TestContext2Apply_provisionerDestroyRefInvalid(t *testing.T) {
 	}
 }
 
+// Provisioner output is given to the hooks line by line and attributed to
+// the right resource, even when it is written in chunks by provisioners
+// running in parallel.
+func TestContext2Apply_provisionerOutputLines(t *testing.T) {
+	m := testModule(t, "apply-provisioner-output-lines")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	p.ApplyFn = func(
+		info *InstanceInfo,
+		is *InstanceState,
+		id *InstanceDiff) (*InstanceState, error) {
+		return &InstanceState{ID: info.HumanId()}, nil
+	}
+	pr := &testChunkedOutputProvisioner{testProvisioner()}
+
+	h := &testProvisionOutputHook{Lines: make(map[string][]string)}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Hooks:  []Hook{h},
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Provisioners: map[string]ResourceProvisionerFactory{
+			"shell": func() (ResourceProvisioner, error) {
+				return pr, nil
+			},
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := ctx.Apply(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if len(h.Lines) != 3 {
+		t.Fatalf("expected output from 3 resources, got: %#v", h.Lines)
+	}
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("shell aws_instance.foo.%d", i)
+		name := fmt.Sprintf("aws_instance.foo.%d", i)
+		expected := []string{
+			"first line of " + name,
+			"second line of " + name,
+			"",
+			"unterminated line of " + name,
+		}
+		if actual := h.Lines[id]; !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("bad output for %s.\n\nexpected: %#v\n\ngot: %#v", id, expected, actual)
+		}
+	}
+}
+
+// testChunkedOutputProvisioner is a provisioner that sends its output in
+// chunks that don't line up with the lines of output.
+type testChunkedOutputProvisioner struct {
+	*MockResourceProvisioner
+}
+
+func (p *testChunkedOutputProvisioner) Apply(
+	output UIOutput, s *InstanceState, c *ResourceConfig) error {
+	output.Output("first line of " + s.ID + "\nsecond ")
+	output.Output("line of " + s.ID + "\r\n")
+	output.Output("\nunterminated ")
+	output.Output("line of " + s.ID)
+	return nil
+}
+
+// testProvisionOutputHook records the provisioner output for each
+// provisioner and resource.
+type testProvisionOutputHook struct {
+	NilHook
+
+	sync.Mutex
+	Lines map[string][]string
+}
+
+func (h *testProvisionOutputHook) ProvisionOutput(
+	info *InstanceInfo, provisioner string, line string) {
+	h.Lock()
+	defer h.Unlock()
+
+	key := provisioner + " " + info.HumanId()
+	h.Lines[key] = append(h.Lines[key], line)
+}
+
 func TestContext2Apply_provisionerResourceRef(t *testing.T) {
 	m := testModule(t, "apply-provisioner-resource-ref")
 	p := testProvider("aws")
@@ -6213,48 +6745,604 @@ func TestContext2Apply_error(t *testing.T) {
 	}
 }
 
-func TestContext2Apply_errorPartial(t *testing.T) {
-	errored := false
+func TestContext2Apply_stopOnError(t *testing.T) {
+	for _, stop := range []bool{false, true} {
+		m := testModule(t, "apply-stop-on-error")
+		p := testProvider("aws")
+		p.DiffFn = testDiffFn
 
-	m := testModule(t, "apply-error")
-	p := testProvider("aws")
-	s := &State{
-		Modules: []*ModuleState{
-			&ModuleState{
-				Path: rootModulePath,
-				Resources: map[string]*ResourceState{
-					"aws_instance.bar": &ResourceState{
-						Type: "aws_instance",
-						Primary: &InstanceState{
-							ID: "bar",
-						},
-					},
-				},
+		var applied []string
+		var lock sync.Mutex
+		p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
+			lock.Lock()
+			defer lock.Unlock()
+
+			applied = append(applied, info.Id)
+			if info.Id == "aws_instance.bar" {
+				return nil, fmt.Errorf("error")
+			}
+
+			return testApplyFn(info, s, d)
+		}
+
+		ctx := testContext2(t, &ContextOpts{
+			Module: m,
+			Providers: map[string]ResourceProviderFactory{
+				"aws": testProviderFuncFixed(p),
 			},
-		},
+			Parallelism: 1, // To check ordering
+			StopOnError: stop,
+		})
+
+		if _, err := ctx.Plan(); err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		if _, err := ctx.Apply(); err == nil {
+			t.Fatalf("stop %t: should have error", stop)
+		}
+
+		if !stop {
+			// Independent resources are still applied after the failure
+			if len(applied) != 2 {
+				t.Fatalf("stop %t: bad: %#v", stop, applied)
+			}
+			continue
+		}
+
+		// Nothing may be started after the failure
+		if applied[len(applied)-1] != "aws_instance.bar" {
+			t.Fatalf("stop %t: bad: %#v", stop, applied)
+		}
+	}
+}
+
+func TestContext2Apply_stopOnErrorOutput(t *testing.T) {
+	m := testModule(t, "apply-stop-on-error-output")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	// foo is in progress when bar fails and finishes only afterwards, so
+	// its output is written after the failure.
+	fooStarted := make(chan struct{})
+	barFailed := make(chan struct{})
+	p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
+		if info.Id == "aws_instance.bar" {
+			<-fooStarted
+			close(barFailed)
+			return nil, fmt.Errorf("error")
+		}
+
+		close(fooStarted)
+		<-barFailed
+		time.Sleep(50 * time.Millisecond)
+		return testApplyFn(info, s, d)
 	}
+
 	ctx := testContext2(t, &ContextOpts{
 		Module: m,
 		Providers: map[string]ResourceProviderFactory{
 			"aws": testProviderFuncFixed(p),
 		},
-		State: s,
+		StopOnError: true,
 	})
 
-	p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
-		if errored {
-			return s, fmt.Errorf("error")
-		}
-		errored = true
-
-		return &InstanceState{
-			ID: "foo",
-			Attributes: map[string]string{
-				"num": "2",
-			},
-		}, nil
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
 	}
-	p.DiffFn = func(*InstanceInfo, *InstanceState, *ResourceConfig) (*InstanceDiff, error) {
+
+	state, err := ctx.Apply()
+	if err == nil {
+		t.Fatal("should have error")
+	}
+
+	// Outputs of the resources that completed are still written
+	actual := state.RootModule().Outputs["foo_num"]
+	if actual == nil || actual.Value != "2" {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
+func TestContext2Apply_retryFailed(t *testing.T) {
+	cases := []struct {
+		Name      string
+		Retries   int
+		Retryable bool
+		Err       bool
+		Applied   int
+	}{
+		{"retried", 1, true, false, 2},
+		{"no retries", 0, true, true, 1},
+		{"not retryable", 1, false, true, 1},
+	}
+
+	for _, tc := range cases {
+		m := testModule(t, "apply-retry-failed")
+		p := testProvider("aws")
+		p.DiffFn = testDiffFn
+
+		var applied int
+		var lock sync.Mutex
+		p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
+			if info.Id == "aws_instance.bar" {
+				lock.Lock()
+				applied++
+				first := applied == 1
+				lock.Unlock()
+
+				if first {
+					err := fmt.Errorf("transient")
+					if tc.Retryable {
+						err = NewRetryableError(err)
+					}
+					return nil, err
+				}
+			}
+
+			return testApplyFn(info, s, d)
+		}
+
+		ctx := testContext2(t, &ContextOpts{
+			Module: m,
+			Providers: map[string]ResourceProviderFactory{
+				"aws": testProviderFuncFixed(p),
+			},
+			RetryFailed: tc.Retries,
+		})
+
+		if _, err := ctx.Plan(); err != nil {
+			t.Fatalf("%s: err: %s", tc.Name, err)
+		}
+
+		state, err := ctx.Apply()
+		if (err != nil) != tc.Err {
+			t.Fatalf("%s: err: %s", tc.Name, err)
+		}
+		if applied != tc.Applied {
+			t.Fatalf("%s: bar applied %d times", tc.Name, applied)
+		}
+
+		// The dependent resource is only created once bar succeeds
+		mod := state.RootModule()
+		if _, ok := mod.Resources["aws_instance.foo"]; !ok {
+			t.Fatalf("%s: foo missing:\n%s", tc.Name, state)
+		}
+		if _, ok := mod.Resources["aws_instance.baz"]; ok == tc.Err {
+			t.Fatalf("%s: bad:\n%s", tc.Name, state)
+		}
+	}
+}
+
+func TestContext2Apply_retryFailedPlanError(t *testing.T) {
+	m := testModule(t, "apply-retry-failed")
+	p := testProvider("aws")
+
+	var applied int
+	var failPlan bool
+	var lock sync.Mutex
+	p.DiffFn = func(info *InstanceInfo, s *InstanceState, c *ResourceConfig) (*InstanceDiff, error) {
+		lock.Lock()
+		fail := failPlan && info.Id == "aws_instance.bar"
+		lock.Unlock()
+
+		if fail {
+			return nil, fmt.Errorf("diff failed")
+		}
+
+		return testDiffFn(info, s, c)
+	}
+	p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
+		if info.Id == "aws_instance.bar" {
+			lock.Lock()
+			applied++
+			failPlan = true
+			lock.Unlock()
+
+			return nil, NewRetryableError(fmt.Errorf("transient"))
+		}
+
+		return testApplyFn(info, s, d)
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		RetryFailed: 1,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Both the apply error and the planning error are reported
+	_, err := ctx.Apply()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	for _, expected := range []string{"transient", "diff failed"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in error: %s", expected, err)
+		}
+	}
+	if applied != 1 {
+		t.Fatalf("bar applied %d times", applied)
+	}
+}
+
+func TestContext2Apply_providerMeta(t *testing.T) {
+	m := testModule(t, "apply-good")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	p.ProviderMetaReturn = "1.2.3"
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The metadata must survive writing and reading the state
+	var buf bytes.Buffer
+	if err := WriteState(state, &buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err = ReadState(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, k := range []string{"aws_instance.foo", "aws_instance.bar"} {
+		rs := state.RootModule().Resources[k]
+		if rs == nil || rs.ProviderMeta != "1.2.3" {
+			t.Fatalf("bad %s:\n%s", k, state)
+		}
+	}
+}
+
+func TestContext2Apply_providerMetaUnsupported(t *testing.T) {
+	m := testModule(t, "apply-good")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+
+	// Hide the ResourceProviderMeta implementation of the mock
+	provider := struct{ ResourceProvider }{p}
+
+	// Metadata recorded by an earlier provider must not be kept
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type:         "aws_instance",
+						ProviderMeta: "1.2.3",
+						Primary: &InstanceState{
+							ID: "foo",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(provider),
+		},
+		State: state,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, k := range []string{"aws_instance.foo", "aws_instance.bar"} {
+		rs := state.RootModule().Resources[k]
+		if rs == nil || rs.ProviderMeta != "" {
+			t.Fatalf("bad %s:\n%s", k, state)
+		}
+	}
+}
+
+func TestContext2Apply_recordApplyTime(t *testing.T) {
+	m := testModule(t, "apply-record-apply-time")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+
+	past := "2000-01-01T00:00:00Z"
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.update": &ResourceState{
+						Type:        "aws_instance",
+						LastApplied: past,
+						Primary: &InstanceState{
+							ID: "update",
+							Attributes: map[string]string{
+								"foo": "old",
+							},
+						},
+					},
+					"aws_instance.noop": &ResourceState{
+						Type:        "aws_instance",
+						LastApplied: past,
+						Primary: &InstanceState{
+							ID: "noop",
+							Attributes: map[string]string{
+								"foo": "bar",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State:           state,
+		RecordApplyTime: true,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	before := time.Now().UTC().Truncate(time.Second)
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The time must survive writing and reading the state
+	var buf bytes.Buffer
+	if err := WriteState(state, &buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err = ReadState(&buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	mod := state.RootModule()
+	for _, k := range []string{"aws_instance.create", "aws_instance.update"} {
+		rs := mod.Resources[k]
+		if rs == nil {
+			t.Fatalf("missing %s:\n%s", k, state)
+		}
+		applied, err := time.Parse(time.RFC3339, rs.LastApplied)
+		if err != nil {
+			t.Fatalf("%s: bad last_applied %q: %s", k, rs.LastApplied, err)
+		}
+		if applied.Before(before) {
+			t.Fatalf("%s: last_applied %s is before the apply", k, applied)
+		}
+	}
+
+	// Resources that weren't changed keep the time they were last applied
+	if rs := mod.Resources["aws_instance.noop"]; rs == nil || rs.LastApplied != past {
+		t.Fatalf("bad noop:\n%#v", rs)
+	}
+}
+
+func TestContext2Apply_replaceTriggeredBy(t *testing.T) {
+	m := testModule(t, "apply-replace-triggered-by")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+
+	apply := func(state *State, vars map[string]interface{}) (*Diff, *State) {
+		ctx := testContext2(t, &ContextOpts{
+			Module: m,
+			Providers: map[string]ResourceProviderFactory{
+				"aws": testProviderFuncFixed(p),
+			},
+			State:     state,
+			Variables: vars,
+		})
+
+		plan, err := ctx.Plan()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		// The diff is cleared as it is applied
+		diff := plan.Diff.DeepCopy()
+
+		state, err = ctx.Apply()
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		return diff, state
+	}
+
+	// The triggers are recorded when the resource is created
+	_, state := apply(nil, nil)
+	initial := state.RootModule().Resources["aws_instance.foo"].ReplaceTriggers
+	if initial == "" {
+		t.Fatalf("replace triggers should be recorded:\n%s", state)
+	}
+
+	cases := []struct {
+		Name    string
+		Vars    map[string]interface{}
+		Replace bool
+	}{
+		{"unchanged", nil, false},
+		{"variable", map[string]interface{}{"version": "2"}, true},
+		{"resource", map[string]interface{}{"image": "b"}, true},
+	}
+
+	for _, tc := range cases {
+		diff, newState := apply(state.DeepCopy(), tc.Vars)
+
+		rd := diff.RootModule().Resources["aws_instance.foo"]
+		replaced := rd != nil && rd.RequiresNew() && rd.GetDestroy()
+		if replaced != tc.Replace {
+			t.Fatalf("%s: expected replace %t, got diff:\n%s", tc.Name, tc.Replace, diff)
+		}
+
+		// Resources without triggers are never replaced
+		if rd := diff.RootModule().Resources["aws_instance.bar"]; rd != nil {
+			t.Fatalf("%s: bad diff for aws_instance.bar:\n%s", tc.Name, diff)
+		}
+
+		recorded := newState.RootModule().Resources["aws_instance.foo"].ReplaceTriggers
+		if (recorded != initial) != tc.Replace {
+			t.Fatalf("%s: bad recorded triggers %q, initially %q", tc.Name, recorded, initial)
+		}
+	}
+}
+
+func TestContext2Apply_recordApplyTimeDisabled(t *testing.T) {
+	m := testModule(t, "apply-good")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	for _, k := range []string{"aws_instance.foo", "aws_instance.bar"} {
+		rs := state.RootModule().Resources[k]
+		if rs == nil || rs.LastApplied != "" {
+			t.Fatalf("bad %s:\n%#v", k, rs)
+		}
+	}
+}
+
+func TestContext2Apply_moduleBarriers(t *testing.T) {
+	m := testModule(t, "apply-module-barrier")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	var events []string
+	var lock sync.Mutex
+	record := func(e string) {
+		lock.Lock()
+		defer lock.Unlock()
+		events = append(events, e)
+	}
+	p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
+		module := info.ModulePath[len(info.ModulePath)-1]
+		record("start " + module)
+
+		// Give the other module a chance to start if it isn't serialized
+		time.Sleep(10 * time.Millisecond)
+
+		record("end " + module)
+		return testApplyFn(info, s, d)
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		ModuleBarriers: true,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if _, err := ctx.Apply(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := []string{
+		"start a", "start a", "end a", "end a",
+		"start b", "start b", "end b", "end b",
+	}
+	sort.Strings(events[:4])
+	sort.Strings(events[4:])
+	sort.Strings(expected[:4])
+	sort.Strings(expected[4:])
+	if !reflect.DeepEqual(events, expected) {
+		t.Fatalf("bad: %#v", events)
+	}
+}
+
+func TestContext2Apply_errorPartial(t *testing.T) {
+	errored := false
+
+	m := testModule(t, "apply-error")
+	p := testProvider("aws")
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.bar": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	p.ApplyFn = func(info *InstanceInfo, s *InstanceState, d *InstanceDiff) (*InstanceState, error) {
+		if errored {
+			return s, fmt.Errorf("error")
+		}
+		errored = true
+
+		return &InstanceState{
+			ID: "foo",
+			Attributes: map[string]string{
+				"num": "2",
+			},
+		}, nil
+	}
+	p.DiffFn = func(*InstanceInfo, *InstanceState, *ResourceConfig) (*InstanceDiff, error) {
 		return &InstanceDiff{
 			Attributes: map[string]*ResourceAttrDiff{
 				"num": &ResourceAttrDiff{
@@ -6420,6 +7508,54 @@ func TestContext2Apply_idAttr(t *testing.T) {
 	}
 }
 
+func TestContext2Apply_outputSensitivePropagate(t *testing.T) {
+	m := testModule(t, "apply-output-sensitive-propagate")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	cases := []struct {
+		Path      []string
+		Name      string
+		Value     string
+		Sensitive bool
+	}{
+		{rootModulePath, "leaked", "prefix-hunter2", true},
+		{rootModulePath, "plain", "foo", false},
+		{[]string{"root", "a"}, "value", "prefix-hunter2", true},
+		{[]string{"root", "a", "b"}, "value", "prefix-hunter2", true},
+	}
+	for _, tc := range cases {
+		mod := state.ModuleByPath(tc.Path)
+		if mod == nil {
+			t.Fatalf("no module %v:\n%s", tc.Path, state)
+		}
+
+		o := mod.Outputs[tc.Name]
+		if o == nil {
+			t.Fatalf("%v: no output %s:\n%s", tc.Path, tc.Name, state)
+		}
+		if o.Value != tc.Value || o.Sensitive != tc.Sensitive {
+			t.Fatalf("%v: bad output %s: %#v", tc.Path, tc.Name, o)
+		}
+	}
+}
+
 func TestContext2Apply_outputBasic(t *testing.T) {
 	m := testModule(t, "apply-output")
 	p := testProvider("aws")
@@ -6831,6 +7967,205 @@ aws_instance.foo:
 	`)
 }
 
+// Targets given when applying a saved plan apply only that part of it.
+func TestContext2Apply_targetedPlan(t *testing.T) {
+	m := testModule(t, "apply-targeted")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if n := len(plan.Diff.RootModule().Resources); n != 2 {
+		t.Fatalf("expected 2 resources in the plan, got %d:\n%s", n, plan)
+	}
+
+	ctx, err = plan.Context(&ContextOpts{
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Targets: []string{"aws_instance.foo"},
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	checkStateString(t, state, `
+aws_instance.foo:
+  ID = foo
+  num = 2
+  type = aws_instance
+	`)
+}
+
+// Targets given when applying a saved plan must be within the plan's own
+// targets, since the plan doesn't cover anything else.
+func TestContext2Apply_targetedPlanOutsideTargets(t *testing.T) {
+	m := testModule(t, "apply-targeted")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Targets: []string{"aws_instance.foo"},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	_, err = plan.Context(&ContextOpts{
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Targets: []string{"aws_instance.bar"},
+	})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "aws_instance.bar is not within the targets of the plan") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+// Providers only used by untargeted resources must not be configured, or
+// asked for input, in any of the walks of a targeted run.
+func TestContext2Apply_targetedUnusedProvider(t *testing.T) {
+	m := testModule(t, "apply-targeted-unused-provider")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	pDO := testProvider("do")
+	pDO.ApplyFn = testApplyFn
+	pDO.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+			"do":  testProviderFuncFixed(pDO),
+		},
+		Targets: []string{"aws_instance.foo"},
+		UIInput: new(MockUIInput),
+	})
+
+	if err := ctx.Input(InputModeProvider); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if pDO.InputCalled {
+		t.Fatal("unused provider should not be asked for input")
+	}
+
+	if _, err := ctx.Refresh(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !p.ConfigureCalled {
+		t.Fatal("targeted provider should be configured")
+	}
+	if pDO.ConfigureCalled {
+		t.Fatal("unused provider should not be configured")
+	}
+
+	checkStateString(t, state, `
+aws_instance.foo:
+  ID = foo
+  num = 2
+  type = aws_instance
+	`)
+}
+
+func TestContext2Apply_targetedDepth(t *testing.T) {
+	m := testModule(t, "apply-targeted-depth")
+	p := testProvider("aws")
+	p.ApplyFn = testApplyFn
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: &State{
+			Modules: []*ModuleState{
+				&ModuleState{
+					Path: rootModulePath,
+					Resources: map[string]*ResourceState{
+						"aws_instance.foo": &ResourceState{
+							Type: "aws_instance",
+							Primary: &InstanceState{
+								ID: "foo",
+								Attributes: map[string]string{
+									"num": "2",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Targets:     []string{"aws_instance.baz"},
+		TargetDepth: testIntPtr(1),
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The depth is kept in the plan for applying it later
+	if plan.TargetDepth == nil || *plan.TargetDepth != 1 {
+		t.Fatalf("bad: %#v", plan.TargetDepth)
+	}
+
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	checkStateString(t, state, `
+aws_instance.bar:
+  ID = foo
+  foo = 2
+  type = aws_instance
+
+  Dependencies:
+    aws_instance.foo
+aws_instance.baz:
+  ID = foo
+  foo = 2
+  type = aws_instance
+
+  Dependencies:
+    aws_instance.bar
+aws_instance.foo:
+  ID = foo
+  num = 2
+	`)
+}
+
 func TestContext2Apply_targetedCount(t *testing.T) {
 	m := testModule(t, "apply-targeted-count")
 	p := testProvider("aws")
@@ -0,0 +1,128 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestContext2Graph(t *testing.T) {
+	m := testModule(t, "context-graph")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.a": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID:         "a",
+							Attributes: map[string]string{"id": "a"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cases := []struct {
+		Type     GraphType
+		Expected string
+	}{
+		{GraphTypePlan, testContextGraphPlanStr},
+		{GraphTypePlanDestroy, testContextGraphPlanDestroyStr},
+		{GraphTypeApply, testContextGraphApplyStr},
+		{GraphTypeRefresh, testContextGraphRefreshStr},
+		{GraphTypeValidate, testContextGraphPlanStr},
+	}
+
+	for _, tc := range cases {
+		ctx := testContext2(t, &ContextOpts{
+			Module: m,
+			Providers: map[string]ResourceProviderFactory{
+				"aws": testProviderFuncFixed(p),
+			},
+			State: state.DeepCopy(),
+		})
+
+		// The apply graph is built from the diff of the plan
+		if tc.Type == GraphTypeApply {
+			if _, err := ctx.Plan(); err != nil {
+				t.Fatalf("err: %s", err)
+			}
+		}
+
+		g, err := ctx.Graph(tc.Type, nil)
+		if err != nil {
+			t.Fatalf("%s: err: %s", tc.Type, err)
+		}
+
+		actual := strings.TrimSpace(g.String())
+		expected := strings.TrimSpace(tc.Expected)
+		if actual != expected {
+			t.Fatalf("%s: bad:\n\n%s\n\nexpected:\n\n%s", tc.Type, actual, expected)
+		}
+	}
+}
+
+func TestContext2Graph_disableReduce(t *testing.T) {
+	m := testModule(t, "context-graph")
+	p := testProvider("aws")
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	g, err := ctx.Graph(GraphTypePlan, &ContextGraphOpts{
+		Validate:      true,
+		DisableReduce: true,
+	})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testContextGraphPlanRawStr)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s\n\nexpected:\n\n%s", actual, expected)
+	}
+}
+
+const testContextGraphPlanStr = `
+aws_instance.a
+  provider.aws
+aws_instance.b
+  aws_instance.a
+provider.aws
+`
+
+const testContextGraphPlanRawStr = `
+aws_instance.a
+  provider.aws
+aws_instance.b
+  aws_instance.a
+  provider.aws
+provider.aws
+`
+
+const testContextGraphPlanDestroyStr = `
+aws_instance.a
+`
+
+const testContextGraphApplyStr = `
+aws_instance.b
+  provider.aws
+meta.count-boundary (count boundary fixup)
+  aws_instance.b
+provider.aws
+`
+
+const testContextGraphRefreshStr = `
+aws_instance.a
+  provider.aws
+provider.aws
+`
@@ -35,6 +35,9 @@ type ImportTarget struct {
 // This operation is idempotent. If the requested resource is already
 // imported, no changes are made to the state.
 //
+// The targets don't depend on each other, so they are imported
+// concurrently up to the parallelism of the Context.
+//
 // Further, this operation also gracefully handles partial state. If during
 // an import there is a failure, all previously imported resources remain
 // imported.
@@ -49,7 +52,14 @@ func (c *Context) Import(opts *ImportOpts) (*State, error) {
 	// the Context.
 	module := opts.Module
 	if module == nil {
-		module = c.module
+		module = c.loadedModule
+	}
+	if module != nil {
+		var err error
+		module, err = module.ExpandCount(c.variables)
+		if err != nil {
+			return c.state, err
+		}
 	}
 
 	// Initialize our graph builder
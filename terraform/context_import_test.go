@@ -3,7 +3,9 @@ package terraform
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestContextImport_basic(t *testing.T) {
@@ -702,6 +704,130 @@ func TestContextImport_customProvider(t *testing.T) {
 	}
 }
 
+func TestContextImport_multipleTargets(t *testing.T) {
+	p := testProvider("aws")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Parallelism: 2,
+	})
+
+	// Each import waits a moment for another to start so that we can
+	// tell the imports run concurrently, but no more than the
+	// parallelism allows.
+	var l sync.Mutex
+	var active, max int
+	p.ImportStateFn = func(info *InstanceInfo, id string) ([]*InstanceState, error) {
+		l.Lock()
+		active++
+		if active > max {
+			max = active
+		}
+		l.Unlock()
+
+		for i := 0; i < 50; i++ {
+			l.Lock()
+			n := active
+			l.Unlock()
+			if n > 1 {
+				break
+			}
+
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		l.Lock()
+		active--
+		l.Unlock()
+
+		return []*InstanceState{
+			&InstanceState{
+				ID:        id,
+				Ephemeral: EphemeralState{Type: "aws_instance"},
+			},
+		}, nil
+	}
+
+	var targets []*ImportTarget
+	for _, name := range []string{"a", "b", "c", "d"} {
+		targets = append(targets, &ImportTarget{
+			Addr: "aws_instance." + name,
+			ID:   "i-" + name,
+		})
+	}
+
+	state, err := ctx.Import(&ImportOpts{Targets: targets})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if max != 2 {
+		t.Fatalf("expected 2 concurrent imports, got %d", max)
+	}
+
+	actual := strings.TrimSpace(state.String())
+	expected := strings.TrimSpace(testImportMultipleTargetsStr)
+	if actual != expected {
+		t.Fatalf("bad: \n%s", actual)
+	}
+}
+
+func TestContextImport_multipleTargetsError(t *testing.T) {
+	p := testProvider("aws")
+	ctx := testContext2(t, &ContextOpts{
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	p.ImportStateFn = func(info *InstanceInfo, id string) ([]*InstanceState, error) {
+		if id == "i-b" || id == "i-d" {
+			return nil, fmt.Errorf("%s not found", id)
+		}
+
+		return []*InstanceState{
+			&InstanceState{
+				ID:        id,
+				Ephemeral: EphemeralState{Type: "aws_instance"},
+			},
+		}, nil
+	}
+
+	var targets []*ImportTarget
+	for _, name := range []string{"a", "b", "c", "d"} {
+		targets = append(targets, &ImportTarget{
+			Addr: "aws_instance." + name,
+			ID:   "i-" + name,
+		})
+	}
+
+	state, err := ctx.Import(&ImportOpts{Targets: targets})
+	if err == nil {
+		t.Fatal("should error")
+	}
+	for _, name := range []string{"b", "d"} {
+		expected := fmt.Sprintf(
+			"import aws_instance.%s (id: i-%s): i-%s not found", name, name, name)
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("expected %q in error: %s", expected, err)
+		}
+	}
+
+	// The imports that succeeded are still in the state
+	for _, name := range []string{"a", "c"} {
+		rs := state.RootModule().Resources["aws_instance."+name]
+		if rs == nil || rs.Primary.ID != "i-"+name {
+			t.Fatalf("bad: %s", state)
+		}
+	}
+	for _, name := range []string{"b", "d"} {
+		if rs := state.RootModule().Resources["aws_instance."+name]; rs != nil {
+			t.Fatalf("bad: %s", state)
+		}
+	}
+}
+
 const testImportStr = `
 aws_instance.foo:
   ID = foo
@@ -787,3 +913,18 @@ aws_instance.foo:
   ID = foo
   provider = aws.alias
 `
+
+const testImportMultipleTargetsStr = `
+aws_instance.a:
+  ID = i-a
+  provider = aws
+aws_instance.b:
+  ID = i-b
+  provider = aws
+aws_instance.c:
+  ID = i-c
+  provider = aws
+aws_instance.d:
+  ID = i-d
+  provider = aws
+`
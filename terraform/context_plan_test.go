@@ -2,6 +2,7 @@ package terraform
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -422,6 +423,167 @@ func TestContext2Plan_moduleOrphans(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_moduleCount(t *testing.T) {
+	m := testModule(t, "plan-module-count")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	// The instances beyond the count are destroyed
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: []string{"root", "child[0]"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+							Attributes: map[string]string{
+								"foo": "web-0",
+							},
+						},
+					},
+				},
+			},
+			&ModuleState{
+				Path: []string{"root", "child[1]"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "baz",
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+		Variables: map[string]interface{}{
+			"num": "1",
+		},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(testTerraformPlanModuleCountStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+func TestContext2Plan_moduleCountTarget(t *testing.T) {
+	m := testModule(t, "plan-module-count")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Variables: map[string]interface{}{
+			"num": "2",
+		},
+		Targets: []string{"module.child[1]"},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(`
+DIFF:
+
+module.child[1]:
+  CREATE: aws_instance.foo
+    foo:  "" => "web-1"
+    type: "" => "aws_instance"
+
+STATE:
+
+<no state>`)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+func TestContext2Plan_moduleCountUnknown(t *testing.T) {
+	m := testModule(t, "plan-module-count")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "module child: the count must be known") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestContext2Plan_moduleCountInput(t *testing.T) {
+	m := testModule(t, "plan-module-count")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	input := new(MockUIInput)
+	input.InputReturnMap = map[string]string{
+		"var.num": "2",
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		UIInput: input,
+	})
+
+	if err := ctx.Input(InputModeStd); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(`
+DIFF:
+
+module.child[0]:
+  CREATE: aws_instance.foo
+    foo:  "" => "web-0"
+    type: "" => "aws_instance"
+module.child[1]:
+  CREATE: aws_instance.foo
+    foo:  "" => "web-1"
+    type: "" => "aws_instance"
+
+STATE:
+
+<no state>`)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
 // https://github.com/hashicorp/terraform/issues/3114
 func TestContext2Plan_moduleOrphansWithProvisioner(t *testing.T) {
 	m := testModule(t, "plan-modules-remove-provisioners")
@@ -783,6 +945,26 @@ func TestContext2Plan_moduleVarWrongTypeNested(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_moduleVarValidation(t *testing.T) {
+	m := testModule(t, "plan-module-var-validation")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatalf("should error")
+	}
+	if !strings.Contains(err.Error(), "does not match the validation regex") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
 func TestContext2Plan_moduleVarWithDefaultValue(t *testing.T) {
 	m := testModule(t, "plan-module-var-with-default-value")
 	p := testProvider("null")
@@ -823,6 +1005,29 @@ func TestContext2Plan_moduleVarComputed(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_moduleComputedOutputFuncs(t *testing.T) {
+	m := testModule(t, "plan-module-computed-output-funcs")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(testTerraformPlanModuleComputedOutputFuncsStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
 func TestContext2Plan_nil(t *testing.T) {
 	m := testModule(t, "plan-nil")
 	p := testProvider("aws")
@@ -1641,6 +1846,62 @@ func TestContext2Plan_countZero(t *testing.T) {
 	}
 }
 
+func TestContext2Plan_outputMissingResource(t *testing.T) {
+	m := testModule(t, "plan-output-missing-resource")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+
+	// The resource was removed from the configuration but is still in the
+	// state, which must not hide the broken reference.
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.gone": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "bar",
+						},
+					},
+				},
+				Outputs: map[string]*OutputState{},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "aws_instance.gone") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestContext2Plan_outputCountZero(t *testing.T) {
+	m := testModule(t, "plan-output-count-zero")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestContext2Plan_countOneIndex(t *testing.T) {
 	m := testModule(t, "plan-count-one-index")
 	p := testProvider("aws")
@@ -2519,16 +2780,16 @@ STATE:
 	}
 }
 
-func TestContext2Plan_targetedModuleWithProvider(t *testing.T) {
-	m := testModule(t, "plan-targeted-module-with-provider")
-	p := testProvider("null")
+func TestContext2Plan_targetModules(t *testing.T) {
+	m := testModule(t, "plan-target-modules")
+	p := testProvider("aws")
 	p.DiffFn = testDiffFn
 	ctx := testContext2(t, &ContextOpts{
 		Module: m,
 		Providers: map[string]ResourceProviderFactory{
-			"null": testProviderFuncFixed(p),
+			"aws": testProviderFuncFixed(p),
 		},
-		Targets: []string{"module.child2"},
+		TargetModules: []string{"module.A"},
 	})
 
 	plan, err := ctx.Plan()
@@ -2540,8 +2801,21 @@ func TestContext2Plan_targetedModuleWithProvider(t *testing.T) {
 	expected := strings.TrimSpace(`
 DIFF:
 
-module.child2:
-  CREATE: null_resource.foo
+CREATE: aws_instance.dep
+  foo:  "" => "dep"
+  type: "" => "aws_instance"
+
+module.A:
+  CREATE: aws_instance.foo
+    foo:  "" => "dep"
+    type: "" => "aws_instance"
+module.A.child:
+  CREATE: aws_instance.nested.0
+    foo:  "" => "nested"
+    type: "" => "aws_instance"
+  CREATE: aws_instance.nested.1
+    foo:  "" => "nested"
+    type: "" => "aws_instance"
 
 STATE:
 
@@ -2552,8 +2826,8 @@ STATE:
 	}
 }
 
-func TestContext2Plan_targetedOrphan(t *testing.T) {
-	m := testModule(t, "plan-targeted-orphan")
+func TestContext2Plan_targetModulesWithTargets(t *testing.T) {
+	m := testModule(t, "plan-target-modules")
 	p := testProvider("aws")
 	p.DiffFn = testDiffFn
 	ctx := testContext2(t, &ContextOpts{
@@ -2561,29 +2835,126 @@ func TestContext2Plan_targetedOrphan(t *testing.T) {
 		Providers: map[string]ResourceProviderFactory{
 			"aws": testProviderFuncFixed(p),
 		},
-		State: &State{
-			Modules: []*ModuleState{
-				&ModuleState{
-					Path: rootModulePath,
-					Resources: map[string]*ResourceState{
-						"aws_instance.orphan": &ResourceState{
-							Type: "aws_instance",
-							Primary: &InstanceState{
-								ID: "i-789xyz",
-							},
-						},
-						"aws_instance.nottargeted": &ResourceState{
-							Type: "aws_instance",
-							Primary: &InstanceState{
-								ID: "i-abc123",
-							},
-						},
-					},
-				},
-			},
-		},
-		Destroy: true,
-		Targets: []string{"aws_instance.orphan"},
+		Targets:       []string{"aws_instance.outside"},
+		TargetModules: []string{"module.A.module.child"},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(`
+DIFF:
+
+CREATE: aws_instance.outside
+  foo:  "" => "outside"
+  type: "" => "aws_instance"
+
+module.A.child:
+  CREATE: aws_instance.nested.0
+    foo:  "" => "nested"
+    type: "" => "aws_instance"
+  CREATE: aws_instance.nested.1
+    foo:  "" => "nested"
+    type: "" => "aws_instance"
+
+STATE:
+
+<no state>
+	`)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestContext2Plan_targetModulesInvalid(t *testing.T) {
+	m := testModule(t, "plan-target-modules")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		TargetModules: []string{"aws_instance.outside"},
+	})
+
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "not a module address") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestContext2Plan_targetedModuleWithProvider(t *testing.T) {
+	m := testModule(t, "plan-targeted-module-with-provider")
+	p := testProvider("null")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"null": testProviderFuncFixed(p),
+		},
+		Targets: []string{"module.child2"},
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(`
+DIFF:
+
+module.child2:
+  CREATE: null_resource.foo
+
+STATE:
+
+<no state>
+	`)
+	if actual != expected {
+		t.Fatalf("expected:\n%s\n\ngot:\n%s", expected, actual)
+	}
+}
+
+func TestContext2Plan_targetedOrphan(t *testing.T) {
+	m := testModule(t, "plan-targeted-orphan")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: &State{
+			Modules: []*ModuleState{
+				&ModuleState{
+					Path: rootModulePath,
+					Resources: map[string]*ResourceState{
+						"aws_instance.orphan": &ResourceState{
+							Type: "aws_instance",
+							Primary: &InstanceState{
+								ID: "i-789xyz",
+							},
+						},
+						"aws_instance.nottargeted": &ResourceState{
+							Type: "aws_instance",
+							Primary: &InstanceState{
+								ID: "i-abc123",
+							},
+						},
+					},
+				},
+			},
+		},
+		Destroy: true,
+		Targets: []string{"aws_instance.orphan"},
 	})
 
 	plan, err := ctx.Plan()
@@ -3095,3 +3466,357 @@ func TestContext2Plan_listOrder(t *testing.T) {
 		t.Fatal("aws_instance.a and aws_instance.b diffs should match:\n", plan)
 	}
 }
+
+func TestContext2Plan_moved(t *testing.T) {
+	m := testModule(t, "plan-moved")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	h := new(MockHook)
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.a": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "a",
+							Attributes: map[string]string{
+								"foo":  "bar",
+								"type": "aws_instance",
+							},
+						},
+					},
+					"aws_instance.c": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "c",
+							Attributes: map[string]string{
+								"foo":  "bar",
+								"type": "aws_instance",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Hooks:  []Hook{h},
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(testTerraformPlanMovedStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+
+	expectedMoved := map[string]string{
+		"aws_instance.a": "aws_instance.b",
+		"aws_instance.c": "aws_instance.d",
+	}
+	if !reflect.DeepEqual(plan.Moved, expectedMoved) {
+		t.Fatalf("bad: %#v", plan.Moved)
+	}
+
+	if !h.PostStateMoveCalled {
+		t.Fatal("should call PostStateMove")
+	}
+	if !reflect.DeepEqual(h.PostStateMoveFrom, []string{"aws_instance.a", "aws_instance.c"}) {
+		t.Fatalf("bad: %#v", h.PostStateMoveFrom)
+	}
+
+	// The state the context was created with must not be modified
+	if s.RootModule().Resources["aws_instance.a"] == nil {
+		t.Fatal("original state should not be modified")
+	}
+}
+
+func TestContext2Plan_movedModule(t *testing.T) {
+	m := testModule(t, "plan-moved-module")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.a": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "a",
+							Attributes: map[string]string{
+								"foo":  "bar",
+								"type": "aws_instance",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !plan.Diff.Empty() {
+		t.Fatalf("should be empty:\n%s", plan.Diff)
+	}
+
+	expectedMoved := map[string]string{
+		"module.child.aws_instance.a": "module.child.aws_instance.b",
+	}
+	if !reflect.DeepEqual(plan.Moved, expectedMoved) {
+		t.Fatalf("bad: %#v", plan.Moved)
+	}
+}
+
+func TestContext2Plan_movedExists(t *testing.T) {
+	m := testModule(t, "plan-moved-exists")
+	p := testProvider("aws")
+	p.DiffFn = testDiffFn
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.a": &ResourceState{
+						Type:    "aws_instance",
+						Primary: &InstanceState{ID: "a"},
+					},
+					"aws_instance.b": &ResourceState{
+						Type:    "aws_instance",
+						Primary: &InstanceState{ID: "b"},
+					},
+				},
+			},
+		},
+	}
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: s,
+	})
+
+	_, err := ctx.Plan()
+	if err == nil {
+		t.Fatal("should error")
+	}
+	if !strings.Contains(err.Error(), "already exists in the state") {
+		t.Fatalf("bad: %s", err)
+	}
+}
+
+func TestContext2Plan_stubProviders(t *testing.T) {
+	m := testModule(t, "plan-stub-providers")
+	ctx := testContext2(t, &ContextOpts{
+		Module:           m,
+		ProviderResolver: StubProviderResolver,
+	})
+
+	if w, e := ctx.Validate(); len(w) > 0 || len(e) > 0 {
+		t.Fatalf("bad: %#v %#v", w, e)
+	}
+
+	// Refresh first like the plan command does, which reads the data source
+	if _, err := ctx.Refresh(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.String())
+	expected := strings.TrimSpace(testTerraformPlanStubProvidersStr)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+func TestContext2Plan_stubProvidersNoop(t *testing.T) {
+	m := testModule(t, "plan-stub-providers")
+	ctx := testContext2(t, &ContextOpts{
+		Module:           m,
+		ProviderResolver: StubProviderResolver,
+	})
+
+	if _, err := ctx.Plan(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	state, err := ctx.Apply()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// Planning again from the applied state has nothing left to do
+	ctx = testContext2(t, &ContextOpts{
+		Module:           m,
+		State:            state,
+		ProviderResolver: StubProviderResolver,
+	})
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !plan.Diff.Empty() {
+		t.Fatalf("should be empty:\n%s", plan.Diff)
+	}
+}
+
+// testEquivalentProvider is a provider that treats JSON values as
+// equivalent if they are the same document.
+type testEquivalentProvider struct {
+	*MockResourceProvider
+}
+
+func (p *testEquivalentProvider) Equivalent(attr, old, new string) bool {
+	var oldValue, newValue interface{}
+	if err := json.Unmarshal([]byte(old), &oldValue); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(new), &newValue); err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(oldValue, newValue)
+}
+
+func testEquivalentState(policy string) *State {
+	return &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "foo",
+							Attributes: map[string]string{
+								"id":     "foo",
+								"policy": policy,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// testEquivalentDiffFn diffs the policy against the state, with its old
+// value set so that the provider can compare the two.
+func testEquivalentDiffFn(
+	info *InstanceInfo, s *InstanceState, c *ResourceConfig) (*InstanceDiff, error) {
+	old := s.Attributes["policy"]
+	policy := c.Config["policy"].(string)
+	if old == policy {
+		return nil, nil
+	}
+
+	return &InstanceDiff{
+		Attributes: map[string]*ResourceAttrDiff{
+			"policy": &ResourceAttrDiff{
+				Old:         old,
+				New:         policy,
+				RequiresNew: true,
+			},
+		},
+	}, nil
+}
+
+func TestContext2Plan_equivalent(t *testing.T) {
+	m := testModule(t, "plan-equivalent")
+	p := &testEquivalentProvider{testProvider("aws")}
+	p.DiffFn = testEquivalentDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: testEquivalentState(`{"a":[2,3],"b":1}`),
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if !plan.Diff.Empty() {
+		t.Fatalf("should be empty:\n%s", plan.Diff)
+	}
+}
+
+func TestContext2Plan_equivalentDifferent(t *testing.T) {
+	m := testModule(t, "plan-equivalent")
+	p := &testEquivalentProvider{testProvider("aws")}
+	p.DiffFn = testEquivalentDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: testEquivalentState(`{"a":[3,2],"b":1}`),
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(plan.Diff.String())
+	expected := strings.TrimSpace(`
+DESTROY/CREATE: aws_instance.foo
+  policy: "{\"a\":[3,2],\"b\":1}" => "{\"b\": 1, \"a\": [2, 3]}" (forces new resource)
+`)
+	if actual != expected {
+		t.Fatalf("bad:\n%s", actual)
+	}
+}
+
+// A provider that doesn't implement ResourceProviderEquivalence keeps the
+// diff of an equivalent value.
+func TestContext2Plan_equivalentUnsupported(t *testing.T) {
+	m := testModule(t, "plan-equivalent")
+	p := testProvider("aws")
+	p.DiffFn = testEquivalentDiffFn
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: testEquivalentState(`{"a":[2,3],"b":1}`),
+	})
+
+	plan, err := ctx.Plan()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if plan.Diff.Empty() {
+		t.Fatal("should not be empty")
+	}
+}
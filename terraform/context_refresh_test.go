@@ -1,11 +1,14 @@
 package terraform
 
 import (
+	"fmt"
 	"reflect"
 	"sort"
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/hashicorp/terraform/config"
 )
 
 func TestContext2Refresh(t *testing.T) {
@@ -277,6 +280,59 @@ func TestContext2Refresh_delete(t *testing.T) {
 	}
 }
 
+func TestContext2Refresh_deposed(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "refresh-basic")
+	ctx := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: &State{
+			Modules: []*ModuleState{
+				&ModuleState{
+					Path: rootModulePath,
+					Resources: map[string]*ResourceState{
+						"aws_instance.web": &ResourceState{
+							Type: "aws_instance",
+							Primary: &InstanceState{
+								ID: "foo",
+							},
+							Deposed: []*InstanceState{
+								&InstanceState{ID: "gone"},
+								&InstanceState{ID: "old"},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// The deposed instance "gone" no longer exists
+	p.RefreshFn = func(
+		info *InstanceInfo, s *InstanceState) (*InstanceState, error) {
+		if s.ID == "gone" {
+			return nil, nil
+		}
+
+		return s, nil
+	}
+
+	s, err := ctx.Refresh()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	rs := s.RootModule().Resources["aws_instance.web"]
+	if rs == nil {
+		t.Fatal("resource should exist")
+	}
+	if len(rs.Deposed) != 1 || rs.Deposed[0].ID != "old" {
+		t.Fatalf("bad: %#v", rs.Deposed)
+	}
+}
+
 func TestContext2Refresh_ignoreUncreated(t *testing.T) {
 	p := testProvider("aws")
 	m := testModule(t, "refresh-basic")
@@ -546,6 +602,91 @@ func TestContext2Refresh_outputPartial(t *testing.T) {
 	}
 }
 
+func TestContext2Refresh_preserveOutputs(t *testing.T) {
+	for _, preserve := range []bool{true, false} {
+		p := testProvider("aws")
+		m := testModule(t, "refresh-preserve-outputs")
+		ctx := testContext2(t, &ContextOpts{
+			Module: m,
+			Providers: map[string]ResourceProviderFactory{
+				"aws": testProviderFuncFixed(p),
+			},
+			State: &State{
+				Modules: []*ModuleState{
+					&ModuleState{
+						Path: rootModulePath,
+						Resources: map[string]*ResourceState{
+							"aws_instance.foo": &ResourceState{
+								Type: "aws_instance",
+								Primary: &InstanceState{
+									ID: "foo",
+									Attributes: map[string]string{
+										"id": "foo",
+									},
+								},
+							},
+							"aws_instance.bar": &ResourceState{
+								Type: "aws_instance",
+								Primary: &InstanceState{
+									ID: "bar",
+									Attributes: map[string]string{
+										"id":  "bar",
+										"foo": "baz",
+									},
+								},
+							},
+						},
+						Outputs: map[string]*OutputState{
+							"foo_id": &OutputState{
+								Type:  "string",
+								Value: "foo",
+							},
+							"bar_foo": &OutputState{
+								Type:  "string",
+								Value: "baz",
+							},
+						},
+					},
+				},
+			},
+			PreserveOutputs: preserve,
+		})
+
+		// foo fails to refresh, and bar comes back without the attribute
+		// its output references.
+		p.RefreshFn = func(info *InstanceInfo, s *InstanceState) (*InstanceState, error) {
+			if info.Id == "aws_instance.foo" {
+				return nil, fmt.Errorf("refresh failed")
+			}
+
+			return &InstanceState{
+				ID:         s.ID,
+				Attributes: map[string]string{"id": s.ID},
+			}, nil
+		}
+
+		if _, err := ctx.Refresh(); err == nil {
+			t.Fatalf("preserve %t: should error", preserve)
+		} else if !strings.Contains(err.Error(), "refresh failed") {
+			t.Fatalf("preserve %t: bad: %s", preserve, err)
+		}
+
+		outputs := ctx.State().RootModule().Outputs
+		if o := outputs["foo_id"]; o == nil || o.Value != "foo" {
+			t.Fatalf("preserve %t: bad foo_id: %#v", preserve, o)
+		}
+
+		o := outputs["bar_foo"]
+		if preserve {
+			if o == nil || o.Value != "baz" {
+				t.Fatalf("bad bar_foo: %#v", o)
+			}
+		} else if o != nil && o.Value != config.UnknownVariableValue {
+			t.Fatalf("bar_foo should not be preserved: %#v", o)
+		}
+	}
+}
+
 func TestContext2Refresh_stateBasic(t *testing.T) {
 	p := testProvider("aws")
 	m := testModule(t, "refresh-basic")
@@ -992,3 +1133,70 @@ func TestContext2Validate(t *testing.T) {
 		t.Fatalf("bad: %s", e)
 	}
 }
+
+func TestContext2Refresh_replaceTriggeredBy(t *testing.T) {
+	p := testProvider("aws")
+	p.RefreshFn = func(info *InstanceInfo, s *InstanceState) (*InstanceState, error) {
+		return s, nil
+	}
+
+	// The resources were created before replace_triggered_by was set
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.image": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID:         "image",
+							Attributes: map[string]string{"foo": "a"},
+						},
+					},
+					"aws_instance.foo": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID:         "foo",
+							Attributes: map[string]string{"foo": "bar"},
+						},
+					},
+					"aws_instance.bar": &ResourceState{
+						Type:            "aws_instance",
+						ReplaceTriggers: "keep",
+						Primary: &InstanceState{
+							ID:         "bar",
+							Attributes: map[string]string{"foo": "bar"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := testContext2(t, &ContextOpts{
+		Module: testModule(t, "apply-replace-triggered-by"),
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		State: state,
+	})
+
+	s, err := ctx.Refresh()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	// The triggers of resources configured with them start to be tracked
+	mod := s.RootModule()
+	if mod.Resources["aws_instance.foo"].ReplaceTriggers == "" {
+		t.Fatalf("replace triggers should be recorded:\n%s", s)
+	}
+	if v := mod.Resources["aws_instance.image"].ReplaceTriggers; v != "" {
+		t.Fatalf("bad: %q", v)
+	}
+
+	// Resources without the argument are left alone
+	if v := mod.Resources["aws_instance.bar"].ReplaceTriggers; v != "keep" {
+		t.Fatalf("bad: %q", v)
+	}
+}
@@ -2,6 +2,8 @@ package terraform
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 )
@@ -254,6 +256,30 @@ func TestContext2Validate_moduleGood(t *testing.T) {
 	}
 }
 
+func TestContext2Validate_moduleCount(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "plan-module-count")
+	c := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		Variables: map[string]interface{}{
+			"num": "2",
+		},
+	})
+
+	// The tree is validated before the module is expanded, so
+	// count.index must still be valid within the module block.
+	w, e := c.Validate()
+	if len(w) > 0 {
+		t.Fatalf("bad: %#v", w)
+	}
+	if len(e) > 0 {
+		t.Fatalf("bad: %#v", e)
+	}
+}
+
 func TestContext2Validate_moduleBadResource(t *testing.T) {
 	m := testModule(t, "validate-module-bad-rc")
 	p := testProvider("aws")
@@ -706,6 +732,31 @@ func TestContext2Validate_selfRefMultiAll(t *testing.T) {
 	}
 }
 
+// A resource attribute referencing another attribute of the same resource
+// is rejected outright rather than only warned about, since interpolating
+// it could never converge.
+func TestContext2Validate_selfRefTag(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "validate-self-ref-tag")
+	c := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	w, e := c.Validate()
+	if len(w) > 0 {
+		t.Fatalf("bad: %#v", w)
+	}
+	if len(e) != 1 {
+		t.Fatalf("bad: %#v", e)
+	}
+	if !strings.Contains(e[0].Error(), `self reference not allowed: "aws_instance.web.id"`) {
+		t.Fatalf("bad: %s", e[0])
+	}
+}
+
 func TestContext2Validate_tainted(t *testing.T) {
 	p := testProvider("aws")
 	m := testModule(t, "validate-good")
@@ -937,3 +988,71 @@ func TestContext2Validate_PlanGraphBuilder(t *testing.T) {
 		t.Fatal(walker.ValidationErrors)
 	}
 }
+
+func TestContext2Validate_unknownAttribute(t *testing.T) {
+	p := testProvider("aws")
+	p.ResourcesReturn = []ResourceType{
+		ResourceType{
+			Name:       "aws_instance",
+			Attributes: []string{"num"},
+		},
+	}
+	m := testModule(t, "validate-unknown-attr")
+	c := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	w, e := c.Validate()
+	if len(e) > 0 {
+		t.Fatalf("bad: %#v", e)
+	}
+	if len(w) != 1 || !strings.Contains(w[0], `unknown attribute "nmu"`) {
+		t.Fatalf("bad: %#v", w)
+	}
+}
+
+func TestContext2Validate_moduleUnusedVariable(t *testing.T) {
+	p := testProvider("aws")
+	m := testModule(t, "validate-module-unused-var")
+
+	// Unused variables are only reported when asked for
+	c := testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+	})
+
+	w, e := c.Validate()
+	if len(w) > 0 {
+		t.Fatalf("bad: %#v", w)
+	}
+	if len(e) > 0 {
+		t.Fatalf("bad: %#v", e)
+	}
+
+	c = testContext2(t, &ContextOpts{
+		Module: m,
+		Providers: map[string]ResourceProviderFactory{
+			"aws": testProviderFuncFixed(p),
+		},
+		WarnUnusedVariables: true,
+	})
+
+	w, e = c.Validate()
+	if len(e) > 0 {
+		t.Fatalf("bad: %#v", e)
+	}
+
+	expected := []string{
+		`module.child.var.unused: variable "unused" is declared but never referenced`,
+		`var.unused: variable "unused" is declared but never referenced`,
+	}
+	sort.Strings(w)
+	if !reflect.DeepEqual(w, expected) {
+		t.Fatalf("bad: %#v", w)
+	}
+}
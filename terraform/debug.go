@@ -521,3 +521,20 @@ func (*DebugHook) PostImportState(ii *InstanceInfo, iss []*InstanceState) (HookA
 func (*DebugHook) PostStateUpdate(*State) (HookAction, error) {
 	return HookActionContinue, nil
 }
+
+func (*DebugHook) PostStateMove(from, to string) (HookAction, error) {
+	if dbug == nil {
+		return HookActionContinue, nil
+	}
+
+	dbug.WriteFile("hook-PostStateMove", []byte(from+" -> "+to+"\n"))
+	return HookActionContinue, nil
+}
+
+func (*DebugHook) PreStateWrite(*State) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*DebugHook) PostStateWrite(*State) (HookAction, error) {
+	return HookActionContinue, nil
+}
@@ -85,6 +85,9 @@ func (n *EvalApply) Eval(ctx EvalContext) (interface{}, error) {
 	if err != nil {
 		if n.Error != nil {
 			helpfulErr := fmt.Errorf("%s: %s", n.Info.Id, err.Error())
+			if IsRetryableError(err) {
+				helpfulErr = NewRetryableError(helpfulErr)
+			}
 			*n.Error = multierror.Append(*n.Error, helpfulErr)
 		} else {
 			return nil, err
@@ -317,7 +320,8 @@ func (n *EvalApplyProvisioners) apply(ctx EvalContext, provs []*config.Provision
 			}
 		}
 
-		// The output function
+		// The output function. Provisioners send their output in arbitrary
+		// chunks, so it is split into lines before it is given to the hooks.
 		outputFn := func(msg string) {
 			ctx.Hook(func(h Hook) (HookAction, error) {
 				h.ProvisionOutput(n.Info, prov.Type, msg)
@@ -326,8 +330,9 @@ func (n *EvalApplyProvisioners) apply(ctx EvalContext, provs []*config.Provision
 		}
 
 		// Invoke the Provisioner
-		output := CallbackUIOutput{OutputFn: outputFn}
-		applyErr := provisioner.Apply(&output, state, provConfig)
+		output := &lineUIOutput{OutputFn: outputFn}
+		applyErr := provisioner.Apply(output, state, provConfig)
+		output.Flush()
 
 		// Call post hook
 		hookErr := ctx.Hook(func(h Hook) (HookAction, error) {
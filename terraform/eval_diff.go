@@ -81,6 +81,10 @@ type EvalDiff struct {
 	// Resource is needed to fetch the ignore_changes list so we can
 	// filter user-requested ignored attributes from the diff.
 	Resource *config.Resource
+
+	// Replace, if set to true, forces the resource to be replaced even if
+	// there is no other diff. See EvalReplaceTriggered.
+	Replace *bool
 }
 
 // TODO: test
@@ -104,6 +108,20 @@ func (n *EvalDiff) Eval(ctx EvalContext) (interface{}, error) {
 	}
 	diffState.init()
 
+	// A forced replacement is handled the same as a tainted resource, so
+	// the provider returns the complete diff for a new resource. The
+	// planned diff is marked as tainted in that case, so the same is done
+	// when the diff is recomputed during apply.
+	replace := n.Replace != nil && *n.Replace
+	if n.Diff != nil && *n.Diff != nil && (*n.Diff).GetDestroyTainted() {
+		replace = true
+	}
+	replace = replace && diffState.ID != ""
+	if replace && !diffState.Tainted {
+		diffState = diffState.DeepCopy()
+		diffState.Tainted = true
+	}
+
 	// Diff!
 	diff, err := provider.Diff(n.Info, diffState, config)
 	if err != nil {
@@ -113,6 +131,10 @@ func (n *EvalDiff) Eval(ctx EvalContext) (interface{}, error) {
 		diff = new(InstanceDiff)
 	}
 
+	// Drop the changes between values the provider says are equivalent
+	// before anything looks at whether the diff requires a new resource.
+	suppressEquivalentDiffs(provider, diff)
+
 	// Set DestroyDeposed if we have deposed instances
 	_, err = readInstanceFromState(ctx, n.Name, nil, func(rs *ResourceState) (*InstanceState, error) {
 		if len(rs.Deposed) > 0 {
@@ -129,6 +151,9 @@ func (n *EvalDiff) Eval(ctx EvalContext) (interface{}, error) {
 	if n.Diff != nil {
 		diff.SetTainted((*n.Diff).GetDestroyTainted())
 	}
+	if replace {
+		diff.SetTainted(true)
+	}
 
 	// Require a destroy if there is an ID and it requires new.
 	if diff.RequiresNew() && state != nil && state.ID != "" {
@@ -308,6 +333,22 @@ func (n *EvalDiffDestroy) Eval(ctx EvalContext) (interface{}, error) {
 	return nil, nil
 }
 
+// EvalDiffDestroyDeposed is an EvalNode implementation that marks a diff to
+// also destroy the deposed instances of the resource, creating the diff if
+// there is none.
+type EvalDiffDestroyDeposed struct {
+	Diff **InstanceDiff
+}
+
+func (n *EvalDiffDestroyDeposed) Eval(ctx EvalContext) (interface{}, error) {
+	if *n.Diff == nil {
+		*n.Diff = new(InstanceDiff)
+	}
+	(*n.Diff).SetDestroyDeposed(true)
+
+	return nil, nil
+}
+
 // EvalDiffDestroyModule is an EvalNode implementation that writes the diff to
 // the full diff.
 type EvalDiffDestroyModule struct {
@@ -426,3 +467,24 @@ func (n *EvalWriteDiff) Eval(ctx EvalContext) (interface{}, error) {
 
 	return nil, nil
 }
+
+// suppressEquivalentDiffs removes the attributes of the diff whose old and
+// new values the provider says are equivalent, if the provider implements
+// ResourceProviderEquivalence.
+func suppressEquivalentDiffs(p ResourceProvider, diff *InstanceDiff) {
+	e, ok := p.(ResourceProviderEquivalence)
+	if !ok {
+		return
+	}
+
+	for k, attr := range diff.CopyAttributes() {
+		if attr == nil || attr.NewComputed || attr.NewRemoved || attr.Old == attr.New {
+			continue
+		}
+
+		if e.Equivalent(k, attr.Old, attr.New) {
+			log.Printf("[DEBUG] %s: old and new values are equivalent, ignoring the diff", k)
+			diff.DelAttribute(k)
+		}
+	}
+}
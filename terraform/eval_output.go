@@ -41,12 +41,23 @@ type EvalWriteOutput struct {
 	Name      string
 	Sensitive bool
 	Value     *config.RawConfig
+
+	// KeepPrevious, if true, leaves the value already in the state in place
+	// when the value can't be interpolated or isn't known, instead of
+	// replacing it with an unknown value that is later pruned.
+	KeepPrevious bool
 }
 
 // TODO: test
 func (n *EvalWriteOutput) Eval(ctx EvalContext) (interface{}, error) {
 	cfg, err := ctx.Interpolate(n.Value, nil)
 	if err != nil {
+		// A reference to a resource that isn't in the configuration will
+		// never resolve, so don't let the output quietly become empty.
+		if _, ok := err.(*resourceNotInConfigError); ok {
+			return nil, fmt.Errorf("output '%s': %s", n.Name, err)
+		}
+
 		// Log error but continue anyway
 		log.Printf("[WARN] Output interpolation %q failed: %s", n.Name, err)
 	}
@@ -66,6 +77,13 @@ func (n *EvalWriteOutput) Eval(ctx EvalContext) (interface{}, error) {
 		mod = state.AddModule(ctx.Path())
 	}
 
+	if n.KeepPrevious && (cfg == nil || cfg.IsComputed("value")) {
+		if prev, ok := mod.Outputs[n.Name]; ok && prev.Value != config.UnknownVariableValue {
+			log.Printf("[DEBUG] Output %q is unknown, keeping the previous value", n.Name)
+			return nil, nil
+		}
+	}
+
 	// Get the value from the config
 	var valueRaw interface{} = config.UnknownVariableValue
 	if cfg != nil {
@@ -23,6 +23,10 @@ type EvalBuildProviderConfig struct {
 	Provider string
 	Config   **ResourceConfig
 	Output   **ResourceConfig
+
+	// ParentProvider is the name of the provider to inherit configuration
+	// from in parent modules. If empty, Provider is used.
+	ParentProvider string
 }
 
 func (n *EvalBuildProviderConfig) Eval(ctx EvalContext) (interface{}, error) {
@@ -40,7 +44,11 @@ func (n *EvalBuildProviderConfig) Eval(ctx EvalContext) (interface{}, error) {
 	}
 
 	// Get the parent configuration if there is one
-	if parent := ctx.ParentProviderConfig(n.Provider); parent != nil {
+	parentName := n.ParentProvider
+	if parentName == "" {
+		parentName = n.Provider
+	}
+	if parent := ctx.ParentProviderConfig(parentName); parent != nil {
 		merged := cfg.raw.Merge(parent.raw)
 		cfg = NewResourceConfig(merged)
 	}
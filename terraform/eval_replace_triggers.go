@@ -0,0 +1,146 @@
+package terraform
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform/config"
+)
+
+// EvalReplaceTriggered is an EvalNode implementation that determines
+// whether a resource must be replaced because the values of its
+// replace_triggered_by lifecycle argument changed since it was last
+// applied.
+type EvalReplaceTriggered struct {
+	Name     string
+	Config   *config.RawConfig
+	Resource *Resource
+	Output   *bool
+}
+
+func (n *EvalReplaceTriggered) Eval(ctx EvalContext) (interface{}, error) {
+	*n.Output = false
+	if n.Config == nil {
+		return nil, nil
+	}
+
+	// Nothing to compare against until the triggers have been recorded
+	prev, err := readReplaceTriggers(ctx, n.Name)
+	if err != nil || prev == "" {
+		return nil, err
+	}
+
+	hash, computed, err := replaceTriggersHash(ctx, n.Config, n.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	// A value that isn't known yet is likely to change, for example the
+	// ID of a resource that is being replaced itself.
+	*n.Output = computed || hash != prev
+	return nil, nil
+}
+
+// EvalWriteReplaceTriggers is an EvalNode implementation that records a
+// hash of the values of the replace_triggered_by lifecycle argument in the
+// state of a resource. If the values aren't known yet, nothing is written.
+//
+// If OnlyIfUnset is true, a hash that was already recorded is kept. This
+// starts tracking the values of resources that existed before the
+// argument was set.
+type EvalWriteReplaceTriggers struct {
+	Name        string
+	Config      *config.RawConfig
+	Resource    *Resource
+	OnlyIfUnset bool
+}
+
+func (n *EvalWriteReplaceTriggers) Eval(ctx EvalContext) (interface{}, error) {
+	if n.OnlyIfUnset {
+		prev, err := readReplaceTriggers(ctx, n.Name)
+		if err != nil || prev != "" {
+			return nil, err
+		}
+	}
+
+	var hash string
+	if n.Config != nil {
+		var computed bool
+		var err error
+		hash, computed, err = replaceTriggersHash(ctx, n.Config, n.Resource)
+		if err != nil || computed {
+			return nil, err
+		}
+	}
+
+	state, lock := ctx.State()
+	if state == nil {
+		return nil, fmt.Errorf("cannot write state to nil state")
+	}
+
+	// Get a write lock so we can access this instance
+	lock.Lock()
+	defer lock.Unlock()
+
+	mod := state.ModuleByPath(ctx.Path())
+	if mod == nil {
+		return nil, nil
+	}
+
+	rs := mod.Resources[n.Name]
+	if rs == nil {
+		return nil, nil
+	}
+
+	rs.ReplaceTriggers = hash
+	return nil, nil
+}
+
+// readReplaceTriggers returns the hash of the replace_triggered_by values
+// recorded in the state of the resource with the given name. It is empty
+// if there is no such resource or it doesn't exist yet.
+func readReplaceTriggers(ctx EvalContext, name string) (string, error) {
+	state, lock := ctx.State()
+	if state == nil {
+		return "", fmt.Errorf("cannot read state from nil state")
+	}
+
+	lock.RLock()
+	defer lock.RUnlock()
+
+	mod := state.ModuleByPath(ctx.Path())
+	if mod == nil {
+		return "", nil
+	}
+
+	rs := mod.Resources[name]
+	if rs == nil || rs.Primary == nil || rs.Primary.ID == "" {
+		return "", nil
+	}
+
+	return rs.ReplaceTriggers, nil
+}
+
+// replaceTriggersHash interpolates the replace_triggered_by values and
+// returns a hash of the result. The second return value is true if any of
+// the values is computed, in which case no hash is returned.
+func replaceTriggersHash(
+	ctx EvalContext, c *config.RawConfig, r *Resource) (string, bool, error) {
+	rc, err := ctx.Interpolate(c.Copy(), r)
+	if err != nil {
+		return "", false, err
+	}
+	if len(rc.ComputedKeys) > 0 {
+		return "", true, nil
+	}
+
+	js, err := json.Marshal(rc.Config[c.Key])
+	if err != nil {
+		return "", false, err
+	}
+
+	sum := sha256.Sum256(js)
+	return hex.EncodeToString(sum[:]), false, nil
+}
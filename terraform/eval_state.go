@@ -1,6 +1,9 @@
 package terraform
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // EvalReadState is an EvalNode implementation that reads the
 // primary InstanceState for a specific resource out of the state.
@@ -142,6 +145,73 @@ func (n *EvalWriteState) Eval(ctx EvalContext) (interface{}, error) {
 	)
 }
 
+// EvalWriteProviderMeta is an EvalNode implementation that records the
+// metadata reported by the provider that applied a resource in its state.
+// The metadata is cleared if the provider doesn't report any.
+type EvalWriteProviderMeta struct {
+	Name     string
+	Provider *ResourceProvider
+}
+
+func (n *EvalWriteProviderMeta) Eval(ctx EvalContext) (interface{}, error) {
+	var meta string
+	if p, ok := (*n.Provider).(ResourceProviderMeta); ok {
+		meta = p.ProviderMeta()
+	}
+
+	state, lock := ctx.State()
+	if state == nil {
+		return nil, fmt.Errorf("cannot write state to nil state")
+	}
+
+	// Get a write lock so we can access this instance
+	lock.Lock()
+	defer lock.Unlock()
+
+	mod := state.ModuleByPath(ctx.Path())
+	if mod == nil {
+		return nil, nil
+	}
+
+	rs := mod.Resources[n.Name]
+	if rs == nil {
+		return nil, nil
+	}
+
+	rs.ProviderMeta = meta
+	return nil, nil
+}
+
+// EvalWriteLastApplied is an EvalNode implementation that records the
+// current time in the state of a resource as the time it was last applied.
+type EvalWriteLastApplied struct {
+	Name string
+}
+
+func (n *EvalWriteLastApplied) Eval(ctx EvalContext) (interface{}, error) {
+	state, lock := ctx.State()
+	if state == nil {
+		return nil, fmt.Errorf("cannot write state to nil state")
+	}
+
+	// Get a write lock so we can access this instance
+	lock.Lock()
+	defer lock.Unlock()
+
+	mod := state.ModuleByPath(ctx.Path())
+	if mod == nil {
+		return nil, nil
+	}
+
+	rs := mod.Resources[n.Name]
+	if rs == nil {
+		return nil, nil
+	}
+
+	rs.LastApplied = time.Now().UTC().Format(time.RFC3339)
+	return nil, nil
+}
+
 // EvalWriteStateDeposed is an EvalNode implementation that writes
 // an InstanceState out to the Deposed list of a resource in the state.
 type EvalWriteStateDeposed struct {
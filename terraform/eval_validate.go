@@ -2,6 +2,8 @@ package terraform
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
 
 	"github.com/hashicorp/terraform/config"
 )
@@ -58,6 +60,19 @@ RETURN:
 	return nil, err
 }
 
+// EvalValidateUnusedVariable is an EvalNode implementation that warns
+// about a module variable that nothing references.
+type EvalValidateUnusedVariable struct {
+	Name string
+}
+
+func (n *EvalValidateUnusedVariable) Eval(ctx EvalContext) (interface{}, error) {
+	return nil, &EvalValidateError{
+		Warnings: []string{fmt.Sprintf(
+			"variable %q is declared but never referenced", n.Name)},
+	}
+}
+
 // EvalValidateProvider is an EvalNode implementation that validates
 // the configuration of a resource.
 type EvalValidateProvider struct {
@@ -126,6 +141,7 @@ func (n *EvalValidateResource) Eval(ctx EvalContext) (interface{}, error) {
 	switch n.ResourceMode {
 	case config.ManagedResourceMode:
 		warns, errs = provider.ValidateResource(n.ResourceType, cfg)
+		warns = append(warns, n.unknownAttributes(provider, cfg, errs)...)
 	case config.DataResourceMode:
 		warns, errs = provider.ValidateDataSource(n.ResourceType, cfg)
 	}
@@ -147,3 +163,64 @@ func (n *EvalValidateResource) Eval(ctx EvalContext) (interface{}, error) {
 		Errors:   errs,
 	}
 }
+
+// unknownAttributes returns a warning for each top-level key in the
+// configuration that isn't one of the attributes the provider reports for
+// the resource type. These are warnings rather than errors since the
+// provider gets the final say in ValidateResource, so keys that any of
+// the errors errs from its validation mention aren't warned about again.
+//
+// This is done here rather than when the configuration is attached to the
+// graph since the attributes come from the provider, which is only
+// available once it's been configured during the walk.
+func (n *EvalValidateResource) unknownAttributes(
+	provider ResourceProvider, cfg *ResourceConfig, errs []error) []string {
+	if cfg == nil {
+		return nil
+	}
+
+	var known map[string]struct{}
+	for _, rt := range provider.Resources() {
+		if rt.Name != n.ResourceType || len(rt.Attributes) == 0 {
+			continue
+		}
+
+		known = make(map[string]struct{}, len(rt.Attributes))
+		for _, a := range rt.Attributes {
+			known[a] = struct{}{}
+		}
+		break
+	}
+	if known == nil {
+		return nil
+	}
+
+	keys := make([]string, 0, len(cfg.Raw))
+	for k, _ := range cfg.Raw {
+		if _, ok := known[k]; ok {
+			continue
+		}
+
+		mentioned := false
+		re := regexp.MustCompile(`\b` + regexp.QuoteMeta(k) + `\b`)
+		for _, err := range errs {
+			if re.MatchString(err.Error()) {
+				mentioned = true
+				break
+			}
+		}
+		if !mentioned {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	warns := make([]string, len(keys))
+	for i, k := range keys {
+		warns[i] = fmt.Sprintf(
+			"%s.%s: unknown attribute %q, it will be ignored",
+			n.ResourceType, n.ResourceName, k)
+	}
+
+	return warns
+}
@@ -2,6 +2,7 @@ package terraform
 
 import (
 	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -132,6 +133,65 @@ func TestEvalValidateResource_warningsAndErrorsPassedThrough(t *testing.T) {
 	}
 }
 
+func TestEvalValidateResource_unknownAttributes(t *testing.T) {
+	mp := testProvider("aws")
+	mp.ResourcesReturn = []ResourceType{
+		ResourceType{
+			Name:       "aws_instance",
+			Attributes: []string{"ami", "num"},
+		},
+	}
+
+	p := ResourceProvider(mp)
+	rc := testResourceConfig(t, map[string]interface{}{
+		"ami":  "foo",
+		"nmu":  "2",
+		"tags": "bar",
+	})
+	node := &EvalValidateResource{
+		Provider:     &p,
+		Config:       &rc,
+		ResourceName: "foo",
+		ResourceType: "aws_instance",
+		ResourceMode: config.ManagedResourceMode,
+	}
+
+	_, err := node.Eval(&MockEvalContext{})
+	if err == nil {
+		t.Fatal("Expected warnings, got none!")
+	}
+
+	verr := err.(*EvalValidateError)
+	expected := []string{
+		`aws_instance.foo: unknown attribute "nmu", it will be ignored`,
+		`aws_instance.foo: unknown attribute "tags", it will be ignored`,
+	}
+	if !reflect.DeepEqual(verr.Warnings, expected) {
+		t.Fatalf("bad: %#v", verr.Warnings)
+	}
+	if len(verr.Errors) != 0 {
+		t.Fatalf("bad: %#v", verr.Errors)
+	}
+}
+
+func TestEvalValidateResource_unknownAttributesNotReported(t *testing.T) {
+	mp := testProvider("aws")
+	p := ResourceProvider(mp)
+	rc := testResourceConfig(t, map[string]interface{}{"nmu": "2"})
+	node := &EvalValidateResource{
+		Provider:     &p,
+		Config:       &rc,
+		ResourceName: "foo",
+		ResourceType: "aws_instance",
+		ResourceMode: config.ManagedResourceMode,
+	}
+
+	_, err := node.Eval(&MockEvalContext{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
 func TestEvalValidateResource_checksResourceName(t *testing.T) {
 	mp := testProvider("aws")
 	p := ResourceProvider(mp)
@@ -37,8 +37,10 @@ func (n *EvalTypeCheckVariable) Eval(ctx EvalContext) (interface{}, error) {
 	targetConfig := currentTree.Config()
 
 	prototypes := make(map[string]config.VariableType)
+	variables := make(map[string]*config.Variable)
 	for _, variable := range targetConfig.Variables {
 		prototypes[variable.Name] = variable.Type()
+		variables[variable.Name] = variable
 	}
 
 	// Only display a module in an error message if we are not in the root module
@@ -63,6 +65,10 @@ func (n *EvalTypeCheckVariable) Eval(ctx EvalContext) (interface{}, error) {
 		case config.VariableTypeString:
 			switch proposedValue.(type) {
 			case string:
+				if err := variables[name].ValidateValue(proposedValue); err != nil {
+					return nil, fmt.Errorf("variable %s%s: %s",
+						name, modulePathDescription, err)
+				}
 				continue
 			default:
 				return nil, fmt.Errorf("variable %s%s should be type %s, got %s",
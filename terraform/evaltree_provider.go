@@ -5,8 +5,9 @@ import (
 )
 
 // ProviderEvalTree returns the evaluation tree for initializing and
-// configuring providers.
-func ProviderEvalTree(n string, config *config.RawConfig) EvalNode {
+// configuring providers. The parent is the name of the provider that
+// configuration is inherited from in parent modules, usually n itself.
+func ProviderEvalTree(n, parent string, config *config.RawConfig) EvalNode {
 	var provider ResourceProvider
 	var resourceConfig *ResourceConfig
 
@@ -27,9 +28,10 @@ func ProviderEvalTree(n string, config *config.RawConfig) EvalNode {
 					Output: &resourceConfig,
 				},
 				&EvalBuildProviderConfig{
-					Provider: n,
-					Config:   &resourceConfig,
-					Output:   &resourceConfig,
+					Provider:       n,
+					ParentProvider: parent,
+					Config:         &resourceConfig,
+					Output:         &resourceConfig,
 				},
 				&EvalInputProvider{
 					Name:     n,
@@ -53,9 +55,10 @@ func ProviderEvalTree(n string, config *config.RawConfig) EvalNode {
 					Output: &resourceConfig,
 				},
 				&EvalBuildProviderConfig{
-					Provider: n,
-					Config:   &resourceConfig,
-					Output:   &resourceConfig,
+					Provider:       n,
+					ParentProvider: parent,
+					Config:         &resourceConfig,
+					Output:         &resourceConfig,
 				},
 				&EvalValidateProvider{
 					Provider: &provider,
@@ -83,9 +86,10 @@ func ProviderEvalTree(n string, config *config.RawConfig) EvalNode {
 					Output: &resourceConfig,
 				},
 				&EvalBuildProviderConfig{
-					Provider: n,
-					Config:   &resourceConfig,
-					Output:   &resourceConfig,
+					Provider:       n,
+					ParentProvider: parent,
+					Config:         &resourceConfig,
+					Output:         &resourceConfig,
 				},
 				&EvalSetProviderConfig{
 					Provider: n,
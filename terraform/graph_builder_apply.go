@@ -34,6 +34,14 @@ type ApplyGraphBuilder struct {
 	// outputs should go into the diff so that this is unnecessary.
 	Targets []string
 
+	// TargetDepth, if non-nil, limits how many resources away from a
+	// target dependencies are included. See TargetsTransformer.Depth.
+	TargetDepth *int
+
+	// TargetModules are modules to target, including all the resources
+	// in their nested child modules. See TargetsTransformer.Modules.
+	TargetModules []string
+
 	// DisableReduce, if true, will not reduce the graph. Great for testing.
 	DisableReduce bool
 
@@ -42,6 +50,15 @@ type ApplyGraphBuilder struct {
 
 	// Validate will do structural validation of the graph.
 	Validate bool
+
+	// ModuleBarriers, if true, serializes the apply of child modules so
+	// that a module's resources only start once the previous module's
+	// resources are complete. See ModuleBarrierTransformer.
+	ModuleBarriers bool
+
+	// RecordApplyTime, if true, records the time each resource is created
+	// or updated in its state. See ResourceState.LastApplied.
+	RecordApplyTime bool
 }
 
 // See GraphBuilder
@@ -65,6 +82,7 @@ func (b *ApplyGraphBuilder) Steps() []GraphTransformer {
 	concreteResource := func(a *NodeAbstractResource) dag.Vertex {
 		return &NodeApplyableResource{
 			NodeAbstractResource: a,
+			RecordApplyTime:      b.RecordApplyTime,
 		}
 	}
 
@@ -88,10 +106,14 @@ func (b *ApplyGraphBuilder) Steps() []GraphTransformer {
 		&AttachStateTransformer{State: b.State},
 
 		// Create all the providers
-		&MissingProviderTransformer{Providers: b.Providers, Concrete: concreteProvider},
+		&MissingProviderTransformer{
+			Providers: b.Providers,
+			Concrete:  concreteProvider,
+			Module:    b.Module,
+		},
 		&ProviderTransformer{},
 		&DisableProviderTransformer{},
-		&ParentProviderTransformer{},
+		&ParentProviderTransformer{Module: b.Module},
 		&AttachProviderConfigTransformer{Module: b.Module},
 
 		// Destruction ordering
@@ -121,7 +143,19 @@ func (b *ApplyGraphBuilder) Steps() []GraphTransformer {
 		&CountBoundaryTransformer{},
 
 		// Target
-		&TargetsTransformer{Targets: b.Targets},
+		&TargetsTransformer{
+			Targets: b.Targets,
+			Depth:   b.TargetDepth,
+			Modules: b.TargetModules,
+		},
+
+		// Serialize modules. This must happen after targeting since the
+		// barriers would otherwise pull every resource of earlier modules
+		// into the targeted graph.
+		GraphTransformIf(
+			func() bool { return b.ModuleBarriers },
+			&ModuleBarrierTransformer{},
+		),
 
 		// Single root
 		&RootTransformer{},
@@ -20,6 +20,14 @@ type DestroyPlanGraphBuilder struct {
 	// Targets are resources to target
 	Targets []string
 
+	// TargetDepth, if non-nil, limits how many resources away from a
+	// target dependencies are included. See TargetsTransformer.Depth.
+	TargetDepth *int
+
+	// TargetModules are modules to target, including all the resources
+	// in their nested child modules. See TargetsTransformer.Modules.
+	TargetModules []string
+
 	// Validate will do structural validation of the graph.
 	Validate bool
 }
@@ -42,10 +50,12 @@ func (b *DestroyPlanGraphBuilder) Steps() []GraphTransformer {
 	}
 
 	steps := []GraphTransformer{
-		// Creates all the nodes represented in the state.
+		// Creates all the nodes represented in the state, including the
+		// deposed instances so that those are destroyed as well.
 		&StateTransformer{
 			Concrete: concreteResource,
 			State:    b.State,
+			Deposed:  true,
 		},
 
 		// Attach the configuration to any resources
@@ -57,7 +67,11 @@ func (b *DestroyPlanGraphBuilder) Steps() []GraphTransformer {
 
 		// Target. Note we don't set "Destroy: true" here since we already
 		// created proper destroy ordering.
-		&TargetsTransformer{Targets: b.Targets},
+		&TargetsTransformer{
+			Targets: b.Targets,
+			Depth:   b.TargetDepth,
+			Modules: b.TargetModules,
+		},
 
 		// Single root
 		&RootTransformer{},
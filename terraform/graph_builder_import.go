@@ -53,10 +53,14 @@ func (b *ImportGraphBuilder) Steps() []GraphTransformer {
 		&ImportStateTransformer{Targets: b.ImportTargets},
 
 		// Provider-related transformations
-		&MissingProviderTransformer{Providers: b.Providers, Concrete: concreteProvider},
+		&MissingProviderTransformer{
+			Providers: b.Providers,
+			Concrete:  concreteProvider,
+			Module:    mod,
+		},
 		&ProviderTransformer{},
 		&DisableProviderTransformer{},
-		&ParentProviderTransformer{},
+		&ParentProviderTransformer{Module: mod},
 		&AttachProviderConfigTransformer{Module: mod},
 
 		// This validates that the providers only depend on variables
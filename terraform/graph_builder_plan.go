@@ -34,6 +34,18 @@ type PlanGraphBuilder struct {
 	// Targets are resources to target
 	Targets []string
 
+	// TargetDepth, if non-nil, limits how many resources away from a
+	// target dependencies are included. See TargetsTransformer.Depth.
+	TargetDepth *int
+
+	// TargetModules are modules to target, including all the resources
+	// in their nested child modules. See TargetsTransformer.Modules.
+	TargetModules []string
+
+	// ReportUnusedVariables, if true, adds nodes for root and module
+	// variables that nothing references so they are reported during validation.
+	ReportUnusedVariables bool
+
 	// DisableReduce, if true, will not reduce the graph. Great for testing.
 	DisableReduce bool
 
@@ -91,10 +103,14 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 		&RootVariableTransformer{Module: b.Module},
 
 		// Create all the providers
-		&MissingProviderTransformer{Providers: b.Providers, Concrete: b.ConcreteProvider},
+		&MissingProviderTransformer{
+			Providers: b.Providers,
+			Concrete:  b.ConcreteProvider,
+			Module:    b.Module,
+		},
 		&ProviderTransformer{},
 		&DisableProviderTransformer{},
-		&ParentProviderTransformer{},
+		&ParentProviderTransformer{Module: b.Module},
 		&AttachProviderConfigTransformer{Module: b.Module},
 
 		// Provisioner-related transformations. Only add these if requested.
@@ -107,14 +123,25 @@ func (b *PlanGraphBuilder) Steps() []GraphTransformer {
 		),
 
 		// Add module variables
-		&ModuleVariableTransformer{Module: b.Module},
+		&ModuleVariableTransformer{
+			Module:       b.Module,
+			ReportUnused: b.ReportUnusedVariables,
+		},
+		GraphTransformIf(
+			func() bool { return b.ReportUnusedVariables },
+			&UnusedRootVariableTransformer{},
+		),
 
 		// Connect so that the references are ready for targeting. We'll
 		// have to connect again later for providers and so on.
 		&ReferenceTransformer{},
 
 		// Target
-		&TargetsTransformer{Targets: b.Targets},
+		&TargetsTransformer{
+			Targets: b.Targets,
+			Depth:   b.TargetDepth,
+			Modules: b.TargetModules,
+		},
 
 		// Single root
 		&RootTransformer{},
@@ -31,9 +31,28 @@ type RefreshGraphBuilder struct {
 	// Targets are resources to target
 	Targets []string
 
+	// TargetDepth, if non-nil, limits how many resources away from a
+	// target dependencies are included. See TargetsTransformer.Depth.
+	TargetDepth *int
+
+	// TargetModules are modules to target, including all the resources
+	// in their nested child modules. See TargetsTransformer.Modules.
+	TargetModules []string
+
+	// PreserveOutputs, if true, keeps the value an output has in State
+	// when its new value can't be determined. When a resource fails to
+	// refresh, the outputs that depend on it are either not evaluated or
+	// evaluated with unknown values, which would otherwise drop them from
+	// the state. The error is still reported.
+	PreserveOutputs bool
+
 	// DisableReduce, if true, will not reduce the graph. Great for testing.
 	DisableReduce bool
 
+	// DisableAttach, if true, will not attach the state or configuration
+	// to resources. Great for testing transforms in isolation.
+	DisableAttach bool
+
 	// Validate will do structural validation of the graph.
 	Validate bool
 }
@@ -71,10 +90,12 @@ func (b *RefreshGraphBuilder) Steps() []GraphTransformer {
 	}
 
 	steps := []GraphTransformer{
-		// Creates all the resources represented in the state
+		// Creates all the resources represented in the state, along with
+		// their deposed instances
 		&StateTransformer{
 			Concrete: concreteResource,
 			State:    b.State,
+			Deposed:  true,
 		},
 
 		// Creates all the data resources that aren't in the state
@@ -86,24 +107,35 @@ func (b *RefreshGraphBuilder) Steps() []GraphTransformer {
 			Mode:       config.DataResourceMode,
 		},
 
-		// Attach the state
-		&AttachStateTransformer{State: b.State},
-
-		// Attach the configuration to any resources
-		&AttachResourceConfigTransformer{Module: b.Module},
+		// Attach the state and the configuration to any resources, unless
+		// we've been asked not to.
+		GraphTransformIf(
+			func() bool { return !b.DisableAttach },
+			GraphTransformMulti(
+				&AttachStateTransformer{State: b.State},
+				&AttachResourceConfigTransformer{Module: b.Module},
+			),
+		),
 
 		// Add root variables
 		&RootVariableTransformer{Module: b.Module},
 
 		// Create all the providers
-		&MissingProviderTransformer{Providers: b.Providers, Concrete: concreteProvider},
+		&MissingProviderTransformer{
+			Providers: b.Providers,
+			Concrete:  concreteProvider,
+			Module:    b.Module,
+		},
 		&ProviderTransformer{},
 		&DisableProviderTransformer{},
-		&ParentProviderTransformer{},
+		&ParentProviderTransformer{Module: b.Module},
 		&AttachProviderConfigTransformer{Module: b.Module},
 
 		// Add the outputs
-		&OutputTransformer{Module: b.Module},
+		&OutputTransformer{
+			Module:       b.Module,
+			KeepPrevious: b.PreserveOutputs,
+		},
 
 		// Add module variables
 		&ModuleVariableTransformer{Module: b.Module},
@@ -113,7 +145,11 @@ func (b *RefreshGraphBuilder) Steps() []GraphTransformer {
 		&ReferenceTransformer{},
 
 		// Target
-		&TargetsTransformer{Targets: b.Targets},
+		&TargetsTransformer{
+			Targets: b.Targets,
+			Depth:   b.TargetDepth,
+			Modules: b.TargetModules,
+		},
 
 		// Single root
 		&RootTransformer{},
@@ -0,0 +1,68 @@
+package terraform
+
+import (
+	"testing"
+)
+
+func TestRefreshGraphBuilder_impl(t *testing.T) {
+	var _ GraphBuilder = new(RefreshGraphBuilder)
+}
+
+func TestRefreshGraphBuilder_disableAttach(t *testing.T) {
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.web": &ResourceState{
+						Type: "aws_instance",
+						Primary: &InstanceState{
+							ID: "foo",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	build := func(disable bool) *NodeRefreshableResource {
+		b := &RefreshGraphBuilder{
+			Module:        testModule(t, "refresh-basic"),
+			State:         state,
+			Providers:     []string{"aws"},
+			DisableAttach: disable,
+		}
+
+		g, err := b.Build(RootModulePath)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+
+		for _, v := range g.Vertices() {
+			if n, ok := v.(*NodeRefreshableResource); ok {
+				return n
+			}
+		}
+
+		t.Fatalf("no refreshable resource in graph:\n\n%s", g.String())
+		return nil
+	}
+
+	// By default the config and state are attached
+	n := build(false)
+	if n.Config == nil {
+		t.Fatal("config should be attached")
+	}
+	if n.ResourceState == nil {
+		t.Fatal("state should be attached")
+	}
+
+	// With attach disabled, neither should be set
+	n = build(true)
+	if n.Config != nil {
+		t.Fatalf("config should not be attached: %#v", n.Config)
+	}
+	if n.ResourceState != nil {
+		t.Fatalf("state should not be attached: %#v", n.ResourceState)
+	}
+}
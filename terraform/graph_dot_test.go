@@ -105,21 +105,56 @@ digraph {
 				return &g
 			},
 			Expect: `
+digraph {
+	compound = "true"
+	newrank = "true"
+	subgraph "root" {
+		"[root] A" [color = "red", penwidth = "2.0"]
+		"[root] B" [color = "red", penwidth = "2.0"]
+		"[root] C" [color = "red", penwidth = "2.0"]
+		"[root] root"
+		"[root] A" -> "[root] C" [color = "red", penwidth = "2.0"]
+		"[root] A" -> "[root] root"
+		"[root] B" -> "[root] A" [color = "red", penwidth = "2.0"]
+		"[root] C" -> "[root] B" [color = "red", penwidth = "2.0"]
+	}
+}
+					`,
+		},
+
+		{
+			Name: "no cycle",
+			Opts: dag.DotOpts{
+				DrawCycles: true,
+			},
+			Graph: func() *Graph {
+				var g Graph
+				root := &testDrawableOrigin{"root"}
+				g.Add(root)
+
+				vA := g.Add(&testDrawable{
+					VertexName: "A",
+				})
+
+				vB := g.Add(&testDrawable{
+					VertexName: "B",
+				})
+
+				g.Connect(dag.BasicEdge(vA, root))
+				g.Connect(dag.BasicEdge(vB, vA))
+
+				return &g
+			},
+			Expect: `
 digraph {
 	compound = "true"
 	newrank = "true"
 	subgraph "root" {
 		"[root] A"
 		"[root] B"
-		"[root] C"
 		"[root] root"
-		"[root] A" -> "[root] B" [color = "red", penwidth = "2.0"]
-		"[root] A" -> "[root] C"
 		"[root] A" -> "[root] root"
 		"[root] B" -> "[root] A"
-		"[root] B" -> "[root] C" [color = "red", penwidth = "2.0"]
-		"[root] C" -> "[root] A" [color = "red", penwidth = "2.0"]
-		"[root] C" -> "[root] B"
 	}
 }
 					`,
@@ -20,12 +20,19 @@ type ContextGraphWalker struct {
 	Operation   walkOperation
 	StopContext context.Context
 
+	// StopOnError, if true, skips the evaluation of any resource vertex
+	// entered after an evaluation has failed. Everything else, such as
+	// outputs and closing providers, is still evaluated.
+	StopOnError bool
+
 	// Outputs, do not set these. Do not read these while the graph
 	// is being walked.
 	ValidationWarnings []string
 	ValidationErrors   []error
 
 	errorLock           sync.Mutex
+	errored             bool
+	nonRetryable        bool
 	once                sync.Once
 	contexts            map[string]*BuiltinEvalContext
 	contextLock         sync.Mutex
@@ -106,6 +113,20 @@ func (w *ContextGraphWalker) EnterEvalTree(v dag.Vertex, n EvalNode) EvalNode {
 	// Acquire a lock on the semaphore
 	w.Context.parallelSem.Acquire()
 
+	// If something already failed, don't start any new resource operations.
+	// The vertex is still walked so that the semaphore is released on exit.
+	if _, ok := v.(GraphNodeResource); ok && w.StopOnError {
+		w.errorLock.Lock()
+		errored := w.errored
+		w.errorLock.Unlock()
+
+		if errored {
+			log.Printf("[INFO] [%s] Skipping %s due to earlier error",
+				w.Operation, dag.VertexName(v))
+			return &EvalNoop{}
+		}
+	}
+
 	// We want to filter the evaluation tree to only include operations
 	// that belong in this operation.
 	return EvalFilter(n, EvalNodeFilterOp(w.Operation))
@@ -131,6 +152,10 @@ func (w *ContextGraphWalker) ExitEvalTree(
 	// error, then just record the normal error.
 	verr, ok := err.(*EvalValidateError)
 	if !ok {
+		w.errored = true
+		if !IsRetryableError(err) {
+			w.nonRetryable = true
+		}
 		return err
 	}
 
@@ -148,6 +173,15 @@ func (w *ContextGraphWalker) ExitEvalTree(
 	return nil
 }
 
+// retryable returns true if the walk failed, and every failure was
+// retryable so that walking again may succeed.
+func (w *ContextGraphWalker) retryable() bool {
+	w.errorLock.Lock()
+	defer w.errorLock.Unlock()
+
+	return w.errored && !w.nonRetryable
+}
+
 func (w *ContextGraphWalker) init() {
 	w.contexts = make(map[string]*BuiltinEvalContext, 5)
 	w.providerCache = make(map[string]ResourceProvider, 5)
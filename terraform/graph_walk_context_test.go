@@ -0,0 +1,61 @@
+package terraform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+)
+
+func TestContextGraphWalker_stopOnError(t *testing.T) {
+	cases := []struct {
+		Stop bool
+		Err  error
+		Skip bool
+	}{
+		{false, errors.New("error"), false},
+		{true, nil, false},
+		{true, &EvalValidateError{Warnings: []string{"warn"}}, false},
+		{true, errors.New("error"), true},
+	}
+
+	for i, tc := range cases {
+		w := &ContextGraphWalker{
+			Context:     &Context{parallelSem: NewSemaphore(1)},
+			Operation:   walkApply,
+			StopOnError: tc.Stop,
+		}
+
+		first := testStopOnErrorResource("first")
+		second := testStopOnErrorResource("second")
+		n := &EvalOpFilter{Ops: []walkOperation{walkApply}, Node: &EvalNoop{}}
+		w.EnterEvalTree(first, n)
+		w.ExitEvalTree(first, nil, tc.Err)
+
+		actual := w.EnterEvalTree(second, n)
+		w.ExitEvalTree(second, nil, nil)
+
+		_, skipped := actual.(*EvalNoop)
+		if skipped != tc.Skip {
+			t.Fatalf("%d: bad: %#v", i, actual)
+		}
+
+		// Vertices other than resources are never skipped
+		actual = w.EnterEvalTree("output", n)
+		w.ExitEvalTree("output", nil, nil)
+
+		if _, skipped := actual.(*EvalNoop); skipped {
+			t.Fatalf("%d: output skipped", i)
+		}
+	}
+}
+
+func testStopOnErrorResource(name string) *NodeAbstractResource {
+	return &NodeAbstractResource{
+		Addr: &ResourceAddress{
+			Mode: config.ManagedResourceMode,
+			Type: "aws_instance",
+			Name: name,
+		},
+	}
+}
@@ -36,9 +36,10 @@ type Hook interface {
 	//
 	// All should be self-explanatory. ProvisionOutput is called with
 	// output sent back by the provisioners. This will be called multiple
-	// times as output comes in, but each call should represent a line of
-	// output. The ProvisionOutput method cannot control whether the
-	// hook continues running.
+	// times as output comes in, once for every line of output without the
+	// line ending. It may be called concurrently for provisioners of
+	// different resources. The ProvisionOutput method cannot control
+	// whether the hook continues running.
 	PreProvisionResource(*InstanceInfo, *InstanceState) (HookAction, error)
 	PostProvisionResource(*InstanceInfo, *InstanceState) (HookAction, error)
 	PreProvision(*InstanceInfo, string) (HookAction, error)
@@ -53,6 +54,17 @@ type Hook interface {
 	// PostStateUpdate is called after the state is updated.
 	PostStateUpdate(*State) (HookAction, error)
 
+	// PostStateMove is called during the plan after the state of a
+	// resource or module is moved from one address to another by a moved
+	// block in the configuration.
+	PostStateMove(from, to string) (HookAction, error)
+
+	// PreStateWrite and PostStateWrite are called before and after the
+	// state is written to persistent storage. PostStateWrite receives the
+	// state as written, including its new serial.
+	PreStateWrite(*State) (HookAction, error)
+	PostStateWrite(*State) (HookAction, error)
+
 	// PreImportState and PostImportState are called before and after
 	// a single resource's state is being improted.
 	PreImportState(*InstanceInfo, string) (HookAction, error)
@@ -120,6 +132,18 @@ func (*NilHook) PostStateUpdate(*State) (HookAction, error) {
 	return HookActionContinue, nil
 }
 
+func (*NilHook) PostStateMove(string, string) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PreStateWrite(*State) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
+func (*NilHook) PostStateWrite(*State) (HookAction, error) {
+	return HookActionContinue, nil
+}
+
 // handleHook turns hook actions into panics. This lets you use the
 // panic/recover mechanism in Go as a flow control mechanism for hook
 // actions.
@@ -92,6 +92,22 @@ type MockHook struct {
 	PostStateUpdateState  *State
 	PostStateUpdateReturn HookAction
 	PostStateUpdateError  error
+
+	PostStateMoveCalled bool
+	PostStateMoveFrom   []string
+	PostStateMoveTo     []string
+	PostStateMoveReturn HookAction
+	PostStateMoveError  error
+
+	PreStateWriteCalled bool
+	PreStateWriteState  *State
+	PreStateWriteReturn HookAction
+	PreStateWriteError  error
+
+	PostStateWriteCalled bool
+	PostStateWriteState  *State
+	PostStateWriteReturn HookAction
+	PostStateWriteError  error
 }
 
 func (h *MockHook) PreApply(n *InstanceInfo, s *InstanceState, d *InstanceDiff) (HookAction, error) {
@@ -243,3 +259,31 @@ func (h *MockHook) PostStateUpdate(s *State) (HookAction, error) {
 	h.PostStateUpdateState = s
 	return h.PostStateUpdateReturn, h.PostStateUpdateError
 }
+
+func (h *MockHook) PostStateMove(from, to string) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PostStateMoveCalled = true
+	h.PostStateMoveFrom = append(h.PostStateMoveFrom, from)
+	h.PostStateMoveTo = append(h.PostStateMoveTo, to)
+	return h.PostStateMoveReturn, h.PostStateMoveError
+}
+
+func (h *MockHook) PreStateWrite(s *State) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PreStateWriteCalled = true
+	h.PreStateWriteState = s
+	return h.PreStateWriteReturn, h.PreStateWriteError
+}
+
+func (h *MockHook) PostStateWrite(s *State) (HookAction, error) {
+	h.Lock()
+	defer h.Unlock()
+
+	h.PostStateWriteCalled = true
+	h.PostStateWriteState = s
+	return h.PostStateWriteReturn, h.PostStateWriteError
+}
@@ -65,6 +65,18 @@ func (h *stopHook) PostStateUpdate(*State) (HookAction, error) {
 	return h.hook()
 }
 
+func (h *stopHook) PostStateMove(string, string) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PreStateWrite(*State) (HookAction, error) {
+	return h.hook()
+}
+
+func (h *stopHook) PostStateWrite(*State) (HookAction, error) {
+	return h.hook()
+}
+
 func (h *stopHook) hook() (HookAction, error) {
 	if h.Stopped() {
 		return HookActionHalt, nil
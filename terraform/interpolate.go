@@ -141,10 +141,16 @@ func (i *Interpolater) valueModuleVar(
 	v *config.ModuleVariable,
 	result map[string]ast.Variable) error {
 
+	// A splat of a module with a count is the list of the values of
+	// the output of every instance.
+	if v.Multi && v.Index == -1 {
+		return i.valueModuleSplatVar(scope, n, v, result)
+	}
+
 	// Build the path to the child module we want
 	path := make([]string, len(scope.Path), len(scope.Path)+1)
 	copy(path, scope.Path)
-	path = append(path, v.Name)
+	path = append(path, v.InstanceName())
 
 	// Grab the lock so that if other interpolations are running or
 	// state is being modified, we'll be safe.
@@ -163,7 +169,7 @@ func (i *Interpolater) valueModuleVar(
 		result[n] = unknownVariable()
 
 		// During apply this is always an error
-		if i.Operation == walkApply {
+		if i.Operation == walkApply || i.Operation == walkEval {
 			return fmt.Errorf(
 				"Couldn't find module %q for var: %s",
 				v.Name, v.FullKey())
@@ -181,7 +187,7 @@ func (i *Interpolater) valueModuleVar(
 			result[n] = unknownVariable()
 
 			// During apply this is always an error
-			if i.Operation == walkApply {
+			if i.Operation == walkApply || i.Operation == walkEval {
 				return fmt.Errorf(
 					"Couldn't find output %q for module var: %s",
 					v.Field, v.FullKey())
@@ -192,6 +198,60 @@ func (i *Interpolater) valueModuleVar(
 	return nil
 }
 
+func (i *Interpolater) valueModuleSplatVar(
+	scope *InterpolationScope,
+	n string,
+	v *config.ModuleVariable,
+	result map[string]ast.Variable) error {
+	// The instances are the children of the module we're in
+	var children map[string]*module.Tree
+	if t := i.Module.Child(scope.Path[1:]); t != nil {
+		children = t.Children()
+	}
+
+	i.StateLock.RLock()
+	defer i.StateLock.RUnlock()
+
+	values := make([]interface{}, 0)
+	for idx := 0; ; idx++ {
+		name := config.ModuleInstanceName(v.Name, idx)
+		if _, ok := children[name]; !ok {
+			break
+		}
+
+		path := make([]string, len(scope.Path), len(scope.Path)+1)
+		copy(path, scope.Path)
+		path = append(path, name)
+
+		var outputState *OutputState
+		if mod := i.State.ModuleByPath(path); mod != nil {
+			outputState = mod.Outputs[v.Field]
+		}
+		if outputState == nil {
+			// As for a single instance, the output isn't known yet
+			// unless we're applying.
+			if i.Operation == walkApply || i.Operation == walkEval {
+				return fmt.Errorf(
+					"Couldn't find output %q of module %q for var: %s",
+					v.Field, name, v.FullKey())
+			}
+
+			result[n] = unknownVariable()
+			return nil
+		}
+
+		values = append(values, outputState.Value)
+	}
+
+	variable, err := hil.InterfaceToVariable(values)
+	if err != nil {
+		return err
+	}
+
+	result[n] = variable
+	return nil
+}
+
 func (i *Interpolater) valuePathVar(
 	scope *InterpolationScope,
 	n string,
@@ -437,8 +497,25 @@ func (i *Interpolater) computeResourceVariable(
 			r = nil
 		}
 	}
+	missingErr := i.Operation == walkApply || i.Operation == walkPlan || i.Operation == walkEval
+	if r == nil && cr != nil && !v.Multi && missingErr {
+		// A resource with a count of zero has no instances, so a reference
+		// to it without an index is empty rather than an error. This lets
+		// conditional resources using "count = var.enabled ? 1 : 0" be
+		// referenced as "type.name.attr" regardless of the count.
+		count, err := i.resourceCountMax(module, cr, v)
+		if err != nil {
+			return nil, fmt.Errorf(
+				"Error reading %s count: %s",
+				v.ResourceId(),
+				err)
+		}
+		if count == 0 {
+			return &ast.Variable{Type: ast.TypeString, Value: ""}, nil
+		}
+	}
 	if r == nil || r.Primary == nil {
-		if i.Operation == walkApply || i.Operation == walkPlan {
+		if missingErr {
 			return nil, fmt.Errorf(
 				"Resource '%s' not found for variable '%s'",
 				v.ResourceId(),
@@ -476,7 +553,7 @@ func (i *Interpolater) computeResourceVariable(
 	// TODO: test by creating a state and configuration that is referencing
 	// a non-existent variable "foo.bar" where the state only has "foo"
 	// and verify plan works, but apply doesn't.
-	if i.Operation == walkApply || i.Operation == walkDestroy {
+	if i.Operation == walkApply || i.Operation == walkDestroy || i.Operation == walkEval {
 		goto MISSING
 	}
 
@@ -520,7 +597,10 @@ MISSING:
 	//
 	// For an input walk, computed values are okay to return because we're only
 	// looking for missing variables to prompt the user for.
-	if i.Operation == walkRefresh || i.Operation == walkPlanDestroy || i.Operation == walkInput {
+	//
+	// When evaluating an expression against the state, such as in the
+	// console, attributes that aren't in the state are simply unknown.
+	if i.Operation == walkRefresh || i.Operation == walkPlanDestroy || i.Operation == walkInput || i.Operation == walkEval {
 		return &unknownVariable, nil
 	}
 
@@ -631,7 +711,7 @@ func (i *Interpolater) computeResourceMultiVariable(
 		//
 		// For an input walk, computed values are okay to return because we're only
 		// looking for missing variables to prompt the user for.
-		if i.Operation == walkRefresh || i.Operation == walkPlanDestroy || i.Operation == walkDestroy || i.Operation == walkInput {
+		if i.Operation == walkRefresh || i.Operation == walkPlanDestroy || i.Operation == walkDestroy || i.Operation == walkInput || i.Operation == walkEval {
 			return &unknownVariable, nil
 		}
 
@@ -697,18 +777,32 @@ func (i *Interpolater) resourceVariableInfo(
 	// Get the module tree that contains our current path. This is
 	// either the current module (path is empty) or a child.
 	modTree := i.Module
-	if len(scope.Path) > 1 {
-		modTree = i.Module.Child(scope.Path[1:])
+	if modTree != nil && len(scope.Path) > 1 {
+		modTree = modTree.Child(scope.Path[1:])
 	}
 
 	// Get the resource from the configuration so we can verify
 	// that the resource is in the configuration and so we can access
-	// the configuration if we need to.
+	// the configuration if we need to. There may be no configuration
+	// at all when evaluating purely against the state.
 	var cr *config.Resource
-	for _, r := range modTree.Config().Resources {
-		if r.Id() == v.ResourceId() {
-			cr = r
-			break
+	if modTree != nil {
+		for _, r := range modTree.Config().Resources {
+			if r.Id() == v.ResourceId() {
+				cr = r
+				break
+			}
+		}
+	}
+
+	// When there is a configuration, a reference to a resource that isn't
+	// in it is a mistake, even if the resource is still in the state.
+	// Without this the reference would quietly evaluate to whatever is
+	// left in the state, or to nothing at all.
+	if modTree != nil && cr == nil {
+		switch i.Operation {
+		case walkValidate, walkPlan, walkApply:
+			return nil, nil, &resourceNotInConfigError{Variable: v}
 		}
 	}
 
@@ -717,6 +811,19 @@ func (i *Interpolater) resourceVariableInfo(
 	return module, cr, nil
 }
 
+// resourceNotInConfigError is the error returned when a variable refers
+// to a resource that doesn't exist in the configuration.
+type resourceNotInConfigError struct {
+	Variable *config.ResourceVariable
+}
+
+func (e *resourceNotInConfigError) Error() string {
+	return fmt.Sprintf(
+		"Resource '%s' not found in configuration for variable '%s'",
+		e.Variable.ResourceId(),
+		e.Variable.FullKey())
+}
+
 func (i *Interpolater) resourceCountMax(
 	ms *ModuleState,
 	cr *config.Resource,
@@ -726,7 +833,7 @@ func (i *Interpolater) resourceCountMax(
 	// If we're NOT applying, then we assume we can read the count
 	// from the state. Plan and so on may not have any state yet so
 	// we do a full interpolation.
-	if i.Operation != walkApply {
+	if i.Operation != walkApply && i.Operation != walkEval {
 		if cr == nil {
 			return 0, nil
 		}
@@ -739,6 +846,11 @@ func (i *Interpolater) resourceCountMax(
 		return count, nil
 	}
 
+	// Without a module state there can't be any resources in it yet
+	if ms == nil {
+		return 0, nil
+	}
+
 	// We need to determine the list of resource keys to get values from.
 	// This needs to be sorted so the order is deterministic. We used to
 	// use "cr.Count()" but that doesn't work if the count is interpolated
@@ -285,8 +285,8 @@ func TestInterpolater_resourceVariableMissingDuringInput(t *testing.T) {
 			&ModuleState{
 				Path:      rootModulePath,
 				Resources: map[string]*ResourceState{
-				// No resources at all yet, because we're still dealing
-				// with input and so the resources haven't been created.
+					// No resources at all yet, because we're still dealing
+					// with input and so the resources haven't been created.
 				},
 			},
 		},
@@ -327,6 +327,47 @@ func TestInterpolater_resourceVariableMissingDuringInput(t *testing.T) {
 	}
 }
 
+func TestInterpolater_resourceVariableCountZero(t *testing.T) {
+	lock := new(sync.RWMutex)
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path:      rootModulePath,
+				Resources: map[string]*ResourceState{},
+			},
+		},
+	}
+
+	i := &Interpolater{
+		Operation: walkApply,
+		Module:    testModule(t, "interpolate-resource-variable-count-zero"),
+		State:     state,
+		StateLock: lock,
+	}
+
+	scope := &InterpolationScope{
+		Path: rootModulePath,
+	}
+
+	// A reference without an index is empty
+	testInterpolate(t, i, scope, "aws_instance.web.foo", ast.Variable{
+		Value: "",
+		Type:  ast.TypeString,
+	})
+
+	// The splat is an empty list
+	testInterpolate(t, i, scope, "aws_instance.web.*.foo", ast.Variable{
+		Value: []ast.Variable{},
+		Type:  ast.TypeList,
+	})
+
+	// An explicit index is still an error since the instance doesn't exist
+	testInterpolateErr(t, i, scope, "aws_instance.web.0.foo")
+
+	// Resources that aren't in the configuration are still an error
+	testInterpolateErr(t, i, scope, "aws_instance.nope.foo")
+}
+
 func TestInterpolater_resourceVariableMulti(t *testing.T) {
 	lock := new(sync.RWMutex)
 	state := &State{
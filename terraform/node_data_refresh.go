@@ -94,7 +94,7 @@ func (n *NodeRefreshableDataResourceInstance) EvalTree() EvalNode {
 	if n.Config != nil {
 		rs = &ResourceState{
 			Type:         n.Config.Type,
-			Provider:     n.Config.Provider,
+			Provider:     n.configProvider(),
 			Dependencies: n.StateReferences(),
 		}
 	}
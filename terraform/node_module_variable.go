@@ -88,15 +88,27 @@ func (n *NodeApplyableModuleVariable) EvalTree() EvalNode {
 		return &EvalNoop{}
 	}
 
-	// Otherwise, interpolate the value of this variable and set it
-	// within the variables mapping.
+	// If we're in an instance of a module with a count, count.index is
+	// the index of the instance. The resource is only used for that.
+	// Validate runs on the tree before the counts are expanded, so there
+	// a module with a count is treated as its first instance.
+	var resource *Resource
+	if _, index := config.ParseModuleInstanceName(n.PathValue[len(n.PathValue)-1]); index != -1 {
+		resource = &Resource{CountIndex: index}
+	} else if n.hasCount() {
+		resource = &Resource{CountIndex: 0}
+	}
+
+	// Interpolate the value of this variable and set it within the
+	// variables mapping.
 	var config *ResourceConfig
 	variables := make(map[string]interface{})
 	return &EvalSequence{
 		Nodes: []EvalNode{
 			&EvalInterpolate{
-				Config: n.Value,
-				Output: &config,
+				Config:   n.Value,
+				Resource: resource,
+				Output:   &config,
 			},
 
 			&EvalVariableBlock{
@@ -123,3 +135,62 @@ func (n *NodeApplyableModuleVariable) EvalTree() EvalNode {
 		},
 	}
 }
+
+// hasCount returns true if the module this variable is in has a count
+// and hasn't been expanded into its instances.
+func (n *NodeApplyableModuleVariable) hasCount() bool {
+	if n.Module == nil || len(n.PathValue) < 2 {
+		return false
+	}
+
+	parent := n.Module.Child(n.PathValue[1 : len(n.PathValue)-1])
+	if parent == nil || parent.Config() == nil {
+		return false
+	}
+
+	name := n.PathValue[len(n.PathValue)-1]
+	for _, m := range parent.Config().Modules {
+		if m.Name == name {
+			return m.RawCount != nil
+		}
+	}
+
+	return false
+}
+
+// NodeUnusedVariable represents a root or module variable that is declared
+// but not referenced by anything in the graph. It exists only so that the
+// variable can be reported during the validate walk.
+type NodeUnusedVariable struct {
+	PathValue []string
+	Config    *config.Variable // Config is the var in the config
+}
+
+func (n *NodeUnusedVariable) Name() string {
+	result := fmt.Sprintf("var.%s", n.Config.Name)
+	if len(n.PathValue) > 1 {
+		result = fmt.Sprintf("%s.%s", modulePrefixStr(n.PathValue), result)
+	}
+
+	return result
+}
+
+// GraphNodeSubPath
+func (n *NodeUnusedVariable) Path() []string {
+	return n.PathValue
+}
+
+// RemovableIfNotTargeted
+func (n *NodeUnusedVariable) RemoveIfNotTargeted() bool {
+	return true
+}
+
+// GraphNodeEvalable
+func (n *NodeUnusedVariable) EvalTree() EvalNode {
+	return &EvalOpFilter{
+		Ops: []walkOperation{walkValidate},
+		Node: &EvalValidateUnusedVariable{
+			Name: n.Config.Name,
+		},
+	}
+}
@@ -12,6 +12,14 @@ import (
 type NodeApplyableOutput struct {
 	PathValue []string
 	Config    *config.Output // Config is the output in the config
+
+	// Sensitive is true if the output is sensitive, either because it is
+	// marked as such or because its value is derived from something
+	// sensitive. See OutputTransformer.
+	Sensitive bool
+
+	// KeepPrevious is passed on to EvalWriteOutput.
+	KeepPrevious bool
 }
 
 func (n *NodeApplyableOutput) Name() string {
@@ -66,9 +74,10 @@ func (n *NodeApplyableOutput) EvalTree() EvalNode {
 		Node: &EvalSequence{
 			Nodes: []EvalNode{
 				&EvalWriteOutput{
-					Name:      n.Config.Name,
-					Sensitive: n.Config.Sensitive,
-					Value:     n.Config.RawConfig,
+					Name:         n.Config.Name,
+					Sensitive:    n.Config.Sensitive || n.Sensitive,
+					Value:        n.Config.RawConfig,
+					KeepPrevious: n.KeepPrevious,
 				},
 			},
 		},
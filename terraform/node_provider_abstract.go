@@ -22,6 +22,10 @@ type NodeAbstractProvider struct {
 	// set if you already have that information.
 
 	Config *config.ProviderConfig
+
+	// ParentNameValue is the name of the provider in the parent module to
+	// inherit configuration from if it isn't the same as NameValue.
+	ParentNameValue string
 }
 
 func (n *NodeAbstractProvider) Name() string {
@@ -73,6 +77,21 @@ func (n *NodeAbstractProvider) AttachProvider(c *config.ProviderConfig) {
 	n.Config = c
 }
 
+// GraphNodeAttachParentProvider
+func (n *NodeAbstractProvider) AttachParentProvider(name string) {
+	n.ParentNameValue = name
+}
+
+// ParentProviderName returns the name of the provider in the parent module
+// that this provider inherits its configuration from.
+func (n *NodeAbstractProvider) ParentProviderName() string {
+	if n.ParentNameValue != "" {
+		return n.ParentNameValue
+	}
+
+	return n.NameValue
+}
+
 // GraphNodeDotter impl.
 func (n *NodeAbstractProvider) DotNode(name string, opts *dag.DotOpts) *dag.DotNode {
 	return &dag.DotNode{
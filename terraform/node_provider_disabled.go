@@ -25,9 +25,10 @@ func (n *NodeDisabledProvider) EvalTree() EvalNode {
 				Output: &resourceConfig,
 			},
 			&EvalBuildProviderConfig{
-				Provider: n.ProviderName(),
-				Config:   &resourceConfig,
-				Output:   &resourceConfig,
+				Provider:       n.ProviderName(),
+				ParentProvider: n.ParentProviderName(),
+				Config:         &resourceConfig,
+				Output:         &resourceConfig,
 			},
 			&EvalSetProviderConfig{
 				Provider: n.ProviderName(),
@@ -95,6 +95,9 @@ func (n *NodeAbstractResource) References() []string {
 		result = append(result, c.DependsOn...)
 		result = append(result, ReferencesFromConfig(c.RawCount)...)
 		result = append(result, ReferencesFromConfig(c.RawConfig)...)
+		if c.RawReplaceTriggers != nil {
+			result = append(result, ReferencesFromConfig(c.RawReplaceTriggers)...)
+		}
 		for _, p := range c.Provisioners {
 			if p.When == config.ProvisionerWhenCreate {
 				result = append(result, ReferencesFromConfig(p.ConnInfo)...)
@@ -174,7 +177,7 @@ func (n *NodeAbstractResource) StateReferences() []string {
 func (n *NodeAbstractResource) ProvidedBy() []string {
 	// If we have a config we prefer that above all else
 	if n.Config != nil {
-		return []string{resourceProvider(n.Config.Type, n.Config.Provider)}
+		return []string{resourceProvider(n.Config.Type, n.configProvider())}
 	}
 
 	// If we have state, then we will use the provider from there
@@ -186,6 +189,17 @@ func (n *NodeAbstractResource) ProvidedBy() []string {
 	return []string{resourceProvider(n.Addr.Type, "")}
 }
 
+// configProvider returns the provider from the configuration for the
+// instance of the resource this node represents.
+func (n *NodeAbstractResource) configProvider() string {
+	index := -1
+	if n.Addr != nil {
+		index = n.Addr.Index
+	}
+
+	return n.Config.ProviderForIndex(index)
+}
+
 // GraphNodeProvisionerConsumer
 func (n *NodeAbstractResource) ProvisionedBy() []string {
 	// If we have no configuration, then we have no provisioners
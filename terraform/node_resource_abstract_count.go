@@ -12,6 +12,23 @@ type NodeAbstractCountResource struct {
 	Validate bool
 }
 
+// GraphNodeProviderConsumer
+func (n *NodeAbstractCountResource) ProvidedBy() []string {
+	// If the instances are spread across multiple providers then the
+	// resource depends on all of them, since the instance for each is
+	// only known once the count is expanded.
+	if n.Config != nil && len(n.Config.Providers) > 0 {
+		result := make([]string, len(n.Config.Providers))
+		for i, p := range n.Config.Providers {
+			result[i] = resourceProvider(n.Config.Type, p)
+		}
+
+		return result
+	}
+
+	return n.NodeAbstractResource.ProvidedBy()
+}
+
 // GraphNodeEvalable
 func (n *NodeAbstractCountResource) EvalTree() EvalNode {
 	// We only check if the count is computed if we're not validating.
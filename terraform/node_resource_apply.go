@@ -10,6 +10,10 @@ import (
 // it is ready to be applied and is represented by a diff.
 type NodeApplyableResource struct {
 	*NodeAbstractResource
+
+	// RecordApplyTime, if true, records the time the resource was applied
+	// in its state. See ResourceState.LastApplied.
+	RecordApplyTime bool
 }
 
 // GraphNodeCreator
@@ -158,7 +162,7 @@ func (n *NodeApplyableResource) evalTreeDataResource(
 			&EvalWriteState{
 				Name:         stateId,
 				ResourceType: n.Config.Type,
-				Provider:     n.Config.Provider,
+				Provider:     n.configProvider(),
 				Dependencies: stateDeps,
 				State:        &state,
 			},
@@ -308,7 +312,7 @@ func (n *NodeApplyableResource) evalTreeManagedResource(
 			&EvalWriteState{
 				Name:         stateId,
 				ResourceType: n.Config.Type,
-				Provider:     n.Config.Provider,
+				Provider:     n.configProvider(),
 				Dependencies: stateDeps,
 				State:        &state,
 			},
@@ -332,11 +336,32 @@ func (n *NodeApplyableResource) evalTreeManagedResource(
 				Else: &EvalWriteState{
 					Name:         stateId,
 					ResourceType: n.Config.Type,
-					Provider:     n.Config.Provider,
+					Provider:     n.configProvider(),
 					Dependencies: stateDeps,
 					State:        &state,
 				},
 			},
+			&EvalWriteProviderMeta{
+				Name:     stateId,
+				Provider: &provider,
+			},
+			&EvalIf{
+				If: func(ctx EvalContext) (bool, error) {
+					return n.RecordApplyTime && err == nil &&
+						state != nil && state.ID != "", nil
+				},
+				Then: &EvalWriteLastApplied{Name: stateId},
+			},
+			&EvalIf{
+				If: func(ctx EvalContext) (bool, error) {
+					return err == nil && state != nil && state.ID != "", nil
+				},
+				Then: &EvalWriteReplaceTriggers{
+					Name:     stateId,
+					Config:   n.Config.RawReplaceTriggers,
+					Resource: resource,
+				},
+			},
 
 			// We clear the diff out here so that future nodes
 			// don't see a diff that is already complete. There
@@ -112,7 +112,7 @@ func (n *NodePlannableResourceInstance) evalTreeDataResource(
 			&EvalWriteState{
 				Name:         stateId,
 				ResourceType: n.Config.Type,
-				Provider:     n.Config.Provider,
+				Provider:     n.configProvider(),
 				Dependencies: stateDeps,
 				State:        &state,
 			},
@@ -134,6 +134,7 @@ func (n *NodePlannableResourceInstance) evalTreeManagedResource(
 	var diff *InstanceDiff
 	var state *InstanceState
 	var resourceConfig *ResourceConfig
+	var replace bool
 
 	return &EvalSequence{
 		Nodes: []EvalNode{
@@ -160,6 +161,12 @@ func (n *NodePlannableResourceInstance) evalTreeManagedResource(
 				Name:   stateId,
 				Output: &state,
 			},
+			&EvalReplaceTriggered{
+				Name:     stateId,
+				Config:   n.Config.RawReplaceTriggers,
+				Resource: resource,
+				Output:   &replace,
+			},
 			&EvalDiff{
 				Name:        stateId,
 				Info:        info,
@@ -169,6 +176,7 @@ func (n *NodePlannableResourceInstance) evalTreeManagedResource(
 				State:       &state,
 				OutputDiff:  &diff,
 				OutputState: &state,
+				Replace:     &replace,
 			},
 			&EvalCheckPreventDestroy{
 				Resource: n.Config,
@@ -177,7 +185,7 @@ func (n *NodePlannableResourceInstance) evalTreeManagedResource(
 			&EvalWriteState{
 				Name:         stateId,
 				ResourceType: n.Config.Type,
-				Provider:     n.Config.Provider,
+				Provider:     n.configProvider(),
 				Dependencies: stateDeps,
 				State:        &state,
 			},
@@ -72,7 +72,7 @@ func (n *NodeRefreshableResource) evalTreeManagedResource() EvalNode {
 		return &EvalReturnError{Error: &err}
 	}
 
-	return &EvalSequence{
+	seq := &EvalSequence{
 		Nodes: []EvalNode{
 			&EvalGetProvider{
 				Name:   n.ProvidedBy()[0],
@@ -97,4 +97,26 @@ func (n *NodeRefreshableResource) evalTreeManagedResource() EvalNode {
 			},
 		},
 	}
+
+	// Start tracking the replacement triggers of resources that were
+	// created before the triggers were configured.
+	if n.Config != nil && n.Config.RawReplaceTriggers != nil {
+		resource := &Resource{
+			Name:       addr.Name,
+			Type:       addr.Type,
+			CountIndex: addr.Index,
+		}
+		if resource.CountIndex < 0 {
+			resource.CountIndex = 0
+		}
+
+		seq.Nodes = append(seq.Nodes, &EvalWriteReplaceTriggers{
+			Name:        stateId,
+			Config:      n.Config.RawReplaceTriggers,
+			Resource:    resource,
+			OnlyIfUnset: true,
+		})
+	}
+
+	return seq
 }
@@ -31,9 +31,29 @@ type Plan struct {
 	Vars    map[string]interface{}
 	Targets []string
 
+	// TargetDepth limits the dependencies that Targets pulls in. See
+	// ContextOpts.TargetDepth.
+	TargetDepth *int
+
+	// TargetModules are the targeted modules. See
+	// ContextOpts.TargetModules.
+	TargetModules []string
+
 	// Backend is the backend that this plan should use and store data with.
 	Backend *BackendState
 
+	// ConfigChecksum is the checksum of the root module configuration
+	// files this plan was created from (see config.DirChecksum). Together
+	// with the serial of State, this lets apply detect a stale plan. This
+	// is empty if the checksum wasn't recorded.
+	ConfigChecksum string
+
+	// Moved are the items that were moved in State by moved blocks in
+	// the configuration before the plan was made, as a map from their
+	// old address to their new one. State already has them at their new
+	// addresses.
+	Moved map[string]string
+
 	once sync.Once
 }
 
@@ -41,11 +61,22 @@ type Plan struct {
 //
 // The following fields in opts are overridden by the plan: Config,
 // Diff, State, Variables.
+//
+// If opts has Targets or TargetModules set they are kept, so that only the
+// subset of the plan that they match is applied. It is an error if they
+// aren't within the targets the plan was created with, if any. Otherwise
+// the targets and target depth the plan was created with are used.
 func (p *Plan) Context(opts *ContextOpts) (*Context, error) {
 	opts.Diff = p.Diff
 	opts.Module = p.Module
 	opts.State = p.State
-	opts.Targets = p.Targets
+	if len(opts.Targets) == 0 && len(opts.TargetModules) == 0 {
+		opts.Targets = p.Targets
+		opts.TargetDepth = p.TargetDepth
+		opts.TargetModules = p.TargetModules
+	} else if err := p.checkTargets(opts.Targets, opts.TargetModules); err != nil {
+		return nil, err
+	}
 
 	opts.Variables = make(map[string]interface{})
 	for k, v := range p.Vars {
@@ -55,6 +86,110 @@ func (p *Plan) Context(opts *ContextOpts) (*Context, error) {
 	return NewContext(opts)
 }
 
+// checkTargets returns an error if any of the given targets or target
+// modules isn't within the targets the plan was created with, since the
+// plan doesn't cover anything outside of those.
+func (p *Plan) checkTargets(targets, modules []string) error {
+	if len(p.Targets) == 0 && len(p.TargetModules) == 0 {
+		return nil
+	}
+
+	planTargets, err := parseTargets(p.Targets)
+	if err != nil {
+		return err
+	}
+	planModules, err := parseTargets(p.TargetModules)
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range targets {
+		addr, err := ParseResourceAddress(raw)
+		if err != nil {
+			return err
+		}
+
+		ok := false
+		for _, m := range planModules {
+			ok = ok || modulePathContains(m.Path, addr.Path)
+		}
+		for _, t := range planTargets {
+			ok = ok || targetContains(t, addr)
+		}
+		if !ok {
+			return fmt.Errorf(
+				"target %s is not within the targets of the plan, "+
+					"create a new plan to apply it", raw)
+		}
+	}
+
+	for _, raw := range modules {
+		addr, err := ParseResourceAddress(raw)
+		if err != nil {
+			return err
+		}
+
+		ok := false
+		for _, m := range planModules {
+			ok = ok || modulePathContains(m.Path, addr.Path)
+		}
+		if !ok {
+			return fmt.Errorf(
+				"target module %s is not within the targets of the plan, "+
+					"create a new plan to apply it", raw)
+		}
+	}
+
+	return nil
+}
+
+func parseTargets(raw []string) ([]*ResourceAddress, error) {
+	result := make([]*ResourceAddress, len(raw))
+	for i, s := range raw {
+		addr, err := ParseResourceAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		result[i] = addr
+	}
+
+	return result, nil
+}
+
+// targetContains returns true if everything that addr targets is also
+// targeted by the target t.
+func targetContains(t, addr *ResourceAddress) bool {
+	if len(t.Path) != len(addr.Path) || !modulePathContains(t.Path, addr.Path) {
+		return false
+	}
+
+	// A module address targets every resource directly within it
+	if t.Type == "" {
+		return true
+	}
+
+	return t.Type == addr.Type &&
+		t.Name == addr.Name &&
+		t.Mode == addr.Mode &&
+		t.InstanceType == addr.InstanceType &&
+		(t.Index == -1 || t.Index == addr.Index)
+}
+
+// modulePathContains returns true if the module path p is the same as or
+// a parent of the path other.
+func modulePathContains(p, other []string) bool {
+	if len(other) < len(p) {
+		return false
+	}
+	for i, name := range p {
+		if other[i] != name {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *Plan) String() string {
 	buf := new(bytes.Buffer)
 	buf.WriteString("DIFF:\n\n")
@@ -75,3 +75,64 @@ func TestReadWritePlan(t *testing.T) {
 		t.Fatalf("bad:\n\n%s\n\nexpected:\n\n%s", actualStr, expectedStr)
 	}
 }
+
+func TestPlanContext_targetDepth(t *testing.T) {
+	plan := &Plan{
+		Module:      testModule(t, "new-good"),
+		State:       NewState(),
+		Targets:     []string{"aws_instance.foo"},
+		TargetDepth: testIntPtr(1),
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WritePlan(plan, buf); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual, err := ReadPlan(buf)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ctx, err := actual.Context(&ContextOpts{})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	if ctx.targetDepth == nil || *ctx.targetDepth != 1 {
+		t.Fatalf("bad: %#v", ctx.targetDepth)
+	}
+}
+
+func TestPlanCheckTargets(t *testing.T) {
+	cases := []struct {
+		PlanTargets, PlanModules []string
+		Targets, Modules         []string
+		Err                      bool
+	}{
+		// A plan without targets covers everything
+		{nil, nil, []string{"aws_instance.foo"}, []string{"module.child"}, false},
+
+		{[]string{"aws_instance.foo"}, nil, []string{"aws_instance.foo"}, nil, false},
+		{[]string{"aws_instance.foo"}, nil, []string{"aws_instance.foo[1]"}, nil, false},
+		{[]string{"aws_instance.foo[1]"}, nil, []string{"aws_instance.foo"}, nil, true},
+		{[]string{"aws_instance.foo"}, nil, []string{"aws_instance.bar"}, nil, true},
+		{[]string{"aws_instance.foo"}, nil, []string{"data.aws_instance.foo"}, nil, true},
+		{[]string{"module.child"}, nil, []string{"module.child.aws_instance.foo"}, nil, false},
+		{[]string{"module.child"}, nil, []string{"module.child.module.grandchild.aws_instance.foo"}, nil, true},
+		{[]string{"aws_instance.foo"}, nil, nil, []string{"module.child"}, true},
+
+		{nil, []string{"module.child"}, []string{"module.child.module.grandchild.aws_instance.foo"}, nil, false},
+		{nil, []string{"module.child"}, []string{"aws_instance.foo"}, nil, true},
+		{nil, []string{"module.child"}, nil, []string{"module.child.module.grandchild"}, false},
+		{nil, []string{"module.child.module.grandchild"}, nil, []string{"module.child"}, true},
+	}
+
+	for i, tc := range cases {
+		p := &Plan{Targets: tc.PlanTargets, TargetModules: tc.PlanModules}
+		err := p.checkTargets(tc.Targets, tc.Modules)
+		if (err != nil) != tc.Err {
+			t.Fatalf("%d: bad: %s", i, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package terraform
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/config/module"
+)
+
+// ProviderResolver chooses the provider to use for the given provider type,
+// such as "aws", in place of the one in ContextOpts.Providers. It returns
+// nil to use the provider from ContextOpts.Providers as usual.
+type ProviderResolver func(typ string) ResourceProviderFactory
+
+// StubProviderResolver is a ProviderResolver that replaces every provider
+// with a StubResourceProvider, so that a configuration can be planned
+// without any real providers or their credentials.
+func StubProviderResolver(typ string) ResourceProviderFactory {
+	return func() (ResourceProvider, error) {
+		return new(StubResourceProvider), nil
+	}
+}
+
+// resolveProviders returns the providers to use for the given module and
+// state, with those chosen by the resolver r in place of the ones in
+// providers. Only the provider types that the configuration or the state
+// use are given to r.
+func resolveProviders(
+	r ProviderResolver,
+	providers map[string]ResourceProviderFactory,
+	m *module.Tree,
+	s *State) map[string]ResourceProviderFactory {
+	result := make(map[string]ResourceProviderFactory, len(providers))
+	for k, v := range providers {
+		result[k] = v
+	}
+
+	for _, typ := range providerTypes(m, s) {
+		if f := r(typ); f != nil {
+			result[typ] = f
+		}
+	}
+
+	return result
+}
+
+// providerTypes returns the sorted provider types used by the resources
+// and provider configurations of the module tree and the resources in the
+// state.
+func providerTypes(m *module.Tree, s *State) []string {
+	types := make(map[string]struct{})
+	add := func(name string) {
+		if idx := strings.IndexRune(name, '.'); idx != -1 {
+			name = name[:idx]
+		}
+		types[name] = struct{}{}
+	}
+
+	var walk func(*module.Tree)
+	walk = func(t *module.Tree) {
+		if c := t.Config(); c != nil {
+			for _, pc := range c.ProviderConfigs {
+				add(pc.Name)
+			}
+
+			for _, r := range c.Resources {
+				add(resourceProvider(r.Type, r.Provider))
+				for _, p := range r.Providers {
+					add(resourceProvider(r.Type, p))
+				}
+			}
+		}
+
+		for _, child := range t.Children() {
+			walk(child)
+		}
+	}
+	if m != nil {
+		walk(m)
+	}
+
+	if s != nil {
+		for _, ms := range s.Modules {
+			for _, rs := range ms.Resources {
+				add(resourceProvider(rs.Type, rs.Provider))
+			}
+		}
+	}
+
+	result := make([]string, 0, len(types))
+	for typ := range types {
+		result = append(result, typ)
+	}
+	sort.Strings(result)
+
+	return result
+}
@@ -0,0 +1,49 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStubProviderResolver(t *testing.T) {
+	m := testModule(t, "plan-stub-providers")
+	s := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+				Resources: map[string]*ResourceState{
+					"null_resource.orphan": &ResourceState{
+						Type: "null_resource",
+					},
+				},
+			},
+		},
+	}
+
+	actual := providerTypes(m, s)
+	expected := []string{"aws", "google", "null"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+
+	// A resolver that returns nil keeps the given provider
+	p := testProvider("aws")
+	providers := resolveProviders(
+		func(typ string) ResourceProviderFactory {
+			if typ == "aws" {
+				return nil
+			}
+			return StubProviderResolver(typ)
+		},
+		map[string]ResourceProviderFactory{"aws": testProviderFuncFixed(p)},
+		m, s)
+	if len(providers) != 3 {
+		t.Fatalf("bad: %#v", providers)
+	}
+	if actual, _ := providers["aws"](); actual != p {
+		t.Fatalf("bad: %#v", actual)
+	}
+	if actual, _ := providers["google"](); !reflect.DeepEqual(actual, new(StubResourceProvider)) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
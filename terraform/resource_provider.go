@@ -160,10 +160,31 @@ type ResourceProviderCloser interface {
 	Close() error
 }
 
+// ResourceProviderMeta is an interface that providers that report metadata
+// about themselves, such as their version, may implement. The metadata is
+// recorded in the state of every resource the provider applies.
+type ResourceProviderMeta interface {
+	ProviderMeta() string
+}
+
+// ResourceProviderEquivalence is an interface that providers may implement
+// to say that two values of an attribute are equivalent even though they
+// are written differently, such as JSON documents with their keys in a
+// different order. The diff of an attribute between equivalent values is
+// dropped from the plan.
+type ResourceProviderEquivalence interface {
+	Equivalent(attr, old, new string) bool
+}
+
 // ResourceType is a type of resource that a resource provider can manage.
 type ResourceType struct {
 	Name       string // Name of the resource, example "instance" (no provider prefix)
 	Importable bool   // Whether this resource supports importing
+
+	// Attributes is the list of top-level configuration keys this
+	// resource type accepts. If this is empty then the provider doesn't
+	// report its attributes and no unknown-attribute checking is done.
+	Attributes []string
 }
 
 // DataSource is a data source that a resource provider implements.
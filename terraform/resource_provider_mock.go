@@ -85,6 +85,9 @@ type MockResourceProvider struct {
 	ImportStateReturn      []*InstanceState
 	ImportStateReturnError error
 	ImportStateFn          func(*InstanceInfo, string) ([]*InstanceState, error)
+
+	ProviderMetaCalled bool
+	ProviderMetaReturn string
 }
 
 func (p *MockResourceProvider) Close() error {
@@ -218,13 +221,23 @@ func (p *MockResourceProvider) Resources() []ResourceType {
 	return p.ResourcesReturn
 }
 
-func (p *MockResourceProvider) ImportState(info *InstanceInfo, id string) ([]*InstanceState, error) {
+func (p *MockResourceProvider) ProviderMeta() string {
 	p.Lock()
 	defer p.Unlock()
 
+	p.ProviderMetaCalled = true
+	return p.ProviderMetaReturn
+}
+
+func (p *MockResourceProvider) ImportState(info *InstanceInfo, id string) ([]*InstanceState, error) {
+	// We only lock while writing data so that imports can run
+	// concurrently. Reading is fine
+	p.Lock()
 	p.ImportStateCalled = true
 	p.ImportStateInfo = info
 	p.ImportStateID = id
+	p.Unlock()
+
 	if p.ImportStateFn != nil {
 		return p.ImportStateFn(info, id)
 	}
@@ -0,0 +1,172 @@
+package terraform
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/flatmap"
+)
+
+// StubResourceProvider is a ResourceProvider that stands in for a real
+// provider without talking to any API. It accepts any configuration and
+// plans every resource and data source to have exactly the attributes in
+// its configuration, so plans made with it are deterministic. This is
+// meant for testing configurations, see StubProviderResolver.
+//
+// Values that aren't known until apply are planned as computed, as is the
+// "id" of a resource that is created. Applying a diff sets the ID of the
+// resource to its address.
+type StubResourceProvider struct{}
+
+func (p *StubResourceProvider) Input(
+	input UIInput, c *ResourceConfig) (*ResourceConfig, error) {
+	return c, nil
+}
+
+func (p *StubResourceProvider) Validate(*ResourceConfig) ([]string, []error) {
+	return nil, nil
+}
+
+func (p *StubResourceProvider) Configure(*ResourceConfig) error {
+	return nil
+}
+
+func (p *StubResourceProvider) Resources() []ResourceType {
+	return nil
+}
+
+func (p *StubResourceProvider) Stop() error {
+	return nil
+}
+
+func (p *StubResourceProvider) ValidateResource(
+	string, *ResourceConfig) ([]string, []error) {
+	return nil, nil
+}
+
+func (p *StubResourceProvider) Diff(
+	info *InstanceInfo,
+	s *InstanceState,
+	c *ResourceConfig) (*InstanceDiff, error) {
+	return stubDiff(s, c), nil
+}
+
+func (p *StubResourceProvider) Apply(
+	info *InstanceInfo,
+	s *InstanceState,
+	d *InstanceDiff) (*InstanceState, error) {
+	return stubApply(info, s, d), nil
+}
+
+func (p *StubResourceProvider) Refresh(
+	info *InstanceInfo, s *InstanceState) (*InstanceState, error) {
+	return s, nil
+}
+
+func (p *StubResourceProvider) ImportState(
+	info *InstanceInfo, id string) ([]*InstanceState, error) {
+	return nil, fmt.Errorf("%s: the stub provider can't import resources", info.HumanId())
+}
+
+func (p *StubResourceProvider) ValidateDataSource(
+	string, *ResourceConfig) ([]string, []error) {
+	return nil, nil
+}
+
+func (p *StubResourceProvider) DataSources() []DataSource {
+	return nil
+}
+
+func (p *StubResourceProvider) ReadDataDiff(
+	info *InstanceInfo, c *ResourceConfig) (*InstanceDiff, error) {
+	return stubDiff(nil, c), nil
+}
+
+func (p *StubResourceProvider) ReadDataApply(
+	info *InstanceInfo, d *InstanceDiff) (*InstanceState, error) {
+	return stubApply(info, nil, d), nil
+}
+
+// stubDiff returns the diff that changes the state s to have exactly the
+// attributes of the configuration c.
+func stubDiff(s *InstanceState, c *ResourceConfig) *InstanceDiff {
+	old := make(map[string]string)
+	if s != nil {
+		for k, v := range s.Attributes {
+			old[k] = v
+		}
+	}
+
+	diff := &InstanceDiff{Attributes: make(map[string]*ResourceAttrDiff)}
+	for k, v := range flatmap.Flatten(c.Config) {
+		if v == config.UnknownVariableValue {
+			diff.Attributes[k] = &ResourceAttrDiff{
+				Old:         old[k],
+				NewComputed: true,
+			}
+			continue
+		}
+
+		if o, ok := old[k]; !ok || o != v {
+			diff.Attributes[k] = &ResourceAttrDiff{
+				Old: o,
+				New: v,
+			}
+		}
+	}
+
+	for k, v := range old {
+		if k == "id" {
+			continue
+		}
+		if _, ok := diff.Attributes[k]; ok {
+			continue
+		}
+		if _, ok := c.Get(k); !ok && !c.IsComputed(k) {
+			diff.Attributes[k] = &ResourceAttrDiff{
+				Old:        v,
+				NewRemoved: true,
+			}
+		}
+	}
+
+	if s == nil || s.ID == "" {
+		diff.Attributes["id"] = &ResourceAttrDiff{NewComputed: true}
+	}
+
+	return diff
+}
+
+// stubApply returns the state that results from applying the diff d made
+// by stubDiff to the state s.
+func stubApply(info *InstanceInfo, s *InstanceState, d *InstanceDiff) *InstanceState {
+	if d.GetDestroy() {
+		return nil
+	}
+
+	result := &InstanceState{Attributes: make(map[string]string)}
+	if s != nil {
+		result = s.DeepCopy()
+		if result.Attributes == nil {
+			result.Attributes = make(map[string]string)
+		}
+	}
+
+	for k, ad := range d.CopyAttributes() {
+		switch {
+		case ad.NewRemoved:
+			delete(result.Attributes, k)
+		case ad.NewComputed:
+			result.Attributes[k] = ""
+		default:
+			result.Attributes[k] = ad.New
+		}
+	}
+
+	if result.ID == "" {
+		result.ID = info.Id
+	}
+	result.Attributes["id"] = result.ID
+
+	return result
+}
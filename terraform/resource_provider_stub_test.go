@@ -0,0 +1,60 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+)
+
+func TestStubResourceProvider_impl(t *testing.T) {
+	var _ ResourceProvider = new(StubResourceProvider)
+}
+
+func TestStubResourceProviderDiff(t *testing.T) {
+	p := new(StubResourceProvider)
+	info := &InstanceInfo{Id: "aws_instance.foo", Type: "aws_instance"}
+	state := &InstanceState{
+		ID: "foo",
+		Attributes: map[string]string{
+			"id":   "foo",
+			"ami":  "old",
+			"size": "large",
+			"gone": "yes",
+		},
+	}
+	c := testResourceConfig(t, map[string]interface{}{
+		"ami":  "new",
+		"size": "large",
+		"ip":   config.UnknownVariableValue,
+	})
+
+	diff, err := p.Diff(info, state, c)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expected := map[string]*ResourceAttrDiff{
+		"ami":  &ResourceAttrDiff{Old: "old", New: "new"},
+		"ip":   &ResourceAttrDiff{NewComputed: true},
+		"gone": &ResourceAttrDiff{Old: "yes", NewRemoved: true},
+	}
+	if !reflect.DeepEqual(diff.Attributes, expected) {
+		t.Fatalf("bad: %#v", diff.Attributes)
+	}
+
+	actual, err := p.Apply(info, state, diff)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	expectedState := map[string]string{
+		"id":   "foo",
+		"ami":  "new",
+		"size": "large",
+		"ip":   "",
+	}
+	if actual.ID != "foo" || !reflect.DeepEqual(actual.Attributes, expectedState) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
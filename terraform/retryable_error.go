@@ -0,0 +1,60 @@
+package terraform
+
+import (
+	"github.com/hashicorp/go-multierror"
+)
+
+// RetryableError is implemented by errors that indicate the failed
+// operation may succeed if it is attempted again, such as a transient
+// network failure. Providers can return one of these from Apply to allow
+// Terraform to retry the resource when ContextOpts.RetryFailed is set.
+type RetryableError interface {
+	error
+
+	Retryable() bool
+}
+
+// NewRetryableError wraps err so that it is reported as retryable.
+func NewRetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &retryableError{err}
+}
+
+// IsRetryableError returns true if err is retryable. A multierror is only
+// retryable if every one of its errors is.
+func IsRetryableError(err error) bool {
+	switch e := err.(type) {
+	case nil:
+		return false
+	case RetryableError:
+		return e.Retryable()
+	case *multierror.Error:
+		if len(e.Errors) == 0 {
+			return false
+		}
+		for _, child := range e.Errors {
+			if !IsRetryableError(child) {
+				return false
+			}
+		}
+
+		return true
+	default:
+		return false
+	}
+}
+
+type retryableError struct {
+	Err error
+}
+
+func (e *retryableError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *retryableError) Retryable() bool {
+	return true
+}
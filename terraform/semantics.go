@@ -104,6 +104,9 @@ func smcUserVariables(c *config.Config, vs map[string]interface{}) []error {
 		case config.VariableTypeString:
 			switch proposedValue.(type) {
 			case string:
+				if err := schema.ValidateValue(proposedValue); err != nil {
+					errs = append(errs, fmt.Errorf("variable %s: %s", name, err))
+				}
 				continue
 			}
 		case config.VariableTypeMap:
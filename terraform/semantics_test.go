@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -55,3 +56,28 @@ func TestSMCUserVariables_mapFromJSON(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSMCUserVariables_validation(t *testing.T) {
+	c := testConfig(t, "smc-uservars-validation")
+
+	errs := smcUserVariables(c, map[string]interface{}{
+		"env":   "prod-east",
+		"token": "abc123",
+	})
+	if len(errs) != 0 {
+		t.Fatalf("err: %#v", errs)
+	}
+
+	errs = smcUserVariables(c, map[string]interface{}{
+		"env":   "dev-east",
+		"token": "secret!",
+	})
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got: %#v", errs)
+	}
+	for _, err := range errs {
+		if strings.Contains(err.Error(), "secret!") {
+			t.Fatalf("sensitive value leaked in error: %s", err)
+		}
+	}
+}
@@ -42,15 +42,25 @@ func newShadowContext(c *Context) (*Context, *Context, Shadow) {
 
 	// Create the shadow
 	shadow := &Context{
-		components: componentsShadow,
-		destroy:    c.destroy,
-		diff:       c.diff.DeepCopy(),
-		hooks:      nil,
-		meta:       c.meta,
-		module:     c.module,
-		state:      c.state.DeepCopy(),
-		targets:    targetRaw.([]string),
-		variables:  varRaw.(map[string]interface{}),
+		components:  componentsShadow,
+		destroy:     c.destroy,
+		diff:        c.diff.DeepCopy(),
+		hooks:       nil,
+		meta:        c.meta,
+		module:      c.module,
+		modBarriers: c.modBarriers,
+		keepOutputs: c.keepOutputs,
+		recordTime:  c.recordTime,
+		retryFailed: c.retryFailed,
+		state:       c.state.DeepCopy(),
+		stopOnError: c.stopOnError,
+		targets:     targetRaw.([]string),
+		targetDepth: c.targetDepth,
+		targetMods:  c.targetMods,
+		variables:   varRaw.(map[string]interface{}),
+		warnUnused:  c.warnUnused,
+
+		loadedModule: c.loadedModule,
 
 		// NOTE(mitchellh): This is not going to work for shadows that are
 		// testing that input results in the proper end state. At the time
@@ -77,15 +87,25 @@ func newShadowContext(c *Context) (*Context, *Context, Shadow) {
 		destroy: c.destroy,
 		diff:    c.diff,
 		// diffLock - no copy
-		hooks:  c.hooks,
-		meta:   c.meta,
-		module: c.module,
-		sh:     c.sh,
-		state:  c.state,
+		hooks:       c.hooks,
+		meta:        c.meta,
+		module:      c.module,
+		sh:          c.sh,
+		modBarriers: c.modBarriers,
+		keepOutputs: c.keepOutputs,
+		recordTime:  c.recordTime,
+		retryFailed: c.retryFailed,
+		state:       c.state,
 		// stateLock - no copy
-		targets:   c.targets,
-		uiInput:   c.uiInput,
-		variables: c.variables,
+		stopOnError: c.stopOnError,
+		targets:     c.targets,
+		targetDepth: c.targetDepth,
+		targetMods:  c.targetMods,
+		uiInput:     c.uiInput,
+		variables:   c.variables,
+		warnUnused:  c.warnUnused,
+
+		loadedModule: c.loadedModule,
 
 		// l - no copy
 		parallelSem:         c.parallelSem,
@@ -43,8 +43,10 @@ func newShadowResourceProvider(p ResourceProvider) (ResourceProvider, shadowReso
 	shadow := &shadowResourceProviderShadow{
 		Shared: &shared,
 
-		resources:   p.Resources(),
-		dataSources: p.DataSources(),
+		resources:    p.Resources(),
+		dataSources:  p.DataSources(),
+		providerMeta: real.ProviderMeta(),
+		equivalent:   real.Equivalent,
 	}
 
 	return real, shadow
@@ -69,6 +71,22 @@ func (p *shadowResourceProviderReal) Close() error {
 	return result
 }
 
+func (p *shadowResourceProviderReal) ProviderMeta() string {
+	if m, ok := p.ResourceProvider.(ResourceProviderMeta); ok {
+		return m.ProviderMeta()
+	}
+
+	return ""
+}
+
+func (p *shadowResourceProviderReal) Equivalent(attr, old, new string) bool {
+	if e, ok := p.ResourceProvider.(ResourceProviderEquivalence); ok {
+		return e.Equivalent(attr, old, new)
+	}
+
+	return false
+}
+
 func (p *shadowResourceProviderReal) Input(
 	input UIInput, c *ResourceConfig) (*ResourceConfig, error) {
 	cCopy := c.DeepCopy()
@@ -283,8 +301,13 @@ type shadowResourceProviderShadow struct {
 	Shared *shadowResourceProviderShared
 
 	// Cached values that are expected to not change
-	resources   []ResourceType
-	dataSources []DataSource
+	resources    []ResourceType
+	dataSources  []DataSource
+	providerMeta string
+
+	// equivalent is the Equivalent method of the real provider. It has no
+	// side effects, so the shadow just calls it.
+	equivalent func(attr, old, new string) bool
 
 	Error     error // Error is the list of errors from the shadow
 	ErrorLock sync.Mutex
@@ -332,6 +355,14 @@ func (p *shadowResourceProviderShadow) DataSources() []DataSource {
 	return p.dataSources
 }
 
+func (p *shadowResourceProviderShadow) ProviderMeta() string {
+	return p.providerMeta
+}
+
+func (p *shadowResourceProviderShadow) Equivalent(attr, old, new string) bool {
+	return p.equivalent(attr, old, new)
+}
+
 func (p *shadowResourceProviderShadow) Close() error {
 	v := p.Shared.CloseErr.Value()
 	if v == nil {
@@ -715,6 +715,32 @@ func (s *State) addModuleState(mod *ModuleState) {
 	s.sort()
 }
 
+// Prune removes any modules that have no resources and no outputs, in
+// addition to the empty structures that are normally cleaned up when the
+// state is written. The root module is never removed.
+//
+// This is useful after moving or removing items from the state, which can
+// otherwise leave around the modules they were in.
+func (s *State) Prune() {
+	if s == nil {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+
+	s.prune()
+
+	for i := 0; i < len(s.Modules); i++ {
+		mod := s.Modules[i]
+		if mod.IsRoot() || !mod.empty() {
+			continue
+		}
+
+		s.Modules = append(s.Modules[:i], s.Modules[i+1:]...)
+		i--
+	}
+}
+
 // prune is used to remove any resources that are no longer required
 func (s *State) prune() {
 	if s == nil {
@@ -1028,6 +1054,13 @@ func (m *ModuleState) IsRoot() bool {
 	return reflect.DeepEqual(m.Path, rootModulePath)
 }
 
+// empty returns true if the module has no resources and no outputs.
+func (m *ModuleState) empty() bool {
+	m.Lock()
+	defer m.Unlock()
+	return len(m.Resources) == 0 && len(m.Outputs) == 0
+}
+
 // IsDescendent returns true if other is a descendent of this module.
 func (m *ModuleState) IsDescendent(other *ModuleState) bool {
 	m.Lock()
@@ -1192,6 +1225,9 @@ func (m *ModuleState) String() string {
 		if rs.Provider != "" {
 			buf.WriteString(fmt.Sprintf("  provider = %s\n", rs.Provider))
 		}
+		if rs.ProviderMeta != "" {
+			buf.WriteString(fmt.Sprintf("  provider_meta = %s\n", rs.ProviderMeta))
+		}
 
 		var attributes map[string]string
 		if rs.Primary != nil {
@@ -1404,6 +1440,22 @@ type ResourceState struct {
 	// If the resource block contained a "provider" key, that value will be set here.
 	Provider string `json:"provider"`
 
+	// ProviderMeta is the metadata, such as its version, reported by the
+	// provider that last applied this resource. It is empty if the provider
+	// doesn't implement ResourceProviderMeta.
+	ProviderMeta string `json:"provider_meta,omitempty"`
+
+	// LastApplied is the time, in RFC 3339 format, at which the resource
+	// was last created or updated. It is only recorded when
+	// ContextOpts.RecordApplyTime is set. It isn't considered by Equal
+	// since it doesn't describe the resource itself.
+	LastApplied string `json:"last_applied,omitempty"`
+
+	// ReplaceTriggers is a hash of the values of the replace_triggered_by
+	// lifecycle argument of the resource when it was last applied. The
+	// resource is replaced when the hash changes.
+	ReplaceTriggers string `json:"replace_triggers,omitempty"`
+
 	mu sync.Mutex
 }
 
@@ -1423,6 +1475,16 @@ func (s *ResourceState) Equal(other *ResourceState) bool {
 		return false
 	}
 
+	if s.ProviderMeta != other.ProviderMeta {
+		return false
+	}
+
+	// The triggers change what the next plan does, so they're part of
+	// the state even though they don't describe the resource itself.
+	if s.ReplaceTriggers != other.ReplaceTriggers {
+		return false
+	}
+
 	// Dependencies must be equal
 	sort.Strings(s.Dependencies)
 	sort.Strings(other.Dependencies)
@@ -101,6 +101,12 @@ func (f *StateFilter) filterSingle(a *ResourceAddress) []*StateFilterResult {
 			}
 
 			if f.relevant(a, r) {
+				if a.Type != "" && a.Mode != key.Mode {
+					// Data sources and managed resources never match
+					// each other, even with the same type and name.
+					continue
+				}
+
 				if a.Name != "" && a.Name != key.Name {
 					// Name doesn't match
 					continue
@@ -118,6 +124,7 @@ func (f *StateFilter) filterSingle(a *ResourceAddress) []*StateFilterResult {
 				// Build the address for this resource
 				addr := &ResourceAddress{
 					Path:  m.Path[1:],
+					Mode:  key.Mode,
 					Name:  key.Name,
 					Type:  key.Type,
 					Index: key.Index,
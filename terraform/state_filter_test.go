@@ -29,6 +29,24 @@ func TestStateFilterFilter(t *testing.T) {
 			},
 		},
 
+		"data source": {
+			"data-source.tfstate",
+			[]string{"data.aws_ami.web"},
+			[]string{
+				"*terraform.ResourceState: data.aws_ami.web",
+				"*terraform.InstanceState: data.aws_ami.web",
+			},
+		},
+
+		"managed resource named like a data source": {
+			"data-source.tfstate",
+			[]string{"aws_ami.web"},
+			[]string{
+				"*terraform.ResourceState: aws_ami.web",
+				"*terraform.InstanceState: aws_ami.web",
+			},
+		},
+
 		"single resource": {
 			"small.tfstate",
 			[]string{"aws_key_pair.onprem"},
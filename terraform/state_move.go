@@ -0,0 +1,221 @@
+package terraform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform/config/module"
+)
+
+// stateMove is a single move of a resource or module in the state, as
+// given by a moved block in the configuration. The addresses are
+// absolute.
+type stateMove struct {
+	From string
+	To   string
+}
+
+// stateMoves returns the moves given by the moved blocks in every module
+// of the tree, sorted by module path so that moves in a parent happen
+// before the moves in its children.
+func stateMoves(t *module.Tree) ([]*stateMove, error) {
+	if t == nil {
+		return nil, nil
+	}
+
+	result := make([]*stateMove, 0)
+	if c := t.Config(); c != nil {
+		for _, m := range c.Moved {
+			from, err := stateMoveAddr(t.Path(), m.From)
+			if err != nil {
+				return nil, fmt.Errorf("moved block at %s: from: %s", m.Pos, err)
+			}
+			to, err := stateMoveAddr(t.Path(), m.To)
+			if err != nil {
+				return nil, fmt.Errorf("moved block at %s: to: %s", m.Pos, err)
+			}
+
+			result = append(result, &stateMove{From: from, To: to})
+		}
+	}
+
+	children := t.Children()
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		moves, err := stateMoves(children[name])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, moves...)
+	}
+
+	return result, nil
+}
+
+// stateMoveAddr turns an address relative to the module at the given
+// path into an absolute address.
+func stateMoveAddr(path []string, raw string) (string, error) {
+	addr, err := ParseResourceAddress(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if len(path) > 0 {
+		full := make([]string, 0, len(path)+len(addr.Path))
+		full = append(full, path...)
+		addr.Path = append(full, addr.Path...)
+	}
+
+	return addr.String(), nil
+}
+
+// Move moves the item in the state at the address from to the address to,
+// along with all of its children. It returns false if there is nothing in
+// the state at from, in which case the state is left unchanged. It is an
+// error if something already exists at to.
+func (s *State) Move(from, to string) (bool, error) {
+	results, err := (&StateFilter{State: s}).Filter(from)
+	if err != nil {
+		return false, err
+	}
+	if len(results) == 0 {
+		return false, nil
+	}
+
+	existing, err := (&StateFilter{State: s}).Filter(to)
+	if err != nil {
+		return false, err
+	}
+	if len(existing) > 0 {
+		return false, fmt.Errorf(
+			"cannot move %s to %s: %s already exists in the state", from, to, to)
+	}
+
+	value := stateMoveValue(results)
+	if err := s.Remove(from); err != nil {
+		return false, err
+	}
+	if err := s.Add(from, to, value); err != nil {
+		return false, err
+	}
+	if err := s.RenameDependencies(from, to); err != nil {
+		return false, err
+	}
+
+	s.Prune()
+	return true, nil
+}
+
+// RenameDependencies updates the dependencies of every resource in the
+// state that refer to the item at the address from, or to anything in it,
+// to refer to the address to instead. This is used after the item is
+// moved, so that the resources that depend on it keep doing so.
+//
+// Dependencies are relative to the module of the resource, so a dependency
+// on something moved to another module is given with its full address.
+func (s *State) RenameDependencies(fromAddrRaw, toAddrRaw string) error {
+	fromAddr, err := ParseResourceAddress(fromAddrRaw)
+	if err != nil {
+		return err
+	}
+	toAddr, err := ParseResourceAddress(toAddrRaw)
+	if err != nil {
+		return err
+	}
+
+	// A resource moved to a module keeps its name
+	if toAddr.Type == "" && fromAddr.Type != "" {
+		toAddr.Mode = fromAddr.Mode
+		toAddr.Type = fromAddr.Type
+		toAddr.Name = fromAddr.Name
+		toAddr.Index = fromAddr.Index
+	}
+
+	from := dependencyAddr(fromAddr)
+	to := dependencyAddr(toAddr)
+
+	s.Lock()
+	defer s.Unlock()
+
+	for _, ms := range s.Modules {
+		prefix := ""
+		for _, p := range ms.Path[1:] {
+			prefix += "module." + p + "."
+		}
+
+		for _, rs := range ms.Resources {
+			for i, dep := range rs.Dependencies {
+				abs := prefix + dep
+				if abs != from && !strings.HasPrefix(abs, from+".") {
+					continue
+				}
+
+				renamed := to + abs[len(from):]
+				if strings.HasPrefix(renamed, prefix) {
+					renamed = renamed[len(prefix):]
+				}
+				rs.Dependencies[i] = renamed
+			}
+		}
+	}
+
+	return nil
+}
+
+// dependencyAddr returns the address in the form used by the dependencies
+// in the state, which give the index of a resource as a suffix rather than
+// in brackets.
+func dependencyAddr(addr *ResourceAddress) string {
+	addr = addr.Copy()
+	index := addr.Index
+	addr.Index = -1
+	addr.InstanceTypeSet = false
+
+	result := addr.String()
+	if index >= 0 {
+		result += fmt.Sprintf(".%d", index)
+	}
+
+	return result
+}
+
+// stateMoveValue returns the value to give to State.Add for the results
+// of filtering the state by the address that is moved.
+func stateMoveValue(results []*StateFilterResult) interface{} {
+	switch v := results[0].Value.(type) {
+	case *ModuleState:
+		// A module is moved along with all of its children
+		result := []*ModuleState{v}
+		for _, r := range results[1:] {
+			if ms, ok := r.Value.(*ModuleState); ok {
+				result = append(result, ms)
+			}
+		}
+
+		return result
+
+	case *ResourceState:
+		// A resource with a count is moved along with all of its instances
+		result := []*ResourceState{v}
+		for _, r := range results[1:] {
+			if rs, ok := r.Value.(*ResourceState); ok && rs.Type == v.Type {
+				result = append(result, rs)
+			}
+		}
+
+		if len(result) == 1 {
+			return result[0]
+		}
+
+		return result
+
+	default:
+		return v
+	}
+}
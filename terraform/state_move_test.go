@@ -0,0 +1,88 @@
+package terraform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStateRenameDependencies(t *testing.T) {
+	cases := map[string]struct {
+		From, To string
+		Path     []string
+		Deps     []string
+		Expected []string
+	}{
+		"resource": {
+			"aws_instance.foo",
+			"aws_instance.bar",
+			rootModulePath,
+			[]string{"aws_instance.foo", "aws_instance.foobar"},
+			[]string{"aws_instance.bar", "aws_instance.foobar"},
+		},
+
+		"counted resource": {
+			"aws_instance.foo",
+			"aws_instance.bar",
+			rootModulePath,
+			[]string{"aws_instance.foo.*", "aws_instance.foo.1"},
+			[]string{"aws_instance.bar.*", "aws_instance.bar.1"},
+		},
+
+		"index": {
+			"aws_instance.foo[1]",
+			"aws_instance.bar",
+			rootModulePath,
+			[]string{"aws_instance.foo.0", "aws_instance.foo.1"},
+			[]string{"aws_instance.foo.0", "aws_instance.bar"},
+		},
+
+		"module": {
+			"module.old",
+			"module.new",
+			rootModulePath,
+			[]string{"module.old.output.id", "module.older.output.id"},
+			[]string{"module.new.output.id", "module.older.output.id"},
+		},
+
+		"in module": {
+			"module.child.aws_instance.foo",
+			"module.child.aws_instance.bar",
+			[]string{"root", "child"},
+			[]string{"aws_instance.foo"},
+			[]string{"aws_instance.bar"},
+		},
+
+		"to other module": {
+			"aws_instance.foo",
+			"module.child",
+			rootModulePath,
+			[]string{"aws_instance.foo"},
+			[]string{"module.child.aws_instance.foo"},
+		},
+	}
+
+	for name, tc := range cases {
+		s := &State{
+			Modules: []*ModuleState{
+				&ModuleState{
+					Path: tc.Path,
+					Resources: map[string]*ResourceState{
+						"aws_instance.dependent": &ResourceState{
+							Type:         "aws_instance",
+							Dependencies: tc.Deps,
+						},
+					},
+				},
+			},
+		}
+
+		if err := s.RenameDependencies(tc.From, tc.To); err != nil {
+			t.Fatalf("%s: err: %s", name, err)
+		}
+
+		actual := s.Modules[0].Resources["aws_instance.dependent"].Dependencies
+		if !reflect.DeepEqual(actual, tc.Expected) {
+			t.Fatalf("%s: bad: %#v", name, actual)
+		}
+	}
+}
@@ -690,6 +690,36 @@ func TestStateIncrementSerialMaybe(t *testing.T) {
 			},
 			4,
 		},
+		"S2 is different, but only via the replace triggers": {
+			&State{
+				Serial: 3,
+				Modules: []*ModuleState{
+					&ModuleState{
+						Path: rootModulePath,
+						Resources: map[string]*ResourceState{
+							"test_instance.foo": &ResourceState{
+								Primary: &InstanceState{ID: "foo"},
+							},
+						},
+					},
+				},
+			},
+			&State{
+				Serial: 3,
+				Modules: []*ModuleState{
+					&ModuleState{
+						Path: rootModulePath,
+						Resources: map[string]*ResourceState{
+							"test_instance.foo": &ResourceState{
+								Primary:         &InstanceState{ID: "foo"},
+								ReplaceTriggers: "abc123",
+							},
+						},
+					},
+				},
+			},
+			4,
+		},
 		"S1 serial is higher": {
 			&State{Serial: 5},
 			&State{
@@ -1000,6 +1030,65 @@ func TestStateRemove(t *testing.T) {
 	}
 }
 
+func TestStatePrune(t *testing.T) {
+	state := &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: rootModulePath,
+			},
+			&ModuleState{
+				Path: []string{"root", "empty"},
+			},
+			&ModuleState{
+				Path: []string{"root", "empty", "nested"},
+				Resources: map[string]*ResourceState{
+					// Has no primary, so prune removes it and then the
+					// module it was in.
+					"test_instance.foo": &ResourceState{
+						Type: "test_instance",
+					},
+				},
+			},
+			&ModuleState{
+				Path: []string{"root", "outputs"},
+				Outputs: map[string]*OutputState{
+					"foo": &OutputState{
+						Type:  "string",
+						Value: "bar",
+					},
+				},
+			},
+			&ModuleState{
+				Path: []string{"root", "resources"},
+				Resources: map[string]*ResourceState{
+					"test_instance.foo": &ResourceState{
+						Type: "test_instance",
+						Primary: &InstanceState{
+							ID: "foo",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	state.Prune()
+
+	var actual [][]string
+	for _, mod := range state.Modules {
+		actual = append(actual, mod.Path)
+	}
+
+	expected := [][]string{
+		rootModulePath,
+		[]string{"root", "outputs"},
+		[]string{"root", "resources"},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("bad: %#v", actual)
+	}
+}
+
 func TestResourceStateEqual(t *testing.T) {
 	cases := []struct {
 		Result   bool
@@ -165,6 +165,10 @@ func testProvisionerFuncFixed(rp ResourceProvisioner) ResourceProvisionerFactory
 	}
 }
 
+func testIntPtr(v int) *int {
+	return &v
+}
+
 // HookRecordApplyOrder is a test hook that records the order of applies
 // by recording the PreApply event.
 type HookRecordApplyOrder struct {
@@ -460,6 +464,42 @@ module.child:
     type = aws_instance
 `
 
+const testTerraformApplyModuleCountStr = `
+aws_instance.all:
+  ID = foo
+  foo = web-0,web-1
+  type = aws_instance
+
+  Dependencies:
+    module.child
+aws_instance.first:
+  ID = foo
+  foo = web-0
+  type = aws_instance
+
+  Dependencies:
+    module.child[0]
+
+module.child[0]:
+  aws_instance.foo:
+    ID = foo
+    foo = web-0
+    type = aws_instance
+
+  Outputs:
+
+  name = web-0
+module.child[1]:
+  aws_instance.foo:
+    ID = foo
+    foo = web-1
+    type = aws_instance
+
+  Outputs:
+
+  name = web-1
+`
+
 const testTerraformApplyModuleBoolStr = `
 aws_instance.bar:
   ID = foo
@@ -1372,6 +1412,24 @@ STATE:
 <no state>
 `
 
+const testTerraformPlanModuleCountStr = `
+DIFF:
+
+module.child[0]:
+module.child[1]:
+  DESTROY: aws_instance.foo
+
+STATE:
+
+module.child[0]:
+  aws_instance.foo:
+    ID = bar
+    foo = web-0
+module.child[1]:
+  aws_instance.foo:
+    ID = baz
+`
+
 const testTerraformPlanModuleOrphansStr = `
 DIFF:
 
@@ -1436,6 +1494,28 @@ STATE:
 <no state>
 `
 
+const testTerraformPlanModuleComputedOutputFuncsStr = `
+DIFF:
+
+CREATE: aws_instance.bar
+  foo:   "" => "<computed>"
+  num:   "" => "<computed>"
+  type:  "" => "aws_instance"
+  value: "" => "<computed>"
+
+module.child:
+  CREATE: aws_instance.foo.0
+    foo:  "" => "<computed>"
+    type: "" => "aws_instance"
+  CREATE: aws_instance.foo.1
+    foo:  "" => "<computed>"
+    type: "" => "aws_instance"
+
+STATE:
+
+<no state>
+`
+
 const testTerraformPlanModuleVarIntStr = `
 DIFF:
 
@@ -1645,3 +1725,47 @@ data.null_data_source.foo:
   foo = yes
   type = null_data_source
 `
+
+const testTerraformPlanMovedStr = `
+DIFF:
+
+UPDATE: aws_instance.d
+  foo:  "" => "baz"
+  type: "" => "aws_instance"
+
+STATE:
+
+aws_instance.b:
+  ID = a
+  foo = bar
+  type = aws_instance
+aws_instance.d:
+  ID = c
+  foo = bar
+  type = aws_instance
+`
+
+const testTerraformPlanStubProvidersStr = `
+DIFF:
+
+CREATE: aws_instance.web
+  ami:         "" => "web"
+  tags.#:      "" => "1"
+  tags.0.Name: "" => "web"
+CREATE: google_compute_instance.web
+  metadata.#: "" => "1"
+  metadata.0: "" => "<computed>"
+  name:       "" => "web"
+
+module.child:
+  CREATE: aws_instance.child.0
+    ami: "" => "web"
+  CREATE: aws_instance.child.1
+    ami: "" => "web"
+
+STATE:
+
+data.aws_ami.web:
+  ID = data.aws_ami.web
+  name = web
+`
@@ -0,0 +1,16 @@
+variable "enabled" {
+  default = true
+}
+
+resource "aws_instance" "foo" {
+  count = "${var.enabled ? 1 : 0}"
+  foo   = "foo"
+}
+
+resource "aws_instance" "bar" {
+  foo = "${aws_instance.foo.foo}"
+}
+
+output "id" {
+  value = "${aws_instance.foo.id}"
+}
@@ -0,0 +1,21 @@
+variable "enabled" {
+  default = false
+}
+
+resource "aws_instance" "foo" {
+  count = "${var.enabled ? 1 : 0}"
+  foo   = "foo"
+}
+
+resource "aws_instance" "bar" {
+  foo = "${join(",", aws_instance.foo.*.foo)}"
+  bar = "x${aws_instance.foo.foo}"
+}
+
+output "id" {
+  value = "${aws_instance.foo.id}"
+}
+
+output "ids" {
+  value = "${join(",", aws_instance.foo.*.id)}"
+}
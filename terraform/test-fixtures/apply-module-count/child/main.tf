@@ -0,0 +1,9 @@
+variable "name" {}
+
+resource "aws_instance" "foo" {
+    foo = "${var.name}"
+}
+
+output "name" {
+    value = "${aws_instance.foo.foo}"
+}
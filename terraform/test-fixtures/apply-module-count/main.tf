@@ -0,0 +1,17 @@
+variable "num" {
+    default = 2
+}
+
+module "child" {
+    source = "./child"
+    count = "${var.num}"
+    name = "web-${count.index}"
+}
+
+resource "aws_instance" "all" {
+    foo = "${join(",", module.child.*.name)}"
+}
+
+resource "aws_instance" "first" {
+    foo = "${module.child.0.name}"
+}
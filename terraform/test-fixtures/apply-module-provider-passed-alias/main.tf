@@ -0,0 +1,16 @@
+provider "aws" {
+    root = "1"
+}
+
+provider "aws" {
+    west = "1"
+    alias = "west"
+}
+
+module "child" {
+    source = "./child"
+
+    providers = {
+        aws = "aws.west"
+    }
+}
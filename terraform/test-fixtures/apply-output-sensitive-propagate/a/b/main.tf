@@ -0,0 +1,5 @@
+variable "input" {}
+
+output "value" {
+  value = "${var.input}"
+}
@@ -0,0 +1,14 @@
+variable "secret" {}
+
+module "b" {
+  source = "./b"
+  input  = "prefix-${var.secret}"
+}
+
+output "value" {
+  value = "${module.b.value}"
+}
+
+output "plain" {
+  value = "foo"
+}
@@ -0,0 +1,17 @@
+variable "secret" {
+  default   = "hunter2"
+  sensitive = true
+}
+
+module "a" {
+  source = "./a"
+  secret = "${var.secret}"
+}
+
+output "leaked" {
+  value = "${module.a.value}"
+}
+
+output "plain" {
+  value = "${module.a.plain}"
+}
@@ -0,0 +1,12 @@
+provider "aws" {
+  alias = "east"
+}
+
+provider "aws" {
+  alias = "west"
+}
+
+resource "aws_instance" "foo" {
+  count    = 4
+  provider = ["aws.east", "aws.west"]
+}
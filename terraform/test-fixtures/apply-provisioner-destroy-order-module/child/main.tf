@@ -0,0 +1,5 @@
+variable "id" {}
+
+resource "aws_instance" "a" {
+    foo = "${var.id}"
+}
@@ -0,0 +1,11 @@
+resource "aws_instance" "b" {
+    provisioner "shell" {
+        foo  = "destroy"
+        when = "destroy"
+    }
+}
+
+module "child" {
+    source = "./child"
+    id     = "${aws_instance.b.id}"
+}
@@ -0,0 +1,10 @@
+resource "aws_instance" "b" {
+    provisioner "shell" {
+        foo  = "destroy"
+        when = "destroy"
+    }
+}
+
+resource "aws_instance" "a" {
+    foo = "${aws_instance.b.id}"
+}
@@ -0,0 +1,5 @@
+resource "aws_instance" "foo" {
+    count = 3
+
+    provisioner "shell" {}
+}
@@ -0,0 +1,11 @@
+resource "aws_instance" "create" {
+  foo = "bar"
+}
+
+resource "aws_instance" "update" {
+  foo = "new"
+}
+
+resource "aws_instance" "noop" {
+  foo = "bar"
+}
@@ -0,0 +1,23 @@
+variable "version" {
+  default = "1"
+}
+
+variable "image" {
+  default = "a"
+}
+
+resource "aws_instance" "image" {
+  foo = "${var.image}"
+}
+
+resource "aws_instance" "foo" {
+  foo = "bar"
+
+  lifecycle {
+    replace_triggered_by = ["${var.version}", "${aws_instance.image.foo}"]
+  }
+}
+
+resource "aws_instance" "bar" {
+  foo = "bar"
+}
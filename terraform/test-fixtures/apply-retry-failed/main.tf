@@ -0,0 +1,11 @@
+resource "aws_instance" "foo" {
+    num = "2"
+}
+
+resource "aws_instance" "bar" {
+    num = "3"
+}
+
+resource "aws_instance" "baz" {
+    foo = "${aws_instance.bar.id}"
+}
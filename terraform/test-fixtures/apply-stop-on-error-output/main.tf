@@ -0,0 +1,11 @@
+resource "aws_instance" "foo" {
+    num = "2"
+}
+
+resource "aws_instance" "bar" {
+    num = "3"
+}
+
+output "foo_num" {
+    value = "${aws_instance.foo.num}"
+}
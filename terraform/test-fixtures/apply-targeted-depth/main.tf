@@ -0,0 +1,11 @@
+resource "aws_instance" "foo" {
+    num = "3"
+}
+
+resource "aws_instance" "bar" {
+    foo = "${aws_instance.foo.num}"
+}
+
+resource "aws_instance" "baz" {
+    foo = "${aws_instance.bar.foo}"
+}
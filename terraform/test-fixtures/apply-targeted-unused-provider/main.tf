@@ -0,0 +1,15 @@
+provider "aws" {
+  region = "us-east-1"
+}
+
+provider "do" {
+  token = "secret"
+}
+
+resource "aws_instance" "foo" {
+  num = "2"
+}
+
+resource "do_droplet" "bar" {
+  num = "2"
+}
@@ -0,0 +1,5 @@
+resource "aws_instance" "a" {}
+
+resource "aws_instance" "b" {
+  foo = "${aws_instance.a.id}"
+}
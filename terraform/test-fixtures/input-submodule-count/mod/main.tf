@@ -1,8 +1,8 @@
-variable "count" {
+variable "num" {
 }
 
 resource "aws_instance" "foo" {
-  count = "${var.count}"
+  count = "${var.num}"
 }
 
 module "submod" {
@@ -0,0 +1,3 @@
+resource "aws_instance" "foo" {
+  policy = "{\"b\": 1, \"a\": [2, 3]}"
+}
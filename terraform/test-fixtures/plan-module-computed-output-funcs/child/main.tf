@@ -0,0 +1,12 @@
+resource "aws_instance" "foo" {
+    count   = 2
+    compute = "foo"
+}
+
+output "ids" {
+    value = ["${aws_instance.foo.*.foo}"]
+}
+
+output "tags" {
+    value = "${map("name", aws_instance.foo.0.foo)}"
+}
@@ -0,0 +1,9 @@
+module "child" {
+    source = "./child"
+}
+
+resource "aws_instance" "bar" {
+    foo   = "${element(module.child.ids, 0)}"
+    num   = "${length(module.child.ids)}"
+    value = "${lookup(module.child.tags, "name")}"
+}
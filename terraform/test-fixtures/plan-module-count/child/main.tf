@@ -0,0 +1,5 @@
+variable "name" {}
+
+resource "aws_instance" "foo" {
+    foo = "${var.name}"
+}
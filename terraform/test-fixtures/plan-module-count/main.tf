@@ -0,0 +1,7 @@
+variable "num" {}
+
+module "child" {
+    source = "./child"
+    count = "${var.num}"
+    name = "web-${count.index}"
+}
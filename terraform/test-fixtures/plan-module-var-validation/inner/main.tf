@@ -0,0 +1,8 @@
+variable "env" {
+    validation {
+        regex = "^prod-"
+    }
+}
+
+// We have to reference it so it isn't pruned
+output "output" { value = "${var.env}" }
@@ -0,0 +1,5 @@
+module "test" {
+    source = "./inner"
+
+    env = "dev-east"
+}
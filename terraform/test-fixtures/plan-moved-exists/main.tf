@@ -0,0 +1,8 @@
+resource "aws_instance" "b" {
+  foo = "bar"
+}
+
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+}
@@ -0,0 +1,17 @@
+resource "aws_instance" "b" {
+  foo = "bar"
+}
+
+resource "aws_instance" "d" {
+  foo = "baz"
+}
+
+moved {
+  from = "aws_instance.a"
+  to   = "aws_instance.b"
+}
+
+moved {
+  from = "aws_instance.c"
+  to   = "aws_instance.d"
+}
@@ -0,0 +1,12 @@
+resource "aws_instance" "foo" {
+    count = 0
+    foo = "bar"
+}
+
+output "single" {
+    value = "${aws_instance.foo.id}"
+}
+
+output "splat" {
+    value = "${aws_instance.foo.*.id}"
+}
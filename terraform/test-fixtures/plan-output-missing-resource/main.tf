@@ -0,0 +1,7 @@
+resource "aws_instance" "foo" {
+    foo = "bar"
+}
+
+output "gone" {
+    value = "${aws_instance.gone.id}"
+}
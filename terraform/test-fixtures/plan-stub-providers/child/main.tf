@@ -0,0 +1,6 @@
+variable "ami" {}
+
+resource "aws_instance" "child" {
+  ami   = "${var.ami}"
+  count = 2
+}
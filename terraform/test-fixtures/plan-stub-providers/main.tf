@@ -0,0 +1,24 @@
+provider "aws" {
+  region = "us-east-1"
+}
+
+data "aws_ami" "web" {
+  name = "web"
+}
+
+resource "aws_instance" "web" {
+  ami  = "${data.aws_ami.web.name}"
+  tags = {
+    Name = "web"
+  }
+}
+
+resource "google_compute_instance" "web" {
+  name     = "web"
+  metadata = ["${aws_instance.web.id}"]
+}
+
+module "child" {
+  source = "./child"
+  ami    = "${aws_instance.web.ami}"
+}
@@ -0,0 +1,4 @@
+resource "aws_instance" "nested" {
+  count = 2
+  foo   = "nested"
+}
@@ -0,0 +1,9 @@
+variable "input" {}
+
+resource "aws_instance" "foo" {
+  foo = "${var.input}"
+}
+
+module "child" {
+  source = "./child"
+}
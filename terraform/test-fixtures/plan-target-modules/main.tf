@@ -0,0 +1,16 @@
+resource "aws_instance" "dep" {
+  foo = "dep"
+}
+
+resource "aws_instance" "outside" {
+  foo = "outside"
+}
+
+module "A" {
+  source = "./A"
+  input  = "${aws_instance.dep.foo}"
+}
+
+module "B" {
+  source = "./B"
+}
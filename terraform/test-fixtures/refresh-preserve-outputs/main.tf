@@ -0,0 +1,11 @@
+resource "aws_instance" "foo" {}
+
+resource "aws_instance" "bar" {}
+
+output "foo_id" {
+    value = "${aws_instance.foo.id}"
+}
+
+output "bar_foo" {
+    value = "${aws_instance.bar.foo}"
+}
@@ -0,0 +1,13 @@
+variable "env" {
+    validation {
+        regex = "^prod-"
+    }
+}
+
+variable "token" {
+    sensitive = true
+
+    validation {
+        regex = "^[a-f0-9]+$"
+    }
+}
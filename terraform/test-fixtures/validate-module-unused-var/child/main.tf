@@ -0,0 +1,6 @@
+variable "used" {}
+variable "unused" {}
+
+resource "aws_instance" "foo" {
+  value = "${var.used}"
+}
@@ -0,0 +1,13 @@
+variable "used" {
+  default = "foo"
+}
+
+variable "unused" {
+  default = "bar"
+}
+
+module "child" {
+  source = "./child"
+  used   = "${var.used}"
+  unused = "bar"
+}
@@ -0,0 +1,5 @@
+resource "aws_instance" "web" {
+    tags {
+        Name = "${aws_instance.web.id}"
+    }
+}
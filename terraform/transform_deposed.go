@@ -54,10 +54,30 @@ type graphNodeDeposedResource struct {
 	ResourceName string
 	ResourceType string
 	Provider     string
+
+	// PathValue is the module path of the resource. This is only set
+	// when the node is added to a graph spanning multiple modules.
+	PathValue []string
 }
 
 func (n *graphNodeDeposedResource) Name() string {
-	return fmt.Sprintf("%s (deposed #%d)", n.ResourceName, n.Index)
+	name := fmt.Sprintf("%s (deposed #%d)", n.ResourceName, n.Index)
+	if path := normalizeModulePath(n.PathValue); len(path) > len(rootModulePath) {
+		name = modulePrefixStr(path) + "." + name
+	}
+
+	return name
+}
+
+// GraphNodeSubPath
+func (n *graphNodeDeposedResource) Path() []string {
+	return n.PathValue
+}
+
+// RemovableIfNotTargeted. Deposed instances are only handled when nothing
+// is targeted, since they can't be targeted themselves.
+func (n *graphNodeDeposedResource) RemoveIfNotTargeted() bool {
+	return true
 }
 
 func (n *graphNodeDeposedResource) ProvidedBy() []string {
@@ -106,8 +126,29 @@ func (n *graphNodeDeposedResource) EvalTree() EvalNode {
 		},
 	})
 
-	// Apply
+	// Plan the destroy of the deposed instances, so that they're destroyed
+	// even if the resource has no primary instance to destroy.
 	var diff *InstanceDiff
+	seq.Nodes = append(seq.Nodes, &EvalOpFilter{
+		Ops: []walkOperation{walkPlanDestroy},
+		Node: &EvalSequence{
+			Nodes: []EvalNode{
+				&EvalReadDiff{
+					Name: n.ResourceName,
+					Diff: &diff,
+				},
+				&EvalDiffDestroyDeposed{
+					Diff: &diff,
+				},
+				&EvalWriteDiff{
+					Name: n.ResourceName,
+					Diff: &diff,
+				},
+			},
+		},
+	})
+
+	// Apply
 	var err error
 	seq.Nodes = append(seq.Nodes, &EvalOpFilter{
 		Ops: []walkOperation{walkApply, walkDestroy},
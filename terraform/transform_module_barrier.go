@@ -0,0 +1,166 @@
+package terraform
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/hashicorp/terraform/dag"
+)
+
+// ModuleBarrierTransformer is a GraphTransformer that serializes the
+// resources of child modules at module granularity.
+//
+// A barrier vertex is added for every child module that depends on all of
+// that module's resources. The modules are then ordered so that existing
+// dependencies between them are respected (ties are broken by module
+// path) and each module's resources are made to depend on the barrier of
+// the module before it. The result is that no resource in a module starts
+// until every resource in the previous module has completed, even when
+// there are no data dependencies between them.
+//
+// Resources in the root module are not affected.
+type ModuleBarrierTransformer struct{}
+
+func (t *ModuleBarrierTransformer) Transform(g *Graph) error {
+	// Group the resources by the module they're in
+	modules := make(map[string]*moduleBarrierGroup)
+	for _, v := range g.Vertices() {
+		if _, ok := v.(GraphNodeResource); !ok {
+			continue
+		}
+		pn, ok := v.(GraphNodeSubPath)
+		if !ok {
+			continue
+		}
+
+		path := normalizeModulePath(pn.Path())
+		if len(path) <= len(rootModulePath) {
+			continue
+		}
+
+		key := modulePrefixStr(path)
+		group, ok := modules[key]
+		if !ok {
+			group = &moduleBarrierGroup{
+				Node: &graphNodeModuleBarrier{PathValue: path},
+			}
+			modules[key] = group
+		}
+
+		group.Resources = append(group.Resources, v)
+	}
+	if len(modules) == 0 {
+		return nil
+	}
+
+	// Record which vertices belong to which module so we can determine
+	// the dependencies between modules.
+	owner := make(map[dag.Vertex]string)
+	for k, group := range modules {
+		for _, v := range group.Resources {
+			owner[v] = k
+		}
+	}
+
+	deps := make(map[string]map[string]struct{})
+	for k, group := range modules {
+		deps[k] = make(map[string]struct{})
+		for _, v := range group.Resources {
+			ancestors, err := g.Ancestors(v)
+			if err != nil {
+				return err
+			}
+
+			for _, a := range ancestors.List() {
+				if other, ok := owner[a]; ok && other != k {
+					deps[k][other] = struct{}{}
+				}
+			}
+		}
+	}
+
+	// Add the barriers, each depending on all the resources of its module
+	for _, group := range modules {
+		g.Add(group.Node)
+		for _, v := range group.Resources {
+			g.Connect(dag.BasicEdge(group.Node, v))
+		}
+	}
+
+	// Chain the modules in dependency order
+	order, rest := moduleBarrierOrder(deps)
+	if len(rest) > 0 {
+		log.Printf(
+			"[WARN] ModuleBarrierTransformer: modules depend on each other, "+
+				"not serializing: %v", rest)
+	}
+	for i := 1; i < len(order); i++ {
+		prev := modules[order[i-1]]
+		for _, v := range modules[order[i]].Resources {
+			g.Connect(dag.BasicEdge(v, prev.Node))
+		}
+	}
+
+	return nil
+}
+
+// moduleBarrierOrder returns the module keys of deps ordered so that every
+// module comes after the modules it depends on. Modules that can't be
+// ordered due to a cycle between them are returned separately.
+func moduleBarrierOrder(deps map[string]map[string]struct{}) ([]string, []string) {
+	remaining := make(map[string]struct{}, len(deps))
+	for k := range deps {
+		remaining[k] = struct{}{}
+	}
+
+	var order []string
+	for len(remaining) > 0 {
+		var ready []string
+		for k := range remaining {
+			ok := true
+			for dep := range deps[k] {
+				if _, waiting := remaining[dep]; waiting {
+					ok = false
+					break
+				}
+			}
+			if ok {
+				ready = append(ready, k)
+			}
+		}
+		if len(ready) == 0 {
+			break
+		}
+
+		// Only take the first ready module so that ties are always
+		// broken by module path.
+		sort.Strings(ready)
+		order = append(order, ready[0])
+		delete(remaining, ready[0])
+	}
+
+	rest := make([]string, 0, len(remaining))
+	for k := range remaining {
+		rest = append(rest, k)
+	}
+	sort.Strings(rest)
+
+	return order, rest
+}
+
+// moduleBarrierGroup is the set of resources within a single module.
+type moduleBarrierGroup struct {
+	Node      *graphNodeModuleBarrier
+	Resources []dag.Vertex
+}
+
+// graphNodeModuleBarrier is the vertex that completes only once all the
+// resources in a module have completed.
+type graphNodeModuleBarrier struct {
+	PathValue []string
+}
+
+func (n *graphNodeModuleBarrier) Name() string {
+	return fmt.Sprintf("%s (barrier)", modulePrefixStr(n.PathValue))
+}
@@ -0,0 +1,117 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/config"
+	"github.com/hashicorp/terraform/dag"
+)
+
+func TestModuleBarrierTransformer(t *testing.T) {
+	g := Graph{Path: RootModulePath}
+
+	node := func(path []string, name string) dag.Vertex {
+		return g.Add(&NodeAbstractResource{
+			Addr: &ResourceAddress{
+				Path:  path,
+				Type:  "aws_instance",
+				Name:  name,
+				Index: -1,
+				Mode:  config.ManagedResourceMode,
+			},
+		})
+	}
+
+	g.Add(&NodeAbstractResource{
+		Addr: &ResourceAddress{
+			Type:  "aws_instance",
+			Name:  "root",
+			Index: -1,
+			Mode:  config.ManagedResourceMode,
+		},
+	})
+	aFoo := node([]string{"a"}, "foo")
+	node([]string{"a"}, "bar")
+	node([]string{"b"}, "foo")
+	cFoo := node([]string{"c"}, "foo")
+
+	// c must still come after a, but b goes in between since a and b
+	// are tied and broken by name.
+	g.Connect(dag.BasicEdge(cFoo, aFoo))
+
+	tf := &ModuleBarrierTransformer{}
+	if err := tf.Transform(&g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testModuleBarrierTransformerStr)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
+
+func TestModuleBarrierTransformer_dependencyOrder(t *testing.T) {
+	g := Graph{Path: RootModulePath}
+
+	node := func(path []string) dag.Vertex {
+		return g.Add(&NodeAbstractResource{
+			Addr: &ResourceAddress{
+				Path:  path,
+				Type:  "aws_instance",
+				Name:  "foo",
+				Index: -1,
+				Mode:  config.ManagedResourceMode,
+			},
+		})
+	}
+
+	// a depends on b, so b must be serialized first despite its name
+	aFoo := node([]string{"a"})
+	bFoo := node([]string{"b"})
+	g.Connect(dag.BasicEdge(aFoo, bFoo))
+
+	tf := &ModuleBarrierTransformer{}
+	if err := tf.Transform(&g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if err := g.Validate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testModuleBarrierTransformerDepStr)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
+
+const testModuleBarrierTransformerStr = `
+aws_instance.root
+module.a (barrier)
+  module.a.aws_instance.bar
+  module.a.aws_instance.foo
+module.a.aws_instance.bar
+module.a.aws_instance.foo
+module.b (barrier)
+  module.b.aws_instance.foo
+module.b.aws_instance.foo
+  module.a (barrier)
+module.c (barrier)
+  module.c.aws_instance.foo
+module.c.aws_instance.foo
+  module.a.aws_instance.foo
+  module.b (barrier)
+`
+
+const testModuleBarrierTransformerDepStr = `
+module.a (barrier)
+  module.a.aws_instance.foo
+module.a.aws_instance.foo
+  module.b (barrier)
+  module.b.aws_instance.foo
+module.b (barrier)
+  module.b.aws_instance.foo
+module.b.aws_instance.foo
+`
@@ -13,10 +13,13 @@ import (
 //
 // This only adds variables that are referenced by other things in the graph.
 // If a module variable is not referenced, it won't be added to the graph.
+// If ReportUnused is set, a NodeUnusedVariable is added in its place
+// so that the variable is reported as a warning during validation.
 type ModuleVariableTransformer struct {
 	Module *module.Tree
 
 	DisablePrune bool // True if pruning unreferenced should be disabled
+	ReportUnused bool // True if pruned variables should be reported
 }
 
 func (t *ModuleVariableTransformer) Transform(g *Graph) error {
@@ -108,6 +111,14 @@ func (t *ModuleVariableTransformer) transformSingle(g *Graph, parent, m *module.
 				log.Printf(
 					"[INFO] Not including %q in graph, nothing depends on it",
 					dag.VertexName(node))
+
+				if t.ReportUnused {
+					g.Add(&NodeUnusedVariable{
+						PathValue: node.PathValue,
+						Config:    v,
+					})
+				}
+
 				continue
 			}
 		}
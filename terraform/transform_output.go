@@ -1,6 +1,10 @@
 package terraform
 
 import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform/config"
 	"github.com/hashicorp/terraform/config/module"
 )
 
@@ -12,13 +16,19 @@ import (
 // even if the dependent items aren't changing.
 type OutputTransformer struct {
 	Module *module.Tree
+
+	// KeepPrevious, if true, makes the outputs keep the value they have in
+	// the state when their new value can't be determined.
+	KeepPrevious bool
 }
 
 func (t *OutputTransformer) Transform(g *Graph) error {
-	return t.transform(g, t.Module)
+	s := &outputSensitivity{Root: t.Module}
+	return t.transform(g, t.Module, s)
 }
 
-func (t *OutputTransformer) transform(g *Graph, m *module.Tree) error {
+func (t *OutputTransformer) transform(
+	g *Graph, m *module.Tree, s *outputSensitivity) error {
 	// If no config, no outputs
 	if m == nil {
 		return nil
@@ -28,7 +38,7 @@ func (t *OutputTransformer) transform(g *Graph, m *module.Tree) error {
 	// we can reference module outputs and they must show up in the
 	// reference map.
 	for _, c := range m.Children() {
-		if err := t.transform(g, c); err != nil {
+		if err := t.transform(g, c, s); err != nil {
 			return err
 		}
 	}
@@ -47,8 +57,10 @@ func (t *OutputTransformer) transform(g *Graph, m *module.Tree) error {
 		// new graph builders for the other operations I suspect we'll
 		// find a way to parameterize this, require new transforms, etc.
 		node := &NodeApplyableOutput{
-			PathValue: normalizeModulePath(m.Path()),
-			Config:    o,
+			PathValue:    normalizeModulePath(m.Path()),
+			Config:       o,
+			Sensitive:    s.Output(m.Path(), o),
+			KeepPrevious: t.KeepPrevious,
 		}
 
 		// Add it!
@@ -57,3 +69,125 @@ func (t *OutputTransformer) transform(g *Graph, m *module.Tree) error {
 
 	return nil
 }
+
+// outputSensitivity determines whether outputs must be treated as
+// sensitive. An output is sensitive if it is marked as such, or if its
+// value is derived from a sensitive variable or from a sensitive output of
+// a child module. Variables of child modules are followed to the values
+// the parent module passes in, so sensitivity propagates through any
+// number of module levels.
+type outputSensitivity struct {
+	Root *module.Tree
+
+	// seen tracks what is being checked to avoid looping on cycles in
+	// the configuration, which are reported elsewhere.
+	seen map[string]struct{}
+}
+
+// Output returns true if the output o of the module at path (not including
+// "root") is sensitive.
+func (s *outputSensitivity) Output(path []string, o *config.Output) bool {
+	if o.Sensitive {
+		return true
+	}
+
+	return s.check(fmt.Sprintf("%s/output.%s", strings.Join(path, "."), o.Name),
+		func() bool { return s.raw(path, o.RawConfig) })
+}
+
+// raw returns true if raw, in the module at path, references anything
+// sensitive.
+func (s *outputSensitivity) raw(path []string, raw *config.RawConfig) bool {
+	if raw == nil {
+		return false
+	}
+
+	for _, v := range raw.Variables {
+		switch v := v.(type) {
+		case *config.UserVariable:
+			if s.variable(path, v.Name) {
+				return true
+			}
+		case *config.ModuleVariable:
+			if s.moduleOutput(append(path[:len(path):len(path)], v.Name), v.Field) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// variable returns true if the variable name of the module at path is
+// either marked sensitive or given a sensitive value by the parent module.
+func (s *outputSensitivity) variable(path []string, name string) bool {
+	return s.check(fmt.Sprintf("%s/var.%s", strings.Join(path, "."), name), func() bool {
+		tree := s.Root.Child(path)
+		if tree == nil {
+			return false
+		}
+		for _, v := range tree.Config().Variables {
+			if v.Name == name && v.Sensitive {
+				return true
+			}
+		}
+
+		// Follow the value given by the parent module, if any
+		if len(path) == 0 {
+			return false
+		}
+		parentPath := path[:len(path)-1]
+		parent := s.Root.Child(parentPath)
+		if parent == nil {
+			return false
+		}
+		for _, m := range parent.Config().Modules {
+			if m.Name != path[len(path)-1] {
+				continue
+			}
+
+			value, ok := m.RawConfig.Raw[name]
+			if !ok {
+				return false
+			}
+			raw, err := config.NewRawConfig(map[string]interface{}{name: value})
+			if err != nil {
+				return false
+			}
+
+			return s.raw(parentPath, raw)
+		}
+
+		return false
+	})
+}
+
+// moduleOutput returns true if the output name of the module at path is
+// sensitive.
+func (s *outputSensitivity) moduleOutput(path []string, name string) bool {
+	tree := s.Root.Child(path)
+	if tree == nil {
+		return false
+	}
+	for _, o := range tree.Config().Outputs {
+		if o.Name == name {
+			return s.Output(path, o)
+		}
+	}
+
+	return false
+}
+
+// check calls f unless key is already being checked further up the stack.
+func (s *outputSensitivity) check(key string, f func() bool) bool {
+	if s.seen == nil {
+		s.seen = make(map[string]struct{})
+	}
+	if _, ok := s.seen[key]; ok {
+		return false
+	}
+
+	s.seen[key] = struct{}{}
+	defer delete(s.seen, key)
+	return f()
+}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/hashicorp/go-multierror"
+	"github.com/hashicorp/terraform/config/module"
 	"github.com/hashicorp/terraform/dag"
 )
 
@@ -23,6 +24,14 @@ type GraphNodeCloseProvider interface {
 	CloseProviderName() string
 }
 
+// GraphNodeAttachParentProvider is an interface that provider nodes can
+// implement to be told the name of the provider in the parent module that
+// they inherit their configuration from, when a module call passes a
+// different provider to the module with "providers".
+type GraphNodeAttachParentProvider interface {
+	AttachParentProvider(string)
+}
+
 // GraphNodeProviderConsumer is an interface that nodes that require
 // a provider must implement. ProvidedBy must return the name of the provider
 // to use.
@@ -118,6 +127,10 @@ type MissingProviderTransformer struct {
 
 	// Concrete, if set, overrides how the providers are made.
 	Concrete ConcreteProviderNodeFunc
+
+	// Module, if set, is the root module and is used to determine which
+	// parent providers are passed to modules with "providers".
+	Module *module.Tree
 }
 
 func (t *MissingProviderTransformer) Transform(g *Graph) error {
@@ -190,7 +203,7 @@ func (t *MissingProviderTransformer) Transform(g *Graph) error {
 				// add a dummy node to check to make sure that we add
 				// that parent provider.
 				check = append(check, &graphNodeProviderConsumerDummy{
-					ProviderValue: p,
+					ProviderValue: parentProviderName(t.Module, path, p),
 					PathValue:     path[:len(path)-1],
 				})
 			}
@@ -207,7 +220,14 @@ func (t *MissingProviderTransformer) Transform(g *Graph) error {
 // This works by finding nodes that are both GraphNodeProviders and
 // GraphNodeSubPath. It then connects the providers to their parent
 // path.
-type ParentProviderTransformer struct{}
+//
+// If Module is set, the "providers" passed to each module call are used
+// to find the parent, so a child "aws" provider may have a parent of
+// "aws.west". Nodes implementing GraphNodeAttachParentProvider are told
+// about such parents so they can inherit the right configuration.
+type ParentProviderTransformer struct {
+	Module *module.Tree // Module is the root module for the config
+}
 
 func (t *ParentProviderTransformer) Transform(g *Graph) error {
 	// Make a mapping of path to dag.Vertex, where path is: "path.name"
@@ -243,8 +263,15 @@ func (t *ParentProviderTransformer) Transform(g *Graph) error {
 		// Determine the parent if we're non-root. This is length 1 since
 		// the 0 index should be "root" since we normalize above.
 		if len(path) > 1 {
+			parentName := parentProviderName(t.Module, path, pn.ProviderName())
+			if parentName != pn.ProviderName() {
+				if apn, ok := raw.(GraphNodeAttachParentProvider); ok {
+					apn.AttachParentProvider(parentName)
+				}
+			}
+
 			path = path[:len(path)-1]
-			key := fmt.Sprintf("%s.%s", strings.Join(path, "."), pn.ProviderName())
+			key := fmt.Sprintf("%s.%s", strings.Join(path, "."), parentName)
 			parentMap[raw] = key
 		}
 	}
@@ -296,6 +323,36 @@ func providerMapKey(k string, v dag.Vertex) string {
 	return pathPrefix + k
 }
 
+// parentProviderName returns the name of the provider in the parent module
+// that the provider with the given name in the module at path inherits its
+// configuration from. This is the same name unless the module call passes
+// a different provider with "providers".
+func parentProviderName(root *module.Tree, path []string, name string) string {
+	path = normalizeModulePath(path)
+	if root == nil || len(path) < 2 {
+		return name
+	}
+
+	parent := root.Child(path[1 : len(path)-1])
+	if parent == nil {
+		return name
+	}
+
+	for _, m := range parent.Config().Modules {
+		if m.Name != path[len(path)-1] {
+			continue
+		}
+
+		if v, ok := m.Providers[name]; ok {
+			return v
+		}
+
+		break
+	}
+
+	return name
+}
+
 func providerVertexMap(g *Graph) map[string]dag.Vertex {
 	m := make(map[string]dag.Vertex)
 	for _, v := range g.Vertices() {
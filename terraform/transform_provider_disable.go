@@ -10,6 +10,10 @@ import (
 // used by anything. This avoids the provider being initialized and configured.
 // This both saves resources but also avoids errors since configuration
 // may imply initialization which may require auth.
+//
+// This runs before targeting in the graph builders. Providers that are
+// only used by untargeted resources are removed by TargetsTransformer,
+// since providers are RemovableIfNotTargeted.
 type DisableProviderTransformer struct{}
 
 func (t *DisableProviderTransformer) Transform(g *Graph) error {
@@ -185,6 +185,36 @@ func (m *ReferenceMap) prefix(v dag.Vertex) string {
 	return prefix
 }
 
+// countPrefix is like prefix, but for vertices in an instance of a module
+// with a count it returns the prefix with the name of the module rather
+// than that of the instance, so that "module.foo.*.bar" references the
+// vertices of every instance of module "foo". It's empty otherwise.
+func (m *ReferenceMap) countPrefix(v dag.Vertex) string {
+	if gn, ok := v.(GraphNodeReferenceGlobal); ok && gn.ReferenceGlobal() {
+		return ""
+	}
+
+	pn, ok := v.(GraphNodeSubPath)
+	if !ok {
+		return ""
+	}
+
+	path := normalizeModulePath(pn.Path())
+	if len(path) < 2 {
+		return ""
+	}
+
+	name, index := config.ParseModuleInstanceName(path[len(path)-1])
+	if index == -1 {
+		return ""
+	}
+
+	countPath := make([]string, len(path))
+	copy(countPath, path)
+	countPath[len(countPath)-1] = name
+	return modulePrefixStr(countPath) + "."
+}
+
 // NewReferenceMap is used to create a new reference map for the
 // given set of vertices.
 func NewReferenceMap(vs []dag.Vertex) *ReferenceMap {
@@ -201,9 +231,12 @@ func NewReferenceMap(vs []dag.Vertex) *ReferenceMap {
 
 		// Go through and cache them
 		prefix := m.prefix(v)
+		countPrefix := m.countPrefix(v)
 		for _, n := range rn.ReferenceableName() {
-			n = prefix + n
-			refMap[n] = append(refMap[n], v)
+			refMap[prefix+n] = append(refMap[prefix+n], v)
+			if countPrefix != "" {
+				refMap[countPrefix+n] = append(refMap[countPrefix+n], v)
+			}
 		}
 
 		// If there is a path, it is always referenceable by that. For
@@ -252,6 +285,15 @@ func ReferenceModulePath(p []string) []string {
 	result := make([]string, 0, len(p)-1)
 	for i := len(p); i > 1; i-- {
 		result = append(result, modulePrefixStr(p[:i]))
+
+		// An instance of a module with a count is also part of the
+		// module as a whole, such as for depends_on = ["module.foo"].
+		if name, index := config.ParseModuleInstanceName(p[i-1]); index != -1 {
+			countPath := make([]string, i)
+			copy(countPath, p[:i])
+			countPath[i-1] = name
+			result = append(result, modulePrefixStr(countPath))
+		}
 	}
 
 	return result
@@ -275,7 +317,9 @@ func ReferencesFromConfig(c *config.RawConfig) []string {
 func ReferenceFromInterpolatedVar(v config.InterpolatedVariable) []string {
 	switch v := v.(type) {
 	case *config.ModuleVariable:
-		return []string{fmt.Sprintf("module.%s.output.%s", v.Name, v.Field)}
+		// A splat depends on the output of every instance of the module,
+		// which are all referenceable by the module's name.
+		return []string{fmt.Sprintf("module.%s.output.%s", v.InstanceName(), v.Field)}
 	case *config.ResourceVariable:
 		id := v.ResourceId()
 
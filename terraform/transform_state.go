@@ -16,6 +16,13 @@ type StateTransformer struct {
 	Concrete ConcreteResourceNodeFunc
 
 	State *State
+
+	// Deposed, if true, also adds a destroy node for every deposed
+	// instance in the state. Each of these depends on the node for the
+	// resource, which is added even if there is no primary instance, so
+	// that the deposed instance is only destroyed once its replacement
+	// has been handled.
+	Deposed bool
 }
 
 func (t *StateTransformer) Transform(g *Graph) error {
@@ -27,6 +34,7 @@ func (t *StateTransformer) Transform(g *Graph) error {
 	// Go through all the modules in the diff.
 	log.Printf("[TRACE] StateTransformer: starting")
 	var nodes []dag.Vertex
+	var deps []dag.Edge
 	for _, ms := range t.State.Modules {
 		log.Printf("[TRACE] StateTransformer: Module: %v", ms.Path)
 
@@ -53,6 +61,27 @@ func (t *StateTransformer) Transform(g *Graph) error {
 			}
 
 			nodes = append(nodes, node)
+
+			if !t.Deposed {
+				continue
+			}
+
+			for i := range rs.Deposed {
+				log.Printf("[TRACE] StateTransformer: Deposed %q #%d", name, i)
+				deposed := &graphNodeDeposedResource{
+					Index:        i,
+					ResourceName: name,
+					ResourceType: rs.Type,
+					Provider:     rs.Provider,
+					PathValue:    addr.Path,
+				}
+
+				// The node for the resource is added even without a primary
+				// instance, and its evaluation writes the diff of the resource,
+				// so the deposed instances always come after it.
+				nodes = append(nodes, deposed)
+				deps = append(deps, dag.BasicEdge(deposed, node))
+			}
 		}
 	}
 
@@ -61,5 +90,10 @@ func (t *StateTransformer) Transform(g *Graph) error {
 		g.Add(n)
 	}
 
+	// Deposed instances are destroyed after their replacement
+	for _, e := range deps {
+		g.Connect(e)
+	}
+
 	return nil
 }
@@ -0,0 +1,107 @@
+package terraform
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStateTransformer(t *testing.T) {
+	g := Graph{Path: RootModulePath}
+	tf := &StateTransformer{State: testStateTransformerDeposedState()}
+	if err := tf.Transform(&g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testStateTransformerStr)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+}
+
+func TestStateTransformer_deposed(t *testing.T) {
+	g := Graph{Path: RootModulePath}
+	tf := &StateTransformer{
+		State:   testStateTransformerDeposedState(),
+		Deposed: true,
+	}
+	if err := tf.Transform(&g); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	actual := strings.TrimSpace(g.String())
+	expected := strings.TrimSpace(testStateTransformerDeposedStr)
+	if actual != expected {
+		t.Fatalf("bad:\n\n%s", actual)
+	}
+
+	// The deposed node must be evaluated within the resource's module
+	for _, v := range g.Vertices() {
+		n, ok := v.(*graphNodeDeposedResource)
+		if !ok || n.ResourceName != "aws_instance.bar" {
+			continue
+		}
+
+		if p := n.Path(); len(p) != 1 || p[0] != "child" {
+			t.Fatalf("bad path: %#v", p)
+		}
+	}
+}
+
+func testStateTransformerDeposedState() *State {
+	return &State{
+		Modules: []*ModuleState{
+			&ModuleState{
+				Path: RootModulePath,
+				Resources: map[string]*ResourceState{
+					"aws_instance.foo": &ResourceState{
+						Type:    "aws_instance",
+						Primary: &InstanceState{ID: "foo"},
+						Deposed: []*InstanceState{
+							&InstanceState{ID: "old"},
+						},
+					},
+					"aws_instance.gone": &ResourceState{
+						Type: "aws_instance",
+						Deposed: []*InstanceState{
+							&InstanceState{ID: "old"},
+						},
+					},
+				},
+			},
+			&ModuleState{
+				Path: []string{"root", "child"},
+				Resources: map[string]*ResourceState{
+					"aws_instance.bar": &ResourceState{
+						Type:    "aws_instance",
+						Primary: &InstanceState{ID: "bar"},
+						Deposed: []*InstanceState{
+							&InstanceState{ID: "old1"},
+							&InstanceState{ID: "old2"},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+const testStateTransformerStr = `
+aws_instance.foo
+aws_instance.gone
+module.child.aws_instance.bar
+`
+
+const testStateTransformerDeposedStr = `
+aws_instance.foo
+aws_instance.foo (deposed #0)
+  aws_instance.foo
+aws_instance.gone
+aws_instance.gone (deposed #0)
+  aws_instance.gone
+module.child.aws_instance.bar
+module.child.aws_instance.bar (deposed #0)
+  module.child.aws_instance.bar
+module.child.aws_instance.bar (deposed #1)
+  module.child.aws_instance.bar
+`
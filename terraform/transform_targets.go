@@ -1,6 +1,7 @@
 package terraform
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/hashicorp/terraform/dag"
@@ -29,6 +30,21 @@ type TargetsTransformer struct {
 	// Set to true when we're in a `terraform destroy` or a
 	// `terraform plan -destroy`
 	Destroy bool
+
+	// Depth, if non-nil, limits how far from a target dependencies are
+	// followed. Only resources count towards the depth, so a depth of 1
+	// keeps the resources a target directly references and a depth of 0
+	// keeps only the targets themselves. Resources further away are
+	// assumed to already be applied and are removed from the graph.
+	Depth *int
+
+	// Modules are module addresses specified by the user, such as
+	// "module.foo". Every resource within a targeted module is targeted,
+	// including the resources of its nested child modules.
+	Modules []string
+
+	// parsed module paths of Modules, without the root
+	modulePaths [][]string
 }
 
 func (t *TargetsTransformer) Transform(g *Graph) error {
@@ -41,7 +57,16 @@ func (t *TargetsTransformer) Transform(g *Graph) error {
 		t.ParsedTargets = addrs
 	}
 
-	if len(t.ParsedTargets) > 0 {
+	if len(t.Modules) > 0 && len(t.modulePaths) == 0 {
+		paths, err := t.parseTargetModules()
+		if err != nil {
+			return err
+		}
+
+		t.modulePaths = paths
+	}
+
+	if len(t.ParsedTargets) > 0 || len(t.modulePaths) > 0 {
 		targetedNodes, err := t.selectTargetedNodes(g, t.ParsedTargets)
 		if err != nil {
 			return err
@@ -78,6 +103,24 @@ func (t *TargetsTransformer) parseTargetAddresses() ([]ResourceAddress, error) {
 	return addrs, nil
 }
 
+func (t *TargetsTransformer) parseTargetModules() ([][]string, error) {
+	paths := make([][]string, len(t.Modules))
+	for i, target := range t.Modules {
+		ta, err := ParseResourceAddress(target)
+		if err != nil {
+			return nil, err
+		}
+		if ta.Type != "" || len(ta.Path) == 0 {
+			return nil, fmt.Errorf(
+				"%q is not a module address, expected an address like \"module.foo\"",
+				target)
+		}
+		paths[i] = ta.Path
+	}
+
+	return paths, nil
+}
+
 // Returns the list of targeted nodes. A targeted node is either addressed
 // directly, or is an Ancestor of a targeted node. Destroy mode keeps
 // Descendents instead of Ancestors.
@@ -85,19 +128,28 @@ func (t *TargetsTransformer) selectTargetedNodes(
 	g *Graph, addrs []ResourceAddress) (*dag.Set, error) {
 	targetedNodes := new(dag.Set)
 	for _, v := range g.Vertices() {
-		if t.nodeIsTarget(v, addrs) {
+		nodeAddrs := addrs
+		if modAddr := t.nodeTargetModule(v); modAddr != nil {
+			// Target everything within the node's own module so that
+			// all its instances are kept when it dynamically expands.
+			nodeAddrs = append(addrs[:len(addrs):len(addrs)], *modAddr)
+		}
+
+		if t.nodeIsTarget(v, nodeAddrs) {
 			targetedNodes.Add(v)
 
 			// We inform nodes that ask about the list of targets - helps for nodes
 			// that need to dynamically expand. Note that this only occurs for nodes
 			// that are already directly targeted.
 			if tn, ok := v.(GraphNodeTargetable); ok {
-				tn.SetTargets(addrs)
+				tn.SetTargets(nodeAddrs)
 			}
 
 			var deps *dag.Set
 			var err error
-			if t.Destroy {
+			if t.Depth != nil {
+				deps = t.boundedDependencies(g, v, *t.Depth)
+			} else if t.Destroy {
 				deps, err = g.Descendents(v)
 			} else {
 				deps, err = g.Ancestors(v)
@@ -115,6 +167,46 @@ func (t *TargetsTransformer) selectTargetedNodes(
 	return targetedNodes, nil
 }
 
+// Returns the dependencies of v (dependents in destroy mode) that are
+// within depth resources of v. Non-resource nodes such as variables and
+// providers are followed but don't add to the depth.
+func (t *TargetsTransformer) boundedDependencies(
+	g *Graph, v dag.Vertex, depth int) *dag.Set {
+	next := g.DownEdges
+	if t.Destroy {
+		next = g.UpEdges
+	}
+
+	result := new(dag.Set)
+	dist := map[dag.Vertex]int{v: 0}
+	queue := []dag.Vertex{v}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, raw := range next(current).List() {
+			d := dist[current]
+			if _, ok := raw.(GraphNodeResource); ok {
+				d++
+			}
+			if d > depth {
+				continue
+			}
+
+			// Only revisit a node if we found a shorter path to it
+			if old, ok := dist[raw]; ok && old <= d {
+				continue
+			}
+
+			dist[raw] = d
+			result.Add(raw)
+			queue = append(queue, raw)
+		}
+	}
+
+	return result
+}
+
 func (t *TargetsTransformer) nodeIsTarget(
 	v dag.Vertex, addrs []ResourceAddress) bool {
 	r, ok := v.(GraphNodeResource)
@@ -132,6 +224,40 @@ func (t *TargetsTransformer) nodeIsTarget(
 	return false
 }
 
+// nodeTargetModule returns an address matching every resource in the same
+// module as v if that module is within one of the targeted modules, or nil
+// if it isn't.
+func (t *TargetsTransformer) nodeTargetModule(v dag.Vertex) *ResourceAddress {
+	r, ok := v.(GraphNodeResource)
+	if !ok {
+		return nil
+	}
+
+	addr := r.ResourceAddr()
+	for _, path := range t.modulePaths {
+		if len(addr.Path) < len(path) {
+			continue
+		}
+
+		match := true
+		for i, name := range path {
+			if addr.Path[i] != name {
+				match = false
+				break
+			}
+		}
+		if match {
+			return &ResourceAddress{
+				Path:         addr.Path,
+				Index:        -1,
+				InstanceType: addr.InstanceType,
+			}
+		}
+	}
+
+	return nil
+}
+
 // RemovableIfNotTargeted is a special interface for graph nodes that
 // aren't directly addressable, but need to be removed from the graph when they
 // are not targeted. (Nodes that are not directly targeted end up in the set of
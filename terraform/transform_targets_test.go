@@ -50,6 +50,64 @@ aws_vpc.me
 	}
 }
 
+func TestTargetsTransformer_depth(t *testing.T) {
+	cases := []struct {
+		Depth    *int
+		Expected string
+	}{
+		{
+			testIntPtr(0),
+			`
+aws_instance.me
+			`,
+		},
+		{
+			testIntPtr(1),
+			`
+aws_instance.me
+  aws_subnet.me
+aws_subnet.me
+			`,
+		},
+		{
+			nil,
+			`
+aws_instance.me
+  aws_subnet.me
+aws_subnet.me
+  aws_vpc.me
+aws_vpc.me
+			`,
+		},
+	}
+
+	for i, tc := range cases {
+		mod := testModule(t, "transform-targets-basic")
+
+		g := Graph{Path: RootModulePath}
+		steps := []GraphTransformer{
+			&ConfigTransformer{Module: mod},
+			&AttachResourceConfigTransformer{Module: mod},
+			&ReferenceTransformer{},
+			&TargetsTransformer{
+				Targets: []string{"aws_instance.me"},
+				Depth:   tc.Depth,
+			},
+		}
+		for _, step := range steps {
+			if err := step.Transform(&g); err != nil {
+				t.Fatalf("%d: err: %s", i, err)
+			}
+		}
+
+		actual := strings.TrimSpace(g.String())
+		expected := strings.TrimSpace(tc.Expected)
+		if actual != expected {
+			t.Fatalf("%d: bad:\n\nexpected:\n%s\n\ngot:\n%s\n", i, expected, actual)
+		}
+	}
+}
+
 func TestTargetsTransformer_destroy(t *testing.T) {
 	mod := testModule(t, "transform-targets-destroy")
 
@@ -1,7 +1,10 @@
 package terraform
 
 import (
+	"log"
+
 	"github.com/hashicorp/terraform/config/module"
+	"github.com/hashicorp/terraform/dag"
 )
 
 // RootVariableTransformer is a GraphTransformer that adds all the root
@@ -38,3 +41,34 @@ func (t *RootVariableTransformer) Transform(g *Graph) error {
 
 	return nil
 }
+
+// UnusedRootVariableTransformer is a GraphTransformer that adds a
+// NodeUnusedVariable for each root variable that nothing in the graph
+// references, so that it is reported during validation.
+//
+// This must run after everything that can reference a root variable,
+// including module variables, has been added to the graph.
+type UnusedRootVariableTransformer struct{}
+
+func (t *UnusedRootVariableTransformer) Transform(g *Graph) error {
+	refMap := NewReferenceMap(g.Vertices())
+	for _, v := range g.Vertices() {
+		node, ok := v.(*NodeRootVariable)
+		if !ok {
+			continue
+		}
+
+		if matches := refMap.ReferencedBy(node); len(matches) == 0 {
+			log.Printf(
+				"[INFO] Root variable %q is not referenced",
+				dag.VertexName(node))
+
+			g.Add(&NodeUnusedVariable{
+				PathValue: RootModulePath,
+				Config:    node.Config,
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package terraform
+
+import (
+	"strings"
+	"sync"
+)
+
+// lineUIOutput is an implementation of UIOutput that buffers the output it
+// is given and calls OutputFn once for every complete line, without the
+// line ending. Output may be called concurrently.
+//
+// Any output after the last line ending is held until Flush is called.
+type lineUIOutput struct {
+	OutputFn func(string)
+
+	mu  sync.Mutex
+	buf string
+}
+
+func (o *lineUIOutput) Output(v string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf += v
+	for {
+		idx := strings.IndexByte(o.buf, '\n')
+		if idx < 0 {
+			break
+		}
+
+		line := strings.TrimSuffix(o.buf[:idx], "\r")
+		o.buf = o.buf[idx+1:]
+		o.OutputFn(line)
+	}
+}
+
+// Flush calls OutputFn with any output that didn't end in a line ending.
+func (o *lineUIOutput) Flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.buf != "" {
+		line := strings.TrimSuffix(o.buf, "\r")
+		o.buf = ""
+		o.OutputFn(line)
+	}
+}
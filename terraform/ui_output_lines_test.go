@@ -0,0 +1,72 @@
+package terraform
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestLineUIOutput_impl(t *testing.T) {
+	var _ UIOutput = new(lineUIOutput)
+}
+
+func TestLineUIOutput(t *testing.T) {
+	var lines []string
+	output := &lineUIOutput{
+		OutputFn: func(line string) {
+			lines = append(lines, line)
+		},
+	}
+
+	output.Output("one\ntw")
+	output.Output("o\r\n\nthr")
+	output.Output("ee")
+	if expected := []string{"one", "two", ""}; !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("bad: %#v", lines)
+	}
+
+	output.Flush()
+	if expected := []string{"one", "two", "", "three"}; !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("bad: %#v", lines)
+	}
+
+	// Nothing is left to flush
+	output.Flush()
+	if len(lines) != 4 {
+		t.Fatalf("bad: %#v", lines)
+	}
+}
+
+func TestLineUIOutput_concurrent(t *testing.T) {
+	var lock sync.Mutex
+	var lines []string
+	output := &lineUIOutput{
+		OutputFn: func(line string) {
+			lock.Lock()
+			defer lock.Unlock()
+			lines = append(lines, line)
+		},
+	}
+
+	var expected []string
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		line := fmt.Sprintf("line %d", i)
+		expected = append(expected, line)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			output.Output(line + "\n")
+		}()
+	}
+	wg.Wait()
+	output.Flush()
+
+	sort.Strings(lines)
+	if !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("bad: %#v", lines)
+	}
+}